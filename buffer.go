@@ -0,0 +1,128 @@
+// Copyright (c) 2020-2023 Ozan Hacıbekiroğlu.
+// Use of this source code is governed by a MIT License
+// that can be found in the LICENSE file.
+
+package ugo
+
+import (
+	"bytes"
+)
+
+// Buffer represents a mutable in-memory byte buffer and implements Object,
+// NameCallerObject and io.Writer interfaces. Buffer objects are created with
+// buffer builtin function and they can be used as writer of fprintf and
+// fprintln builtin functions. Buffer is not safe for concurrent use.
+type Buffer struct {
+	ObjectImpl
+	Value bytes.Buffer
+}
+
+var (
+	_ NameCallerObject = (*Buffer)(nil)
+	_ LengthGetter     = (*Buffer)(nil)
+	_ Copier           = (*Buffer)(nil)
+)
+
+// TypeName implements Object interface.
+func (*Buffer) TypeName() string {
+	return "buffer"
+}
+
+// String implements Object interface and returns the unread contents of the
+// buffer.
+func (o *Buffer) String() string {
+	return o.Value.String()
+}
+
+// IsFalsy implements Object interface.
+func (o *Buffer) IsFalsy() bool {
+	return o.Value.Len() == 0
+}
+
+// Equal implements Object interface.
+func (o *Buffer) Equal(right Object) bool {
+	v, ok := right.(*Buffer)
+	return ok && v == o
+}
+
+// Copy implements Copier interface.
+func (o *Buffer) Copy() Object {
+	cp := &Buffer{}
+	cp.Value.Write(o.Value.Bytes())
+	return cp
+}
+
+// Len implements LengthGetter interface.
+func (o *Buffer) Len() int {
+	return o.Value.Len()
+}
+
+// Write implements io.Writer interface.
+func (o *Buffer) Write(p []byte) (int, error) {
+	return o.Value.Write(p)
+}
+
+// CallName implements NameCallerObject interface.
+func (o *Buffer) CallName(name string, c Call) (Object, error) {
+	fn, ok := bufferMethods[name]
+	if !ok {
+		return Undefined, ErrInvalidIndex.NewError(name)
+	}
+	return fn(o, &c)
+}
+
+var bufferMethods = map[string]func(*Buffer, *Call) (Object, error){
+	"write": func(o *Buffer, c *Call) (Object, error) {
+		if err := c.CheckLen(1); err != nil {
+			return Undefined, err
+		}
+		var n int
+		switch v := c.Get(0).(type) {
+		case Bytes:
+			n, _ = o.Value.Write(v)
+		case String:
+			n, _ = o.Value.WriteString(string(v))
+		default:
+			return Undefined, NewArgumentTypeError(
+				"1st", "bytes|string", v.TypeName())
+		}
+		return Int(n), nil
+	},
+	"writeString": func(o *Buffer, c *Call) (Object, error) {
+		if err := c.CheckLen(1); err != nil {
+			return Undefined, err
+		}
+		s, ok := c.Get(0).(String)
+		if !ok {
+			return Undefined, NewArgumentTypeError(
+				"1st", "string", c.Get(0).TypeName())
+		}
+		n, _ := o.Value.WriteString(string(s))
+		return Int(n), nil
+	},
+	"bytes": func(o *Buffer, c *Call) (Object, error) {
+		if err := c.CheckLen(0); err != nil {
+			return Undefined, err
+		}
+		return Bytes(o.Value.Bytes()).Copy(), nil
+	},
+	"string": func(o *Buffer, c *Call) (Object, error) {
+		if err := c.CheckLen(0); err != nil {
+			return Undefined, err
+		}
+		return String(o.Value.String()), nil
+	},
+	"len": func(o *Buffer, c *Call) (Object, error) {
+		if err := c.CheckLen(0); err != nil {
+			return Undefined, err
+		}
+		return Int(o.Value.Len()), nil
+	},
+	"reset": func(o *Buffer, c *Call) (Object, error) {
+		if err := c.CheckLen(0); err != nil {
+			return Undefined, err
+		}
+		o.Value.Reset()
+		return Undefined, nil
+	},
+}
@@ -6,13 +6,19 @@ package ugo
 
 import (
 	"bytes"
+	"container/list"
 	"errors"
 	"fmt"
 	"io"
+	"math"
 	"os"
+	"reflect"
+	"regexp"
 	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"time"
 	"unicode/utf8"
 
 	"github.com/ozanh/ugo/token"
@@ -80,32 +86,148 @@ const (
 
 	BuiltinMakeArray
 	BuiltinCap
+	BuiltinZip
+	BuiltinUnzip
+	BuiltinGroupBy
+	BuiltinCountBy
+	BuiltinUnique
+	BuiltinMatchAll
+	BuiltinReplaceAll
+	BuiltinDump
+	BuiltinExit
+	BuiltinLog
+	BuiltinDebug
+	BuiltinInfo
+	BuiltinWarn
+	BuiltinIntExact
+	BuiltinSortByKeys
+	BuiltinSearchSorted
+	BuiltinInsertSorted
+	BuiltinReverse
+	BuiltinFill
+	BuiltinConcat
+	BuiltinMerge
+	BuiltinDeepMerge
+	BuiltinPick
+	BuiltinOmit
+	BuiltinGetPath
+	BuiltinSetPath
+	BuiltinIsValidChar
+	BuiltinMakeArrayCap
+	BuiltinMakeMap
+	BuiltinTryDiv
+	BuiltinTryMod
+	BuiltinClone
+	BuiltinSyncMap
+	BuiltinUnsyncMap
+	BuiltinFreeze
+	BuiltinSame
+	BuiltinFprintf
+	BuiltinFprintln
+	BuiltinBuffer
+	BuiltinErrorKind
+	BuiltinRetry
+	BuiltinMemoize
+	BuiltinLazy
+	BuiltinDiff
+	BuiltinToInt8
+	BuiltinToInt16
+	BuiltinToInt32
+	BuiltinToUint8
+	BuiltinToUint16
+	BuiltinToUint32
+	BuiltinPack
+	BuiltinUnpack
+	BuiltinGlobalsSnapshot
+	BuiltinTransaction
+	BuiltinSizeOf
+	BuiltinEnumerate
+	BuiltinChunk
+	BuiltinWindows
 )
 
 // BuiltinsMap is list of builtin types, exported for REPL.
 var BuiltinsMap = map[string]BuiltinType{
-	"append":      BuiltinAppend,
-	"delete":      BuiltinDelete,
-	"copy":        BuiltinCopy,
-	"repeat":      BuiltinRepeat,
-	"contains":    BuiltinContains,
-	"len":         BuiltinLen,
-	"sort":        BuiltinSort,
-	"sortReverse": BuiltinSortReverse,
-	"error":       BuiltinError,
-	"typeName":    BuiltinTypeName,
-	"bool":        BuiltinBool,
-	"int":         BuiltinInt,
-	"uint":        BuiltinUint,
-	"float":       BuiltinFloat,
-	"char":        BuiltinChar,
-	"string":      BuiltinString,
-	"bytes":       BuiltinBytes,
-	"chars":       BuiltinChars,
-	"printf":      BuiltinPrintf,
-	"println":     BuiltinPrintln,
-	"sprintf":     BuiltinSprintf,
-	"globals":     BuiltinGlobals,
+	"append":          BuiltinAppend,
+	"delete":          BuiltinDelete,
+	"copy":            BuiltinCopy,
+	"clone":           BuiltinClone,
+	"freeze":          BuiltinFreeze,
+	"same":            BuiltinSame,
+	"repeat":          BuiltinRepeat,
+	"zip":             BuiltinZip,
+	"enumerate":       BuiltinEnumerate,
+	"chunk":           BuiltinChunk,
+	"windows":         BuiltinWindows,
+	"unzip":           BuiltinUnzip,
+	"reverse":         BuiltinReverse,
+	"fill":            BuiltinFill,
+	"concat":          BuiltinConcat,
+	"merge":           BuiltinMerge,
+	"deepMerge":       BuiltinDeepMerge,
+	"pick":            BuiltinPick,
+	"omit":            BuiltinOmit,
+	"getPath":         BuiltinGetPath,
+	"setPath":         BuiltinSetPath,
+	"diff":            BuiltinDiff,
+	"isValidChar":     BuiltinIsValidChar,
+	"makeArray":       BuiltinMakeArrayCap,
+	"makeMap":         BuiltinMakeMap,
+	"syncMap":         BuiltinSyncMap,
+	"unsyncMap":       BuiltinUnsyncMap,
+	"tryDiv":          BuiltinTryDiv,
+	"tryMod":          BuiltinTryMod,
+	"groupBy":         BuiltinGroupBy,
+	"countBy":         BuiltinCountBy,
+	"unique":          BuiltinUnique,
+	"matchAll":        BuiltinMatchAll,
+	"replaceAll":      BuiltinReplaceAll,
+	"contains":        BuiltinContains,
+	"len":             BuiltinLen,
+	"sort":            BuiltinSort,
+	"sortReverse":     BuiltinSortReverse,
+	"sortByKeys":      BuiltinSortByKeys,
+	"searchSorted":    BuiltinSearchSorted,
+	"insertSorted":    BuiltinInsertSorted,
+	"error":           BuiltinError,
+	"errorKind":       BuiltinErrorKind,
+	"retry":           BuiltinRetry,
+	"transaction":     BuiltinTransaction,
+	"memoize":         BuiltinMemoize,
+	"lazy":            BuiltinLazy,
+	"typeName":        BuiltinTypeName,
+	"bool":            BuiltinBool,
+	"int":             BuiltinInt,
+	"intExact":        BuiltinIntExact,
+	"uint":            BuiltinUint,
+	"toInt8":          BuiltinToInt8,
+	"toInt16":         BuiltinToInt16,
+	"toInt32":         BuiltinToInt32,
+	"toUint8":         BuiltinToUint8,
+	"toUint16":        BuiltinToUint16,
+	"toUint32":        BuiltinToUint32,
+	"pack":            BuiltinPack,
+	"unpack":          BuiltinUnpack,
+	"float":           BuiltinFloat,
+	"char":            BuiltinChar,
+	"string":          BuiltinString,
+	"bytes":           BuiltinBytes,
+	"chars":           BuiltinChars,
+	"printf":          BuiltinPrintf,
+	"println":         BuiltinPrintln,
+	"fprintf":         BuiltinFprintf,
+	"fprintln":        BuiltinFprintln,
+	"buffer":          BuiltinBuffer,
+	"sprintf":         BuiltinSprintf,
+	"dump":            BuiltinDump,
+	"sizeOf":          BuiltinSizeOf,
+	"globals":         BuiltinGlobals,
+	"globalsSnapshot": BuiltinGlobalsSnapshot,
+	"exit":            BuiltinExit,
+	"log":             BuiltinLog,
+	"debug":           BuiltinDebug,
+	"info":            BuiltinInfo,
+	"warn":            BuiltinWarn,
 
 	"isError":     BuiltinIsError,
 	"isInt":       BuiltinIsInt,
@@ -161,16 +283,135 @@ var BuiltinObjects = [...]Object{
 		Value:   funcPORO(builtinCopyFunc),
 		ValueEx: funcPOROEx(builtinCopyFunc),
 	},
+	BuiltinClone: &BuiltinFunction{
+		Name:    "clone",
+		Value:   funcPORO(builtinCloneFunc),
+		ValueEx: funcPOROEx(builtinCloneFunc),
+	},
+	BuiltinFreeze: &BuiltinFunction{
+		Name:    "freeze",
+		Value:   funcPORO(builtinFreezeFunc),
+		ValueEx: funcPOROEx(builtinFreezeFunc),
+	},
+	BuiltinSame: &BuiltinFunction{
+		Name:    "same",
+		Value:   funcPOORO(builtinSameFunc),
+		ValueEx: funcPOOROEx(builtinSameFunc),
+	},
 	BuiltinRepeat: &BuiltinFunction{
 		Name:    "repeat",
 		Value:   funcPOiROe(builtinRepeatFunc),
 		ValueEx: funcPOiROeEx(builtinRepeatFunc),
 	},
+	BuiltinZip: &BuiltinFunction{
+		Name:    "zip",
+		Value:   callExAdapter(builtinZipFunc),
+		ValueEx: builtinZipFunc,
+	},
+	BuiltinUnzip: &BuiltinFunction{
+		Name:    "unzip",
+		Value:   funcPOROe(builtinUnzipFunc),
+		ValueEx: funcPOROeEx(builtinUnzipFunc),
+	},
+	BuiltinReverse: &BuiltinFunction{
+		Name:    "reverse",
+		Value:   funcPOROe(builtinReverseFunc),
+		ValueEx: funcPOROeEx(builtinReverseFunc),
+	},
+	BuiltinFill: &BuiltinFunction{
+		Name:    "fill",
+		Value:   callExAdapter(builtinFillFunc),
+		ValueEx: builtinFillFunc,
+	},
+	BuiltinConcat: &BuiltinFunction{
+		Name:    "concat",
+		Value:   callExAdapter(builtinConcatFunc),
+		ValueEx: builtinConcatFunc,
+	},
+	BuiltinMerge: &BuiltinFunction{
+		Name:    "merge",
+		Value:   callExAdapter(builtinMergeFunc),
+		ValueEx: builtinMergeFunc,
+	},
+	BuiltinDeepMerge: &BuiltinFunction{
+		Name:    "deepMerge",
+		Value:   callExAdapter(builtinDeepMergeFunc),
+		ValueEx: builtinDeepMergeFunc,
+	},
+	BuiltinPick: &BuiltinFunction{
+		Name:    "pick",
+		Value:   funcPOOROe(builtinPickFunc),
+		ValueEx: funcPOOROeEx(builtinPickFunc),
+	},
+	BuiltinOmit: &BuiltinFunction{
+		Name:    "omit",
+		Value:   funcPOOROe(builtinOmitFunc),
+		ValueEx: funcPOOROeEx(builtinOmitFunc),
+	},
+	BuiltinMakeArrayCap: &BuiltinFunction{
+		Name:    "makeArray",
+		Value:   callExAdapter(builtinMakeArrayCapFunc),
+		ValueEx: builtinMakeArrayCapFunc,
+	},
+	BuiltinMakeMap: &BuiltinFunction{
+		Name:    "makeMap",
+		Value:   callExAdapter(builtinMakeMapFunc),
+		ValueEx: builtinMakeMapFunc,
+	},
+	BuiltinSyncMap: &BuiltinFunction{
+		Name:    "syncMap",
+		Value:   funcPOROe(builtinSyncMapFunc),
+		ValueEx: funcPOROeEx(builtinSyncMapFunc),
+	},
+	BuiltinUnsyncMap: &BuiltinFunction{
+		Name:    "unsyncMap",
+		Value:   funcPOROe(builtinUnsyncMapFunc),
+		ValueEx: funcPOROeEx(builtinUnsyncMapFunc),
+	},
+	BuiltinTryDiv: &BuiltinFunction{
+		Name:    "tryDiv",
+		Value:   funcPOOROe(builtinTryDivFunc),
+		ValueEx: funcPOOROeEx(builtinTryDivFunc),
+	},
+	BuiltinTryMod: &BuiltinFunction{
+		Name:    "tryMod",
+		Value:   funcPOOROe(builtinTryModFunc),
+		ValueEx: funcPOOROeEx(builtinTryModFunc),
+	},
+	BuiltinDiff: &BuiltinFunction{
+		Name:    "diff",
+		Value:   funcPOORO(builtinDiffFunc),
+		ValueEx: funcPOOROEx(builtinDiffFunc),
+	},
+	BuiltinGetPath: &BuiltinFunction{
+		Name:    "getPath",
+		Value:   funcPOsRO(builtinGetPathFunc),
+		ValueEx: funcPOsROEx(builtinGetPathFunc),
+	},
+	BuiltinSetPath: &BuiltinFunction{
+		Name:    "setPath",
+		Value:   funcPOsORe(builtinSetPathFunc),
+		ValueEx: funcPOsOReEx(builtinSetPathFunc),
+	},
+	// Value and ValueEx of builtins calling Invoker are set in init
+	BuiltinGroupBy: &BuiltinFunction{Name: "groupBy"},
+	BuiltinCountBy: &BuiltinFunction{Name: "countBy"},
+	BuiltinUnique:  &BuiltinFunction{Name: "unique"},
 	BuiltinContains: &BuiltinFunction{
 		Name:    "contains",
 		Value:   funcPOOROe(builtinContainsFunc),
 		ValueEx: funcPOOROeEx(builtinContainsFunc),
 	},
+	BuiltinMatchAll: &BuiltinFunction{
+		Name:    "matchAll",
+		Value:   funcPOOROe(builtinMatchAllFunc),
+		ValueEx: funcPOOROeEx(builtinMatchAllFunc),
+	},
+	BuiltinReplaceAll: &BuiltinFunction{
+		Name:    "replaceAll",
+		Value:   callExAdapter(builtinReplaceAllFunc),
+		ValueEx: builtinReplaceAllFunc,
+	},
 	BuiltinLen: &BuiltinFunction{
 		Name:    "len",
 		Value:   funcPORO(builtinLenFunc),
@@ -191,10 +432,22 @@ var BuiltinObjects = [...]Object{
 		Value:   funcPOROe(builtinSortReverseFunc),
 		ValueEx: funcPOROeEx(builtinSortReverseFunc),
 	},
+	// Value and ValueEx of builtins calling Invoker are set in init
+	BuiltinSortByKeys:   &BuiltinFunction{Name: "sortByKeys"},
+	BuiltinSearchSorted: &BuiltinFunction{Name: "searchSorted"},
+	BuiltinInsertSorted: &BuiltinFunction{Name: "insertSorted"},
+	BuiltinRetry:        &BuiltinFunction{Name: "retry"},
+	BuiltinMemoize:      &BuiltinFunction{Name: "memoize"},
+	BuiltinLazy:         &BuiltinFunction{Name: "lazy"},
 	BuiltinError: &BuiltinFunction{
 		Name:    "error",
-		Value:   funcPORO(builtinErrorFunc),
-		ValueEx: funcPOROEx(builtinErrorFunc),
+		Value:   callExAdapter(builtinErrorFunc),
+		ValueEx: builtinErrorFunc,
+	},
+	BuiltinErrorKind: &BuiltinFunction{
+		Name:    "errorKind",
+		Value:   funcPOROe(builtinErrorKindFunc),
+		ValueEx: funcPOROeEx(builtinErrorKindFunc),
 	},
 	BuiltinTypeName: &BuiltinFunction{
 		Name:    "typeName",
@@ -211,11 +464,82 @@ var BuiltinObjects = [...]Object{
 		Value:   funcPi64RO(builtinIntFunc),
 		ValueEx: funcPi64ROEx(builtinIntFunc),
 	},
+	BuiltinIntExact: &BuiltinFunction{
+		Name:    "intExact",
+		Value:   funcPOROe(builtinIntExactFunc),
+		ValueEx: funcPOROeEx(builtinIntExactFunc),
+	},
 	BuiltinUint: &BuiltinFunction{
 		Name:    "uint",
 		Value:   funcPu64RO(builtinUintFunc),
 		ValueEx: funcPu64ROEx(builtinUintFunc),
 	},
+	BuiltinToInt8: &BuiltinFunction{
+		Name:    "toInt8",
+		Value:   funcPi64RO(builtinToInt8Func),
+		ValueEx: funcPi64ROEx(builtinToInt8Func),
+	},
+	BuiltinToInt16: &BuiltinFunction{
+		Name:    "toInt16",
+		Value:   funcPi64RO(builtinToInt16Func),
+		ValueEx: funcPi64ROEx(builtinToInt16Func),
+	},
+	BuiltinToInt32: &BuiltinFunction{
+		Name:    "toInt32",
+		Value:   funcPi64RO(builtinToInt32Func),
+		ValueEx: funcPi64ROEx(builtinToInt32Func),
+	},
+	BuiltinToUint8: &BuiltinFunction{
+		Name:    "toUint8",
+		Value:   funcPu64RO(builtinToUint8Func),
+		ValueEx: funcPu64ROEx(builtinToUint8Func),
+	},
+	BuiltinToUint16: &BuiltinFunction{
+		Name:    "toUint16",
+		Value:   funcPu64RO(builtinToUint16Func),
+		ValueEx: funcPu64ROEx(builtinToUint16Func),
+	},
+	BuiltinToUint32: &BuiltinFunction{
+		Name:    "toUint32",
+		Value:   funcPu64RO(builtinToUint32Func),
+		ValueEx: funcPu64ROEx(builtinToUint32Func),
+	},
+	BuiltinPack: &BuiltinFunction{
+		Name:    "pack",
+		Value:   callExAdapter(builtinPackFunc),
+		ValueEx: builtinPackFunc,
+	},
+	BuiltinUnpack: &BuiltinFunction{
+		Name:    "unpack",
+		Value:   callExAdapter(builtinUnpackFunc),
+		ValueEx: builtinUnpackFunc,
+	},
+	BuiltinGlobalsSnapshot: &BuiltinFunction{
+		Name:    "globalsSnapshot",
+		Value:   callExAdapter(builtinGlobalsSnapshotFunc),
+		ValueEx: builtinGlobalsSnapshotFunc,
+	},
+	BuiltinTransaction: &BuiltinFunction{Name: "transaction"},
+	BuiltinChunk: &BuiltinFunction{
+		Name:    "chunk",
+		Value:   funcPOiROe(builtinChunkFunc),
+		ValueEx: funcPOiROeEx(builtinChunkFunc),
+	},
+	BuiltinWindows: &BuiltinFunction{
+		Name:    "windows",
+		Value:   funcPOiROe(builtinWindowsFunc),
+		ValueEx: funcPOiROeEx(builtinWindowsFunc),
+	},
+	BuiltinEnumerate: &BuiltinFunction{
+		Name:    "enumerate",
+		Value:   funcPOROe(builtinEnumerateFunc),
+		ValueEx: funcPOROeEx(builtinEnumerateFunc),
+	},
+	BuiltinSizeOf: &BuiltinFunction{
+		Name:    "sizeOf",
+		Value:   funcPORO(builtinSizeOfFunc),
+		ValueEx: funcPOROEx(builtinSizeOfFunc),
+	},
 	BuiltinFloat: &BuiltinFunction{
 		Name:    "float",
 		Value:   funcPf64RO(builtinFloatFunc),
@@ -251,11 +575,56 @@ var BuiltinObjects = [...]Object{
 		Value:   callExAdapter(builtinPrintlnFunc),
 		ValueEx: builtinPrintlnFunc,
 	},
+	BuiltinFprintf: &BuiltinFunction{
+		Name:    "fprintf",
+		Value:   callExAdapter(builtinFprintfFunc),
+		ValueEx: builtinFprintfFunc,
+	},
+	BuiltinFprintln: &BuiltinFunction{
+		Name:    "fprintln",
+		Value:   callExAdapter(builtinFprintlnFunc),
+		ValueEx: builtinFprintlnFunc,
+	},
+	BuiltinBuffer: &BuiltinFunction{
+		Name:    "buffer",
+		Value:   callExAdapter(builtinBufferFunc),
+		ValueEx: builtinBufferFunc,
+	},
 	BuiltinSprintf: &BuiltinFunction{
 		Name:    "sprintf",
 		Value:   callExAdapter(builtinSprintfFunc),
 		ValueEx: builtinSprintfFunc,
 	},
+	BuiltinDump: &BuiltinFunction{
+		Name:    "dump",
+		Value:   funcPORO(builtinDumpFunc),
+		ValueEx: funcPOROEx(builtinDumpFunc),
+	},
+	BuiltinExit: &BuiltinFunction{
+		Name:    "exit",
+		Value:   callExAdapter(builtinExitFunc),
+		ValueEx: builtinExitFunc,
+	},
+	BuiltinLog: &BuiltinFunction{
+		Name:    "log",
+		Value:   callExAdapter(builtinLogFunc),
+		ValueEx: builtinLogFunc,
+	},
+	BuiltinDebug: &BuiltinFunction{
+		Name:    "debug",
+		Value:   callExAdapter(newLevelLogFunc(LogDebug)),
+		ValueEx: newLevelLogFunc(LogDebug),
+	},
+	BuiltinInfo: &BuiltinFunction{
+		Name:    "info",
+		Value:   callExAdapter(newLevelLogFunc(LogInfo)),
+		ValueEx: newLevelLogFunc(LogInfo),
+	},
+	BuiltinWarn: &BuiltinFunction{
+		Name:    "warn",
+		Value:   callExAdapter(newLevelLogFunc(LogWarn)),
+		ValueEx: newLevelLogFunc(LogWarn),
+	},
 	BuiltinGlobals: &BuiltinFunction{
 		Name:    "globals",
 		Value:   callExAdapter(builtinGlobalsFunc),
@@ -286,6 +655,11 @@ var BuiltinObjects = [...]Object{
 		Value:   funcPORO(builtinIsCharFunc),
 		ValueEx: funcPOROEx(builtinIsCharFunc),
 	},
+	BuiltinIsValidChar: &BuiltinFunction{
+		Name:    "isValidChar",
+		Value:   funcPORO(builtinIsValidCharFunc),
+		ValueEx: funcPOROEx(builtinIsValidCharFunc),
+	},
 	BuiltinIsBool: &BuiltinFunction{
 		Name:    "isBool",
 		Value:   funcPORO(builtinIsBoolFunc),
@@ -349,6 +723,27 @@ var BuiltinObjects = [...]Object{
 	BuiltinTypeError:               ErrType,
 }
 
+func init() {
+	// Builtins calling Invoker refer to VM which refers to BuiltinObjects, so
+	// they are set here to prevent initialization cycle.
+	setBuiltinFuncEx(BuiltinGroupBy, builtinGroupByFunc)
+	setBuiltinFuncEx(BuiltinCountBy, builtinCountByFunc)
+	setBuiltinFuncEx(BuiltinUnique, builtinUniqueFunc)
+	setBuiltinFuncEx(BuiltinSortByKeys, builtinSortByKeysFunc)
+	setBuiltinFuncEx(BuiltinSearchSorted, builtinSearchSortedFunc)
+	setBuiltinFuncEx(BuiltinInsertSorted, builtinInsertSortedFunc)
+	setBuiltinFuncEx(BuiltinRetry, builtinRetryFunc)
+	setBuiltinFuncEx(BuiltinMemoize, builtinMemoizeFunc)
+	setBuiltinFuncEx(BuiltinLazy, builtinLazyFunc)
+	setBuiltinFuncEx(BuiltinTransaction, builtinTransactionFunc)
+}
+
+func setBuiltinFuncEx(typ BuiltinType, fn CallableExFunc) {
+	f := BuiltinObjects[typ].(*BuiltinFunction)
+	f.Value = callExAdapter(fn)
+	f.ValueEx = fn
+}
+
 func builtinMakeArrayFunc(n int, arg Object) (Object, error) {
 	if n <= 0 {
 		return arg, nil
@@ -377,6 +772,107 @@ func builtinMakeArrayFunc(n int, arg Object) (Object, error) {
 	return ret, nil
 }
 
+func builtinMakeArrayCapFunc(c Call) (Object, error) {
+	size := c.Len()
+	if size < 1 || size > 2 {
+		return Undefined, ErrWrongNumArguments.NewError(
+			"want=1..2 got=" + strconv.Itoa(size))
+	}
+
+	length, err := makeSizeArg(c, 0)
+	if err != nil {
+		return Undefined, err
+	}
+
+	capacity := length
+	if size == 2 {
+		if capacity, err = makeSizeArg(c, 1); err != nil {
+			return Undefined, err
+		}
+		if capacity < length {
+			return Undefined, ErrType.NewError(
+				"capacity " + strconv.Itoa(capacity) +
+					" is less than length " + strconv.Itoa(length))
+		}
+	}
+
+	ret := make(Array, length, capacity)
+	for i := range ret {
+		ret[i] = Undefined
+	}
+	return ret, nil
+}
+
+func builtinMakeMapFunc(c Call) (Object, error) {
+	size := c.Len()
+	if size > 1 {
+		return Undefined, ErrWrongNumArguments.NewError(
+			"want=0..1 got=" + strconv.Itoa(size))
+	}
+
+	var capacity int
+	if size == 1 {
+		var err error
+		if capacity, err = makeSizeArg(c, 0); err != nil {
+			return Undefined, err
+		}
+	}
+	return make(Map, capacity), nil
+}
+
+func builtinTryDivFunc(x, y Object) (Object, error) {
+	return tryBinaryOp(token.Quo, x, y)
+}
+
+func builtinTryModFunc(x, y Object) (Object, error) {
+	return tryBinaryOp(token.Rem, x, y)
+}
+
+// tryBinaryOp returns [result, undefined] of the operation or
+// [undefined, error] if divisor is zero. Other errors are returned as is.
+func tryBinaryOp(tok token.Token, x, y Object) (Object, error) {
+	ret, err := x.BinaryOp(tok, y)
+	if err != nil {
+		if e, ok := err.(*Error); ok && errors.Is(e, ErrZeroDivision) {
+			return Array{Undefined, e}, nil
+		}
+		return Undefined, err
+	}
+	return Array{ret, Undefined}, nil
+}
+
+// maxMakeSize is the maximum length and capacity of arrays and maps created by
+// makeArray and makeMap builtin functions.
+const maxMakeSize = 1 << 24
+
+// makeSizeArg returns the size argument at given index of Call which must be
+// in [0, maxMakeSize] range.
+func makeSizeArg(c Call, idx int) (int, error) {
+	v, err := sizeArg(c, idx)
+	if err != nil {
+		return 0, err
+	}
+	if v > maxMakeSize {
+		return 0, ErrType.NewError("size " + strconv.Itoa(v) +
+			" exceeds the maximum " + strconv.Itoa(maxMakeSize))
+	}
+	return v, nil
+}
+
+// sizeArg returns the non-negative int argument at given index of Call.
+func sizeArg(c Call, idx int) (int, error) {
+	v, ok := ToGoInt(c.Get(idx))
+	if !ok {
+		return 0, NewArgumentTypeError(ordinal(idx+1), "int",
+			c.Get(idx).TypeName())
+	}
+	if v < 0 {
+		return 0, NewArgumentTypeError(ordinal(idx+1),
+			"non-negative integer", "negative integer")
+	}
+	return v, nil
+}
+
 func builtinAppendFunc(c Call) (Object, error) {
 	target, ok := c.shift()
 	if !ok {
@@ -438,12 +934,70 @@ func builtinDeleteFunc(arg Object, key string) (err error) {
 }
 
 func builtinCopyFunc(arg Object) Object {
+	if v, ok := arg.(*Frozen); ok {
+		return Thaw(v, false)
+	}
 	if v, ok := arg.(Copier); ok {
 		return v.Copy()
 	}
 	return arg
 }
 
+func builtinCloneFunc(arg Object) Object {
+	if v, ok := arg.(*Frozen); ok {
+		return Thaw(v, true)
+	}
+	return deepCopy(arg)
+}
+
+func builtinFreezeFunc(arg Object) Object {
+	return Freeze(arg)
+}
+
+// builtinSameFunc reports whether given arguments refer to the same object.
+// Values of reference types are same if they point to the same memory, other
+// values are same if they are equal and of same type.
+func builtinSameFunc(x, y Object) Object {
+	vx, vy := reflect.ValueOf(x), reflect.ValueOf(y)
+	if vx.Type() != vy.Type() {
+		return False
+	}
+	switch vx.Kind() {
+	case reflect.Slice:
+		return Bool(vx.Len() == vy.Len() && vx.Pointer() == vy.Pointer())
+	case reflect.Map, reflect.Ptr, reflect.Func:
+		return Bool(vx.Pointer() == vy.Pointer())
+	}
+	if vx.Type().Comparable() {
+		return Bool(x == y)
+	}
+	return False
+}
+
+func builtinEnumerateFunc(arg Object) (Object, error) {
+	if !arg.CanIterate() {
+		return Undefined, NewArgumentTypeError("1st", "iterable",
+			arg.TypeName())
+	}
+	return &Enumerator{Value: arg}, nil
+}
+
+func builtinSyncMapFunc(arg Object) (Object, error) {
+	m, ok := unfreeze(arg).(Map)
+	if !ok {
+		return Undefined, NewArgumentTypeError("1st", "map", arg.TypeName())
+	}
+	return &SyncMap{Value: m.Copy().(Map)}, nil
+}
+
+func builtinUnsyncMapFunc(arg Object) (Object, error) {
+	m, ok := arg.(*SyncMap)
+	if !ok {
+		return Undefined, NewArgumentTypeError("1st", "syncMap", arg.TypeName())
+	}
+	return m.Copy().(*SyncMap).Value, nil
+}
+
 func builtinRepeatFunc(arg Object, count int) (ret Object, err error) {
 	if count < 0 {
 		return nil, NewArgumentTypeError(
@@ -474,154 +1028,1496 @@ func builtinRepeatFunc(arg Object, count int) (ret Object, err error) {
 	return
 }
 
-func builtinContainsFunc(arg0, arg1 Object) (Object, error) {
-	var ok bool
-	switch obj := arg0.(type) {
-	case Map:
-		_, ok = obj[arg1.String()]
-	case *SyncMap:
-		_, ok = obj.Get(arg1.String())
-	case Array:
-		for _, item := range obj {
-			if item.Equal(arg1) {
-				ok = true
-				break
-			}
-		}
-	case String:
-		ok = strings.Contains(string(obj), arg1.String())
-	case Bytes:
-		switch v := arg1.(type) {
-		case Int:
-			ok = bytes.Contains(obj, []byte{byte(v)})
-		case Uint:
-			ok = bytes.Contains(obj, []byte{byte(v)})
-		case Char:
-			ok = bytes.Contains(obj, []byte{byte(v)})
-		case String:
-			ok = bytes.Contains(obj, []byte(v))
-		case Bytes:
-			ok = bytes.Contains(obj, v)
-		default:
-			return Undefined, NewArgumentTypeError(
-				"2nd",
-				"int|uint|string|char|bytes",
-				arg1.TypeName(),
-			)
+// builtinChunkFunc splits the array into arrays of given size, the last array
+// has the remaining elements if the length is not divisible by size.
+func builtinChunkFunc(arg Object, size int) (Object, error) {
+	arr, err := sizedArrayArgs(arg, size)
+	if err != nil {
+		return Undefined, err
+	}
+
+	n := len(arr) / size
+	if len(arr)%size != 0 {
+		n++
+	}
+	out := make(Array, 0, n)
+	for len(arr) > 0 {
+		n := size
+		if n > len(arr) {
+			n = len(arr)
 		}
-	case *UndefinedType:
-	default:
-		return Undefined, NewArgumentTypeError(
-			"1st",
-			"map|array|string|bytes",
-			arg0.TypeName(),
-		)
+		out = append(out, append(Array{}, arr[:n]...))
+		arr = arr[n:]
 	}
-	return Bool(ok), nil
+	return out, nil
 }
 
-func builtinLenFunc(arg Object) Object {
-	var n int
-	if v, ok := arg.(LengthGetter); ok {
-		n = v.Len()
+// builtinWindowsFunc returns the sliding windows of given size of the array.
+// It returns an empty array if size is greater than the length of the array.
+func builtinWindowsFunc(arg Object, size int) (Object, error) {
+	arr, err := sizedArrayArgs(arg, size)
+	if err != nil {
+		return Undefined, err
 	}
-	return Int(n)
+
+	if size > len(arr) {
+		return Array{}, nil
+	}
+	out := make(Array, 0, len(arr)-size+1)
+	for i := 0; i+size <= len(arr); i++ {
+		out = append(out, append(Array{}, arr[i:i+size]...))
+	}
+	return out, nil
 }
 
-func builtinCapFunc(arg Object) Object {
-	var n int
-	switch v := arg.(type) {
-	case Array:
-		n = cap(v)
-	case Bytes:
-		n = cap(v)
+// sizedArrayArgs checks the arguments of chunk and windows builtins.
+func sizedArrayArgs(arg Object, size int) (Array, error) {
+	arr, ok := arg.(Array)
+	if !ok {
+		return nil, NewArgumentTypeError("1st", "array", arg.TypeName())
 	}
-	return Int(n)
+	if size <= 0 {
+		return nil, NewArgumentTypeError("2nd", "positive integer",
+			"non-positive integer")
+	}
+	return arr, nil
 }
 
-func builtinSortFunc(arg Object) (ret Object, err error) {
+func builtinZipFunc(c Call) (Object, error) {
+	size := c.Len()
+	if size == 0 {
+		return Undefined, ErrWrongNumArguments.NewError("want>=1 got=0")
+	}
+
+	arrays := make([]Array, size)
+	n := -1
+	for i := 0; i < size; i++ {
+		arr, ok := c.Get(i).(Array)
+		if !ok {
+			return Undefined, NewArgumentTypeError(ordinal(i+1), "array",
+				c.Get(i).TypeName())
+		}
+		if n == -1 || len(arr) < n {
+			n = len(arr)
+		}
+		arrays[i] = arr
+	}
+
+	ret := make(Array, n)
+	for i := 0; i < n; i++ {
+		tuple := make(Array, size)
+		for j, arr := range arrays {
+			tuple[j] = arr[i]
+		}
+		ret[i] = tuple
+	}
+	return ret, nil
+}
+
+func builtinUnzipFunc(arg Object) (Object, error) {
+	arr, ok := arg.(Array)
+	if !ok {
+		return Undefined, NewArgumentTypeError("1st", "array", arg.TypeName())
+	}
+
+	n := -1
+	for _, v := range arr {
+		tuple, ok := v.(Array)
+		if !ok {
+			return Undefined, ErrType.NewError(
+				"expected array of arrays, found element " + v.TypeName())
+		}
+		if n == -1 || len(tuple) < n {
+			n = len(tuple)
+		}
+	}
+	if n == -1 {
+		return Array{}, nil
+	}
+
+	ret := make(Array, n)
+	for i := 0; i < n; i++ {
+		elems := make(Array, len(arr))
+		for j, v := range arr {
+			elems[j] = v.(Array)[i]
+		}
+		ret[i] = elems
+	}
+	return ret, nil
+}
+
+func builtinReverseFunc(arg Object) (Object, error) {
 	switch obj := arg.(type) {
 	case Array:
-		sort.Slice(obj, func(i, j int) bool {
-			v, e := obj[i].BinaryOp(token.Less, obj[j])
-			if e != nil && err == nil {
-				err = e
-				return false
-			}
-			if v != nil {
-				return !v.IsFalsy()
-			}
-			return false
-		})
-		ret = arg
+		for i, j := 0, len(obj)-1; i < j; i, j = i+1, j-1 {
+			obj[i], obj[j] = obj[j], obj[i]
+		}
+		return obj, nil
 	case String:
 		s := []rune(obj)
-		sort.Slice(s, func(i, j int) bool {
-			return s[i] < s[j]
-		})
-		ret = String(s)
+		for i, j := 0, len(s)-1; i < j; i, j = i+1, j-1 {
+			s[i], s[j] = s[j], s[i]
+		}
+		return String(s), nil
 	case Bytes:
-		sort.Slice(obj, func(i, j int) bool {
-			return obj[i] < obj[j]
-		})
-		ret = arg
+		for i, j := 0, len(obj)-1; i < j; i, j = i+1, j-1 {
+			obj[i], obj[j] = obj[j], obj[i]
+		}
+		return obj, nil
 	case *UndefinedType:
-		ret = Undefined
-	default:
-		ret = Undefined
-		err = NewArgumentTypeError(
-			"1st",
-			"array|string|bytes",
-			arg.TypeName(),
-		)
+		return Undefined, nil
 	}
-	return
+	return Undefined, NewArgumentTypeError("1st", "array|string|bytes",
+		arg.TypeName())
 }
 
-func builtinSortReverseFunc(arg Object) (Object, error) {
-	switch obj := arg.(type) {
-	case Array:
-		var err error
-		sort.Slice(obj, func(i, j int) bool {
-			v, e := obj[j].BinaryOp(token.Less, obj[i])
-			if e != nil && err == nil {
-				err = e
-				return false
-			}
+func builtinFillFunc(c Call) (Object, error) {
+	size := c.Len()
+	if size < 2 || size > 4 {
+		return Undefined, ErrWrongNumArguments.NewError(
+			"want=2..4 got=" + strconv.Itoa(size))
+	}
+
+	arr, ok := c.Get(0).(Array)
+	if !ok {
+		return Undefined, NewArgumentTypeError("1st", "array",
+			c.Get(0).TypeName())
+	}
+
+	start, end := 0, len(arr)
+	for i, p := range []*int{&start, &end} {
+		if size < i+3 {
+			break
+		}
+		v, ok := ToGoInt(c.Get(i + 2))
+		if !ok {
+			return Undefined, NewArgumentTypeError(ordinal(i+3), "int",
+				c.Get(i+2).TypeName())
+		}
+		*p = v
+	}
+
+	if start < 0 || end > len(arr) || start > end {
+		return Undefined, ErrIndexOutOfBounds.NewError(
+			"[" + strconv.Itoa(start) + ":" + strconv.Itoa(end) +
+				"] with length " + strconv.Itoa(len(arr)))
+	}
+
+	value := c.Get(1)
+	for i := start; i < end; i++ {
+		arr[i] = value
+	}
+	return arr, nil
+}
+
+func builtinConcatFunc(c Call) (Object, error) {
+	size := c.Len()
+	n := 0
+	for i := 0; i < size; i++ {
+		arr, ok := c.Get(i).(Array)
+		if !ok {
+			return Undefined, NewArgumentTypeError(ordinal(i+1), "array",
+				c.Get(i).TypeName())
+		}
+		n += len(arr)
+	}
+
+	ret := make(Array, 0, n)
+	for i := 0; i < size; i++ {
+		ret = append(ret, c.Get(i).(Array)...)
+	}
+	return ret, nil
+}
+
+func builtinMergeFunc(c Call) (Object, error) {
+	return mergeMaps(c, false)
+}
+
+func builtinDeepMergeFunc(c Call) (Object, error) {
+	return mergeMaps(c, true)
+}
+
+// mergeMaps returns a new map by merging the maps in Call from left to right.
+// If deep is true, nested maps are merged recursively.
+func mergeMaps(c Call, deep bool) (Object, error) {
+	size := c.Len()
+	if size == 0 {
+		return Undefined, ErrWrongNumArguments.NewError("want>=1 got=0")
+	}
+
+	ret := Map{}
+	for i := 0; i < size; i++ {
+		m, ok := unfreeze(c.Get(i)).(Map)
+		if !ok {
+			return Undefined, NewArgumentTypeError(ordinal(i+1), "map",
+				c.Get(i).TypeName())
+		}
+		if deep {
+			if err := deepMergeMap(ret, m, nil); err != nil {
+				return Undefined, err
+			}
+			continue
+		}
+		for k, v := range m {
+			ret[k] = v
+		}
+	}
+	return ret, nil
+}
+
+// deepMergeMap merges src into dst recursively. Nested maps of src are never
+// assigned to dst, new maps are created for them so all maps in dst belong to
+// dst and source maps are not modified. path holds the references of source
+// maps being merged to detect cycles, which cannot be merged into new maps.
+func deepMergeMap(dst, src Map, path []objectRef) error {
+	ref := refOf(src)
+	for _, r := range path {
+		if r == ref {
+			return ErrType.NewError(
+				"deepMerge: unsupported value: encountered a cycle via map")
+		}
+	}
+	path = append(path, ref)
+
+	for k, v := range src {
+		sm, ok := unfreeze(v).(Map)
+		if !ok {
+			dst[k] = v
+			continue
+		}
+
+		dm, ok := dst[k].(Map)
+		if !ok {
+			dm = Map{}
+			dst[k] = dm
+		}
+		if err := deepMergeMap(dm, sm, path); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func builtinPickFunc(arg0, arg1 Object) (Object, error) {
+	m, keys, err := mapKeysArgs(arg0, arg1)
+	if err != nil {
+		return Undefined, err
+	}
+
+	ret := make(Map, len(keys))
+	for _, k := range keys {
+		if v, ok := m[k]; ok {
+			ret[k] = v
+		}
+	}
+	return ret, nil
+}
+
+func builtinOmitFunc(arg0, arg1 Object) (Object, error) {
+	m, keys, err := mapKeysArgs(arg0, arg1)
+	if err != nil {
+		return Undefined, err
+	}
+
+	ret := make(Map, len(m))
+	for k, v := range m {
+		ret[k] = v
+	}
+	for _, k := range keys {
+		delete(ret, k)
+	}
+	return ret, nil
+}
+
+// mapKeysArgs validates map and array of string keys arguments of pick and
+// omit builtins.
+func mapKeysArgs(arg0, arg1 Object) (Map, []string, error) {
+	m, ok := unfreeze(arg0).(Map)
+	if !ok {
+		return nil, nil, NewArgumentTypeError("1st", "map", arg0.TypeName())
+	}
+
+	arr, ok := arg1.(Array)
+	if !ok {
+		return nil, nil, NewArgumentTypeError("2nd", "array", arg1.TypeName())
+	}
+
+	keys := make([]string, len(arr))
+	for i, v := range arr {
+		s, ok := v.(String)
+		if !ok {
+			return nil, nil, ErrType.NewError(
+				"expected array of strings, found element " + v.TypeName())
+		}
+		keys[i] = string(s)
+	}
+	return m, keys, nil
+}
+
+// builtinDiffFunc returns the differences between x and y as a map having
+// "added", "removed" and "changed" maps keyed by dotted paths like the paths of
+// getPath.
+func builtinDiffFunc(x, y Object) Object {
+	d := &differ{added: Map{}, removed: Map{}, changed: Map{}}
+	d.diff("", x, y)
+	return Map{"added": d.added, "removed": d.removed, "changed": d.changed}
+}
+
+type differ struct {
+	added   Map
+	removed Map
+	changed Map
+	// path holds the pairs of arrays and maps being compared, a pair is not
+	// compared again in itself so cyclic values do not recurse infinitely.
+	path map[[2]objectRef]struct{}
+}
+
+func (d *differ) diff(path string, x, y Object) {
+	if v, ok := x.(*Frozen); ok {
+		x = v.Value
+	}
+	if v, ok := y.(*Frozen); ok {
+		y = v.Value
+	}
+
+	if rx, ry := refOf(x), refOf(y); rx.ptr != 0 && ry.ptr != 0 {
+		pair := [2]objectRef{rx, ry}
+		if _, ok := d.path[pair]; ok {
+			return
+		}
+		if d.path == nil {
+			d.path = make(map[[2]objectRef]struct{})
+		}
+		d.path[pair] = struct{}{}
+		defer delete(d.path, pair)
+	}
+
+	switch xv := x.(type) {
+	case Map:
+		if yv, ok := y.(Map); ok {
+			for k, v := range xv {
+				if w, ok := yv[k]; ok {
+					d.diff(joinPath(path, k), v, w)
+				} else {
+					d.removed[joinPath(path, k)] = v
+				}
+			}
+			for k, w := range yv {
+				if _, ok := xv[k]; !ok {
+					d.added[joinPath(path, k)] = w
+				}
+			}
+			return
+		}
+	case Array:
+		if yv, ok := y.(Array); ok {
+			for i, v := range xv {
+				k := joinPath(path, strconv.Itoa(i))
+				if i < len(yv) {
+					d.diff(k, v, yv[i])
+				} else {
+					d.removed[k] = v
+				}
+			}
+			for i := len(xv); i < len(yv); i++ {
+				d.added[joinPath(path, strconv.Itoa(i))] = yv[i]
+			}
+			return
+		}
+	}
+
+	// values of different types are not equal, e.g. 1 and 1.0
+	if x.TypeName() != y.TypeName() || !x.Equal(y) {
+		d.changed[path] = Array{x, y}
+	}
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func builtinGetPathFunc(o Object, path string) Object {
+	if path == "" {
+		return o
+	}
+
+	for _, k := range strings.Split(path, ".") {
+		key := pathKey(o, k)
+		if key == nil {
+			return Undefined
+		}
+
+		v, err := o.IndexGet(key)
+		if err != nil || v == nil {
+			return Undefined
+		}
+		o = v
+	}
+	return o
+}
+
+func builtinSetPathFunc(o Object, path string, value Object) error {
+	if path == "" {
+		return ErrInvalidIndex.NewError("empty path")
+	}
+
+	keys := strings.Split(path, ".")
+	for i, k := range keys {
+		key := pathKey(o, k)
+		if key == nil {
+			return ErrInvalidIndex.NewError(
+				"invalid array index " + strconv.Quote(k) + " in path " +
+					strconv.Quote(strings.Join(keys[:i+1], ".")))
+		}
+
+		if i == len(keys)-1 {
+			return o.IndexSet(key, value)
+		}
+
+		v, err := o.IndexGet(key)
+		if err != nil {
+			return err
+		}
+		if v == nil || v == Undefined {
+			// create missing intermediate nodes as maps
+			v = Map{}
+			if err = o.IndexSet(key, v); err != nil {
+				return err
+			}
+		}
+		o = v
+	}
+	return nil
+}
+
+// pathKey returns the index object of a path component of getPath and setPath
+// builtins. Components are integer indices for arrays and string keys for
+// others. It returns nil if k is not a valid array index.
+func pathKey(o Object, k string) Object {
+	if _, ok := o.(Array); !ok {
+		return String(k)
+	}
+
+	i, err := strconv.Atoi(k)
+	if err != nil || i < 0 {
+		return nil
+	}
+	return Int(i)
+}
+
+func builtinGroupByFunc(c Call) (Object, error) {
+	ret := Map{}
+	err := arrayInvoke(c, func(elem, key Object) {
+		k := key.String()
+		if v, ok := ret[k]; ok {
+			ret[k] = append(v.(Array), elem)
+		} else {
+			ret[k] = Array{elem}
+		}
+	})
+	if err != nil {
+		return Undefined, err
+	}
+	return ret, nil
+}
+
+func builtinCountByFunc(c Call) (Object, error) {
+	ret := Map{}
+	err := arrayInvoke(c, func(_, key Object) {
+		k := key.String()
+		if v, ok := ret[k]; ok {
+			ret[k] = v.(Int) + 1
+		} else {
+			ret[k] = Int(1)
+		}
+	})
+	if err != nil {
+		return Undefined, err
+	}
+	return ret, nil
+}
+
+func builtinUniqueFunc(c Call) (Object, error) {
+	if c.Len() == 2 {
+		var ret Array
+		var keys Array
+		err := arrayInvoke(c, func(elem, key Object) {
+			for _, k := range keys {
+				if k.Equal(key) {
+					return
+				}
+			}
+			keys = append(keys, key)
+			ret = append(ret, elem)
+		})
+		if err != nil {
+			return Undefined, err
+		}
+		if ret == nil {
+			ret = Array{}
+		}
+		return ret, nil
+	}
+
+	if err := c.CheckLen(1); err != nil {
+		return Undefined, ErrWrongNumArguments.NewError(
+			"want=1..2 got=" + strconv.Itoa(c.Len()))
+	}
+
+	arr, ok := c.Get(0).(Array)
+	if !ok {
+		return Undefined, NewArgumentTypeError("1st", "array", c.Get(0).TypeName())
+	}
+
+	ret := make(Array, 0, len(arr))
+L:
+	for _, elem := range arr {
+		for _, v := range ret {
+			if v.Equal(elem) {
+				continue L
+			}
+		}
+		ret = append(ret, elem)
+	}
+	return ret, nil
+}
+
+// arrayInvoke calls the callable at 2nd argument for each element of the array
+// at 1st argument, and passes the element and the result of the call to fn.
+func arrayInvoke(c Call, fn func(elem, result Object)) error {
+	if err := c.CheckLen(2); err != nil {
+		return err
+	}
+
+	arr, ok := c.Get(0).(Array)
+	if !ok {
+		return NewArgumentTypeError("1st", "array", c.Get(0).TypeName())
+	}
+
+	inv, err := newCallInvoker(c, 1)
+	if err != nil {
+		return err
+	}
+	inv.Acquire()
+	defer inv.Release()
+
+	for _, elem := range arr {
+		ret, err := inv.Invoke(elem)
+		if err != nil {
+			return err
+		}
+		fn(elem, ret)
+	}
+	return nil
+}
+
+// newCallInvoker returns a new Invoker for the callable argument at given index
+// of the Call. CompiledFunction arguments require a VM.
+func newCallInvoker(c Call, idx int) (*Invoker, error) {
+	return newInvoker(c.vm, c.Get(idx), ordinal(idx+1))
+}
+
+// newInvoker returns a new Invoker for the callee which is an argument at given
+// position, pos is only used in errors.
+func newInvoker(vm *VM, callee Object, pos string) (*Invoker, error) {
+	if !callee.CanCall() {
+		return nil, NewArgumentTypeError(pos, "callable", callee.TypeName())
+	}
+	if vm == nil {
+		if _, ok := callee.(*CompiledFunction); ok {
+			return nil, ErrNotCallable.NewError("VM is required to call " +
+				callee.TypeName())
+		}
+	}
+	return NewInvoker(vm, callee), nil
+}
+
+func builtinContainsFunc(arg0, arg1 Object) (Object, error) {
+	var ok bool
+	switch obj := arg0.(type) {
+	case Map:
+		_, ok = obj[arg1.String()]
+	case *SyncMap:
+		_, ok = obj.Get(arg1.String())
+	case Array:
+		for _, item := range obj {
+			if item.Equal(arg1) {
+				ok = true
+				break
+			}
+		}
+	case String:
+		ok = strings.Contains(string(obj), arg1.String())
+	case Bytes:
+		switch v := arg1.(type) {
+		case Int:
+			ok = bytes.Contains(obj, []byte{byte(v)})
+		case Uint:
+			ok = bytes.Contains(obj, []byte{byte(v)})
+		case Char:
+			ok = bytes.Contains(obj, []byte{byte(v)})
+		case String:
+			ok = bytes.Contains(obj, []byte(v))
+		case Bytes:
+			ok = bytes.Contains(obj, v)
+		default:
+			return Undefined, NewArgumentTypeError(
+				"2nd",
+				"int|uint|string|char|bytes",
+				arg1.TypeName(),
+			)
+		}
+	case *UndefinedType:
+	default:
+		return Undefined, NewArgumentTypeError(
+			"1st",
+			"map|array|string|bytes",
+			arg0.TypeName(),
+		)
+	}
+	return Bool(ok), nil
+}
+
+func builtinMatchAllFunc(pattern, arg Object) (Object, error) {
+	p, ok := pattern.(String)
+	if !ok {
+		return Undefined, NewArgumentTypeError("1st", "string", pattern.TypeName())
+	}
+
+	re, errObj := compileRegexp(string(p))
+	if errObj != nil {
+		return errObj, nil
+	}
+
+	switch v := arg.(type) {
+	case String:
+		matches := re.FindAllStringSubmatch(string(v), -1)
+		ret := make(Array, 0, len(matches))
+		for _, m := range matches {
+			groups := make(Array, len(m))
+			for i := range m {
+				groups[i] = String(m[i])
+			}
+			ret = append(ret, groups)
+		}
+		return ret, nil
+	case Bytes:
+		matches := re.FindAllSubmatch(v, -1)
+		ret := make(Array, 0, len(matches))
+		for _, m := range matches {
+			groups := make(Array, len(m))
+			for i := range m {
+				groups[i] = Bytes(m[i])
+			}
+			ret = append(ret, groups)
+		}
+		return ret, nil
+	}
+	return Undefined, NewArgumentTypeError("2nd", "string|bytes", arg.TypeName())
+}
+
+func builtinReplaceAllFunc(c Call) (Object, error) {
+	if err := c.CheckLen(3); err != nil {
+		return Undefined, err
+	}
+
+	p, ok := c.Get(0).(String)
+	if !ok {
+		return Undefined, NewArgumentTypeError("1st", "string", c.Get(0).TypeName())
+	}
+
+	re, errObj := compileRegexp(string(p))
+	if errObj != nil {
+		return errObj, nil
+	}
+
+	switch v := c.Get(1).(type) {
+	case String:
+		repl, ok := c.Get(2).(String)
+		if !ok {
+			return Undefined, NewArgumentTypeError("3rd", "string",
+				c.Get(2).TypeName())
+		}
+		return String(re.ReplaceAllString(string(v), string(repl))), nil
+	case Bytes:
+		repl, ok := ToGoByteSlice(c.Get(2))
+		if !ok {
+			return Undefined, NewArgumentTypeError("3rd", "bytes|string",
+				c.Get(2).TypeName())
+		}
+		return Bytes(re.ReplaceAll(v, repl)), nil
+	}
+	return Undefined, NewArgumentTypeError("2nd", "string|bytes",
+		c.Get(1).TypeName())
+}
+
+// compileRegexp compiles given pattern or gets it from the cache. If pattern
+// is not valid, returned error object should be returned to the script.
+func compileRegexp(pattern string) (*regexp.Regexp, *Error) {
+	if re, ok := regexpCache.get(pattern); ok {
+		return re, nil
+	}
+
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, &Error{Message: err.Error(), Cause: err}
+	}
+	regexpCache.add(pattern, re)
+	return re, nil
+}
+
+// regexpCacheSize is the maximum number of compiled patterns kept by
+// matchAll and replaceAll builtins.
+const regexpCacheSize = 64
+
+var regexpCache = &regexpLRU{
+	items: make(map[string]*list.Element, regexpCacheSize),
+	order: list.New(),
+}
+
+type regexpLRUItem struct {
+	pattern string
+	re      *regexp.Regexp
+}
+
+// regexpLRU is a least recently used cache for compiled regular expressions
+// which is safe for concurrent use.
+type regexpLRU struct {
+	mu    sync.Mutex
+	items map[string]*list.Element
+	order *list.List
+}
+
+func (c *regexpLRU) get(pattern string) (*regexp.Regexp, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[pattern]; ok {
+		c.order.MoveToFront(e)
+		return e.Value.(*regexpLRUItem).re, true
+	}
+	return nil, false
+}
+
+func (c *regexpLRU) add(pattern string, re *regexp.Regexp) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[pattern]; ok {
+		c.order.MoveToFront(e)
+		return
+	}
+
+	c.items[pattern] = c.order.PushFront(&regexpLRUItem{pattern: pattern, re: re})
+	if c.order.Len() > regexpCacheSize {
+		e := c.order.Back()
+		c.order.Remove(e)
+		delete(c.items, e.Value.(*regexpLRUItem).pattern)
+	}
+}
+
+func builtinLenFunc(arg Object) Object {
+	var n int
+	if v, ok := arg.(LengthGetter); ok {
+		n = v.Len()
+	}
+	return intObject(Int(n))
+}
+
+func builtinCapFunc(arg Object) Object {
+	var n int
+	switch v := arg.(type) {
+	case Array:
+		n = cap(v)
+	case Bytes:
+		n = cap(v)
+	case CapGetter:
+		n = v.Cap()
+	}
+	return Int(n)
+}
+
+func builtinSortFunc(arg Object) (ret Object, err error) {
+	switch obj := arg.(type) {
+	case Array:
+		sort.SliceStable(obj, func(i, j int) bool {
+			v, e := obj[i].BinaryOp(token.Less, obj[j])
+			if e != nil && err == nil {
+				err = e
+				return false
+			}
+			if v != nil {
+				return !v.IsFalsy()
+			}
+			return false
+		})
+		ret = arg
+	case String:
+		s := []rune(obj)
+		sort.Slice(s, func(i, j int) bool {
+			return s[i] < s[j]
+		})
+		ret = String(s)
+	case Bytes:
+		sort.Slice(obj, func(i, j int) bool {
+			return obj[i] < obj[j]
+		})
+		ret = arg
+	case *UndefinedType:
+		ret = Undefined
+	default:
+		ret = Undefined
+		err = NewArgumentTypeError(
+			"1st",
+			"array|string|bytes",
+			arg.TypeName(),
+		)
+	}
+	return
+}
+
+func builtinSortReverseFunc(arg Object) (Object, error) {
+	switch obj := arg.(type) {
+	case Array:
+		var err error
+		sort.SliceStable(obj, func(i, j int) bool {
+			v, e := obj[j].BinaryOp(token.Less, obj[i])
+			if e != nil && err == nil {
+				err = e
+				return false
+			}
 			if v != nil {
 				return !v.IsFalsy()
 			}
-			return false
-		})
+			return false
+		})
+
+		if err != nil {
+			return nil, err
+		}
+		return obj, nil
+	case String:
+		s := []rune(obj)
+		sort.Slice(s, func(i, j int) bool {
+			return s[j] < s[i]
+		})
+		return String(s), nil
+	case Bytes:
+		sort.Slice(obj, func(i, j int) bool {
+			return obj[j] < obj[i]
+		})
+		return obj, nil
+	case *UndefinedType:
+		return Undefined, nil
+	}
+
+	return Undefined, NewArgumentTypeError(
+		"1st",
+		"array|string|bytes",
+		arg.TypeName(),
+	)
+}
+
+func builtinSortByKeysFunc(c Call) (Object, error) {
+	if err := c.CheckLen(2); err != nil {
+		return Undefined, err
+	}
+
+	arr, ok := c.Get(0).(Array)
+	if !ok {
+		return Undefined, NewArgumentTypeError("1st", "array",
+			c.Get(0).TypeName())
+	}
+
+	keyFns, ok := c.Get(1).(Array)
+	if !ok {
+		if !c.Get(1).CanCall() {
+			return Undefined, NewArgumentTypeError("2nd", "array|callable",
+				c.Get(1).TypeName())
+		}
+		keyFns = Array{c.Get(1)}
+	}
+
+	invokers := make([]*Invoker, len(keyFns))
+	for i, fn := range keyFns {
+		inv, err := newInvoker(c.vm, fn, "2nd")
+		if err != nil {
+			return Undefined, err
+		}
+		inv.Acquire()
+		defer inv.Release()
+		invokers[i] = inv
+	}
+
+	// keys are computed once for each element before sorting
+	type sortElem struct {
+		elem Object
+		keys []Object
+	}
+
+	elems := make([]sortElem, len(arr))
+	for i, elem := range arr {
+		keys := make([]Object, len(invokers))
+		for j, inv := range invokers {
+			key, err := inv.Invoke(elem)
+			if err != nil {
+				return Undefined, err
+			}
+			keys[j] = key
+		}
+		elems[i] = sortElem{elem: elem, keys: keys}
+	}
+
+	var err error
+	less := func(x, y Object) bool {
+		v, e := x.BinaryOp(token.Less, y)
+		if e != nil {
+			if err == nil {
+				err = e
+			}
+			return false
+		}
+		return !v.IsFalsy()
+	}
+
+	sort.SliceStable(elems, func(i, j int) bool {
+		for k := range invokers {
+			x, y := elems[i].keys[k], elems[j].keys[k]
+			if less(x, y) {
+				return true
+			}
+			if less(y, x) {
+				return false
+			}
+		}
+		return false
+	})
+	if err != nil {
+		return Undefined, err
+	}
+
+	for i := range elems {
+		arr[i] = elems[i].elem
+	}
+	return arr, nil
+}
+
+func builtinSearchSortedFunc(c Call) (Object, error) {
+	_, idx, err := searchSorted(c, false)
+	if err != nil {
+		return Undefined, err
+	}
+	return Int(idx), nil
+}
+
+func builtinInsertSortedFunc(c Call) (Object, error) {
+	arr, idx, err := searchSorted(c, true)
+	if err != nil {
+		return Undefined, err
+	}
+
+	ret := make(Array, len(arr)+1)
+	copy(ret, arr[:idx])
+	ret[idx] = c.Get(1)
+	copy(ret[idx+1:], arr[idx:])
+	return ret, nil
+}
+
+// searchSorted returns the sorted array and the index to insert the value in
+// Call using binary search. If after is true, index after the equal elements is
+// returned, otherwise index of the first element not less than the value is
+// returned.
+func searchSorted(c Call, after bool) (Array, int, error) {
+	if c.Len() < 2 || c.Len() > 3 {
+		return nil, 0, ErrWrongNumArguments.NewError(
+			"want=2..3 got=" + strconv.Itoa(c.Len()))
+	}
+
+	arr, ok := c.Get(0).(Array)
+	if !ok {
+		return nil, 0, NewArgumentTypeError("1st", "array", c.Get(0).TypeName())
+	}
+
+	less := func(x, y Object) (bool, error) {
+		v, err := x.BinaryOp(token.Less, y)
+		if err != nil {
+			return false, err
+		}
+		return !v.IsFalsy(), nil
+	}
+
+	if c.Len() == 3 {
+		inv, err := newCallInvoker(c, 2)
+		if err != nil {
+			return nil, 0, err
+		}
+		inv.Acquire()
+		defer inv.Release()
+
+		less = func(x, y Object) (bool, error) {
+			v, err := inv.Invoke(x, y)
+			if err != nil {
+				return false, err
+			}
+			return !v.IsFalsy(), nil
+		}
+	}
+
+	x := c.Get(1)
+	lo, hi := 0, len(arr)
+	for lo < hi {
+		mid := int(uint(lo+hi) >> 1)
+
+		var (
+			ok  bool
+			err error
+		)
+		if after {
+			// move right while x is not less than the element
+			ok, err = less(x, arr[mid])
+			ok = !ok
+		} else {
+			ok, err = less(arr[mid], x)
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+
+		if ok {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+	return arr, lo, nil
+}
+
+func builtinErrorFunc(c Call) (Object, error) {
+	size := c.Len()
+	if size != 1 && size != 2 {
+		return Undefined, ErrWrongNumArguments.NewError(
+			"want=1..2 got=" + strconv.Itoa(size))
+	}
+
+	err := &Error{Name: "error", Message: c.Get(0).String()}
+	if size == 2 {
+		data, ok := unfreeze(c.Get(1)).(Map)
+		if !ok {
+			return Undefined, NewArgumentTypeError("2nd", "map",
+				c.Get(1).TypeName())
+		}
+		if _, ok := c.Get(1).(*Frozen); ok {
+			data = data.Copy().(Map)
+		}
+		err.Data = data
+	}
+	return err, nil
+}
+
+func builtinErrorKindFunc(arg Object) (Object, error) {
+	name, ok := arg.(String)
+	if !ok {
+		return Undefined, NewArgumentTypeError("1st", "string", arg.TypeName())
+	}
+	return &Error{Name: string(name)}, nil
+}
+
+func builtinRetryFunc(c Call) (Object, error) {
+	size := c.Len()
+	if size != 1 && size != 2 {
+		return Undefined, ErrWrongNumArguments.NewError(
+			"want=1..2 got=" + strconv.Itoa(size))
+	}
+
+	attempts, delay, backoff := 3, time.Duration(0), 1.0
+	if size == 2 {
+		opts, ok := unfreeze(c.Get(1)).(Map)
+		if !ok {
+			return Undefined, NewArgumentTypeError("2nd", "map",
+				c.Get(1).TypeName())
+		}
+		var err error
+		if attempts, delay, backoff, err = retryOptions(opts); err != nil {
+			return Undefined, err
+		}
+	}
+
+	inv, err := newCallInvoker(c, 0)
+	if err != nil {
+		return Undefined, err
+	}
+	inv.Acquire()
+	defer inv.Release()
+
+	for i := 1; ; i++ {
+		ret, err := inv.Invoke()
+		if err == nil {
+			return ret, nil
+		}
+		if errors.Is(err, ErrVMAborted) || errors.Is(err, ErrExit) {
+			return Undefined, err
+		}
+		if i >= attempts {
+			return WrapGoError(err), nil
+		}
+		if err := sleep(c.vm, delay); err != nil {
+			return Undefined, err
+		}
+		delay = time.Duration(float64(delay) * backoff)
+	}
+}
+
+// builtinTransactionFunc calls the function with a shallow copy of the map
+// and replaces the entries of the map with the entries of the copy if the
+// function does not throw. Elements are shared with the copy, changes made to
+// nested containers are not rolled back.
+func builtinTransactionFunc(c Call) (Object, error) {
+	if err := c.CheckLen(2); err != nil {
+		return Undefined, err
+	}
+	m, ok := c.Get(0).(Map)
+	if !ok {
+		return Undefined, NewArgumentTypeError("1st", "map",
+			c.Get(0).TypeName())
+	}
+
+	inv, err := newCallInvoker(c, 1)
+	if err != nil {
+		return Undefined, err
+	}
+	inv.Acquire()
+	defer inv.Release()
+
+	view := m.Copy().(Map)
+	ret, err := inv.Invoke(view)
+	if err != nil {
+		return Undefined, err
+	}
+	for k := range m {
+		if _, ok := view[k]; !ok {
+			delete(m, k)
+		}
+	}
+	for k, v := range view {
+		m[k] = v
+	}
+	return ret, nil
+}
+
+// retryOptions returns the options of retry builtin function from the map.
+func retryOptions(opts Map) (
+	attempts int,
+	delay time.Duration,
+	backoff float64,
+	err error,
+) {
+	attempts, backoff = 3, 1.0
+	for k, v := range opts {
+		switch k {
+		case "attempts":
+			n, ok := v.(Int)
+			if !ok {
+				err = NewArgumentTypeError(k, "int", v.TypeName())
+				return
+			}
+			if n < 1 {
+				err = NewArgumentTypeError(k, "positive integer",
+					"non-positive integer")
+				return
+			}
+			attempts = int(n)
+		case "delay":
+			n, ok := v.(Int)
+			if !ok {
+				err = NewArgumentTypeError(k, "int", v.TypeName())
+				return
+			}
+			if n < 0 {
+				err = NewArgumentTypeError(k, "non-negative integer",
+					"negative integer")
+				return
+			}
+			delay = time.Duration(n)
+		case "backoff":
+			var f float64
+			switch v := v.(type) {
+			case Float:
+				f = float64(v)
+			case Int:
+				f = float64(v)
+			default:
+				err = NewArgumentTypeError(k, "float|int", v.TypeName())
+				return
+			}
+			if f <= 0 {
+				err = NewArgumentTypeError(k, "positive number",
+					"non-positive number")
+				return
+			}
+			backoff = f
+		default:
+			err = ErrInvalidIndex.NewError("unknown retry option " +
+				strconv.Quote(k))
+			return
+		}
+	}
+	return
+}
+
+// sleep pauses the current goroutine for at least the duration d. It returns
+// ErrVMAborted if vm is aborted while sleeping.
+func sleep(vm *VM, d time.Duration) error {
+	const interval = 10 * time.Millisecond
+	for d > 0 {
+		if vm != nil && vm.Aborted() {
+			return ErrVMAborted
+		}
+		if d <= interval {
+			time.Sleep(d)
+			break
+		}
+		time.Sleep(interval)
+		d -= interval
+	}
+	if vm != nil && vm.Aborted() {
+		return ErrVMAborted
+	}
+	return nil
+}
+
+func builtinMemoizeFunc(c Call) (Object, error) {
+	size := c.Len()
+	if size != 1 && size != 2 {
+		return Undefined, ErrWrongNumArguments.NewError(
+			"want=1..2 got=" + strconv.Itoa(size))
+	}
+
+	fn := c.Get(0)
+	if !fn.CanCall() {
+		return Undefined, NewArgumentTypeError("1st", "callable",
+			fn.TypeName())
+	}
+
+	var maxSize int
+	if size == 2 {
+		var err error
+		if maxSize, err = sizeArg(c, 1); err != nil {
+			return Undefined, err
+		}
+	}
+
+	cache := &memoCache{
+		items:   make(map[string]*list.Element),
+		order:   list.New(),
+		maxSize: maxSize,
+	}
+	call := func(c Call) (Object, error) {
+		var sb strings.Builder
+		for i := 0; i < c.Len(); i++ {
+			if err := writeMemoKey(&sb, c.Get(i), ordinal(i+1),
+				nil); err != nil {
+				return Undefined, err
+			}
+		}
+		key := sb.String()
+		if v, ok := cache.get(key); ok {
+			return v, nil
+		}
 
+		inv, err := newInvoker(c.vm, fn, "1st")
 		if err != nil {
-			return nil, err
+			return Undefined, err
 		}
-		return obj, nil
-	case String:
-		s := []rune(obj)
-		sort.Slice(s, func(i, j int) bool {
-			return s[j] < s[i]
-		})
-		return String(s), nil
-	case Bytes:
-		sort.Slice(obj, func(i, j int) bool {
-			return obj[j] < obj[i]
-		})
-		return obj, nil
-	case *UndefinedType:
-		return Undefined, nil
+		inv.Acquire()
+		defer inv.Release()
+
+		ret, err := inv.Invoke(c.callArgs()...)
+		if err != nil {
+			return Undefined, err
+		}
+		cache.add(key, ret)
+		return ret, nil
 	}
+	return &Function{
+		Name:    "memoize",
+		Value:   callExAdapter(call),
+		ValueEx: call,
+	}, nil
+}
 
-	return Undefined, NewArgumentTypeError(
-		"1st",
-		"array|string|bytes",
-		arg.TypeName(),
+// writeMemoKey writes the cache key of memoize argument o to sb. Keys of
+// arrays and maps are built from their elements, other values except the
+// primitive ones are not supported as their string representations are not
+// unique, e.g. all compiled functions have the same string representation.
+func writeMemoKey(sb *strings.Builder, o Object, pos string,
+	path []objectRef) error {
+	// type name and length prevent collisions like 1 and "1"
+	sb.WriteString(o.TypeName())
+	sb.WriteByte(':')
+
+	switch v := unfreeze(o).(type) {
+	case Bool, Int, Uint, Float, Char, String, Bytes, *UndefinedType:
+		s := v.String()
+		sb.WriteString(strconv.Itoa(len(s)))
+		sb.WriteByte(':')
+		sb.WriteString(s)
+		return nil
+	case Array, Map:
+		ref := refOf(v)
+		if ref.ptr != 0 {
+			for _, r := range path {
+				if r == ref {
+					return ErrType.NewError("memoize: unsupported value " +
+						"for argument '" + pos + "': encountered a cycle")
+				}
+			}
+			path = append(path, ref)
+		}
+		if arr, ok := v.(Array); ok {
+			sb.WriteString(strconv.Itoa(len(arr)))
+			sb.WriteByte('[')
+			for _, elem := range arr {
+				if err := writeMemoKey(sb, elem, pos, path); err != nil {
+					return err
+				}
+			}
+			return nil
+		}
+		m := v.(Map)
+		keys := make([]string, 0, len(m))
+		for k := range m {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		sb.WriteString(strconv.Itoa(len(m)))
+		sb.WriteByte('{')
+		for _, k := range keys {
+			sb.WriteString(strconv.Itoa(len(k)))
+			sb.WriteByte(':')
+			sb.WriteString(k)
+			if err := writeMemoKey(sb, m[k], pos, path); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	return NewArgumentTypeError(pos, "primitive, array or map", o.TypeName())
+}
+
+func builtinLazyFunc(c Call) (Object, error) {
+	if err := c.CheckLen(1); err != nil {
+		return Undefined, err
+	}
+
+	fn := c.Get(0)
+	if !fn.CanCall() {
+		return Undefined, NewArgumentTypeError("1st", "callable",
+			fn.TypeName())
+	}
+
+	var (
+		mu        sync.Mutex
+		done      bool
+		computing bool
+		value     Object
+		lastErr   *Error
 	)
+	call := func(c Call) (Object, error) {
+		if err := c.CheckLen(0); err != nil {
+			return Undefined, err
+		}
+
+		mu.Lock()
+		if done {
+			mu.Unlock()
+			if lastErr != nil {
+				return Undefined, lastErr
+			}
+			return value, nil
+		}
+		if computing {
+			mu.Unlock()
+			return Undefined, ErrType.NewError(
+				"lazy value is called while it is being computed")
+		}
+		inv, err := newInvoker(c.vm, fn, "1st")
+		if err != nil {
+			mu.Unlock()
+			return Undefined, err
+		}
+		computing = true
+		mu.Unlock()
+
+		inv.Acquire()
+		ret, err := inv.Invoke()
+		inv.Release()
+
+		mu.Lock()
+		defer mu.Unlock()
+		computing = false
+		if err != nil {
+			// aborted or exited VM must not affect the next calls
+			if errors.Is(err, ErrVMAborted) || errors.Is(err, ErrExit) {
+				return Undefined, err
+			}
+			lastErr = WrapGoError(err)
+			done = true
+			return Undefined, lastErr
+		}
+		value, done = ret, true
+		return value, nil
+	}
+	return &Function{
+		Name:    "lazy",
+		Value:   callExAdapter(call),
+		ValueEx: call,
+	}, nil
+}
+
+type memoCacheItem struct {
+	key   string
+	value Object
+}
+
+// memoCache is a least recently used cache for the results of memoized
+// functions which is safe for concurrent use. Zero maxSize means no limit.
+type memoCache struct {
+	mu      sync.Mutex
+	items   map[string]*list.Element
+	order   *list.List
+	maxSize int
+}
+
+func (c *memoCache) get(key string) (Object, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		c.order.MoveToFront(e)
+		return e.Value.(*memoCacheItem).value, true
+	}
+	return nil, false
 }
 
-func builtinErrorFunc(arg Object) Object {
-	return &Error{Name: "error", Message: arg.String()}
+func (c *memoCache) add(key string, value Object) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if e, ok := c.items[key]; ok {
+		// recursive calls may have already added the key
+		c.order.MoveToFront(e)
+		return
+	}
+
+	c.items[key] = c.order.PushFront(&memoCacheItem{key: key, value: value})
+	if c.maxSize > 0 && c.order.Len() > c.maxSize {
+		e := c.order.Back()
+		c.order.Remove(e)
+		delete(c.items, e.Value.(*memoCacheItem).key)
+	}
 }
 
 func builtinTypeNameFunc(arg Object) Object { return String(arg.TypeName()) }
@@ -630,8 +2526,49 @@ func builtinBoolFunc(arg Object) Object { return Bool(!arg.IsFalsy()) }
 
 func builtinIntFunc(v int64) Object { return Int(v) }
 
+func builtinIntExactFunc(arg Object) (Object, error) {
+	switch v := arg.(type) {
+	case Int:
+		return v, nil
+	case Uint:
+		if v > math.MaxInt64 {
+			return &Error{Message: "uint " + v.String() + " overflows int"}, nil
+		}
+		return Int(v), nil
+	case Float:
+		f := float64(v)
+		// float64(math.MaxInt64) is rounded up to 2^63 which overflows
+		if math.IsNaN(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+			return &Error{Message: "float " + v.String() + " overflows int"}, nil
+		}
+		if f != math.Trunc(f) {
+			return &Error{
+				Message: "float " + v.String() + " has fractional part",
+			}, nil
+		}
+		return Int(f), nil
+	}
+	return Undefined, NewArgumentTypeError("1st", "int|uint|float",
+		arg.TypeName())
+}
+
 func builtinUintFunc(v uint64) Object { return Uint(v) }
 
+// Fixed size integer conversions truncate the value to the given width and
+// sign extend signed results like Go's conversions.
+
+func builtinToInt8Func(v int64) Object { return Int(int8(v)) }
+
+func builtinToInt16Func(v int64) Object { return Int(int16(v)) }
+
+func builtinToInt32Func(v int64) Object { return Int(int32(v)) }
+
+func builtinToUint8Func(v uint64) Object { return Uint(uint8(v)) }
+
+func builtinToUint16Func(v uint64) Object { return Uint(uint16(v)) }
+
+func builtinToUint32Func(v uint64) Object { return Uint(uint32(v)) }
+
 func builtinFloatFunc(v float64) Object { return Float(v) }
 
 func builtinCharFunc(arg Object) (Object, error) {
@@ -649,7 +2586,7 @@ func builtinCharFunc(arg Object) (Object, error) {
 	)
 }
 
-func builtinStringFunc(arg Object) Object { return String(arg.String()) }
+func builtinStringFunc(arg Object) Object { return stringObject(arg.String()) }
 
 func builtinBytesFunc(c Call) (Object, error) {
 	size := c.Len()
@@ -760,6 +2697,73 @@ func builtinPrintlnFunc(c Call) (ret Object, err error) {
 	return
 }
 
+func builtinFprintfFunc(c Call) (ret Object, err error) {
+	ret = Undefined
+	size := c.Len()
+	if size < 2 {
+		err = ErrWrongNumArguments.NewError(wantGEqXGotY(2, size))
+		return
+	}
+
+	w, ok := c.Get(0).(io.Writer)
+	if !ok {
+		err = NewArgumentTypeError("1st", "writer", c.Get(0).TypeName())
+		return
+	}
+	if size == 2 {
+		_, err = fmt.Fprint(w, c.Get(1).String())
+		return
+	}
+
+	vargs := make([]interface{}, 0, size-2)
+	for i := 2; i < size; i++ {
+		vargs = append(vargs, c.Get(i))
+	}
+	_, err = fmt.Fprintf(w, c.Get(1).String(), vargs...)
+	return
+}
+
+func builtinFprintlnFunc(c Call) (ret Object, err error) {
+	ret = Undefined
+	size := c.Len()
+	if size < 1 {
+		err = ErrWrongNumArguments.NewError(wantGEqXGotY(1, size))
+		return
+	}
+
+	w, ok := c.Get(0).(io.Writer)
+	if !ok {
+		err = NewArgumentTypeError("1st", "writer", c.Get(0).TypeName())
+		return
+	}
+
+	vargs := make([]interface{}, 0, size-1)
+	for i := 1; i < size; i++ {
+		vargs = append(vargs, c.Get(i))
+	}
+	_, err = fmt.Fprintln(w, vargs...)
+	return
+}
+
+func builtinBufferFunc(c Call) (Object, error) {
+	size := c.Len()
+	if size > 1 {
+		return Undefined, ErrWrongNumArguments.NewError(
+			"want=0..1 got=" + strconv.Itoa(size))
+	}
+
+	buf := &Buffer{}
+	if size == 1 {
+		b, ok := ToGoByteSlice(c.Get(0))
+		if !ok {
+			return Undefined, NewArgumentTypeError(
+				"1st", "bytes|string", c.Get(0).TypeName())
+		}
+		buf.Value.Write(b)
+	}
+	return buf, nil
+}
+
 func builtinSprintfFunc(c Call) (ret Object, err error) {
 	ret = Undefined
 	switch size := c.Len(); size {
@@ -778,10 +2782,198 @@ func builtinSprintfFunc(c Call) (ret Object, err error) {
 	return
 }
 
+func builtinDumpFunc(arg Object) Object {
+	var d dumper
+	d.dump(arg, 0)
+	return String(d.sb.String())
+}
+
+// dumper writes a stable, type annotated and indented representation of
+// objects. Map keys are sorted and reference cycles of arrays and maps are
+// detected by tracking the objects being dumped.
+type dumper struct {
+	sb      strings.Builder
+	visited []objectRef
+}
+
+func (d *dumper) dump(o Object, depth int) {
+	switch v := o.(type) {
+	case Array:
+		ref := refOf(v)
+		if d.enter(ref) {
+			d.sb.WriteString("array(<cycle>)")
+			return
+		}
+		defer d.leave(ref)
+
+		d.sb.WriteString("array(len=")
+		d.sb.WriteString(strconv.Itoa(len(v)))
+		d.sb.WriteString(") [")
+		if len(v) == 0 {
+			d.sb.WriteString("]")
+			return
+		}
+		d.sb.WriteString("\n")
+		for _, e := range v {
+			d.indent(depth + 1)
+			d.dump(e, depth+1)
+			d.sb.WriteString(",\n")
+		}
+		d.indent(depth)
+		d.sb.WriteString("]")
+	case Map:
+		d.dumpMap("map", v, depth)
+	case *SyncMap:
+		v.mu.RLock()
+		defer v.mu.RUnlock()
+		d.dumpMap("syncMap", v.Value, depth)
+	case String:
+		d.sb.WriteString("string(")
+		d.sb.WriteString(strconv.Quote(string(v)))
+		d.sb.WriteString(")")
+	case Char:
+		d.sb.WriteString("char(")
+		d.sb.WriteString(strconv.QuoteRune(rune(v)))
+		d.sb.WriteString(")")
+	case Bytes:
+		d.sb.WriteString("bytes(")
+		d.sb.WriteString(fmt.Sprint([]byte(v)))
+		d.sb.WriteString(")")
+	case *UndefinedType:
+		d.sb.WriteString("undefined")
+	case nil:
+		d.sb.WriteString("<nil>")
+	default:
+		d.sb.WriteString(o.TypeName())
+		d.sb.WriteString("(")
+		d.sb.WriteString(o.String())
+		d.sb.WriteString(")")
+	}
+}
+
+func (d *dumper) dumpMap(typeName string, m Map, depth int) {
+	ref := refOf(m)
+	if d.enter(ref) {
+		d.sb.WriteString(typeName)
+		d.sb.WriteString("(<cycle>)")
+		return
+	}
+	defer d.leave(ref)
+
+	d.sb.WriteString(typeName)
+	d.sb.WriteString("(len=")
+	d.sb.WriteString(strconv.Itoa(len(m)))
+	d.sb.WriteString(") {")
+	if len(m) == 0 {
+		d.sb.WriteString("}")
+		return
+	}
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	d.sb.WriteString("\n")
+	for _, k := range keys {
+		d.indent(depth + 1)
+		d.sb.WriteString(strconv.Quote(k))
+		d.sb.WriteString(": ")
+		d.dump(m[k], depth+1)
+		d.sb.WriteString(",\n")
+	}
+	d.indent(depth)
+	d.sb.WriteString("}")
+}
+
+// enter reports whether the referenced object is already being dumped or adds
+// it to visited objects.
+func (d *dumper) enter(ref objectRef) bool {
+	if ref.ptr == 0 {
+		return false
+	}
+	for _, r := range d.visited {
+		if r == ref {
+			return true
+		}
+	}
+	d.visited = append(d.visited, ref)
+	return false
+}
+
+func (d *dumper) leave(ref objectRef) {
+	if ref.ptr != 0 {
+		d.visited = d.visited[:len(d.visited)-1]
+	}
+}
+
+func (d *dumper) indent(depth int) {
+	for i := 0; i < depth; i++ {
+		d.sb.WriteString("  ")
+	}
+}
+
+// objectRef identifies array and map objects by their underlying data.
+type objectRef struct {
+	ptr uintptr
+	len int
+}
+
+// refOf returns the reference of array and map objects. Returned reference
+// has zero ptr for empty arrays and other objects.
+func refOf(o Object) objectRef {
+	switch v := o.(type) {
+	case Array:
+		if len(v) > 0 {
+			return objectRef{ptr: reflect.ValueOf(v).Pointer(), len: len(v)}
+		}
+	case Map:
+		if v != nil {
+			return objectRef{ptr: reflect.ValueOf(v).Pointer(), len: -1}
+		}
+	}
+	return objectRef{}
+}
+
+// pointerRef returns the reference of pointer objects like sync maps and
+// errors. Negative length distinguishes them from array and map references.
+func pointerRef(o Object) objectRef {
+	return objectRef{ptr: reflect.ValueOf(o).Pointer(), len: -2}
+}
+
+func builtinExitFunc(c Call) (Object, error) {
+	var code Int
+	switch c.Len() {
+	case 0:
+	case 1:
+		v, ok := ToInt(c.Get(0))
+		if !ok {
+			return Undefined, NewArgumentTypeError("1st", "int",
+				c.Get(0).TypeName())
+		}
+		code = v
+	default:
+		return Undefined, ErrWrongNumArguments.NewError(
+			"want=0..1 got=" + strconv.Itoa(c.Len()))
+	}
+
+	err := ErrExit.NewError("exit code " + code.String())
+	err.Data = Map{"code": code}
+	return Undefined, err
+}
+
 func builtinGlobalsFunc(c Call) (Object, error) {
 	return c.VM().GetGlobals(), nil
 }
 
+func builtinGlobalsSnapshotFunc(c Call) (Object, error) {
+	if err := c.CheckLen(0); err != nil {
+		return Undefined, err
+	}
+	return deepCopy(c.VM().GetGlobals()), nil
+}
+
 func builtinIsErrorFunc(c Call) (ret Object, err error) {
 	ret = False
 	switch c.Len() {
@@ -823,6 +3015,11 @@ func builtinIsCharFunc(arg Object) Object {
 	return Bool(ok)
 }
 
+func builtinIsValidCharFunc(arg Object) Object {
+	v, ok := arg.(Char)
+	return Bool(ok && utf8.ValidRune(rune(v)))
+}
+
 func builtinIsBoolFunc(arg Object) Object {
 	_, ok := arg.(Bool)
 	return Bool(ok)
@@ -839,6 +3036,9 @@ func builtinIsBytesFunc(arg Object) Object {
 }
 
 func builtinIsMapFunc(arg Object) Object {
+	if f, ok := arg.(*Frozen); ok {
+		arg = f.Value
+	}
 	_, ok := arg.(Map)
 	return Bool(ok)
 }
@@ -849,6 +3049,9 @@ func builtinIsSyncMapFunc(arg Object) Object {
 }
 
 func builtinIsArrayFunc(arg Object) Object {
+	if f, ok := arg.(*Frozen); ok {
+		arg = f.Value
+	}
 	_, ok := arg.(Array)
 	return Bool(ok)
 }
@@ -877,6 +3080,23 @@ func builtinIsCallableFunc(arg Object) Object { return Bool(arg.CanCall()) }
 
 func builtinIsIterableFunc(arg Object) Object { return Bool(arg.CanIterate()) }
 
+// ordinal returns the ordinal form of given argument position like 1st, 2nd.
+func ordinal(num int) string {
+	suffix := "th"
+	switch num % 10 {
+	case 1:
+		suffix = "st"
+	case 2:
+		suffix = "nd"
+	case 3:
+		suffix = "rd"
+	}
+	if v := num % 100; v >= 11 && v <= 13 {
+		suffix = "th"
+	}
+	return strconv.Itoa(num) + suffix
+}
+
 func callExAdapter(fn CallableExFunc) CallableFunc {
 	return func(args ...Object) (Object, error) {
 		return fn(Call{args: args})
@@ -1,6 +1,7 @@
 package ugo_test
 
 import (
+	"strconv"
 	"testing"
 
 	. "github.com/ozanh/ugo"
@@ -21,3 +22,42 @@ func TestBuiltinTypes(t *testing.T) {
 		t.Fatal("builtin 'global' is not *BuiltinFunction type")
 	}
 }
+
+func BenchmarkMakeArray(b *testing.B) {
+	keys := make(Array, 1000)
+	for i := range keys {
+		keys[i] = String(strconv.Itoa(i))
+	}
+	for _, tc := range []struct {
+		name   string
+		script string
+	}{
+		{"append", `
+		param keys
+		arr := []
+		for i := 0; i < 1000; i++ { arr = append(arr, true) }
+		m := {}
+		for k in keys { m[k] = true }`},
+		{"preallocated", `
+		param keys
+		arr := makeArray(0, 1000)
+		for i := 0; i < 1000; i++ { arr = append(arr, true) }
+		m := makeMap(len(keys))
+		for k in keys { m[k] = true }`},
+	} {
+		b.Run(tc.name, func(b *testing.B) {
+			bc, err := Compile([]byte(tc.script), DefaultCompilerOptions)
+			if err != nil {
+				b.Fatal(err)
+			}
+			vm := NewVM(bc)
+			b.ReportAllocs()
+			b.ResetTimer()
+			for i := 0; i < b.N; i++ {
+				if _, err := vm.Run(nil, keys); err != nil {
+					b.Fatal(err)
+				}
+			}
+		})
+	}
+}
@@ -10,6 +10,8 @@ import (
 	"io"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/ozanh/ugo/parser"
 	"github.com/ozanh/ugo/token"
@@ -21,6 +23,64 @@ type Bytecode struct {
 	Main       *CompiledFunction
 	Constants  []Object
 	NumModules int
+	// decoded holds decodedInsts of compiled functions, which is computed
+	// once and shared by VMs running the bytecode.
+	decoded atomic.Value
+}
+
+// decodedInsts maps the first instruction of compiled functions to their
+// decoded instructions. Closures created from the same function share the
+// instructions, so a single entry is used for all of them. Instructions of
+// Main and compiled function constants are decoded up front, instructions of
+// other functions like the ones passed from other bytecodes are decoded on
+// their first call and stored in others.
+type decodedInsts struct {
+	consts map[*byte][]decodedInst
+	others sync.Map // map[*byte][]decodedInst
+}
+
+// decodedInsts returns decoded instructions of given function, which are
+// decoded once and cached.
+func (bc *Bytecode) decodedInsts(fn *CompiledFunction) []decodedInst {
+	if len(fn.Instructions) == 0 {
+		return nil
+	}
+	d := bc.decodedMap()
+	key := &fn.Instructions[0]
+	if ops, ok := d.consts[key]; ok && len(ops) == len(fn.Instructions) {
+		return ops
+	}
+	if v, ok := d.others.Load(key); ok {
+		if ops := v.([]decodedInst); len(ops) == len(fn.Instructions) {
+			return ops
+		}
+	}
+	ops := decodeInstructions(fn.Instructions)
+	d.others.Store(key, ops)
+	return ops
+}
+
+// decodedMap returns the decoded instructions of Main and compiled function
+// constants, they are decoded on first call.
+func (bc *Bytecode) decodedMap() *decodedInsts {
+	d, _ := bc.decoded.Load().(*decodedInsts)
+	if d == nil {
+		d = &decodedInsts{consts: make(map[*byte][]decodedInst)}
+		d.add(bc.Main)
+		for _, c := range bc.Constants {
+			if f, ok := c.(*CompiledFunction); ok {
+				d.add(f)
+			}
+		}
+		bc.decoded.Store(d)
+	}
+	return d
+}
+
+func (d *decodedInsts) add(fn *CompiledFunction) {
+	if fn != nil && len(fn.Instructions) > 0 {
+		d.consts[&fn.Instructions[0]] = decodeInstructions(fn.Instructions)
+	}
 }
 
 // Fprint writes constants and instructions to given Writer in a human readable form.
@@ -10,6 +10,7 @@ import (
 	"io/ioutil"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/ozanh/ugo"
 
@@ -165,10 +166,91 @@ func TestREPL(t *testing.T) {
 		require.NoError(t, r.execute(".modules_cache"))
 		testHasPrefix(t, string(cw.consume()), "[{")
 	})
+	t.Run("import path", func(t *testing.T) {
+		r := newREPL(ctx, cw)
+		require.NoError(t, r.execute(`import("path")`))
+		testHasPrefix(t, string(cw.consume()), "\n⇦   {")
+		require.NoError(t, r.execute(".modules_cache"))
+		testHasPrefix(t, string(cw.consume()), "[{")
+	})
+	t.Run("import debug", func(t *testing.T) {
+		r := newREPL(ctx, cw)
+		require.NoError(t, r.execute(`import("debug")`))
+		testHasPrefix(t, string(cw.consume()), "\n⇦   {")
+		require.NoError(t, r.execute(".modules_cache"))
+		testHasPrefix(t, string(cw.consume()), "[{")
+	})
+	t.Run("import runtime", func(t *testing.T) {
+		r := newREPL(ctx, cw)
+		require.NoError(t, r.execute(`import("runtime")`))
+		testHasPrefix(t, string(cw.consume()), "\n⇦   {")
+		require.NoError(t, r.execute(".modules_cache"))
+		testHasPrefix(t, string(cw.consume()), "[{")
+	})
+	t.Run("import decimal", func(t *testing.T) {
+		r := newREPL(ctx, cw)
+		require.NoError(t, r.execute(`import("decimal")`))
+		testHasPrefix(t, string(cw.consume()), "\n⇦   {")
+		require.NoError(t, r.execute(".modules_cache"))
+		testHasPrefix(t, string(cw.consume()), "[{")
+	})
+	t.Run("import hash", func(t *testing.T) {
+		r := newREPL(ctx, cw)
+		require.NoError(t, r.execute(`import("hash")`))
+		testHasPrefix(t, string(cw.consume()), "\n⇦   {")
+		require.NoError(t, r.execute(".modules_cache"))
+		testHasPrefix(t, string(cw.consume()), "[{")
+	})
+	t.Run("import encoding", func(t *testing.T) {
+		r := newREPL(ctx, cw)
+		require.NoError(t, r.execute(`import("encoding")`))
+		testHasPrefix(t, string(cw.consume()), "\n⇦   {")
+		require.NoError(t, r.execute(".modules_cache"))
+		testHasPrefix(t, string(cw.consume()), "[{")
+	})
 	t.Run("memory_stats", func(t *testing.T) {
 		require.NoError(t, r.execute(".memory_stats"))
 		testHasPrefix(t, string(cw.consume()), "Go Memory Stats")
 	})
+	t.Run("time", func(t *testing.T) {
+		r := newREPL(ctx, cw)
+		require.NoError(t, r.execute(".time"))
+		require.Equal(t, "Last run took 0s\n", string(cw.consume()))
+		require.NoError(t, r.execute("x := 0; for i := 0; i < 1000; i++ { x += i }"))
+		cw.consume()
+		require.NoError(t, r.execute(".time"))
+		out := string(cw.consume())
+		require.Regexp(t, `^Last run took [0-9.]+(ns|µs|ms|s)\n$`, out)
+		require.NotEqual(t, "Last run took 0s\n", out)
+	})
+	t.Run("color", func(t *testing.T) {
+		r := newREPL(ctx, cw)
+		require.False(t, r.color)
+		require.False(t, r.pager)
+		require.NoError(t, r.execute(".color"))
+		require.Equal(t, "color is off\n", string(cw.consume()))
+		require.NoError(t, r.execute(`"a"`))
+		require.Equal(t, "\n⇦   \"a\"\n", string(cw.consume()))
+
+		require.NoError(t, r.execute(".color on"))
+		require.Equal(t, "color is on\n", string(cw.consume()))
+		require.NoError(t, r.execute(`"a"`))
+		require.Equal(t, "\n⇦   "+colorGreen+"\"a\""+colorReset+"\n",
+			string(cw.consume()))
+		require.NoError(t, r.execute(`1`))
+		require.Equal(t, "\n⇦   "+colorCyan+"1"+colorReset+"\n",
+			string(cw.consume()))
+		require.NoError(t, r.execute(`throw "x"`))
+		testHasPrefix(t, string(cw.consume()), "\n!   "+colorRed+"error: x")
+		require.NoError(t, r.execute(`[]`))
+		require.Equal(t, "\n⇦   []\n", string(cw.consume()))
+
+		require.NoError(t, r.execute(".color off"))
+		require.Equal(t, "color is off\n", string(cw.consume()))
+		require.NoError(t, r.execute(".color x"))
+		require.Equal(t, "usage: .color [on|off]\n", string(cw.consume()))
+		require.False(t, r.color)
+	})
 	t.Run("reset", func(t *testing.T) {
 		r := newREPL(ctx, cw)
 		require.NoError(t, r.execute("test := 1"))
@@ -182,6 +264,65 @@ func TestREPL(t *testing.T) {
 	})
 }
 
+func TestIsTerminal(t *testing.T) {
+	require.False(t, isTerminal(&console{buf: bytes.NewBuffer(nil)}))
+	f, err := ioutil.TempFile(t.TempDir(), "out")
+	require.NoError(t, err)
+	defer f.Close()
+	require.False(t, isTerminal(f))
+}
+
+func TestCompleteLine(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cw := &console{buf: bytes.NewBuffer(nil)}
+	r := newREPL(ctx, cw)
+
+	require.NoError(t, r.execute(`m := {alpha: 1, beta: {gamma: 2, delta: 3}, also: 4}`))
+	require.NoError(t, r.execute(`e := error("x", {code: 1})`))
+	cw.consume()
+
+	require.Equal(t, []string{"m.alpha", "m.also", "m.beta"}, r.completeLine("m."))
+	require.Equal(t, []string{"m.alpha", "m.also"}, r.completeLine("m.al"))
+	require.Equal(t, []string{"x := m.beta.delta", "x := m.beta.gamma"},
+		r.completeLine("x := m.beta."))
+	require.Equal(t, []string{"println(m.beta.gamma"},
+		r.completeLine("println(m.beta.g"))
+	require.Equal(t, []string{"e.Data", "e.Message", "e.Name", "e.New", "e.code"},
+		r.completeLine("e."))
+	require.Equal(t, []string{"e.code"}, r.completeLine("e.c"))
+
+	globals := r.completeLine("Gosched.")
+	require.Equal(t, complete("Gosched."), globals)
+	require.Equal(t, complete("m.x"), r.completeLine("m.x"))
+	require.Equal(t, complete("undefinedVar."), r.completeLine("undefinedVar."))
+	require.Equal(t, complete(".com"), r.completeLine(".com"))
+	require.Contains(t, r.completeLine(".com"), ".commands")
+}
+
+func TestREPLExtraBuiltins(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	cw := &console{buf: bytes.NewBuffer(nil)}
+	r := newREPL(ctx, cw)
+	r.eval.Opts.SymbolTable.SetExtraBuiltins(map[string]ugo.Object{
+		"double": &ugo.Function{
+			Name: "double",
+			Value: func(args ...ugo.Object) (ugo.Object, error) {
+				return args[0].(ugo.Int) * 2, nil
+			},
+		},
+	})
+	r.setSymbolSuggestions()
+	require.Equal(t, []string{"double"}, r.completeLine("doub"))
+
+	require.NoError(t, r.execute(`double(21)`))
+	require.Equal(t, ugo.Int(42), r.lastResult)
+	require.Equal(t, []string{"double"}, r.completeLine("doub"))
+}
+
 func TestFlags(t *testing.T) {
 	defer resetGlobals()
 
@@ -233,6 +374,15 @@ func TestFlags(t *testing.T) {
 
 	resetGlobals()
 
+	fs = flag.NewFlagSet("inline", flag.ExitOnError)
+	fp, to, err = parseFlags(fs, []string{"-e", "return 1+2", "-timeout", "1s"})
+	require.NoError(t, err)
+	require.Empty(t, fp)
+	require.Equal(t, time.Second, to)
+	require.Equal(t, "return 1+2", inlineScript)
+
+	resetGlobals()
+
 	fs = flag.NewFlagSet("file does not exist", flag.ExitOnError)
 	_, _, err = parseFlags(fs, []string{"testdata/doesnotexist"})
 	require.Error(t, err)
@@ -244,6 +394,7 @@ func resetGlobals() {
 	traceParser = false
 	traceOptimizer = false
 	traceCompiler = false
+	inlineScript = ""
 }
 
 func TestExecuteScript(t *testing.T) {
@@ -274,6 +425,62 @@ func TestExecuteScript(t *testing.T) {
 	}
 }
 
+func TestExecuteInline(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var out bytes.Buffer
+	require.NoError(t, executeInline(ctx, []byte("return 1+2"), &out))
+	require.Equal(t, "3\n", out.String())
+
+	out.Reset()
+	require.NoError(t, executeInline(ctx, []byte(`return "a" + "b"`), &out))
+	require.Equal(t, "ab\n", out.String())
+
+	out.Reset()
+	require.NoError(t, executeInline(ctx, []byte("x := 1"), &out))
+	require.Equal(t, "", out.String())
+
+	out.Reset()
+	require.NoError(t, executeInline(ctx,
+		[]byte(`return import("strings").ToUpper("x")`), &out))
+	require.Equal(t, "X\n", out.String())
+
+	out.Reset()
+	err := executeInline(ctx, []byte(`throw "x"`), &out)
+	require.Error(t, err)
+	require.Equal(t, "error: x", err.Error())
+	require.Equal(t, "", out.String())
+
+	err = executeInline(ctx, []byte(`return (`), &out)
+	require.Error(t, err)
+}
+
+func TestExecuteScriptExit(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var out bytes.Buffer
+	err := executeInline(ctx, []byte(`
+	try {
+		exit(3)
+	} catch err {
+		return "caught"
+	} finally {
+		x := "finally"
+	}`), &out)
+	require.Error(t, err)
+	code, ok := ugo.ExitCode(err)
+	require.True(t, ok)
+	require.Equal(t, 3, code)
+	require.Equal(t, "", out.String())
+
+	err = executeScript(ctx, "(test)", ".", []byte(`exit()`), nil)
+	code, ok = ugo.ExitCode(err)
+	require.True(t, ok)
+	require.Equal(t, 0, code)
+}
+
 func testHasPrefix(t *testing.T, s, pref string) {
 	t.Helper()
 	v := strings.HasPrefix(s, pref)
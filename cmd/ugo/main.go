@@ -16,6 +16,7 @@ import (
 	"io"
 	"io/ioutil"
 	"os"
+	"os/exec"
 	"path/filepath"
 	"runtime"
 	"sort"
@@ -29,10 +30,19 @@ import (
 	"github.com/ozanh/ugo/importers"
 	"github.com/ozanh/ugo/token"
 
+	ugobits "github.com/ozanh/ugo/stdlib/bits"
+	ugodebug "github.com/ozanh/ugo/stdlib/debug"
+	ugodecimal "github.com/ozanh/ugo/stdlib/decimal"
+	ugoencoding "github.com/ozanh/ugo/stdlib/encoding"
 	ugofmt "github.com/ozanh/ugo/stdlib/fmt"
+	ugohash "github.com/ozanh/ugo/stdlib/hash"
 	ugojson "github.com/ozanh/ugo/stdlib/json"
+	ugopath "github.com/ozanh/ugo/stdlib/path"
+	ugoruntime "github.com/ozanh/ugo/stdlib/runtime"
 	ugostrings "github.com/ozanh/ugo/stdlib/strings"
+	ugosync "github.com/ozanh/ugo/stdlib/sync"
 	ugotime "github.com/ozanh/ugo/stdlib/time"
+	ugovalidate "github.com/ozanh/ugo/stdlib/validate"
 )
 
 const (
@@ -41,12 +51,26 @@ const (
 	promptPrefix2 = "... "
 )
 
+// ANSI escape codes to colorize results.
+const (
+	colorReset   = "\x1b[0m"
+	colorRed     = "\x1b[31m"
+	colorGreen   = "\x1b[32m"
+	colorYellow  = "\x1b[33m"
+	colorMagenta = "\x1b[35m"
+	colorCyan    = "\x1b[36m"
+)
+
+// pagerMinLines is the minimum number of lines of a result to page it.
+const pagerMinLines = 50
+
 var (
 	noOptimizer    bool
 	traceEnabled   bool
 	traceParser    bool
 	traceOptimizer bool
 	traceCompiler  bool
+	inlineScript   string
 )
 
 var suggestions []suggest
@@ -84,7 +108,10 @@ type repl struct {
 	script       *bytes.Buffer
 	lastBytecode *ugo.Bytecode
 	lastResult   ugo.Object
+	lastDuration time.Duration
 	isMultiline  bool
+	color        bool
+	pager        bool
 }
 
 func newREPL(ctx context.Context, stdout io.Writer) *repl {
@@ -108,11 +135,14 @@ func newREPL(ctx context.Context, stdout io.Writer) *repl {
 		opts.Trace = stdout
 	}
 
+	tty := isTerminal(stdout)
 	r := &repl{
 		ctx:    ctx,
 		eval:   ugo.NewEval(opts, scriptGlobals),
 		out:    stdout,
 		script: bytes.NewBuffer(nil),
+		color:  tty,
+		pager:  tty,
 	}
 	r.setSymbolSuggestions()
 
@@ -132,6 +162,8 @@ func newREPL(ctx context.Context, stdout io.Writer) *repl {
 		".symbols+":      r.cmdSymbolsVerbose,
 		".modules_cache": r.cmdModulesCache,
 		".memory_stats":  r.cmdMemoryStats,
+		".time":          r.cmdTime,
+		".color":         r.cmdColor,
 		".reset":         func(string) error { return errReset },
 		".exit":          func(string) error { return errExit },
 	}
@@ -269,6 +301,32 @@ func (r *repl) cmdMemoryStats(_ string) error {
 	return nil
 }
 
+func (r *repl) cmdTime(_ string) error {
+	_, _ = fmt.Fprintf(r.out, "Last run took %s\n", r.lastDuration)
+	return nil
+}
+
+func (r *repl) cmdColor(line string) error {
+	fields := strings.Fields(line)
+	if len(fields) > 1 {
+		switch fields[1] {
+		case "on":
+			r.color = true
+		case "off":
+			r.color = false
+		default:
+			_, _ = fmt.Fprintln(r.out, "usage: .color [on|off]")
+			return nil
+		}
+	}
+	if r.color {
+		_, _ = fmt.Fprintln(r.out, "color is on")
+	} else {
+		_, _ = fmt.Fprintln(r.out, "color is off")
+	}
+	return nil
+}
+
 func (r *repl) cmdModulesCache(_ string) error {
 	_, _ = fmt.Fprintf(r.out, "%v\n", r.eval.ModulesCache)
 	return nil
@@ -279,6 +337,54 @@ func (r *repl) writeString(msg string) {
 	_, _ = fmt.Fprintln(r.out)
 }
 
+// writeResult writes the result message, large messages are paged if output
+// is a terminal.
+func (r *repl) writeResult(msg string) {
+	if r.pager && strings.Count(msg, "\n") >= pagerMinLines {
+		if err := r.page(msg); err == nil {
+			return
+		}
+	}
+	r.writeString(msg)
+}
+
+// page runs the pager set in PAGER environment variable or less to show msg.
+func (r *repl) page(msg string) error {
+	pager := strings.Fields(os.Getenv("PAGER"))
+	if len(pager) == 0 {
+		pager = []string{"less", "-R"}
+	}
+
+	cmd := exec.CommandContext(r.ctx, pager[0], pager[1:]...)
+	cmd.Stdin = strings.NewReader(msg + "\n")
+	cmd.Stdout = r.out
+	cmd.Stderr = os.Stderr
+	return cmd.Run()
+}
+
+func (r *repl) colorize(color, s string) string {
+	if !r.color || color == "" {
+		return s
+	}
+	return color + s + colorReset
+}
+
+func resultColor(o ugo.Object) string {
+	switch o.(type) {
+	case ugo.String, ugo.Bytes:
+		return colorGreen
+	case ugo.Int, ugo.Uint, ugo.Float:
+		return colorCyan
+	case ugo.Char:
+		return colorYellow
+	case ugo.Bool, *ugo.UndefinedType:
+		return colorMagenta
+	case *ugo.Error, *ugo.RuntimeError:
+		return colorRed
+	}
+	return ""
+}
+
 func (r *repl) execute(line string) error {
 	switch {
 	case !r.isMultiline && line == "":
@@ -308,28 +414,48 @@ func (r *repl) execute(line string) error {
 func (r *repl) executeScript() {
 	var err error
 
+	start := time.Now()
 	r.lastResult, r.lastBytecode, err = r.eval.Run(r.ctx, r.script.Bytes())
+	r.lastDuration = time.Since(start)
 	if err != nil {
-		r.writeString(fmt.Sprintf("\n!   %+v", err))
+		r.writeResult("\n!   " + r.colorize(colorRed, fmt.Sprintf("%+v", err)))
 		return
 	}
 
+	var s string
 	switch v := r.lastResult.(type) {
 	case ugo.String:
-		r.writeString(fmt.Sprintf("\n⇦   %q", string(v)))
+		s = fmt.Sprintf("%q", string(v))
 	case ugo.Char:
-		r.writeString(fmt.Sprintf("\n⇦   %q", rune(v)))
+		s = fmt.Sprintf("%q", rune(v))
 	case ugo.Bytes:
-		r.writeString(fmt.Sprintf("\n⇦   %v", []byte(v)))
+		s = fmt.Sprintf("%v", []byte(v))
 	default:
-		r.writeString(fmt.Sprintf("\n⇦   %v", r.lastResult))
+		s = fmt.Sprintf("%v", r.lastResult)
 	}
+	r.writeResult("\n⇦   " + r.colorize(resultColor(r.lastResult), s))
 }
 
 func (r *repl) setSymbolSuggestions() {
 	symbols := r.eval.Opts.SymbolTable.Symbols()
 	suggestions = suggestions[:initialSuggLen]
 
+	extras := r.eval.Opts.SymbolTable.ExtraBuiltins()
+	names := make([]string, 0, len(extras))
+	for k := range extras {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+	for _, k := range names {
+		suggestions = append(suggestions,
+			suggest{
+				text:        k,
+				description: "Extra Builtin",
+				typ:         "builtin",
+			},
+		)
+	}
+
 	for _, s := range symbols {
 		if s.Scope != ugo.ScopeBuiltin {
 			suggestions = append(suggestions,
@@ -364,7 +490,7 @@ func (r *repl) run(history io.Reader) error {
 	defer line.Close()
 
 	line.SetMultiLineMode(true)
-	line.SetCompleter(complete)
+	line.SetCompleter(r.completeLine)
 	_, err := line.ReadHistory(history)
 	if err != nil {
 		err = &ugo.Error{Message: "failed history read", Cause: err}
@@ -409,6 +535,112 @@ func complete(line string) (completions []string) {
 	return
 }
 
+// completeLine completes the selectors of maps in the REPL state like "m.k"
+// with the keys of the map, it falls back to complete for other inputs.
+func (r *repl) completeLine(line string) []string {
+	start := len(line)
+	for start > 0 {
+		c := line[start-1]
+		if c != '.' && c != '_' && !isAlphaNumeric(c) {
+			break
+		}
+		start--
+	}
+
+	parts := strings.Split(line[start:], ".")
+	if len(parts) < 2 || parts[0] == "" {
+		return complete(line)
+	}
+
+	obj := r.lookupSymbol(parts[0])
+	for _, p := range parts[1 : len(parts)-1] {
+		if obj == nil {
+			break
+		}
+		obj = selectorKeys(obj)[p]
+	}
+	if obj == nil {
+		return complete(line)
+	}
+
+	var keys []string
+	partial := parts[len(parts)-1]
+	for k := range selectorKeys(obj) {
+		if strings.HasPrefix(k, partial) {
+			keys = append(keys, k)
+		}
+	}
+	if len(keys) == 0 {
+		return complete(line)
+	}
+	sort.Strings(keys)
+
+	prefix := line[:len(line)-len(partial)]
+	completions := make([]string, len(keys))
+	for i, k := range keys {
+		completions[i] = prefix + k
+	}
+	return completions
+}
+
+// lookupSymbol returns the value of local or global symbol from the eval
+// state or nil if it is not found.
+func (r *repl) lookupSymbol(name string) ugo.Object {
+	for _, sym := range r.eval.Opts.SymbolTable.Symbols() {
+		if sym.Name != name {
+			continue
+		}
+		switch sym.Scope {
+		case ugo.ScopeLocal:
+			if sym.Index < len(r.eval.Locals) {
+				return r.eval.Locals[sym.Index]
+			}
+		case ugo.ScopeGlobal:
+			if r.eval.Globals != nil {
+				v, err := r.eval.Globals.IndexGet(ugo.String(name))
+				if err == nil {
+					return v
+				}
+			}
+		}
+		return nil
+	}
+	return nil
+}
+
+// selectorKeys returns the values of the keys which can be used as selector
+// for maps and errors without calling any script code.
+func selectorKeys(obj ugo.Object) ugo.Map {
+	switch v := obj.(type) {
+	case ugo.Map:
+		return v
+	case *ugo.SyncMap:
+		return v.Copy().(*ugo.SyncMap).Value
+	case *ugo.Error:
+		m := ugo.Map{
+			"Name":    ugo.String(v.Name),
+			"Message": ugo.String(v.Message),
+			"New":     ugo.Undefined,
+			"Data":    v.Data,
+		}
+		for k, val := range v.Data {
+			if _, ok := m[k]; !ok {
+				m[k] = val
+			}
+		}
+		return m
+	case *ugo.RuntimeError:
+		if v.Err != nil {
+			return selectorKeys(v.Err)
+		}
+	}
+	return nil
+}
+
+func isAlphaNumeric(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9'
+}
+
 func defaultSymbolTable() *ugo.SymbolTable {
 	table := ugo.NewSymbolTable()
 	_, err := table.DefineGlobal("Gosched")
@@ -424,6 +656,15 @@ func defaultModuleMap(workdir string) *ugo.ModuleMap {
 		AddBuiltinModule("strings", ugostrings.Module).
 		AddBuiltinModule("fmt", ugofmt.Module).
 		AddBuiltinModule("json", ugojson.Module).
+		AddBuiltinModule("path", ugopath.Module).
+		AddBuiltinModule("debug", ugodebug.Module).
+		AddBuiltinModule("runtime", ugoruntime.Module).
+		AddBuiltinModule("decimal", ugodecimal.Module).
+		AddBuiltinModule("hash", ugohash.Module).
+		AddBuiltinModule("encoding", ugoencoding.Module).
+		AddBuiltinModule("sync", ugosync.Module).
+		AddBuiltinModule("validate", ugovalidate.Module).
+		AddBuiltinModule("bits", ugobits.Module).
 		SetExtImporter(
 			&importers.FileImporter{
 				WorkDir:    workdir,
@@ -461,6 +702,8 @@ func initSuggestions() {
 		{text: ".return+", description: "Print Last Return Result (verbose)"},
 		{text: ".modules_cache", description: "Print Modules Cache"},
 		{text: ".memory_stats", description: "Print Memory Stats"},
+		{text: ".time", description: "Print Execution Time of Last Run"},
+		{text: ".color", description: "Print or Set Color Output [on|off]"},
 		{text: ".gc", description: "Run Garbage Collector"},
 		{text: ".symbols", description: "Print Symbols"},
 		{text: ".symbols+", description: "Print Symbols (verbose)"},
@@ -509,15 +752,19 @@ func parseFlags(
 	flagset.StringVar(&trace, "trace", "",
 		`Comma separated units: -trace parser,optimizer,compiler`)
 	flagset.BoolVar(&noOptimizer, "no-optimizer", false, `Disable optimization`)
+	flagset.StringVar(&inlineScript, "e", "",
+		"Execute given script instead of a script file and print the returned "+
+			"value if it is not undefined")
 	flagset.DurationVar(&timeout, "timeout", 0,
-		"Program timeout. It is applicable if a script file is provided and "+
-			"must be non-zero duration")
+		"Program timeout. It is applicable if a script file or inline script "+
+			"is provided and must be non-zero duration")
 
 	flagset.Usage = func() {
 		_, _ = fmt.Fprint(flagset.Output(),
 			"Usage: ugo [flags] [uGO script file]\n\n",
 			"If script file is not provided, REPL terminal application is started\n",
-			"Use - to read from stdin\n\n",
+			"Use - to read from stdin\n",
+			"Use -e to execute inline script, e.g. ugo -e 'return 1+2'\n\n",
 			"\nFlags:\n",
 		)
 		flagset.PrintDefaults()
@@ -541,7 +788,7 @@ func parseFlags(
 		}
 	}
 
-	if flagset.NArg() != 1 {
+	if inlineScript != "" || flagset.NArg() != 1 {
 		return
 	}
 
@@ -560,6 +807,34 @@ func executeScript(
 	script []byte,
 	traceOut io.Writer,
 ) error {
+	_, err := runScript(ctx, modulePath, workdir, script, traceOut)
+	return err
+}
+
+// executeInline executes the script given with -e flag and writes the returned
+// value to out if it is not undefined.
+func executeInline(
+	ctx context.Context,
+	script []byte,
+	out io.Writer,
+) error {
+	ret, err := runScript(ctx, "(eval)", ".", script, out)
+	if err != nil {
+		return err
+	}
+	if ret != nil && ret != ugo.Undefined {
+		_, _ = fmt.Fprintln(out, ret)
+	}
+	return nil
+}
+
+func runScript(
+	ctx context.Context,
+	modulePath string,
+	workdir string,
+	script []byte,
+	traceOut io.Writer,
+) (ret ugo.Object, err error) {
 	opts := ugo.DefaultCompilerOptions
 	opts.SymbolTable = defaultSymbolTable()
 	opts.ModuleMap = defaultModuleMap(workdir)
@@ -574,7 +849,7 @@ func executeScript(
 
 	bc, err := ugo.Compile(script, opts)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	vm := ugo.NewVM(bc).SetRecover(true)
@@ -582,7 +857,7 @@ func executeScript(
 	done := make(chan struct{})
 	go func() {
 		defer close(done)
-		_, err = vm.Run(scriptGlobals)
+		ret, err = vm.Run(scriptGlobals)
 	}()
 
 	select {
@@ -594,7 +869,7 @@ func executeScript(
 			err = ctx.Err()
 		}
 	}
-	return err
+	return ret, err
 }
 
 func hasMode(f *os.File, m os.FileMode) bool {
@@ -605,6 +880,11 @@ func hasMode(f *os.File, m os.FileMode) bool {
 	return info.Mode()&m == m
 }
 
+func isTerminal(w io.Writer) bool {
+	f, ok := w.(*os.File)
+	return ok && hasMode(f, os.ModeCharDevice)
+}
+
 func hasInputRedirection() bool {
 	info, err := os.Stdin.Stat()
 	if err != nil {
@@ -634,6 +914,16 @@ func main() {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
+	if inlineScript != "" {
+		if timeout > 0 {
+			ctx, cancel = context.WithTimeout(ctx, timeout)
+			defer cancel()
+		}
+		err = executeInline(ctx, []byte(inlineScript), os.Stdout)
+		checkErr(err, cancel)
+		return
+	}
+
 	if len(filePath) == 0 && hasInputRedirection() {
 		filePath = "-"
 	}
@@ -705,6 +995,13 @@ func checkErr(err error, fn func()) {
 		return
 	}
 
+	if code, ok := ugo.ExitCode(err); ok {
+		if fn != nil {
+			fn()
+		}
+		os.Exit(code)
+	}
+
 	defer os.Exit(1)
 	_, _ = fmt.Fprintf(os.Stderr, "%+v\n", err)
 	if fn != nil {
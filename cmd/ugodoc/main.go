@@ -29,10 +29,19 @@ import (
 
 	"github.com/ozanh/ugo"
 
+	ugobits "github.com/ozanh/ugo/stdlib/bits"
+	ugodebug "github.com/ozanh/ugo/stdlib/debug"
+	ugodecimal "github.com/ozanh/ugo/stdlib/decimal"
+	ugoencoding "github.com/ozanh/ugo/stdlib/encoding"
 	ugofmt "github.com/ozanh/ugo/stdlib/fmt"
+	ugohash "github.com/ozanh/ugo/stdlib/hash"
 	ugojson "github.com/ozanh/ugo/stdlib/json"
+	ugopath "github.com/ozanh/ugo/stdlib/path"
+	ugoruntime "github.com/ozanh/ugo/stdlib/runtime"
 	ugostrings "github.com/ozanh/ugo/stdlib/strings"
+	ugosync "github.com/ozanh/ugo/stdlib/sync"
 	ugotime "github.com/ozanh/ugo/stdlib/time"
+	ugovalidate "github.com/ozanh/ugo/stdlib/validate"
 )
 
 const ugoDocPrefix = "ugo:doc"
@@ -180,6 +189,24 @@ func getModuleItem(module, key string) string {
 		moduleMap = ugofmt.Module
 	case "json":
 		moduleMap = ugojson.Module
+	case "path":
+		moduleMap = ugopath.Module
+	case "debug":
+		moduleMap = ugodebug.Module
+	case "runtime":
+		moduleMap = ugoruntime.Module
+	case "decimal":
+		moduleMap = ugodecimal.Module
+	case "hash":
+		moduleMap = ugohash.Module
+	case "encoding":
+		moduleMap = ugoencoding.Module
+	case "sync":
+		moduleMap = ugosync.Module
+	case "validate":
+		moduleMap = ugovalidate.Module
+	case "bits":
+		moduleMap = ugobits.Module
 	default:
 		panic(fmt.Errorf("unknown module:%s", module))
 	}
@@ -54,10 +54,14 @@ type (
 		moduleStore   *moduleStore
 		modulePath    string
 		variadic      bool
+		results       []*Symbol
 		loops         []*loopStmts
 		loopIndex     int
 		tryCatchIndex int
+		catchErrors   []*Symbol
+		finallyDepth  int
 		iotaVal       int
+		extraBuiltins map[string]int
 		opts          CompilerOptions
 		trace         io.Writer
 		indent        int
@@ -76,8 +80,47 @@ type (
 		OptimizerMaxCycle int
 		OptimizeConst     bool
 		OptimizeExpr      bool
-		moduleStore       *moduleStore
-		constsCache       map[Object]int
+		// StrictComparison makes == and != operators consider values of
+		// different types unequal and other relational operators throw
+		// TypeError for them. Frozen values are compared like their mutable
+		// versions.
+		StrictComparison bool
+		// DisabledBuiltins are builtin names disabled in SymbolTable so using
+		// them results in an unresolved reference compile error, which is
+		// useful to sandbox untrusted scripts.
+		DisabledBuiltins []string
+		// DeterministicMaps makes for-in loops iterate map and syncMap keys in
+		// sorted order, which is slower but reproducible.
+		DeterministicMaps bool
+		// CopyOnAssign enables value semantics for arrays and maps. Arrays and
+		// maps are deep copied when they are assigned to variables, indexes
+		// or selectors so that the assigned value does not share its
+		// elements with the source. Function arguments and return values are
+		// not copied. It is disabled by default and arrays and maps are
+		// assigned by reference.
+		CopyOnAssign bool
+		// TypeCheck enables reporting operations on constant values which
+		// always fail at runtime, like "a" - "b", as warnings to OnWarning.
+		TypeCheck bool
+		// WarnShadowing enables reporting declarations of variables and
+		// parameters shadowing builtins or having keyword-like names, like
+		// "append" or "nil", as warnings to OnWarning.
+		WarnShadowing bool
+		// OnWarning is called for each compile time warning if it is set.
+		OnWarning func(w *Warning)
+		// AlwaysSourceMap guarantees that source map of every compiled
+		// function has a valid source position for each instruction, which
+		// is required by debuggers. Instructions generated by the compiler
+		// without a corresponding node get position of the previous
+		// instruction.
+		AlwaysSourceMap bool
+		// ExtraBuiltins are resolved like builtins in scripts and source
+		// modules, without defining global variables. Objects are stored as
+		// constants of the bytecode. They take precedence over the builtins
+		// having the same name.
+		ExtraBuiltins map[string]Object
+		moduleStore   *moduleStore
+		constsCache   map[Object]int
 	}
 
 	// CompilerError represents a compiler error.
@@ -102,11 +145,13 @@ type (
 	}
 
 	// loopStmts represents a loopStmts construct that the compiler uses to
-	// track the current loopStmts.
+	// track the current loopStmts. Labeled blocks are tracked as loops with a
+	// label, which can only be exited with a labeled break statement.
 	loopStmts struct {
 		continues         []int
 		breaks            []int
 		lastTryCatchIndex int
+		label             string
 	}
 )
 
@@ -125,6 +170,14 @@ func NewCompiler(file *parser.SourceFile, opts CompilerOptions) *Compiler {
 		opts.SymbolTable = NewSymbolTable()
 	}
 
+	if len(opts.DisabledBuiltins) > 0 {
+		opts.SymbolTable.DisableBuiltin(opts.DisabledBuiltins...)
+	}
+
+	if len(opts.ExtraBuiltins) > 0 {
+		opts.SymbolTable.SetExtraBuiltins(opts.ExtraBuiltins)
+	}
+
 	if opts.constsCache == nil {
 		opts.constsCache = make(map[Object]int)
 		for i := range opts.Constants {
@@ -183,6 +236,19 @@ func Compile(script []byte, opts CompilerOptions) (*Bytecode, error) {
 		return nil, err
 	}
 
+	if opts.OnWarning != nil {
+		if opts.TypeCheck {
+			for _, w := range typeCheck(pf, opts) {
+				opts.OnWarning(w)
+			}
+		}
+		if opts.WarnShadowing {
+			for _, w := range shadowCheck(pf, opts) {
+				opts.OnWarning(w)
+			}
+		}
+	}
+
 	compiler := NewCompiler(srcFile, opts)
 	compiler.SetGlobalSymbolsIndex()
 
@@ -263,7 +329,16 @@ func (c *Compiler) Bytecode() *Bytecode {
 	}
 
 	if lastOp != OpReturn || len(jumpPos) > 0 {
-		c.emit(nil, OpReturn, 0)
+		if len(c.results) > 0 {
+			c.emitResults(nil)
+			c.emit(nil, OpReturn, 1)
+		} else {
+			c.emit(nil, OpReturn, 0)
+		}
+	}
+
+	if c.opts.AlwaysSourceMap {
+		c.fillSourceMap()
 	}
 
 	return &Bytecode{
@@ -280,6 +355,40 @@ func (c *Compiler) Bytecode() *Bytecode {
 	}
 }
 
+// fillSourceMap sets source position of each instruction without a valid
+// position to the position of the previous instruction. Leading instructions
+// without a position get the first valid position, or the beginning of the
+// file if there is none.
+func (c *Compiler) fillSourceMap() {
+	var offsets []int
+	var operands = make([]int, 0, 4)
+	var offset int
+	for i := 0; i < len(c.instructions); i += offset + 1 {
+		offsets = append(offsets, i)
+		operands, offset = ReadOperands(
+			OpcodeOperands[c.instructions[i]],
+			c.instructions[i+1:],
+			operands,
+		)
+	}
+
+	last := parser.Pos(c.file.Base)
+	for _, i := range offsets {
+		if p := parser.Pos(c.sourceMap[i]); p != parser.NoPos {
+			last = p
+			break
+		}
+	}
+
+	for _, i := range offsets {
+		if p := parser.Pos(c.sourceMap[i]); p != parser.NoPos {
+			last = p
+		} else {
+			c.sourceMap[i] = int(last)
+		}
+	}
+}
+
 // Compile compiles parser.Node and builds Bytecode.
 func (c *Compiler) Compile(node parser.Node) error {
 	if c.trace != nil {
@@ -293,10 +402,8 @@ func (c *Compiler) Compile(node parser.Node) error {
 
 	switch node := node.(type) {
 	case *parser.File:
-		for _, stmt := range node.Stmts {
-			if err := c.Compile(stmt); err != nil {
-				return err
-			}
+		if err := c.compileStmts(node.Stmts); err != nil {
+			return err
 		}
 	case *parser.ExprStmt:
 		if err := c.Compile(node.Expr); err != nil {
@@ -350,16 +457,24 @@ func (c *Compiler) Compile(node parser.Node) error {
 		return c.compileCatchStmt(node)
 	case *parser.FinallyStmt:
 		return c.compileFinallyStmt(node)
+	case *parser.CleanupStmt:
+		return c.errorf(node, "cleanup statement not in a block")
 	case *parser.ThrowStmt:
 		return c.compileThrowStmt(node)
+	case *parser.MatchStmt:
+		return c.compileMatchStmt(node)
 	case *parser.ForStmt:
 		return c.compileForStmt(node)
+	case *parser.DoWhileStmt:
+		return c.compileDoWhileStmt(node)
 	case *parser.ForInStmt:
 		return c.compileForInStmt(node)
 	case *parser.BranchStmt:
 		return c.compileBranchStmt(node)
 	case *parser.BlockStmt:
 		return c.compileBlockStmt(node)
+	case *parser.LabeledStmt:
+		return c.compileLabeledStmt(node)
 	case *parser.DeclStmt:
 		return c.compileDeclStmt(node)
 	case *parser.AssignStmt:
@@ -523,6 +638,15 @@ func (c *Compiler) getModule(name string) (moduleStoreItem, bool) {
 	return indexes, ok
 }
 
+// moduleName returns the module path of the compiler, "(main)" is returned for
+// the main module if module path is not set.
+func (c *Compiler) moduleName() string {
+	if c.modulePath == "" {
+		return "(main)"
+	}
+	return c.modulePath
+}
+
 func (c *Compiler) baseModuleMap() *ModuleMap {
 	if c.parent == nil {
 		return c.moduleMap
@@ -597,8 +721,21 @@ func (c *Compiler) leaveLoop() {
 }
 
 func (c *Compiler) currentLoop() *loopStmts {
-	if c.loopIndex >= 0 {
-		return c.loops[c.loopIndex]
+	for i := c.loopIndex; i >= 0; i-- {
+		if c.loops[i].label == "" {
+			return c.loops[i]
+		}
+	}
+	return nil
+}
+
+// labeledBlock returns the enclosing labeled block with the given label in
+// the current function, or nil if it does not exist.
+func (c *Compiler) labeledBlock(label string) *loopStmts {
+	for i := c.loopIndex; i >= 0; i-- {
+		if c.loops[i].label == label {
+			return c.loops[i]
+		}
 	}
 	return nil
 }
@@ -621,6 +758,11 @@ func (c *Compiler) fork(
 		OptimizerMaxCycle: c.opts.OptimizerMaxCycle,
 		OptimizeConst:     c.opts.OptimizeConst,
 		OptimizeExpr:      c.opts.OptimizeExpr,
+		StrictComparison:  c.opts.StrictComparison,
+		DeterministicMaps: c.opts.DeterministicMaps,
+		CopyOnAssign:      c.opts.CopyOnAssign,
+		AlwaysSourceMap:   c.opts.AlwaysSourceMap,
+		ExtraBuiltins:     c.symbolTable.ExtraBuiltins(),
 		moduleStore:       c.moduleStore,
 		constsCache:       c.constsCache,
 	})
@@ -725,12 +867,12 @@ func MakeInstruction(buf []byte, op Opcode, args ...int) ([]byte, error) {
 		return buf, nil
 	case OpGetBuiltin, OpReturn, OpBinaryOp, OpUnary, OpGetIndex, OpGetLocal,
 		OpSetLocal, OpGetFree, OpSetFree, OpGetLocalPtr, OpGetFreePtr, OpThrow,
-		OpFinalizer, OpDefineLocal:
+		OpFinalizer, OpDefineLocal, OpStrictCompare:
 		buf = append(buf, byte(args[0]))
 		return buf, nil
 	case OpEqual, OpNotEqual, OpNull, OpTrue, OpFalse, OpPop, OpSliceIndex,
 		OpSetIndex, OpIterInit, OpIterNext, OpIterKey, OpIterValue,
-		OpSetupCatch, OpSetupFinally, OpNoOp:
+		OpSetupCatch, OpSetupFinally, OpNoOp, OpIterInitSorted, OpCopyValue:
 		return buf, nil
 	default:
 		return buf, &Error{
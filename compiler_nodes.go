@@ -81,8 +81,8 @@ func (c *Compiler) compileTryStmt(node *parser.TryStmt) error {
 			//
 			// catch block is optional.
 			// if err is elided  in `catch {}`, OpPop removes the error from stack.
-			// catch pops the error from error handler, re-throw requires explicit
-			// throw expression `throw err`.
+			// catch pops the error from error handler, re-throw requires
+			// `throw err` or bare `throw` which throws the caught error.
 		} finally {
 			// emit: OpSetupFinally
 			//
@@ -105,10 +105,8 @@ func (c *Compiler) compileTryStmt(node *parser.TryStmt) error {
 	var catchPos, finallyPos int
 	if node.Body != nil && len(node.Body.Stmts) > 0 {
 		// in order not to fork symbol table in Body, compile stmts here instead of in *BlockStmt
-		for _, stmt := range node.Body.Stmts {
-			if err := c.Compile(stmt); err != nil {
-				return err
-			}
+		if err := c.compileStmts(node.Body.Stmts); err != nil {
+			return err
 		}
 	}
 
@@ -154,6 +152,24 @@ func (c *Compiler) compileTryStmt(node *parser.TryStmt) error {
 
 func (c *Compiler) compileCatchStmt(node *parser.CatchStmt) error {
 	c.emit(node, OpSetupCatch)
+	var errSymbol *Symbol
+	if node.Body != nil && hasBareThrow(node.Body) {
+		// keep caught error in a hidden variable to re-throw it with bare
+		// throw, even if catch variable is elided or reassigned.
+		var exists bool
+		errSymbol, exists = c.symbolTable.DefineLocal(":error")
+		if exists {
+			c.emit(node, OpSetLocal, errSymbol.Index)
+		} else {
+			c.emit(node, OpDefineLocal, errSymbol.Index)
+		}
+		if node.Ident != nil {
+			c.emit(node, OpGetLocal, errSymbol.Index)
+		}
+		c.catchErrors = append(c.catchErrors, errSymbol)
+		defer func() { c.catchErrors = c.catchErrors[:len(c.catchErrors)-1] }()
+	}
+
 	if node.Ident != nil {
 		symbol, exists := c.symbolTable.DefineLocal(node.Ident.Name)
 		if exists {
@@ -161,7 +177,7 @@ func (c *Compiler) compileCatchStmt(node *parser.CatchStmt) error {
 		} else {
 			c.emit(node, OpDefineLocal, symbol.Index)
 		}
-	} else {
+	} else if errSymbol == nil {
 		c.emit(node, OpPop)
 	}
 
@@ -170,12 +186,7 @@ func (c *Compiler) compileCatchStmt(node *parser.CatchStmt) error {
 	}
 
 	// in order not to fork symbol table in Body, compile stmts here instead of in *BlockStmt
-	for _, stmt := range node.Body.Stmts {
-		if err := c.Compile(stmt); err != nil {
-			return err
-		}
-	}
-	return nil
+	return c.compileStmts(node.Body.Stmts)
 }
 
 func (c *Compiler) compileFinallyStmt(node *parser.FinallyStmt) error {
@@ -183,11 +194,48 @@ func (c *Compiler) compileFinallyStmt(node *parser.FinallyStmt) error {
 		return nil
 	}
 
+	c.finallyDepth++
+	defer func() { c.finallyDepth-- }()
+
 	// in order not to fork symbol table in Body, compile stmts here instead of in *BlockStmt
-	for _, stmt := range node.Body.Stmts {
-		if err := c.Compile(stmt); err != nil {
-			return err
+	return c.compileStmts(node.Body.Stmts)
+}
+
+// compileStmts compiles statements of a block. A cleanup statement is
+// compiled as a try statement whose body is the rest of the statements and
+// whose finally block evaluates the cleanup expression, so cleanups run in
+// reverse order when the block exits normally, by a branch or by an error.
+func (c *Compiler) compileStmts(stmts []parser.Stmt) error {
+	for i, stmt := range stmts {
+		cleanup, ok := stmt.(*parser.CleanupStmt)
+		if !ok {
+			if err := c.Compile(stmt); err != nil {
+				return err
+			}
+			continue
+		}
+
+		rest := stmts[i+1:]
+		end := cleanup.End()
+		if len(rest) > 0 {
+			end = rest[len(rest)-1].End()
 		}
+		return c.Compile(&parser.TryStmt{
+			TryPos: cleanup.CleanupPos,
+			Body: &parser.BlockStmt{
+				Stmts:  rest,
+				LBrace: cleanup.CleanupPos,
+				RBrace: end,
+			},
+			Finally: &parser.FinallyStmt{
+				FinallyPos: cleanup.CleanupPos,
+				Body: &parser.BlockStmt{
+					Stmts:  []parser.Stmt{&parser.ExprStmt{Expr: cleanup.Expr}},
+					LBrace: cleanup.Expr.Pos(),
+					RBrace: cleanup.Expr.End(),
+				},
+			},
+		})
 	}
 	return nil
 }
@@ -197,11 +245,137 @@ func (c *Compiler) compileThrowStmt(node *parser.ThrowStmt) error {
 		if err := c.Compile(node.Expr); err != nil {
 			return err
 		}
+	} else {
+		// bare throw re-throws the error of innermost catch block
+		if len(c.catchErrors) == 0 {
+			if c.finallyDepth > 0 {
+				return c.errorf(node, "throw without expression in finally "+
+					"block, caught error is only available in catch block")
+			}
+			return c.errorf(node, "throw without expression outside of catch "+
+				"block, use 'throw <expression>' to throw an error")
+		}
+		c.emit(node, OpGetLocal, c.catchErrors[len(c.catchErrors)-1].Index)
 	}
 	c.emit(node, OpThrow, 1)
 	return nil
 }
 
+// hasBareThrow reports whether block has a throw statement without expression
+// which belongs to the catch block of the block. Function literals and nested
+// catch blocks are not inspected.
+func hasBareThrow(block *parser.BlockStmt) bool {
+	var found bool
+	parser.Inspect(block, func(node parser.Node) bool {
+		switch n := node.(type) {
+		case *parser.FuncLit, *parser.CatchStmt:
+			return false
+		case *parser.ThrowStmt:
+			if n.Expr == nil {
+				found = true
+			}
+		}
+		return !found
+	})
+	return found
+}
+
+func (c *Compiler) compileMatchStmt(node *parser.MatchStmt) error {
+	c.symbolTable = c.symbolTable.Fork(true)
+	defer func() {
+		c.symbolTable = c.symbolTable.Parent(false)
+	}()
+
+	// match statement is compiled like following:
+	//
+	//   :match := subject
+	//   if isError(:match, Kind1) || isError(:match, Kind2) {
+	//     ... case body ...
+	//   } else if ... {
+	//   } else {
+	//     ... case _ body ...
+	//   }
+	//
+	// ":match" is a local variable but it will not conflict with other user
+	// variables because character ":" is not allowed in the variable names.
+	// If the subject is bound like `match e := subject {}`, e is used instead.
+	name := ":match"
+	if node.Ident != nil {
+		name = node.Ident.Name
+	}
+	subject, exists := c.symbolTable.DefineLocal(name)
+	if exists {
+		return c.errorf(node, "%s redeclared in this block", name)
+	}
+	if err := c.Compile(node.Subject); err != nil {
+		return err
+	}
+	c.emit(node, OpDefineLocal, subject.Index)
+
+	var endJumps []int
+	for i, cl := range node.Cases {
+		ok := isDefaultCase(cl)
+		if ok && i < len(node.Cases)-1 {
+			return c.errorf(node.Cases[i+1], "unreachable case clause")
+		}
+
+		nextJump := -1
+		if !ok {
+			var orJumps []int
+			for j, kind := range cl.Kinds {
+				c.emit(cl, OpGetBuiltin, int(BuiltinIsError))
+				c.emit(cl, OpGetLocal, subject.Index)
+				if err := c.Compile(kind); err != nil {
+					return err
+				}
+				c.emit(cl, OpCall, 2, 0)
+				if j < len(cl.Kinds)-1 {
+					orJumps = append(orJumps, c.emit(cl, OpOrJump, 0))
+				}
+			}
+			curPos := len(c.instructions)
+			for _, pos := range orJumps {
+				c.changeOperand(pos, curPos)
+			}
+			nextJump = c.emit(cl, OpJumpFalsy, 0)
+		}
+
+		if err := c.compileCaseBody(cl); err != nil {
+			return err
+		}
+
+		if nextJump > -1 {
+			endJumps = append(endJumps, c.emit(cl, OpJump, 0))
+			c.changeOperand(nextJump, len(c.instructions))
+		}
+	}
+
+	curPos := len(c.instructions)
+	for _, pos := range endJumps {
+		c.changeOperand(pos, curPos)
+	}
+	return nil
+}
+
+// isDefaultCase reports whether case clause is "case _:" which matches any
+// value. Other identifiers are resolved as error kinds, so that a misspelled
+// error kind is reported as an unresolved reference.
+func isDefaultCase(cl *parser.CaseClause) bool {
+	if len(cl.Kinds) != 1 {
+		return false
+	}
+	ident, ok := cl.Kinds[0].(*parser.Ident)
+	return ok && ident.Name == "_"
+}
+
+func (c *Compiler) compileCaseBody(cl *parser.CaseClause) error {
+	c.symbolTable = c.symbolTable.Fork(true)
+	defer func() {
+		c.symbolTable = c.symbolTable.Parent(false)
+	}()
+	return c.compileStmts(cl.Body)
+}
+
 func (c *Compiler) compileDeclStmt(node *parser.DeclStmt) error {
 	decl := node.Decl.(*parser.GenDecl)
 	if len(decl.Specs) == 0 {
@@ -375,6 +549,9 @@ func (c *Compiler) compileAssignStmt(
 		if err := c.Compile(expr); err != nil {
 			return err
 		}
+		if !isArrDestruct && (op == token.Assign || op == token.Define) {
+			c.emitCopyValue(node, expr)
+		}
 	}
 
 	if isArrDestruct {
@@ -444,6 +621,7 @@ func (c *Compiler) compileDestructuring(
 		c.emit(node, OpGetLocal, tempArrSymbol.Index)
 		c.emit(node, OpConstant, c.addConstant(Int(lhsIndex)))
 		c.emit(node, OpGetIndex, 1)
+		c.emitCopyValue(node, nil)
 		err := c.compileDefineAssign(node, expr, keyword, op, keyword != token.Const)
 		if err != nil {
 			return err
@@ -458,6 +636,43 @@ func (c *Compiler) compileDestructuring(
 	return nil
 }
 
+// emitCopyValue emits OpCopyValue to copy the assigned value on top of the
+// stack if CopyOnAssign option is set. Values of literals are not shared, so
+// they are not copied. A nil rhs means value is not known at compile time.
+func (c *Compiler) emitCopyValue(node parser.Node, rhs parser.Expr) {
+	if c.opts.CopyOnAssign && !isUnsharedLit(rhs) {
+		c.emit(node, OpCopyValue)
+	}
+}
+
+// isUnsharedLit reports whether expr creates a new value which cannot share
+// arrays or maps with other values.
+func isUnsharedLit(expr parser.Expr) bool {
+	switch e := expr.(type) {
+	case *parser.FuncLit, *parser.IntLit, *parser.UintLit, *parser.FloatLit,
+		*parser.CharLit, *parser.StringLit, *parser.BoolLit,
+		*parser.UndefinedLit:
+		return true
+	case *parser.ArrayLit:
+		for _, elem := range e.Elements {
+			if !isUnsharedLit(elem) {
+				return false
+			}
+		}
+		return true
+	case *parser.MapLit:
+		for _, elem := range e.Elements {
+			if !isUnsharedLit(elem.Value) {
+				return false
+			}
+		}
+		return true
+	case *parser.ParenExpr:
+		return isUnsharedLit(e.Expr)
+	}
+	return false
+}
+
 func (c *Compiler) compileDefine(
 	node parser.Node,
 	ident string,
@@ -583,8 +798,14 @@ func resolveAssignLHS(expr parser.Expr) (name string, selectors []parser.Expr) {
 func (c *Compiler) compileBranchStmt(node *parser.BranchStmt) error {
 	switch node.Token {
 	case token.Break:
-		curLoop := c.currentLoop()
-		if curLoop == nil {
+		var curLoop *loopStmts
+		if node.Label != nil {
+			curLoop = c.labeledBlock(node.Label.Name)
+			if curLoop == nil {
+				return c.errorf(node, "break label not defined: %s",
+					node.Label.Name)
+			}
+		} else if curLoop = c.currentLoop(); curLoop == nil {
 			return c.errorf(node, "break not allowed outside loop")
 		}
 
@@ -597,6 +818,11 @@ func (c *Compiler) compileBranchStmt(node *parser.BranchStmt) error {
 		}
 		curLoop.breaks = append(curLoop.breaks, pos)
 	case token.Continue:
+		if node.Label != nil {
+			// only blocks can be labeled
+			return c.errorf(node, "invalid continue label: %s",
+				node.Label.Name)
+		}
 		curLoop := c.currentLoop()
 		if curLoop == nil {
 			return c.errorf(node, "continue not allowed outside loop")
@@ -616,16 +842,38 @@ func (c *Compiler) compileBranchStmt(node *parser.BranchStmt) error {
 	return nil
 }
 
+func (c *Compiler) compileLabeledStmt(stmt *parser.LabeledStmt) error {
+	name := stmt.Label.Name
+	if c.labeledBlock(name) != nil {
+		return c.errorf(stmt, "label %s already defined", name)
+	}
+
+	block := c.enterLoop()
+	block.label = name
+
+	if err := c.Compile(stmt.Body); err != nil {
+		c.leaveLoop()
+		return err
+	}
+
+	c.leaveLoop()
+
+	// break statements with the label jump to the end of the block
+	postStmtPos := len(c.instructions)
+	for _, pos := range block.breaks {
+		c.changeOperand(pos, postStmtPos)
+	}
+	return nil
+}
+
 func (c *Compiler) compileBlockStmt(node *parser.BlockStmt) error {
 	if len(node.Stmts) == 0 {
 		return nil
 	}
 
 	c.symbolTable = c.symbolTable.Fork(true)
-	for _, stmt := range node.Stmts {
-		if err := c.Compile(stmt); err != nil {
-			return err
-		}
+	if err := c.compileStmts(node.Stmts); err != nil {
+		return err
 	}
 
 	c.symbolTable = c.symbolTable.Parent(false)
@@ -633,6 +881,15 @@ func (c *Compiler) compileBlockStmt(node *parser.BlockStmt) error {
 }
 
 func (c *Compiler) compileReturnStmt(node *parser.ReturnStmt) error {
+	if node.Result == nil && len(c.results) > 0 {
+		c.emitResults(node)
+		if c.tryCatchIndex > -1 {
+			c.emit(node, OpFinalizer, 0)
+		}
+		c.emit(node, OpReturn, 1)
+		return nil
+	}
+
 	if node.Result == nil {
 		if c.tryCatchIndex > -1 {
 			c.emit(node, OpFinalizer, 0)
@@ -653,6 +910,17 @@ func (c *Compiler) compileReturnStmt(node *parser.ReturnStmt) error {
 	return nil
 }
 
+// emitResults pushes the values of named results to the stack, multiple
+// results are returned as an array like comma separated return values.
+func (c *Compiler) emitResults(node parser.Node) {
+	for _, s := range c.results {
+		c.emit(node, OpGetLocal, s.Index)
+	}
+	if len(c.results) > 1 {
+		c.emit(node, OpArray, len(c.results))
+	}
+}
+
 func (c *Compiler) compileForStmt(stmt *parser.ForStmt) error {
 	c.symbolTable = c.symbolTable.Fork(true)
 	defer func() {
@@ -661,7 +929,7 @@ func (c *Compiler) compileForStmt(stmt *parser.ForStmt) error {
 
 	// init statement
 	if stmt.Init != nil {
-		if err := c.Compile(stmt.Init); err != nil {
+		if err := c.compileForClause(stmt.Init); err != nil {
 			return err
 		}
 	}
@@ -695,7 +963,7 @@ func (c *Compiler) compileForStmt(stmt *parser.ForStmt) error {
 
 	// post statement
 	if stmt.Post != nil {
-		if err := c.Compile(stmt.Post); err != nil {
+		if err := c.compileForClause(stmt.Post); err != nil {
 			return err
 		}
 	}
@@ -709,6 +977,89 @@ func (c *Compiler) compileForStmt(stmt *parser.ForStmt) error {
 		c.changeOperand(postCondPos, postStmtPos)
 	}
 
+	// else block is skipped by break statements
+	if stmt.Else != nil {
+		if err := c.compileLoopElse(stmt.Else); err != nil {
+			return err
+		}
+		postStmtPos = len(c.instructions)
+	}
+
+	// update all break/continue jump positions
+	for _, pos := range loop.breaks {
+		c.changeOperand(pos, postStmtPos)
+	}
+
+	for _, pos := range loop.continues {
+		c.changeOperand(pos, postBodyPos)
+	}
+	return nil
+}
+
+// compileForClause compiles init and post statements of a for statement.
+// Unlike other assignments, multiple values can be assigned to the same number
+// of variables like "i, j := 0, 10", which is compiled as destructuring of an
+// array so that all values are evaluated before assignment.
+func (c *Compiler) compileForClause(stmt parser.Stmt) error {
+	switch s := stmt.(type) {
+	case *parser.MultiStmt:
+		for _, st := range s.Stmts {
+			if err := c.compileForClause(st); err != nil {
+				return err
+			}
+		}
+		return nil
+	case *parser.AssignStmt:
+		if len(s.RHS) > 1 && len(s.LHS) == len(s.RHS) &&
+			(s.Token == token.Assign || s.Token == token.Define) {
+			arr := &parser.ArrayLit{
+				Elements: s.RHS,
+				LBrack:   s.RHS[0].Pos(),
+				RBrack:   s.RHS[len(s.RHS)-1].End() - 1,
+			}
+			return c.compileAssignStmt(s, s.LHS, []parser.Expr{arr},
+				token.Var, s.Token)
+		}
+	}
+	return c.Compile(stmt)
+}
+
+func (c *Compiler) compileDoWhileStmt(stmt *parser.DoWhileStmt) error {
+	c.symbolTable = c.symbolTable.Fork(true)
+	defer func() {
+		c.symbolTable = c.symbolTable.Parent(false)
+	}()
+
+	// pre-body position
+	preBodyPos := len(c.instructions)
+
+	// enter loop
+	loop := c.enterLoop()
+
+	// body statement
+	if err := c.Compile(stmt.Body); err != nil {
+		c.leaveLoop()
+		return err
+	}
+
+	c.leaveLoop()
+
+	// post-body position
+	postBodyPos := len(c.instructions)
+
+	// condition expression
+	if err := c.Compile(stmt.Cond); err != nil {
+		return err
+	}
+	postCondPos := c.emit(stmt, OpJumpFalsy, 0)
+
+	// back to body
+	c.emit(stmt, OpJump, preBodyPos)
+
+	// post-statement position
+	postStmtPos := len(c.instructions)
+	c.changeOperand(postCondPos, postStmtPos)
+
 	// update all break/continue jump positions
 	for _, pos := range loop.breaks {
 		c.changeOperand(pos, postStmtPos)
@@ -748,7 +1099,11 @@ func (c *Compiler) compileForInStmt(stmt *parser.ForInStmt) error {
 		return err
 	}
 
-	c.emit(stmt, OpIterInit)
+	if c.opts.DeterministicMaps {
+		c.emit(stmt, OpIterInitSorted)
+	} else {
+		c.emit(stmt, OpIterInit)
+	}
 	c.emit(stmt, OpDefineLocal, itSymbol.Index)
 
 	// pre-condition position
@@ -807,6 +1162,14 @@ func (c *Compiler) compileForInStmt(stmt *parser.ForInStmt) error {
 	postStmtPos := len(c.instructions)
 	c.changeOperand(postCondPos, postStmtPos)
 
+	// else block is skipped by break statements
+	if stmt.Else != nil {
+		if err := c.compileLoopElse(stmt.Else); err != nil {
+			return err
+		}
+		postStmtPos = len(c.instructions)
+	}
+
 	// update all break/continue jump positions
 	for _, pos := range loop.breaks {
 		c.changeOperand(pos, postStmtPos)
@@ -818,6 +1181,19 @@ func (c *Compiler) compileForInStmt(stmt *parser.ForInStmt) error {
 	return nil
 }
 
+// compileLoopElse compiles else block of a loop, which is placed after the
+// loop so that only the loop condition jumps to it. Variables declared by the
+// loop are not visible in else block, and break and continue statements in
+// else block belong to the enclosing loop.
+func (c *Compiler) compileLoopElse(block *parser.BlockStmt) error {
+	loopTable := c.symbolTable
+	c.symbolTable = loopTable.Parent(false)
+	defer func() {
+		c.symbolTable = loopTable
+	}()
+	return c.Compile(block)
+}
+
 func (c *Compiler) compileFuncLit(node *parser.FuncLit) error {
 	params := make([]string, len(node.Type.Params.List))
 	for i, ident := range node.Type.Params.List {
@@ -829,8 +1205,21 @@ func (c *Compiler) compileFuncLit(node *parser.FuncLit) error {
 		return c.error(node, err)
 	}
 
+	var results []*Symbol
+	if node.Type.Results != nil {
+		for _, ident := range node.Type.Results.List {
+			symbol, exists := symbolTable.DefineLocal(ident.Name)
+			if exists {
+				return c.errorf(ident, "%q redeclared in this block",
+					ident.Name)
+			}
+			results = append(results, symbol)
+		}
+	}
+
 	fork := c.fork(c.file, c.modulePath, c.moduleMap, symbolTable)
 	fork.variadic = node.Type.Params.VarArgs
+	fork.results = results
 	if err := fork.Compile(node.Body); err != nil {
 		return err
 	}
@@ -892,6 +1281,15 @@ func (c *Compiler) compileBinaryExpr(node *parser.BinaryExpr) error {
 		return err
 	}
 
+	if c.opts.StrictComparison {
+		switch node.Token {
+		case token.Equal, token.NotEqual, token.Less, token.LessEq,
+			token.Greater, token.GreaterEq:
+			c.emit(node, OpStrictCompare, int(node.Token))
+			return nil
+		}
+	}
+
 	switch node.Token {
 	case token.Equal:
 		c.emit(node, OpEqual)
@@ -1000,6 +1398,11 @@ func (c *Compiler) compileSliceExpr(node *parser.SliceExpr) error {
 }
 
 func (c *Compiler) compileCallExpr(node *parser.CallExpr) error {
+	if obj, ok := c.constFreeze(node); ok {
+		c.emit(node, OpConstant, c.addConstant(obj))
+		return nil
+	}
+
 	var op = OpCall
 	var selExpr *parser.SelectorExpr
 	var isSelector bool
@@ -1039,6 +1442,28 @@ func (c *Compiler) compileCallExpr(node *parser.CallExpr) error {
 	return nil
 }
 
+// constFreeze returns the frozen constant if node is a call of freeze builtin
+// with an array or map literal consisting of constant literals.
+func (c *Compiler) constFreeze(node *parser.CallExpr) (Object, bool) {
+	ident, ok := node.Func.(*parser.Ident)
+	if !ok || ident.Name != "freeze" ||
+		len(node.Args) != 1 || node.Ellipsis.IsValid() {
+		return nil, false
+	}
+	symbol, ok := c.symbolTable.Resolve(ident.Name)
+	if !ok || symbol.Scope != ScopeBuiltin {
+		return nil, false
+	}
+	obj, ok := constFrozen(node.Args[0])
+	if !ok {
+		return nil, false
+	}
+	if _, ok = obj.(*Frozen); !ok {
+		return nil, false
+	}
+	return obj, true
+}
+
 func (c *Compiler) compileImportExpr(node *parser.ImportExpr) error {
 	moduleName := node.ModuleName
 	if moduleName == "" {
@@ -1156,6 +1581,23 @@ func (c *Compiler) compileCondExpr(node *parser.CondExpr) error {
 func (c *Compiler) compileIdent(node *parser.Ident) error {
 	symbol, ok := c.symbolTable.Resolve(node.Name)
 	if !ok {
+		// following names are resolved at compile time unless they are
+		// shadowed by a variable.
+		var obj Object
+		switch node.Name {
+		case "__module__":
+			obj = String(c.moduleName())
+		case "__file__":
+			obj = String(c.file.Set().Position(node.Pos()).Filename)
+		case "__line__":
+			obj = Int(c.file.Set().Position(node.Pos()).Line)
+		case "__column__":
+			obj = Int(c.file.Set().Position(node.Pos()).Column)
+		}
+		if obj != nil {
+			c.emit(node, OpConstant, c.addConstant(obj))
+			return nil
+		}
 		if c.iotaVal < 0 || node.Name != "iota" {
 			return c.errorf(node, "unresolved reference %q", node.Name)
 		}
@@ -1169,6 +1611,9 @@ func (c *Compiler) compileIdent(node *parser.Ident) error {
 	case ScopeLocal:
 		c.emit(node, OpGetLocal, symbol.Index)
 	case ScopeBuiltin:
+		if symbol.Index < 0 {
+			return c.compileExtraBuiltin(node)
+		}
 		c.emit(node, OpGetBuiltin, symbol.Index)
 	case ScopeFree:
 		c.emit(node, OpGetFree, symbol.Index)
@@ -1176,6 +1621,26 @@ func (c *Compiler) compileIdent(node *parser.Ident) error {
 	return nil
 }
 
+// compileExtraBuiltin emits the constant of the extra builtin, constants are
+// added once for each name.
+func (c *Compiler) compileExtraBuiltin(node *parser.Ident) error {
+	if idx, ok := c.extraBuiltins[node.Name]; ok {
+		c.emit(node, OpConstant, idx)
+		return nil
+	}
+	obj, ok := c.symbolTable.extraBuiltin(node.Name)
+	if !ok {
+		return c.errorf(node, "unresolved reference %q", node.Name)
+	}
+	if c.extraBuiltins == nil {
+		c.extraBuiltins = make(map[string]int)
+	}
+	idx := c.addConstant(obj)
+	c.extraBuiltins[node.Name] = idx
+	c.emit(node, OpConstant, idx)
+	return nil
+}
+
 func (c *Compiler) compileArrayLit(node *parser.ArrayLit) error {
 	for _, elem := range node.Elements {
 		if err := c.Compile(elem); err != nil {
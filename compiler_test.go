@@ -374,6 +374,72 @@ func TestCompiler_Compile(t *testing.T) {
 		)),
 	))
 
+	expectCompileWithOpts(t, `a := [1]; b := a; c := [a]`,
+		CompilerOptions{CopyOnAssign: true}, bytecode(
+			Array{Int(1)},
+			compFunc(concatInsts(
+				makeInst(OpConstant, 0),
+				makeInst(OpArray, 1),
+				makeInst(OpDefineLocal, 0),
+				makeInst(OpGetLocal, 0),
+				makeInst(OpCopyValue),
+				makeInst(OpDefineLocal, 1),
+				makeInst(OpGetLocal, 0),
+				makeInst(OpArray, 1),
+				makeInst(OpCopyValue),
+				makeInst(OpDefineLocal, 2),
+				makeInst(OpReturn, 0),
+			),
+				withLocals(3),
+			),
+		))
+
+	expectCompileWithOpts(t, `for k in {} {}`,
+		CompilerOptions{DeterministicMaps: true}, bytecode(
+			Array{},
+			compFunc(concatInsts(
+				makeInst(OpMap, 0),
+				makeInst(OpIterInitSorted),
+				makeInst(OpDefineLocal, 0),
+				makeInst(OpGetLocal, 0),
+				makeInst(OpIterNext),
+				makeInst(OpJumpFalsy, 20),
+				makeInst(OpGetLocal, 0),
+				makeInst(OpIterValue),
+				makeInst(OpDefineLocal, 1),
+				makeInst(OpJump, 6),
+				makeInst(OpReturn, 0),
+			),
+				withLocals(2),
+			),
+		))
+
+	strictOpts := CompilerOptions{StrictComparison: true}
+	for _, tok := range []token.Token{token.Equal, token.NotEqual, token.Less,
+		token.LessEq, token.Greater, token.GreaterEq} {
+		expectCompileWithOpts(t, `1 `+tok.String()+` 2u`, strictOpts,
+			bytecode(
+				Array{Int(1), Uint(2)},
+				compFunc(concatInsts(
+					makeInst(OpConstant, 0),
+					makeInst(OpConstant, 1),
+					makeInst(OpStrictCompare, int(tok)),
+					makeInst(OpPop),
+					makeInst(OpReturn, 0),
+				)),
+			))
+	}
+	expectCompileWithOpts(t, `1 + 2u`, strictOpts, bytecode(
+		Array{Int(1), Uint(2)},
+		compFunc(concatInsts(
+			makeInst(OpConstant, 0),
+			makeInst(OpConstant, 1),
+			makeInst(OpBinaryOp, int(token.Add)),
+			makeInst(OpPop),
+			makeInst(OpReturn, 0),
+		)),
+	))
+
 	expectCompile(t, `-1`, bytecode(
 		Array{Int(1)},
 		compFunc(concatInsts(
@@ -1479,15 +1545,41 @@ func TestCompiler_Compile(t *testing.T) {
 			withLocals(1),
 		),
 	))
-	expectCompileError(t, `try {}`, `Parse Error: expected 'finally', found newline`)
-	expectCompileError(t, `catch {}`, `Parse Error: expected statement, found 'catch'`)
-	expectCompileError(t, `finally {}`, `Parse Error: expected statement, found 'finally'`)
+	expectCompileError(t, `try {}`, `Parse Error: try statement requires a `+
+		`catch or finally block, add 'catch {}' or 'finally {}' after try block`)
+	expectCompileError(t, `try {} x`, `Parse Error: try statement requires a `+
+		`catch or finally block, add 'catch {}' or 'finally {}' after try block`)
+	expectCompileError(t, `try { x := 1 }
+	return 1`, `Parse Error: try statement requires a catch or finally block`)
+	expectCompileError(t, `catch {}`,
+		`Parse Error: catch block without try statement`)
+	expectCompileError(t, `finally {}`,
+		`Parse Error: finally block without try statement`)
+	expectCompileError(t, `try {} catch {} catch {}`,
+		`Parse Error: multiple catch blocks in try statement`)
+	expectCompileError(t, `try {} finally {} finally {}`,
+		`Parse Error: multiple finally blocks in try statement`)
+	expectCompileError(t, `try {} finally {} catch {}`,
+		`Parse Error: catch block must come before finally block`)
 	// catch and finally must in the same line with right brace.
 	expectCompileError(t, `try {}
-	catch {}`, `Parse Error: expected 'finally', found newline`)
+	catch {}`, `Parse Error: catch must be on the same line as closing brace `+
+		`of try block`)
+	expectCompileError(t, `try {}
+	finally {}`, `Parse Error: finally must be on the same line as closing `+
+		`brace of try block`)
 	expectCompileError(t, `try {
 	} catch {}
-	finally {}`, `Parse Error: expected statement, found 'finally'`)
+	finally {}`, `Parse Error: finally must be on the same line as closing `+
+		`brace of catch block`)
+	// bare throw requires a caught error
+	expectCompileError(t, `throw`, `Compile Error: throw without expression `+
+		`outside of catch block, use 'throw <expression>' to throw an error`)
+	expectCompileError(t, `try {} catch {} finally { throw }`,
+		`Compile Error: throw without expression in finally block, `+
+			`caught error is only available in catch block`)
+	expectCompileError(t, `try {} catch { f := func() { throw } }`,
+		`Compile Error: throw without expression outside of catch block`)
 
 	// 4 instructions are generated for every source module import.
 	// If module's returned value is already stored, ignore storing.
@@ -1722,6 +1814,330 @@ func TestCompilerScopes(t *testing.T) {
 	))
 }
 
+func TestCompilerDisabledBuiltins(t *testing.T) {
+	opts := DefaultCompilerOptions
+	opts.DisabledBuiltins = []string{"len", "copy"}
+
+	expectCompileErrorWithOpts(t, `len("abc")`, opts,
+		`Compile Error: unresolved reference "len"`)
+	expectCompileErrorWithOpts(t, `f := func(x) { return copy(x) }`, opts,
+		`Compile Error: unresolved reference "copy"`)
+	expectCompileErrorWithOpts(t, `f := len`, opts,
+		`Compile Error: unresolved reference "len"`)
+
+	unoptimized := opts
+	unoptimized.OptimizeConst = false
+	unoptimized.OptimizeExpr = false
+	expectCompileErrorWithOpts(t, `len("abc")`, unoptimized,
+		`Compile Error: unresolved reference "len"`)
+
+	mm := NewModuleMap()
+	mm.AddSourceModule("mod", []byte(`return len("abc")`))
+	modOpts := opts
+	modOpts.ModuleMap = mm
+	expectCompileErrorWithOpts(t, `import("mod")`, modOpts,
+		`Compile Error: unresolved reference "len"`)
+
+	// disabled builtin names can be defined as variables
+	_, err := Compile([]byte(`len := 1; return len`), opts)
+	require.NoError(t, err)
+	_, err = Compile([]byte(`return append([], 1)`), opts)
+	require.NoError(t, err)
+
+	// options are merged with symbol table's disabled builtins
+	st := NewSymbolTable().DisableBuiltin("append")
+	stOpts := opts
+	stOpts.SymbolTable = st
+	expectCompileErrorWithOpts(t, `return append([], 1)`, stOpts,
+		`Compile Error: unresolved reference "append"`)
+	expectCompileErrorWithOpts(t, `len("abc")`, stOpts,
+		`Compile Error: unresolved reference "len"`)
+	require.ElementsMatch(t, []string{"append", "copy", "len"},
+		st.DisabledBuiltins())
+
+	_, err = Compile([]byte(`return len("abc")`), DefaultCompilerOptions)
+	require.NoError(t, err)
+}
+
+func TestCompilerExtraBuiltins(t *testing.T) {
+	double := &Function{
+		Name: "double",
+		Value: func(args ...Object) (Object, error) {
+			return args[0].(Int) * 2, nil
+		},
+	}
+	strlen := &Function{
+		Name: "len",
+		Value: func(args ...Object) (Object, error) {
+			return Int(-1), nil
+		},
+	}
+	opts := DefaultCompilerOptions
+	opts.ExtraBuiltins = map[string]Object{"double": double, "len": strlen}
+
+	run := func(t *testing.T, script string, opts CompilerOptions) Object {
+		t.Helper()
+		bc, err := Compile([]byte(script), opts)
+		require.NoError(t, err)
+		ret, err := NewVM(bc).Run(nil)
+		require.NoError(t, err)
+		return ret
+	}
+
+	require.Equal(t, Int(42), run(t, `return double(21)`, opts))
+	require.Equal(t, Int(8),
+		run(t, `f := func(x) { return double(x) }; return f(double(2))`, opts))
+
+	// a single constant is added for repeated references
+	bc, err := Compile([]byte(`return [double, double, double]`), opts)
+	require.NoError(t, err)
+	var count int
+	for _, c := range bc.Constants {
+		if c == double {
+			count++
+		}
+	}
+	require.Equal(t, 1, count)
+
+	// extra builtins take precedence and are not evaluated by optimizer
+	require.Equal(t, Int(-1), run(t, `return len("abc")`, opts))
+	require.Equal(t, Int(3), run(t, `return len("abc")`, DefaultCompilerOptions))
+
+	// extra builtins are not globals
+	require.Equal(t, Undefined, run(t, `return globals().double`, opts))
+
+	// local variables shadow extra builtins
+	require.Equal(t, Int(1), run(t, `double := 1; return double`, opts))
+
+	mm := NewModuleMap()
+	mm.AddSourceModule("mod", []byte(`return double(5)`))
+	modOpts := opts
+	modOpts.ModuleMap = mm
+	require.Equal(t, Int(10), run(t, `return import("mod")`, modOpts))
+
+	disabledOpts := opts
+	disabledOpts.DisabledBuiltins = []string{"double"}
+	expectCompileErrorWithOpts(t, `double(1)`, disabledOpts,
+		`Compile Error: unresolved reference "double"`)
+
+	expectCompileErrorWithOpts(t, `double(1)`, DefaultCompilerOptions,
+		`Compile Error: unresolved reference "double"`)
+}
+
+func TestCompilerPureFunction(t *testing.T) {
+	newDouble := func(pure bool) *Function {
+		return &Function{
+			Name: "double",
+			Value: func(args ...Object) (Object, error) {
+				if len(args) != 1 {
+					return nil, ErrWrongNumArguments.NewError("want=1")
+				}
+				return args[0].(Int) * 2, nil
+			},
+			Pure: pure,
+		}
+	}
+	pure := newDouble(true)
+	opts := DefaultCompilerOptions
+	opts.ExtraBuiltins = map[string]Object{"double": pure}
+
+	// calls with constant arguments are folded
+	expectCompileWithOpts(t, `return double(21)`, opts, bytecode(
+		Array{Int(42)},
+		compFunc(concatInsts(
+			makeInst(OpConstant, 0),
+			makeInst(OpReturn, 1),
+		)),
+	))
+	expectCompileWithOpts(t, `return string(double(1+2))`, opts, bytecode(
+		Array{String("6")},
+		compFunc(concatInsts(
+			makeInst(OpConstant, 0),
+			makeInst(OpReturn, 1),
+		)),
+	))
+
+	// calls with variable arguments are not folded
+	expectCompileWithOpts(t, `param x; return double(x)`, opts, bytecode(
+		Array{pure},
+		compFunc(concatInsts(
+			makeInst(OpConstant, 0),
+			makeInst(OpGetLocal, 0),
+			makeInst(OpCall, 1, 0),
+			makeInst(OpReturn, 1),
+		),
+			withParams(1),
+			withLocals(1),
+		),
+	))
+
+	// non-pure functions are never folded
+	impure := newDouble(false)
+	opts.ExtraBuiltins = map[string]Object{"double": impure}
+	expectCompileWithOpts(t, `return double(21)`, opts, bytecode(
+		Array{impure, Int(21)},
+		compFunc(concatInsts(
+			makeInst(OpConstant, 0),
+			makeInst(OpConstant, 1),
+			makeInst(OpCall, 1, 0),
+			makeInst(OpReturn, 1),
+		)),
+	))
+
+	// shadowed pure functions are not folded
+	opts.ExtraBuiltins = map[string]Object{"double": pure}
+	bc, err := Compile([]byte(
+		`double := func(x) { return x }; return double(21)`), opts)
+	require.NoError(t, err)
+	ret, err := NewVM(bc).Run(nil)
+	require.NoError(t, err)
+	require.Equal(t, Int(21), ret)
+
+	// errors of pure functions are reported at compile time
+	_, err = Compile([]byte(`return double(1, 2)`), opts)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "WrongNumberOfArgumentsError: want=1")
+
+	// pure module members are not folded, only extra builtins are
+	var calls int
+	counted := &Function{
+		Value: func(args ...Object) (Object, error) {
+			calls++
+			return args[0].(Int) * 2, nil
+		},
+		Pure: true,
+	}
+	opts.ExtraBuiltins = nil
+	opts.ModuleMap = NewModuleMap().
+		AddBuiltinModule("m", map[string]Object{"double": counted})
+	bc, err = Compile([]byte(`m := import("m"); return m.double(21)`), opts)
+	require.NoError(t, err)
+	require.Equal(t, 0, calls)
+	ret, err = NewVM(bc).Run(nil)
+	require.NoError(t, err)
+	require.Equal(t, Int(42), ret)
+	require.Equal(t, 1, calls)
+
+	require.True(t, pure.Copy().(*Function).Pure)
+}
+
+func TestCompilerFreezeConstant(t *testing.T) {
+	expectCompile(t, `return freeze([1, {a: "x"}])`, bytecode(
+		Array{&Frozen{Value: Array{
+			Int(1),
+			&Frozen{Value: Map{"a": String("x")}},
+		}}},
+		compFunc(concatInsts(
+			makeInst(OpConstant, 0),
+			makeInst(OpReturn, 1),
+		)),
+	))
+
+	expectCompile(t, `x := 1; return freeze([x])`, bytecode(
+		Array{Int(1)},
+		compFunc(concatInsts(
+			makeInst(OpConstant, 0),
+			makeInst(OpDefineLocal, 0),
+			makeInst(OpGetBuiltin, int(BuiltinFreeze)),
+			makeInst(OpGetLocal, 0),
+			makeInst(OpArray, 1),
+			makeInst(OpCall, 1, 0),
+			makeInst(OpReturn, 1),
+		),
+			withLocals(1),
+		),
+	))
+}
+
+func TestCompilerAlwaysSourceMap(t *testing.T) {
+	script := `
+	mod := import("mod")
+	f := func(a, ...b) {
+		for x in b {
+			if x == a { return x }
+		}
+	}
+	g := func() {
+		try {
+			throw "x"
+		} catch err {
+		} finally {
+		}
+	}
+	out := 0
+	for i := 0; i < 3; i++, out++ {}
+	v := f(1, 2, 1) || mod.h()
+	`
+	mm := NewModuleMap()
+	mm.AddSourceModule("mod", []byte(`return {h: func() { }}`))
+
+	// check that some instructions have no position without the option
+	var missing int
+	opts := DefaultCompilerOptions
+	opts.ModuleMap = mm
+	bc, err := Compile([]byte(script), opts)
+	require.NoError(t, err)
+	forEachCompiledFunction(bc, func(cf *CompiledFunction) {
+		forEachInstruction(cf, func(ip int) {
+			if p, ok := cf.SourceMap[ip]; !ok || p == 0 {
+				missing++
+			}
+		})
+	})
+	require.NotZero(t, missing)
+
+	for _, empty := range []bool{false, true} {
+		opts.AlwaysSourceMap = true
+		s := script
+		if empty {
+			s = ""
+		}
+		bc, err := Compile([]byte(s), opts)
+		require.NoError(t, err)
+
+		var numFuncs int
+		forEachCompiledFunction(bc, func(cf *CompiledFunction) {
+			numFuncs++
+			forEachInstruction(cf, func(ip int) {
+				p, ok := cf.SourceMap[ip]
+				require.True(t, ok, "no source position at %d\n%s", ip, cf)
+				require.NotZero(t, p, "no source position at %d\n%s", ip, cf)
+				pos := bc.FileSet.Position(cf.SourcePos(ip))
+				require.True(t, pos.IsValid(),
+					"invalid source position at %d\n%s", ip, cf)
+			})
+		})
+		if empty {
+			require.Equal(t, 1, numFuncs)
+		} else {
+			// main, f, g, module and h
+			require.Equal(t, 5, numFuncs)
+		}
+	}
+}
+
+func forEachCompiledFunction(bc *Bytecode, fn func(cf *CompiledFunction)) {
+	fn(bc.Main)
+	for _, c := range bc.Constants {
+		if cf, ok := c.(*CompiledFunction); ok {
+			fn(cf)
+		}
+	}
+}
+
+func forEachInstruction(cf *CompiledFunction, fn func(ip int)) {
+	operands := make([]int, 0, 4)
+	var offset int
+	for ip := 0; ip < len(cf.Instructions); ip += offset + 1 {
+		fn(ip)
+		operands, offset = ReadOperands(
+			OpcodeOperands[cf.Instructions[ip]],
+			cf.Instructions[ip+1:],
+			operands,
+		)
+	}
+}
+
 func expectCompileError(t *testing.T, script string, errStr string) {
 	t.Helper()
 	expectCompileErrorWithOpts(t, script, CompilerOptions{}, errStr)
@@ -0,0 +1,153 @@
+// Copyright (c) 2020-2023 Ozan Hacıbekiroğlu.
+// Use of this source code is governed by a MIT License
+// that can be found in the LICENSE file.
+
+package ugo
+
+import (
+	"sync/atomic"
+
+	"github.com/ozanh/ugo/parser"
+)
+
+// execCounter counts executed instructions of Main and compiled function
+// constants of a Bytecode. Closures created from the same function share the
+// instructions, so they are counted together. It is shared by the VMs
+// acquired from the pool, counters are updated atomically.
+type execCounter struct {
+	bc    *Bytecode
+	funcs map[*byte]*funcCounts
+}
+
+type funcCounts struct {
+	fn     *CompiledFunction
+	counts []uint64
+}
+
+func newExecCounter(bc *Bytecode) *execCounter {
+	c := &execCounter{bc: bc, funcs: make(map[*byte]*funcCounts)}
+	c.add(bc.Main)
+	for _, o := range bc.Constants {
+		if f, ok := o.(*CompiledFunction); ok {
+			c.add(f)
+		}
+	}
+	return c
+}
+
+func (c *execCounter) add(fn *CompiledFunction) {
+	if fn != nil && len(fn.Instructions) > 0 {
+		c.funcs[&fn.Instructions[0]] = &funcCounts{
+			fn:     fn,
+			counts: make([]uint64, len(fn.Instructions)),
+		}
+	}
+}
+
+// lookup returns the counters of the instructions of fn, or nil if fn is not
+// known or c is nil.
+func (c *execCounter) lookup(fn *CompiledFunction) []uint64 {
+	if c == nil || len(fn.Instructions) == 0 {
+		return nil
+	}
+	if fc := c.funcs[&fn.Instructions[0]]; fc != nil &&
+		len(fc.counts) == len(fn.Instructions) {
+		return fc.counts
+	}
+	return nil
+}
+
+// lines calls fn for each instruction of the function having a source
+// position with the count of the instruction.
+func (fc *funcCounts) lines(fileSet *parser.SourceFileSet,
+	fn func(pos parser.SourceFilePos, p parser.Pos, count uint64)) {
+	IterateInstructions(fc.fn.Instructions,
+		func(ip int, _ Opcode, _ []int, _ int) bool {
+			p, ok := fc.fn.SourceMap[ip]
+			if !ok || parser.Pos(p) == parser.NoPos {
+				return true
+			}
+			pos := fileSet.Position(parser.Pos(p))
+			if pos.IsValid() {
+				fn(pos, parser.Pos(p), atomic.LoadUint64(&fc.counts[ip]))
+			}
+			return true
+		},
+	)
+}
+
+// coveredLines returns the lines of the main source file having instructions,
+// which are set to true if any of their instructions is executed.
+func (c *execCounter) coveredLines() map[int]bool {
+	lines := make(map[int]bool)
+	fileSet := c.bc.FileSet
+	if fileSet == nil || len(fileSet.Files) == 0 {
+		return lines
+	}
+	main := fileSet.Files[0]
+
+	for _, fc := range c.funcs {
+		fc.lines(fileSet,
+			func(pos parser.SourceFilePos, p parser.Pos, count uint64) {
+				if int(p) >= main.Base && int(p) <= main.Base+main.Size {
+					lines[pos.Line] = lines[pos.Line] || count > 0
+				}
+			},
+		)
+	}
+	return lines
+}
+
+// recordExec records the current instruction of the current frame for coverage
+// and profiling, it is only called if one of them is enabled.
+func (vm *VM) recordExec() {
+	if fn := vm.curFrame.fn; fn != vm.execFn {
+		vm.execFn = fn
+		vm.coverCounts = vm.cover.lookup(fn)
+		vm.profCounts = vm.prof.lookup(fn)
+	}
+	if c := vm.coverCounts; c != nil && atomic.LoadUint64(&c[vm.ip]) == 0 {
+		atomic.StoreUint64(&c[vm.ip], 1)
+	}
+	if c := vm.profCounts; c != nil {
+		atomic.AddUint64(&c[vm.ip], 1)
+	}
+}
+
+// resetInstrumentation must be called after coverage, profiling or bytecode
+// is changed.
+func (vm *VM) resetInstrumentation() {
+	vm.instrumented = vm.cover != nil || vm.prof != nil
+	vm.execFn = nil
+	vm.coverCounts = nil
+	vm.profCounts = nil
+}
+
+// SetCoverage enables or disables recording of executed instructions to
+// report coverage of source lines with Coverage method. Enabling coverage
+// resets the recorded data, which is kept across runs until it is disabled or
+// bytecode is changed. Recording makes execution slower, it is intended for
+// measuring coverage of script tests.
+func (vm *VM) SetCoverage(v bool) *VM {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+	vm.cover = nil
+	if v && vm.bytecode != nil && vm.bytecode.Main != nil {
+		vm.cover = newExecCounter(vm.bytecode)
+	}
+	vm.resetInstrumentation()
+	return vm
+}
+
+// Coverage returns the coverage of the lines of the main source file which
+// have instructions. A line is true if any of its instructions is executed
+// since coverage is enabled, otherwise false. Lines of imported source modules
+// are not reported. It returns nil if coverage is not enabled.
+func (vm *VM) Coverage() map[int]bool {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+	if vm.cover == nil {
+		return nil
+	}
+	return vm.cover.coveredLines()
+}
@@ -0,0 +1,98 @@
+package ugo_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ozanh/ugo"
+)
+
+func TestVMCoverage(t *testing.T) {
+	script := `param x
+out := ""
+if x > 0 {
+	out = "positive"
+} else {
+	out = "negative"
+}
+unused := func() {
+	return 0
+}
+key := func(v) {
+	return v % 2
+}
+groupBy([1, 2, 3], key)
+return out`
+	bc, err := Compile([]byte(script), DefaultCompilerOptions)
+	require.NoError(t, err)
+
+	vm := NewVM(bc)
+	require.Nil(t, vm.Coverage())
+
+	vm.SetCoverage(true)
+	for line, covered := range vm.Coverage() {
+		require.False(t, covered, "line %d", line)
+	}
+
+	ret, err := vm.Run(nil, Int(1))
+	require.NoError(t, err)
+	require.Equal(t, String("positive"), ret)
+	// param declaration has no instructions
+	require.Equal(t, map[int]bool{
+		2:  true,
+		3:  true,
+		4:  true,
+		6:  false,
+		8:  true,
+		9:  false,
+		11: true,
+		12: true, // called by groupBy in a pooled VM
+		14: true,
+		15: true,
+	}, vm.Coverage())
+
+	// coverage is accumulated across runs
+	ret, err = vm.Run(nil, Int(-1))
+	require.NoError(t, err)
+	require.Equal(t, String("negative"), ret)
+	cov := vm.Coverage()
+	require.True(t, cov[4])
+	require.True(t, cov[6])
+	require.False(t, cov[9])
+
+	// enabling coverage again resets it
+	vm.SetCoverage(true)
+	_, err = vm.Run(nil, Int(-1))
+	require.NoError(t, err)
+	cov = vm.Coverage()
+	require.False(t, cov[4])
+	require.True(t, cov[6])
+
+	vm.SetCoverage(false)
+	require.Nil(t, vm.Coverage())
+	_, err = vm.Run(nil, Int(1))
+	require.NoError(t, err)
+	require.Nil(t, vm.Coverage())
+}
+
+func TestVMCoverageModule(t *testing.T) {
+	mm := NewModuleMap()
+	mm.AddSourceModule("mod", []byte(`
+return {
+	f: func() { return 1 },
+}`))
+	opts := DefaultCompilerOptions
+	opts.ModuleMap = mm
+	bc, err := Compile([]byte(`mod := import("mod")
+
+return mod.f()`), opts)
+	require.NoError(t, err)
+
+	vm := NewVM(bc).SetCoverage(true)
+	ret, err := vm.Run(nil)
+	require.NoError(t, err)
+	require.Equal(t, Int(1), ret)
+	// only lines of the main source file are reported
+	require.Equal(t, map[int]bool{1: true, 3: true}, vm.Coverage())
+}
@@ -0,0 +1,110 @@
+// Copyright (c) 2020-2023 Ozan Hacıbekiroğlu.
+// Use of this source code is governed by a MIT License
+// that can be found in the LICENSE file.
+
+package ugo
+
+import (
+	"encoding/json"
+
+	"github.com/ozanh/ugo/parser"
+)
+
+// Disassembly is a machine readable form of a Bytecode for external tools like
+// analyzers and test snapshots. It can be encoded to JSON.
+type Disassembly struct {
+	NumModules int              `json:"numModules"`
+	Constants  []DisasmConstant `json:"constants"`
+	Main       *DisasmFunction  `json:"main"`
+}
+
+// DisasmConstant represents a constant of a Bytecode. Function is set instead
+// of Value if the constant is a compiled function.
+type DisasmConstant struct {
+	Index    int             `json:"index"`
+	Type     string          `json:"type"`
+	Value    string          `json:"value,omitempty"`
+	Function *DisasmFunction `json:"function,omitempty"`
+}
+
+// DisasmFunction represents a compiled function and its instructions.
+type DisasmFunction struct {
+	NumParams    int                 `json:"numParams"`
+	NumLocals    int                 `json:"numLocals"`
+	Variadic     bool                `json:"variadic"`
+	Instructions []DisasmInstruction `json:"instructions"`
+}
+
+// DisasmInstruction represents a single instruction. Constant is the index of
+// the constant referenced by the instruction, if any. Source position fields
+// are only set for instructions in the source map.
+type DisasmInstruction struct {
+	Offset   int    `json:"offset"`
+	Op       string `json:"op"`
+	Operands []int  `json:"operands"`
+	Constant *int   `json:"constant,omitempty"`
+	File     string `json:"file,omitempty"`
+	Line     int    `json:"line,omitempty"`
+	Column   int    `json:"column,omitempty"`
+}
+
+// Disassemble returns the machine readable form of the Bytecode.
+func (bc *Bytecode) Disassemble() *Disassembly {
+	d := &Disassembly{
+		NumModules: bc.NumModules,
+		Constants:  make([]DisasmConstant, len(bc.Constants)),
+		Main:       bc.disasmFunction(bc.Main),
+	}
+	for i, c := range bc.Constants {
+		dc := DisasmConstant{Index: i, Type: c.TypeName()}
+		if cf, ok := c.(*CompiledFunction); ok {
+			dc.Function = bc.disasmFunction(cf)
+		} else {
+			dc.Value = c.String()
+		}
+		d.Constants[i] = dc
+	}
+	return d
+}
+
+// MarshalJSON implements json.Marshaler interface and encodes the result of
+// Disassemble method.
+func (bc *Bytecode) MarshalJSON() ([]byte, error) {
+	return json.Marshal(bc.Disassemble())
+}
+
+func (bc *Bytecode) disasmFunction(cf *CompiledFunction) *DisasmFunction {
+	if cf == nil {
+		return nil
+	}
+	df := &DisasmFunction{
+		NumParams:    cf.NumParams,
+		NumLocals:    cf.NumLocals,
+		Variadic:     cf.Variadic,
+		Instructions: []DisasmInstruction{},
+	}
+	IterateInstructions(cf.Instructions,
+		func(pos int, opcode Opcode, operands []int, _ int) bool {
+			inst := DisasmInstruction{
+				Offset:   pos,
+				Op:       OpcodeNames[opcode],
+				Operands: append([]int{}, operands...),
+			}
+			switch opcode {
+			case OpConstant, OpGetGlobal, OpSetGlobal, OpClosure, OpLoadModule:
+				idx := operands[0]
+				inst.Constant = &idx
+			}
+			if p, ok := cf.SourceMap[pos]; ok && bc.FileSet != nil {
+				if fp := bc.FileSet.Position(parser.Pos(p)); fp.IsValid() {
+					inst.File = fp.Filename
+					inst.Line = fp.Line
+					inst.Column = fp.Column
+				}
+			}
+			df.Instructions = append(df.Instructions, inst)
+			return true
+		},
+	)
+	return df
+}
@@ -0,0 +1,78 @@
+package ugo_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ozanh/ugo"
+)
+
+func TestBytecodeDisassemble(t *testing.T) {
+	script := `x := "a"
+f := func(y) { return y + x }
+return f(1)`
+	bc, err := Compile([]byte(script), DefaultCompilerOptions)
+	require.NoError(t, err)
+
+	d := bc.Disassemble()
+	require.Equal(t, 0, d.NumModules)
+	require.Equal(t, 3, len(d.Constants))
+	require.Equal(t, DisasmConstant{Index: 0, Type: "string", Value: "a"},
+		d.Constants[0])
+	require.Equal(t, DisasmConstant{Index: 2, Type: "int", Value: "1"},
+		d.Constants[2])
+
+	intPtr := func(v int) *int { return &v }
+	fn := d.Constants[1]
+	require.Equal(t, "compiledFunction", fn.Type)
+	require.Empty(t, fn.Value)
+	require.NotNil(t, fn.Function)
+	require.Equal(t, 1, fn.Function.NumParams)
+	require.Equal(t, []DisasmInstruction{
+		{Offset: 0, Op: "GETLOCAL", Operands: []int{0},
+			File: "(main)", Line: 2, Column: 23},
+		{Offset: 2, Op: "GETFREE", Operands: []int{0},
+			File: "(main)", Line: 2, Column: 27},
+		{Offset: 4, Op: "BINARYOP", Operands: []int{12},
+			File: "(main)", Line: 2, Column: 23},
+		{Offset: 6, Op: "RETURN", Operands: []int{1},
+			File: "(main)", Line: 2, Column: 16},
+	}, fn.Function.Instructions)
+
+	var ops []string
+	for _, inst := range d.Main.Instructions {
+		ops = append(ops, inst.Op)
+	}
+	require.Equal(t, []string{"CONSTANT", "DEFINELOCAL", "GETLOCALPTR",
+		"CLOSURE", "DEFINELOCAL", "GETLOCAL", "CONSTANT", "CALL", "RETURN"},
+		ops)
+	require.Equal(t, 2, d.Main.NumLocals)
+	require.Equal(t, intPtr(0), d.Main.Instructions[0].Constant)
+	require.Nil(t, d.Main.Instructions[1].Constant)
+	require.Equal(t, intPtr(1), d.Main.Instructions[3].Constant)
+	require.Equal(t, []int{1, 1}, d.Main.Instructions[3].Operands)
+	require.Equal(t, intPtr(2), d.Main.Instructions[6].Constant)
+	require.Equal(t, 3, d.Main.Instructions[8].Line)
+
+	b, err := json.Marshal(bc)
+	require.NoError(t, err)
+	var decoded Disassembly
+	require.NoError(t, json.Unmarshal(b, &decoded))
+	require.Equal(t, d, &decoded)
+
+	var m map[string]interface{}
+	require.NoError(t, json.Unmarshal(b, &m))
+	main := m["main"].(map[string]interface{})
+	first := main["instructions"].([]interface{})[0].(map[string]interface{})
+	require.Equal(t, map[string]interface{}{
+		"offset":   float64(0),
+		"op":       "CONSTANT",
+		"operands": []interface{}{float64(0)},
+		"constant": float64(0),
+		"file":     "(main)",
+		"line":     float64(1),
+		"column":   float64(6),
+	}, first)
+}
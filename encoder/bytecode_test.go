@@ -33,6 +33,7 @@ var testObjects = []ugo.Object{
 	&ugo.RuntimeError{Err: ugo.ErrInvalidIndex},
 	ugo.Map{"key": &ugo.Function{Name: "f"}},
 	&ugo.SyncMap{Value: ugo.Map{"k": ugo.String("")}},
+	&ugo.Frozen{Value: ugo.Array{ugo.Int(1), &ugo.Frozen{Value: ugo.Map{}}}},
 	ugo.Array{ugo.Undefined, ugo.True, ugo.False},
 	&time.Time{Value: gotime.Time{}},
 	&json.EncoderOptions{Value: ugo.Int(1)},
@@ -88,6 +88,7 @@ func init() {
 	gob.Register((*ugo.Error)(nil))
 	gob.Register((*ugo.RuntimeError)(nil))
 	gob.Register((*ugo.SyncMap)(nil))
+	gob.Register((*ugo.Frozen)(nil))
 	gob.Register((*ugo.ObjectPtr)(nil))
 	gob.Register((*time.Time)(nil))
 	gob.Register((*json.EncoderOptions)(nil))
@@ -5,6 +5,7 @@
 package ugo
 
 import (
+	"errors"
 	"fmt"
 )
 
@@ -55,8 +56,71 @@ var (
 
 	// ErrType represents a type error.
 	ErrType = &Error{Name: "TypeError"}
+
+	// ErrNotConstantExpr is an error where an expression cannot be evaluated
+	// to a constant by EvalConstExpr.
+	ErrNotConstantExpr = &Error{Name: "NotConstantExpressionError"}
+
+	// ErrExit represents an exit error thrown by exit builtin function to stop
+	// the script. It cannot be caught by catch blocks but finally blocks are
+	// executed. Exit code is stored in "code" key of the error's Data. Use
+	// ExitCode function to get the code.
+	ErrExit = &Error{Name: "ExitError"}
 )
 
+// ExitCode returns the exit code and true if err is thrown by exit builtin
+// function, otherwise it returns 0 and false.
+func ExitCode(err error) (int, bool) {
+	if !errors.Is(err, ErrExit) {
+		return 0, false
+	}
+
+	var e *Error
+	if errors.As(err, &e) {
+		if code, ok := e.Data["code"].(Int); ok {
+			return int(code), true
+		}
+	}
+	return 0, true
+}
+
+// WrapGoError converts err to an *Error so that Go functions can return errors
+// consistently with uGO errors. *Error values are returned as is, the error of
+// a *RuntimeError is unwrapped and other errors are wrapped with a new *Error
+// whose Cause is err, so that isError and errors.Is still match them. Position
+// of the call is added to the stack trace by VM when the returned error is
+// thrown.
+//
+// It returns a nil *Error if err is nil, which is a non-nil error if it is
+// returned as an error, so err must be checked before throwing it:
+//
+//	if err != nil {
+//		return Undefined, WrapGoError(err)
+//	}
+func WrapGoError(err error) *Error {
+	switch v := err.(type) {
+	case nil:
+		return nil
+	case *Error:
+		return v
+	case *RuntimeError:
+		return v.Err
+	}
+	return &Error{Message: err.Error(), Cause: err}
+}
+
+// Throwf creates a new Error of given kind like NewError method of kind with a
+// message formatted according to format specifier. kind is one of the builtin
+// errors like ErrType or an error created by errorKind builtin function. If
+// kind is nil, a plain error is created.
+func Throwf(kind *Error, format string, args ...interface{}) *Error {
+	msg := fmt.Sprintf(format, args...)
+	if kind == nil {
+		return &Error{Message: msg}
+	}
+	return kind.NewError(msg)
+}
+
 // NewOperandTypeError creates a new Error from ErrType.
 func NewOperandTypeError(token, leftType, rightType string) *Error {
 	return ErrType.NewError(
@@ -6,9 +6,14 @@ package ugo
 
 import (
 	"context"
+
+	"github.com/ozanh/ugo/parser"
 )
 
 // Eval compiles and runs scripts within same scope.
+// Each run compiles only the given script by reusing the symbol table,
+// constants, local variables and modules cache of previous runs, so scripts
+// of previous runs are not compiled again.
 // If executed script's return statement has no value to return or return is
 // omitted, it returns last value on stack.
 // Warning: Eval is not safe to use concurrently.
@@ -124,3 +129,46 @@ func (*Eval) fixOpPop(bytecode *Bytecode) {
 		bytecode.Main.Instructions[fixPos+2] = 1    // set number of return to 1
 	}
 }
+
+// EvalConstExpr parses and evaluates a single constant expression in src by
+// using the optimizer without compiling and running a script, e.g. `1 + 2 * 3`
+// results in Int(7). Only literals, operators and the builtin functions which
+// are allowed by the optimizer can be used. ErrNotConstantExpr is returned if
+// src is not a single expression or the expression cannot be folded to a
+// constant like `x + 1`.
+func EvalConstExpr(src []byte) (Object, error) {
+	fileSet := parser.NewFileSet()
+	srcFile := fileSet.AddFile("(const)", -1, len(src))
+	pf, err := parser.NewParser(srcFile, src, nil).ParseFile()
+	if err != nil {
+		return nil, err
+	}
+
+	var stmt *parser.ExprStmt
+	if len(pf.Stmts) == 1 {
+		stmt, _ = pf.Stmts[0].(*parser.ExprStmt)
+	}
+	if stmt == nil {
+		return nil, ErrNotConstantExpr.NewError("expected single expression")
+	}
+
+	opts := DefaultCompilerOptions
+	opts.OptimizeConst = true
+	opts.OptimizeExpr = true
+	if err = NewOptimizer(pf, nil, opts).Optimize(); err != nil {
+		return nil, err
+	}
+
+	expr := stmt.Expr
+	for {
+		p, ok := expr.(*parser.ParenExpr)
+		if !ok {
+			break
+		}
+		expr = p.Expr
+	}
+	if obj, ok := litObject(expr); ok {
+		return obj, nil
+	}
+	return nil, ErrNotConstantExpr.NewError(expr.String())
+}
@@ -2,7 +2,9 @@ package ugo_test
 
 import (
 	"context"
+	"errors"
 	"runtime"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -123,3 +125,140 @@ func TestEval(t *testing.T) {
 			`Parse Error: expected statement, found '...'`)
 	})
 }
+
+func TestEvalIncremental(t *testing.T) {
+	// Eval compiles only the given script in each run, results must be same as
+	// compiling all statements of the session at once.
+	testCases := []struct {
+		name  string
+		stmts []string
+		vars  string
+	}{
+		{
+			name:  "locals",
+			stmts: []string{`a := 1`, `b := a + 1`, `a = b * 10`, `var c`},
+			vars:  `a, b, c`,
+		},
+		{
+			name: "closures",
+			stmts: []string{
+				`a := 1`,
+				`get := func() { return a }`,
+				`inc := func() { a++ }`,
+				`a = 10`,
+				`inc()`,
+				`x := get()`,
+				`counter := func() { n := 0; return func() { n++; return n } }()`,
+				`counter()`,
+				`y := counter()`,
+			},
+			vars: `a, x, y, get()`,
+		},
+		{
+			name: "blocks",
+			stmts: []string{
+				`a := 0`,
+				`for i := 0; i < 5; i++ { a += i }`,
+				`if a > 5 { b := a; a = b * 2 } else { a = -1 }`,
+				`b := "b"`,
+				`try { throw "err" } catch err { b += string(err.Message) }`,
+				`for k, v in {x: 1} { b += k + v }`,
+			},
+			vars: `a, b`,
+		},
+		{
+			name: "shadowing",
+			stmts: []string{
+				`len := func(x) { return -1 }`,
+				`a := len([1, 2])`,
+				`f := func() { len := 3; return len }`,
+				`b := f()`,
+				`a = a + 10`,
+			},
+			vars: `a, b, len("xy")`,
+		},
+		{
+			name: "modules",
+			stmts: []string{
+				`time := import("time")`,
+				`d := time.Second`,
+				`time.Second = 1`,
+				`m := import("time")`,
+			},
+			vars: `d == m.Minute / 60, m.Second`,
+		},
+	}
+
+	opts := DefaultCompilerOptions
+	for _, tC := range testCases {
+		t.Run(tC.name, func(t *testing.T) {
+			mm := NewModuleMap().AddBuiltinModule("time", ugotime.Module)
+
+			full := strings.Join(tC.stmts, "\n") + "\nreturn [" + tC.vars + "]"
+			o := opts
+			o.ModuleMap = mm
+			bc, err := Compile([]byte(full), o)
+			require.NoError(t, err)
+			expected, err := NewVM(bc).Run(nil)
+			require.NoError(t, err)
+
+			o = opts
+			o.ModuleMap = mm.Copy()
+			eval := NewEval(o, nil)
+			for _, s := range tC.stmts {
+				_, _, err := eval.Run(context.Background(), []byte(s))
+				require.NoError(t, err, s)
+			}
+			ret, _, err := eval.Run(context.Background(),
+				[]byte("["+tC.vars+"]"))
+			require.NoError(t, err)
+			require.Equal(t, expected, ret)
+		})
+	}
+}
+
+func TestEvalConstExpr(t *testing.T) {
+	testCases := []struct {
+		s string
+		e Object
+	}{
+		{s: `1 + 2 * 3`, e: Int(7)},
+		{s: `(1 + 2) * 3`, e: Int(9)},
+		{s: `((10))`, e: Int(10)},
+		{s: `-5u`, e: Uint(1<<64 - 5)},
+		{s: `1.5 * 2`, e: Float(3)},
+		{s: `"a" + "b" + 'c' + 1`, e: String("abc1")},
+		{s: `'a'`, e: Char('a')},
+		{s: `1 < 2 && "x"`, e: String("x")},
+		{s: `0 || false`, e: False},
+		{s: `1 > 2 ? "yes" : "no"`, e: String("no")},
+		{s: `len("abc") * 2`, e: Int(6)},
+		{s: `undefined`, e: Undefined},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.s, func(t *testing.T) {
+			ret, err := EvalConstExpr([]byte(tC.s))
+			require.NoError(t, err)
+			require.Equal(t, tC.e, ret)
+		})
+	}
+
+	for _, s := range []string{
+		`x + 1`, `[1, 2]`, `{a: 1}`, `func() {}`, `1; 2`, `a := 1`,
+		`printf("x")`, ``,
+	} {
+		_, err := EvalConstExpr([]byte(s))
+		require.Error(t, err, s)
+		require.True(t, errors.Is(err, ErrNotConstantExpr), s)
+	}
+	_, err := EvalConstExpr([]byte(`x + 1`))
+	require.EqualError(t, err, "NotConstantExpressionError: (x + 1)")
+
+	_, err = EvalConstExpr([]byte(`1 +`))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "Parse Error")
+
+	_, err = EvalConstExpr([]byte(`1 / 0`))
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "ZeroDivisionError")
+}
@@ -0,0 +1,283 @@
+// Copyright (c) 2020-2023 Ozan Hacıbekiroğlu.
+// Use of this source code is governed by a MIT License
+// that can be found in the LICENSE file.
+
+package ugo
+
+import (
+	"github.com/ozanh/ugo/parser"
+	"github.com/ozanh/ugo/token"
+)
+
+// Frozen represents an immutable array or map and implements Object
+// interface. Value is either an Array or a Map whose array and map elements
+// are frozen as well. Frozen values are created with freeze builtin function,
+// calls with constant array and map literals are evaluated at compile time and
+// stored as constants.
+type Frozen struct {
+	Value Object
+}
+
+var (
+	_ Object       = (*Frozen)(nil)
+	_ LengthGetter = (*Frozen)(nil)
+)
+
+// Freeze returns a frozen deep copy of given array or map. If o is neither an
+// array nor a map, it is returned as is. Reference cycles of o are preserved
+// in the frozen copy.
+func Freeze(o Object) Object {
+	return freeze(o, map[objectRef]*Frozen{})
+}
+
+// freeze returns the frozen copy of o, seen holds the frozen copies of
+// arrays and maps by their references to freeze shared and cyclic references
+// once.
+func freeze(o Object, seen map[objectRef]*Frozen) Object {
+	ref := refOf(o)
+	if f, ok := seen[ref]; ok && ref.ptr != 0 {
+		return f
+	}
+
+	switch v := o.(type) {
+	case Array:
+		f := &Frozen{}
+		seen[ref] = f
+		arr := make(Array, len(v))
+		for i := range v {
+			arr[i] = freeze(v[i], seen)
+		}
+		f.Value = arr
+		return f
+	case Map:
+		f := &Frozen{}
+		seen[ref] = f
+		m := make(Map, len(v))
+		for k, e := range v {
+			m[k] = freeze(e, seen)
+		}
+		f.Value = m
+		return f
+	}
+	return o
+}
+
+// deepCopy returns o itself if it has no mutable elements, otherwise a frozen
+// deep copy of o. Frozen elements are immutable but other elements like sync
+// maps are not, so they are copied.
+func (o *Frozen) deepCopy(seen map[objectRef]Object) Object {
+	ref := pointerRef(o)
+	if cp, ok := seen[ref]; ok {
+		return cp
+	}
+	if !o.hasMutable(map[*Frozen]struct{}{}) {
+		seen[ref] = o
+		return o
+	}
+
+	cp := &Frozen{}
+	seen[ref] = cp
+	cp.Value = deepCopyRefs(o.Value, seen)
+	return cp
+}
+
+// hasMutable reports whether o has an element which is copied by deep copy.
+func (o *Frozen) hasMutable(seen map[*Frozen]struct{}) bool {
+	if _, ok := seen[o]; ok {
+		return false
+	}
+	seen[o] = struct{}{}
+
+	var mutable bool
+	o.each(func(e Object) bool {
+		switch v := e.(type) {
+		case *Frozen:
+			mutable = v.hasMutable(seen)
+		case DeepCopier, Copier:
+			mutable = true
+		}
+		return !mutable
+	})
+	return mutable
+}
+
+// each calls fn for elements of o until fn returns false.
+func (o *Frozen) each(fn func(Object) bool) {
+	switch v := o.Value.(type) {
+	case Array:
+		for _, e := range v {
+			if !fn(e) {
+				return
+			}
+		}
+	case Map:
+		for _, e := range v {
+			if !fn(e) {
+				return
+			}
+		}
+	}
+}
+
+// Thaw returns a mutable copy of o if it is frozen. If deep is true, frozen
+// elements are thawed recursively and other elements are deep copied,
+// otherwise elements are shared with o and they remain frozen. If o is not
+// frozen, it is returned as is.
+func Thaw(o Object, deep bool) Object {
+	var seen map[*Frozen]Object
+	if deep {
+		seen = map[*Frozen]Object{}
+	}
+	return thaw(o, seen)
+}
+
+// thaw returns the mutable copy of o if it is frozen. Elements are thawed
+// recursively if seen is not nil, which holds the thawed copies of frozen
+// values to preserve shared and cyclic references.
+func thaw(o Object, seen map[*Frozen]Object) Object {
+	f, ok := o.(*Frozen)
+	if !ok {
+		return o
+	}
+	if v, ok := seen[f]; ok {
+		return v
+	}
+
+	elem := func(e Object) Object {
+		if seen == nil {
+			return e
+		}
+		if _, ok := e.(*Frozen); ok {
+			return thaw(e, seen)
+		}
+		return deepCopy(e)
+	}
+
+	switch v := f.Value.(type) {
+	case Array:
+		arr := make(Array, len(v))
+		if seen != nil {
+			seen[f] = arr
+		}
+		for i := range v {
+			arr[i] = elem(v[i])
+		}
+		return arr
+	case Map:
+		m := make(Map, len(v))
+		if seen != nil {
+			seen[f] = m
+		}
+		for k, e := range v {
+			m[k] = elem(e)
+		}
+		return m
+	}
+	return f.Value
+}
+
+// unfreeze returns the underlying value of o if it is frozen, otherwise o.
+// Returned arrays and maps of frozen values must not be modified.
+func unfreeze(o Object) Object {
+	if f, ok := o.(*Frozen); ok {
+		return f.Value
+	}
+	return o
+}
+
+// TypeName implements Object interface. Type names of frozen values are
+// different from their mutable versions to reflect immutability, but isArray
+// and isMap builtin functions report true for frozen arrays and maps.
+func (o *Frozen) TypeName() string {
+	switch o.Value.(type) {
+	case Array:
+		return "frozenArray"
+	case Map:
+		return "frozenMap"
+	}
+	return "frozen"
+}
+
+// String implements Object interface.
+func (o *Frozen) String() string {
+	return o.Value.String()
+}
+
+// BinaryOp implements Object interface.
+func (o *Frozen) BinaryOp(tok token.Token, right Object) (Object, error) {
+	return o.Value.BinaryOp(tok, right)
+}
+
+// IsFalsy implements Object interface.
+func (o *Frozen) IsFalsy() bool {
+	return o.Value.IsFalsy()
+}
+
+// Equal implements Object interface. Frozen values are equal to frozen and
+// mutable values having equal elements.
+func (o *Frozen) Equal(right Object) bool {
+	if v, ok := right.(*Frozen); ok {
+		right = v.Value
+	}
+	return o.Value.Equal(right)
+}
+
+// Call implements Object interface.
+func (*Frozen) Call(...Object) (Object, error) {
+	return nil, ErrNotCallable
+}
+
+// CanCall implements Object interface.
+func (*Frozen) CanCall() bool { return false }
+
+// Iterate implements Object interface.
+func (o *Frozen) Iterate() Iterator {
+	return o.Value.Iterate()
+}
+
+// CanIterate implements Object interface.
+func (*Frozen) CanIterate() bool { return true }
+
+// IndexGet implements Object interface.
+func (o *Frozen) IndexGet(index Object) (Object, error) {
+	return o.Value.IndexGet(index)
+}
+
+// IndexSet implements Object interface and always returns
+// ErrNotIndexAssignable.
+func (o *Frozen) IndexSet(_, _ Object) error {
+	return ErrNotIndexAssignable.NewError(o.TypeName())
+}
+
+// Len implements LengthGetter interface.
+func (o *Frozen) Len() int {
+	return o.Value.(LengthGetter).Len()
+}
+
+// constFrozen returns the frozen value of given expression if it is an array
+// or a map literal consisting of constant literals.
+func constFrozen(expr parser.Expr) (Object, bool) {
+	switch expr := expr.(type) {
+	case *parser.ArrayLit:
+		arr := make(Array, len(expr.Elements))
+		for i, e := range expr.Elements {
+			v, ok := constFrozen(e)
+			if !ok {
+				return nil, false
+			}
+			arr[i] = v
+		}
+		return &Frozen{Value: arr}, true
+	case *parser.MapLit:
+		m := make(Map, len(expr.Elements))
+		for _, e := range expr.Elements {
+			v, ok := constFrozen(e.Value)
+			if !ok {
+				return nil, false
+			}
+			m[e.Key] = v
+		}
+		return &Frozen{Value: m}, true
+	}
+	return litObject(expr)
+}
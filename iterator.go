@@ -5,6 +5,7 @@
 package ugo
 
 import (
+	"sort"
 	"sync"
 	"unicode/utf8"
 )
@@ -45,7 +46,7 @@ func (it *ArrayIterator) Next() bool {
 
 // Key implements Iterator interface.
 func (it *ArrayIterator) Key() Object {
-	return Int(it.i - 1)
+	return intObject(Int(it.i - 1))
 }
 
 // Value implements Iterator interface.
@@ -73,14 +74,14 @@ func (it *BytesIterator) Next() bool {
 
 // Key implements Iterator interface.
 func (it *BytesIterator) Key() Object {
-	return Int(it.i - 1)
+	return intObject(Int(it.i - 1))
 }
 
 // Value implements Iterator interface.
 func (it *BytesIterator) Value() Object {
 	i := it.i - 1
 	if i > -1 && i < len(it.V) {
-		return Int(it.V[i])
+		return intObject(Int(it.V[i]))
 	}
 	return Undefined
 }
@@ -102,7 +103,7 @@ func (it *MapIterator) Next() bool {
 
 // Key implements Iterator interface.
 func (it *MapIterator) Key() Object {
-	return String(it.keys[it.i-1])
+	return stringObject(it.keys[it.i-1])
 }
 
 // Value implements Iterator interface.
@@ -114,6 +115,19 @@ func (it *MapIterator) Value() Object {
 	return v
 }
 
+// sortIteratorKeys sorts the keys of map iterators to iterate maps in a
+// deterministic order. Other iterators are not changed.
+func sortIteratorKeys(it Iterator) {
+	switch v := it.(type) {
+	case *MapIterator:
+		sort.Strings(v.keys)
+	case *SyncIterator:
+		sortIteratorKeys(v.Iterator)
+	case *EnumerateIterator:
+		sortIteratorKeys(v.Iterator)
+	}
+}
+
 // SyncIterator represents an iterator for the SyncMap.
 type SyncIterator struct {
 	mu sync.Mutex
@@ -170,10 +184,80 @@ func (it *StringIterator) Next() bool {
 
 // Key implements Iterator interface.
 func (it *StringIterator) Key() Object {
-	return Int(it.k)
+	return intObject(Int(it.k))
 }
 
 // Value implements Iterator interface.
 func (it *StringIterator) Value() Object {
 	return Char(it.r)
 }
+
+// Enumerator is an iterable object yielding [index, value] pairs of the
+// elements of an iterable Value, where index starts from 0 and is incremented
+// for each element. It is created by enumerate builtin function.
+type Enumerator struct {
+	ObjectImpl
+	Value Object
+}
+
+var _ Object = (*Enumerator)(nil)
+
+// TypeName implements Object interface.
+func (*Enumerator) TypeName() string {
+	return "enumerator"
+}
+
+// String implements Object interface.
+func (*Enumerator) String() string {
+	return "<enumerator>"
+}
+
+// IsFalsy implements Object interface.
+func (*Enumerator) IsFalsy() bool {
+	return false
+}
+
+// Equal implements Object interface.
+func (o *Enumerator) Equal(right Object) bool {
+	v, ok := right.(*Enumerator)
+	return ok && v == o
+}
+
+// CanIterate implements Object interface.
+func (*Enumerator) CanIterate() bool {
+	return true
+}
+
+// Iterate implements Object interface. Each call returns a new iterator
+// starting from the first element of Value.
+func (o *Enumerator) Iterate() Iterator {
+	return &EnumerateIterator{Iterator: o.Value.Iterate(), i: -1}
+}
+
+// EnumerateIterator represents an iterator for the Enumerator. Keys are the
+// indexes and values are [index, value] pairs of the wrapped Iterator.
+type EnumerateIterator struct {
+	Iterator
+	i int
+}
+
+var _ Iterator = (*EnumerateIterator)(nil)
+
+// Next implements Iterator interface.
+func (it *EnumerateIterator) Next() bool {
+	if !it.Iterator.Next() {
+		return false
+	}
+	it.i++
+	return true
+}
+
+// Key implements Iterator interface.
+func (it *EnumerateIterator) Key() Object {
+	return intObject(Int(it.i))
+}
+
+// Value implements Iterator interface.
+func (it *EnumerateIterator) Value() Object {
+	return Array{intObject(Int(it.i)), it.Iterator.Value()}
+}
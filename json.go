@@ -0,0 +1,265 @@
+// Copyright (c) 2020-2023 Ozan Hacıbekiroğlu.
+// Use of this source code is governed by a MIT License
+// that can be found in the LICENSE file.
+
+package ugo
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"math"
+	"sort"
+	"strconv"
+)
+
+// JSONMarshaler is implemented by objects which can encode themselves to
+// JSON. Builtin types implement it and stdlib/json module uses it to encode
+// other objects, so custom types of embedders can implement it to be encoded
+// by scripts. It has the same method as json.Marshaler interface.
+type JSONMarshaler interface {
+	MarshalJSON() ([]byte, error)
+}
+
+// JSONUnmarshaler is implemented by objects which can decode a JSON
+// representation of themselves. stdlib/json module calls it to decode JSON
+// into a given object. It has the same method as json.Unmarshaler interface.
+type JSONUnmarshaler interface {
+	UnmarshalJSON([]byte) error
+}
+
+var (
+	_ JSONMarshaler = (*UndefinedType)(nil)
+	_ JSONMarshaler = Bool(false)
+	_ JSONMarshaler = Int(0)
+	_ JSONMarshaler = Uint(0)
+	_ JSONMarshaler = Float(0)
+	_ JSONMarshaler = Char(0)
+	_ JSONMarshaler = String("")
+	_ JSONMarshaler = Bytes(nil)
+	_ JSONMarshaler = Array(nil)
+	_ JSONMarshaler = Map(nil)
+	_ JSONMarshaler = (*SyncMap)(nil)
+	_ JSONMarshaler = (*Frozen)(nil)
+)
+
+// MarshalJSON implements JSONMarshaler interface, undefined is encoded as null.
+func (*UndefinedType) MarshalJSON() ([]byte, error) {
+	return []byte("null"), nil
+}
+
+// MarshalJSON implements JSONMarshaler interface.
+func (o Bool) MarshalJSON() ([]byte, error) { return marshalJSON(o) }
+
+// MarshalJSON implements JSONMarshaler interface.
+func (o Int) MarshalJSON() ([]byte, error) { return marshalJSON(o) }
+
+// MarshalJSON implements JSONMarshaler interface.
+func (o Uint) MarshalJSON() ([]byte, error) { return marshalJSON(o) }
+
+// MarshalJSON implements JSONMarshaler interface. NaN and infinite values
+// cannot be encoded.
+func (o Float) MarshalJSON() ([]byte, error) { return marshalJSON(o) }
+
+// MarshalJSON implements JSONMarshaler interface, char is encoded as its
+// integer value.
+func (o Char) MarshalJSON() ([]byte, error) { return marshalJSON(o) }
+
+// MarshalJSON implements JSONMarshaler interface.
+func (o String) MarshalJSON() ([]byte, error) { return marshalJSON(o) }
+
+// MarshalJSON implements JSONMarshaler interface, bytes are encoded as base64
+// encoded string.
+func (o Bytes) MarshalJSON() ([]byte, error) { return marshalJSON(o) }
+
+// MarshalJSON implements JSONMarshaler interface. Elements which do not
+// implement JSONMarshaler are encoded as null.
+func (o Array) MarshalJSON() ([]byte, error) { return marshalJSON(o) }
+
+// MarshalJSON implements JSONMarshaler interface. Keys are sorted and values
+// which do not implement JSONMarshaler are encoded as null.
+func (o Map) MarshalJSON() ([]byte, error) { return marshalJSON(o) }
+
+// MarshalJSON implements JSONMarshaler interface like Map.
+func (o *SyncMap) MarshalJSON() ([]byte, error) { return marshalJSON(o) }
+
+// MarshalJSON implements JSONMarshaler interface and encodes the frozen value.
+func (o *Frozen) MarshalJSON() ([]byte, error) { return marshalJSON(o) }
+
+func marshalJSON(o Object) ([]byte, error) {
+	var e jsonEncoder
+	if err := e.encode(o); err != nil {
+		return nil, err
+	}
+	return e.buf.Bytes(), nil
+}
+
+// jsonEncoder encodes builtin types to JSON. Arrays and maps being encoded are
+// kept in path to report cyclic references as error.
+type jsonEncoder struct {
+	buf  bytes.Buffer
+	path []objectRef
+}
+
+func (e *jsonEncoder) encode(o Object) error {
+	switch v := o.(type) {
+	case nil, *UndefinedType:
+		e.buf.WriteString("null")
+	case Bool:
+		e.buf.WriteString(strconv.FormatBool(bool(v)))
+	case Int:
+		e.buf.WriteString(strconv.FormatInt(int64(v), 10))
+	case Uint:
+		e.buf.WriteString(strconv.FormatUint(uint64(v), 10))
+	case Char:
+		e.buf.WriteString(strconv.FormatInt(int64(v), 10))
+	case Float:
+		return e.encodeFloat(float64(v))
+	case String:
+		return e.encodeString(string(v))
+	case Bytes:
+		e.buf.WriteByte('"')
+		e.buf.WriteString(base64.StdEncoding.EncodeToString(v))
+		e.buf.WriteByte('"')
+	case Array:
+		return e.encodeArray(v)
+	case Map:
+		return e.encodeMap(v)
+	case *SyncMap:
+		if v == nil {
+			e.buf.WriteString("null")
+			return nil
+		}
+		v.mu.RLock()
+		defer v.mu.RUnlock()
+		return e.encodeMap(v.Value)
+	case *Frozen:
+		if v == nil {
+			e.buf.WriteString("null")
+			return nil
+		}
+		return e.encode(v.Value)
+	case JSONMarshaler:
+		b, err := v.MarshalJSON()
+		if err != nil {
+			return err
+		}
+		return json.Compact(&e.buf, b)
+	default:
+		e.buf.WriteString("null")
+	}
+	return nil
+}
+
+// encodeFloat encodes f like ES6 number to string conversion like
+// encoding/json package.
+func (e *jsonEncoder) encodeFloat(f float64) error {
+	if math.IsInf(f, 0) || math.IsNaN(f) {
+		return ErrType.NewError("json: unsupported value: " +
+			strconv.FormatFloat(f, 'g', -1, 64))
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	b := strconv.AppendFloat(nil, f, format, -1, 64)
+	if format == 'e' {
+		// clean up e-09 to e-9
+		n := len(b)
+		if n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+	e.buf.Write(b)
+	return nil
+}
+
+func (e *jsonEncoder) encodeString(s string) error {
+	b, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	e.buf.Write(b)
+	return nil
+}
+
+// enter returns an error if the referenced object is already being encoded or
+// adds it to path.
+func (e *jsonEncoder) enter(ref objectRef, typeName string) error {
+	if ref.ptr == 0 {
+		return nil
+	}
+	for _, r := range e.path {
+		if r == ref {
+			return ErrType.NewError(
+				"json: unsupported value: encountered a cycle via " + typeName)
+		}
+	}
+	e.path = append(e.path, ref)
+	return nil
+}
+
+func (e *jsonEncoder) leave(ref objectRef) {
+	if ref.ptr != 0 {
+		e.path = e.path[:len(e.path)-1]
+	}
+}
+
+func (e *jsonEncoder) encodeArray(arr Array) error {
+	if arr == nil {
+		e.buf.WriteString("null")
+		return nil
+	}
+	ref := refOf(arr)
+	if err := e.enter(ref, "array"); err != nil {
+		return err
+	}
+	defer e.leave(ref)
+
+	e.buf.WriteByte('[')
+	for i, v := range arr {
+		if i > 0 {
+			e.buf.WriteByte(',')
+		}
+		if err := e.encode(v); err != nil {
+			return err
+		}
+	}
+	e.buf.WriteByte(']')
+	return nil
+}
+
+func (e *jsonEncoder) encodeMap(m Map) error {
+	if m == nil {
+		e.buf.WriteString("null")
+		return nil
+	}
+	ref := refOf(m)
+	if err := e.enter(ref, "map"); err != nil {
+		return err
+	}
+	defer e.leave(ref)
+
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	e.buf.WriteByte('{')
+	for i, k := range keys {
+		if i > 0 {
+			e.buf.WriteByte(',')
+		}
+		if err := e.encodeString(k); err != nil {
+			return err
+		}
+		e.buf.WriteByte(':')
+		if err := e.encode(m[k]); err != nil {
+			return err
+		}
+	}
+	e.buf.WriteByte('}')
+	return nil
+}
@@ -0,0 +1,86 @@
+package ugo_test
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ozanh/ugo"
+)
+
+type jsonPoint struct {
+	ObjectImpl
+	X, Y int
+}
+
+func (p *jsonPoint) MarshalJSON() ([]byte, error) {
+	return json.Marshal(map[string]int{"x": p.X, "y": p.Y})
+}
+
+func TestJSONMarshaler(t *testing.T) {
+	testCases := []struct {
+		obj  Object
+		want string
+	}{
+		{Undefined, `null`},
+		{True, `true`},
+		{Int(-1), `-1`},
+		{Uint(math.MaxUint64), `18446744073709551615`},
+		{Float(3.4), `3.4`},
+		{Float(1e-7), `1e-7`},
+		{Float(1e21), `1e+21`},
+		{Char('x'), `120`},
+		{String("a<\"b\">"), `"a\u003c\"b\"\u003e"`},
+		{Bytes{0, 1}, `"AAE="`},
+		{Bytes(nil), `""`},
+		{Array{}, `[]`},
+		{Array(nil), `null`},
+		{Map{}, `{}`},
+		{Map(nil), `null`},
+		{Array{Int(1), Undefined, Map{"b": Char('a'), "a": Array{}}},
+			`[1,null,{"a":[],"b":97}]`},
+		{&SyncMap{Value: Map{"a": Int(1)}}, `{"a":1}`},
+		{Freeze(Map{"a": Array{Int(1)}}), `{"a":[1]}`},
+		// custom marshalers are called, other objects are encoded as null
+		{Array{&jsonPoint{X: 1, Y: 2}, &Function{}}, `[{"x":1,"y":2},null]`},
+	}
+	for _, tC := range testCases {
+		b, err := json.Marshal(tC.obj)
+		require.NoError(t, err, "%#v", tC.obj)
+		require.Equal(t, tC.want, string(b), "%#v", tC.obj)
+
+		b, err = tC.obj.(JSONMarshaler).MarshalJSON()
+		require.NoError(t, err, "%#v", tC.obj)
+		require.Equal(t, tC.want, string(b), "%#v", tC.obj)
+	}
+
+	// Go values holding objects
+	b, err := json.Marshal(map[string]Object{"u": Undefined, "c": Char('a')})
+	require.NoError(t, err)
+	require.Equal(t, `{"c":97,"u":null}`, string(b))
+
+	_, err = Float(math.NaN()).MarshalJSON()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "json: unsupported value: NaN")
+	_, err = Array{Float(math.Inf(1))}.MarshalJSON()
+	require.Error(t, err)
+
+	arr := Array{Int(1), nil}
+	arr[1] = arr
+	_, err = arr.MarshalJSON()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "encountered a cycle via array")
+	m := Map{}
+	m["m"] = Array{m}
+	_, err = m.MarshalJSON()
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "encountered a cycle via map")
+
+	// shared values are not cycles
+	shared := Array{Int(1)}
+	b, err = Array{shared, shared}.MarshalJSON()
+	require.NoError(t, err)
+	require.Equal(t, `[[1],[1]]`, string(b))
+}
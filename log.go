@@ -0,0 +1,225 @@
+// Copyright (c) 2020-2023 Ozan Hacıbekiroğlu.
+// Use of this source code is governed by a MIT License
+// that can be found in the LICENSE file.
+
+package ugo
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// LogWriter is the default writer for log builtins if VM has no logger.
+var LogWriter io.Writer = os.Stderr
+
+// LogLevel represents the level of log builtins.
+type LogLevel int
+
+// Log levels
+const (
+	LogDebug LogLevel = iota
+	LogInfo
+	LogWarn
+	LogError
+)
+
+var logLevelNames = [...]string{
+	LogDebug: "debug",
+	LogInfo:  "info",
+	LogWarn:  "warn",
+	LogError: "error",
+}
+
+// String returns the name of the log level.
+func (l LogLevel) String() string {
+	if l >= LogDebug && l <= LogError {
+		return logLevelNames[l]
+	}
+	return "LogLevel(" + strconv.Itoa(int(l)) + ")"
+}
+
+// logConfig holds the log settings of a VM. Setters replace it as a whole so
+// that log builtins can load it without locking the VM, which is held by Run.
+type logConfig struct {
+	writer io.Writer
+	level  LogLevel
+	json   bool
+}
+
+func (vm *VM) loadLogConfig() logConfig {
+	if cfg, ok := vm.logConfig.Load().(*logConfig); ok {
+		return *cfg
+	}
+	return logConfig{}
+}
+
+// SetLogger sets the writer of log builtins. If it is not set, LogWriter is
+// used. Each log entry is written with a single Write call.
+func (vm *VM) SetLogger(w io.Writer) *VM {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+	cfg := vm.loadLogConfig()
+	cfg.writer = w
+	vm.logConfig.Store(&cfg)
+	return vm
+}
+
+// SetLogLevel sets the minimum level of the log entries to be written, entries
+// having lower levels are discarded. Default level is LogDebug.
+func (vm *VM) SetLogLevel(level LogLevel) *VM {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+	cfg := vm.loadLogConfig()
+	cfg.level = level
+	vm.logConfig.Store(&cfg)
+	return vm
+}
+
+// SetLogJSON sets the format of log entries to JSON lines if v is true,
+// otherwise entries are written as key=value pairs.
+func (vm *VM) SetLogJSON(v bool) *VM {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+	cfg := vm.loadLogConfig()
+	cfg.json = v
+	vm.logConfig.Store(&cfg)
+	return vm
+}
+
+func builtinLogFunc(c Call) (Object, error) {
+	if c.Len() < 2 || c.Len() > 3 {
+		return Undefined, ErrWrongNumArguments.NewError(
+			"want=2..3 got=" + strconv.Itoa(c.Len()))
+	}
+
+	s, ok := c.Get(0).(String)
+	if !ok {
+		return Undefined, NewArgumentTypeError("1st", "string", c.Get(0).TypeName())
+	}
+
+	for i, name := range logLevelNames {
+		if name == string(s) {
+			c.shift()
+			return writeLog(c, LogLevel(i))
+		}
+	}
+	return Undefined, ErrType.NewError(
+		"invalid log level: " + strconv.Quote(string(s)))
+}
+
+func newLevelLogFunc(level LogLevel) CallableExFunc {
+	return func(c Call) (Object, error) {
+		if c.Len() < 1 || c.Len() > 2 {
+			return Undefined, ErrWrongNumArguments.NewError(
+				"want=1..2 got=" + strconv.Itoa(c.Len()))
+		}
+		return writeLog(c, level)
+	}
+}
+
+// writeLog writes the message and optional fields in Call to the logger of the
+// VM with given level.
+func writeLog(c Call, level LogLevel) (Object, error) {
+	var fields Map
+	if c.Len() == 2 {
+		var ok bool
+		if fields, ok = c.Get(1).(Map); !ok {
+			return Undefined, NewArgumentTypeError("fields", "map",
+				c.Get(1).TypeName())
+		}
+	}
+
+	w := LogWriter
+	var asJSON bool
+	if vm := c.VM(); vm != nil {
+		cfg := vm.loadLogConfig()
+		if level < cfg.level {
+			return Undefined, nil
+		}
+		if cfg.writer != nil {
+			w = cfg.writer
+		}
+		asJSON = cfg.json
+	}
+
+	var line string
+	if asJSON {
+		line = formatLogJSON(level, c.Get(0).String(), fields)
+	} else {
+		line = formatLogKV(level, c.Get(0).String(), fields)
+	}
+
+	if _, err := io.WriteString(w, line); err != nil {
+		return Undefined, err
+	}
+	return Undefined, nil
+}
+
+func sortedKeys(m Map) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func formatLogKV(level LogLevel, msg string, fields Map) string {
+	var sb strings.Builder
+	sb.WriteString("level=")
+	sb.WriteString(level.String())
+	sb.WriteString(" msg=")
+	sb.WriteString(logfmtValue(msg))
+
+	for _, k := range sortedKeys(fields) {
+		sb.WriteByte(' ')
+		sb.WriteString(logfmtValue(k))
+		sb.WriteByte('=')
+		sb.WriteString(logfmtValue(fields[k].String()))
+	}
+	sb.WriteByte('\n')
+	return sb.String()
+}
+
+// logfmtValue quotes s if it is empty or contains space, quote, equal sign or
+// control characters.
+func logfmtValue(s string) string {
+	if s == "" {
+		return `""`
+	}
+	for _, r := range s {
+		if r <= ' ' || r == '"' || r == '=' || r == 0x7f {
+			return strconv.Quote(s)
+		}
+	}
+	return s
+}
+
+func formatLogJSON(level LogLevel, msg string, fields Map) string {
+	var sb strings.Builder
+	sb.WriteString(`{"level":`)
+	sb.WriteString(strconv.Quote(level.String()))
+	sb.WriteString(`,"msg":`)
+	writeJSONValue(&sb, msg)
+
+	for _, k := range sortedKeys(fields) {
+		sb.WriteByte(',')
+		writeJSONValue(&sb, k)
+		sb.WriteByte(':')
+		writeJSONValue(&sb, ToInterface(fields[k]))
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+func writeJSONValue(sb *strings.Builder, v interface{}) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		b, _ = json.Marshal(err.Error())
+	}
+	sb.Write(b)
+}
@@ -0,0 +1,129 @@
+package ugo_test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ozanh/ugo"
+)
+
+func TestVMLog(t *testing.T) {
+	run := func(t *testing.T, script string, fn func(vm *VM)) (string, error) {
+		t.Helper()
+		bc, err := Compile([]byte(script), DefaultCompilerOptions)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		vm := NewVM(bc).SetLogger(&buf)
+		if fn != nil {
+			fn(vm)
+		}
+		_, err = vm.Run(nil)
+		return buf.String(), err
+	}
+
+	out, err := run(t, `log("info", "hello")`, nil)
+	require.NoError(t, err)
+	require.Equal(t, "level=info msg=hello\n", out)
+
+	out, err = run(t, `
+	debug("a b", {z: 1, a: "x y", m: [1, 2]})
+	info("started", {id: 1})
+	warn("", {"k=v": true})
+	log("error", "failed", {err: error("x")})
+	`, nil)
+	require.NoError(t, err)
+	require.Equal(t,
+		"level=debug msg=\"a b\" a=\"x y\" m=\"[1, 2]\" z=1\n"+
+			"level=info msg=started id=1\n"+
+			"level=warn msg=\"\" \"k=v\"=true\n"+
+			"level=error msg=failed err=\"error: x\"\n", out)
+
+	out, err = run(t, `
+	debug("a", {x: 1})
+	info("b", {s: "x\"y", u: undefined, f: 1.5, arr: [1, "a"]})
+	`, func(vm *VM) { vm.SetLogJSON(true) })
+	require.NoError(t, err)
+	require.Equal(t,
+		`{"level":"debug","msg":"a","x":1}`+"\n"+
+			`{"level":"info","msg":"b","arr":[1,"a"],"f":1.5,"s":"x\"y","u":null}`+"\n",
+		out)
+
+	// disabled levels are no-op
+	out, err = run(t, `
+	debug("a")
+	info("b")
+	log("debug", "c", {x: 1})
+	warn("d")
+	log("error", "e")
+	`, func(vm *VM) { vm.SetLogLevel(LogWarn) })
+	require.NoError(t, err)
+	require.Equal(t, "level=warn msg=d\nlevel=error msg=e\n", out)
+
+	// child VMs inherit logger
+	out, err = run(t, `groupBy([1], func(x) { info("child", {x: x}); return x })`,
+		nil)
+	require.NoError(t, err)
+	require.Equal(t, "level=info msg=child x=1\n", out)
+
+	_, err = run(t, `log("fatal", "x")`, nil)
+	require.ErrorIs(t, err, ErrType)
+	_, err = run(t, `log(1, "x")`, nil)
+	require.ErrorIs(t, err, ErrType)
+	_, err = run(t, `log("info")`, nil)
+	require.ErrorIs(t, err, ErrWrongNumArguments)
+	_, err = run(t, `info()`, nil)
+	require.ErrorIs(t, err, ErrWrongNumArguments)
+	_, err = run(t, `info("a", {}, 1)`, nil)
+	require.ErrorIs(t, err, ErrWrongNumArguments)
+	_, err = run(t, `info("a", [])`, nil)
+	require.ErrorIs(t, err, ErrType)
+
+	// default writer is used without a VM
+	var buf bytes.Buffer
+	old := LogWriter
+	LogWriter = &buf
+	defer func() { LogWriter = old }()
+	_, err = BuiltinObjects[BuiltinWarn].Call(String("x"))
+	require.NoError(t, err)
+	require.Equal(t, "level=warn msg=x\n", buf.String())
+
+	require.Equal(t, "error", LogError.String())
+	require.Equal(t, "LogLevel(9)", LogLevel(9).String())
+}
+
+func TestVMLogConcurrentSettings(t *testing.T) {
+	bc, err := Compile([]byte(`return func() { info("x") }`),
+		DefaultCompilerOptions)
+	require.NoError(t, err)
+	vm := NewVM(bc).SetLogger(ioutil.Discard)
+	fn, err := vm.Run(nil)
+	require.NoError(t, err)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 4)
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if _, err := NewInvoker(vm, fn).Invoke(); err != nil {
+					errs <- err
+					return
+				}
+			}
+		}()
+	}
+	for i := 0; i < 100; i++ {
+		vm.SetLogLevel(LogLevel(i % 2)).SetLogJSON(i%3 == 0)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+}
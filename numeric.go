@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"strconv"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/ozanh/ugo/internal/compat"
 	"github.com/ozanh/ugo/token"
@@ -16,6 +17,31 @@ import (
 // Int represents signed integer values and implements Object interface.
 type Int int64
 
+// Range of interned Int values.
+const (
+	minInternedInt = -128
+	maxInternedInt = 1023
+)
+
+// internedInts holds Int values converted to Object interface once to prevent
+// allocations of small integers which are common in loops and indexing.
+var internedInts = func() []Object {
+	arr := make([]Object, maxInternedInt-minInternedInt+1)
+	for i := range arr {
+		arr[i] = Int(i + minInternedInt)
+	}
+	return arr
+}()
+
+// intObject returns v as Object, shared interned values are returned for
+// small integers. Int is immutable so sharing them is safe.
+func intObject(v Int) Object {
+	if v >= minInternedInt && v <= maxInternedInt {
+		return internedInts[v-minInternedInt]
+	}
+	return v
+}
+
 // TypeName implements Object interface.
 func (Int) TypeName() string {
 	return "int"
@@ -73,36 +99,80 @@ func (Int) IndexGet(index Object) (Object, error) {
 	return nil, ErrNotIndexable
 }
 
+// intBinaryOp applies the binary operation to int operands. It returns nil if
+// operation is not supported or fails, e.g. division by zero, so callers can
+// fall back to BinaryOp method to get the error.
+func intBinaryOp(tok token.Token, x, y Int) Object {
+	switch tok {
+	case token.Add:
+		return intObject(x + y)
+	case token.Sub:
+		return intObject(x - y)
+	case token.Mul:
+		return intObject(x * y)
+	case token.Quo:
+		if y == 0 {
+			return nil
+		}
+		return intObject(x / y)
+	case token.Rem:
+		if y == 0 {
+			return nil
+		}
+		return intObject(x % y)
+	case token.And:
+		return intObject(x & y)
+	case token.Or:
+		return intObject(x | y)
+	case token.Xor:
+		return intObject(x ^ y)
+	case token.AndNot:
+		return intObject(x &^ y)
+	case token.Less:
+		return Bool(x < y)
+	case token.LessEq:
+		return Bool(x <= y)
+	case token.Greater:
+		return Bool(x > y)
+	case token.GreaterEq:
+		return Bool(x >= y)
+	}
+	return nil
+}
+
 // BinaryOp implements Object interface.
 func (o Int) BinaryOp(tok token.Token, right Object) (Object, error) {
 	switch v := right.(type) {
 	case Int:
 		switch tok {
 		case token.Add:
-			return o + v, nil
+			return intObject(o + v), nil
 		case token.Sub:
-			return o - v, nil
+			return intObject(o - v), nil
 		case token.Mul:
-			return o * v, nil
+			return intObject(o * v), nil
 		case token.Quo:
 			if v == 0 {
 				return nil, ErrZeroDivision
 			}
-			return o / v, nil
+			return intObject(o / v), nil
 		case token.Rem:
-			return o % v, nil
+			if v == 0 {
+				return nil, ErrZeroDivision
+			}
+			return intObject(o % v), nil
 		case token.And:
-			return o & v, nil
+			return intObject(o & v), nil
 		case token.Or:
-			return o | v, nil
+			return intObject(o | v), nil
 		case token.Xor:
-			return o ^ v, nil
+			return intObject(o ^ v), nil
 		case token.AndNot:
-			return o &^ v, nil
+			return intObject(o &^ v), nil
 		case token.Shl:
-			return o << v, nil
+			return intObject(o << v), nil
 		case token.Shr:
-			return o >> v, nil
+			return intObject(o >> v), nil
 		case token.Less:
 			return Bool(o < v), nil
 		case token.LessEq:
@@ -236,6 +306,9 @@ func (o Uint) BinaryOp(tok token.Token, right Object) (Object, error) {
 			}
 			return o / v, nil
 		case token.Rem:
+			if v == 0 {
+				return nil, ErrZeroDivision
+			}
 			return o % v, nil
 		case token.And:
 			return o & v, nil
@@ -433,8 +506,13 @@ func (Char) TypeName() string {
 	return "char"
 }
 
-// String implements Object interface.
+// String implements Object interface. Invalid code points, i.e. surrogate
+// halves and values out of Unicode range, are converted to the Unicode
+// replacement character U+FFFD.
 func (o Char) String() string {
+	if !utf8.ValidRune(rune(o)) {
+		return string(utf8.RuneError)
+	}
 	return string(o)
 }
 
@@ -502,6 +580,9 @@ func (o Char) BinaryOp(tok token.Token, right Object) (Object, error) {
 			}
 			return o / v, nil
 		case token.Rem:
+			if v == 0 {
+				return nil, ErrZeroDivision
+			}
 			return o % v, nil
 		case token.And:
 			return o & v, nil
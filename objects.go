@@ -9,6 +9,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"reflect"
 	"strconv"
 	"strings"
 	"sync"
@@ -83,11 +84,57 @@ type Object interface {
 	IndexSet(index, value Object) error
 }
 
-// Copier wraps the Copy method to create a deep copy of the object.
+// Copier wraps the Copy method to create a shallow copy of the object.
+// Containers like array and map copy only the container itself, the elements
+// of the copy refer to the same values as the elements of the original.
 type Copier interface {
 	Copy() Object
 }
 
+// DeepCopier wraps the DeepCopy method to create a deep copy of the object.
+// Containers copy their elements recursively, nothing is shared between the
+// original and the copy except immutable values.
+type DeepCopier interface {
+	DeepCopy() Object
+}
+
+// deepCopy returns a deep copy of the given object if DeepCopier is
+// implemented, otherwise it falls back to Copier or returns the object as is.
+func deepCopy(o Object) Object {
+	switch v := o.(type) {
+	case DeepCopier:
+		return v.DeepCopy()
+	case Copier:
+		return v.Copy()
+	}
+	return o
+}
+
+// deepCopyRefs is like deepCopy but arrays, maps, sync maps, frozen values and
+// errors are copied once by their references using seen, so shared and cyclic
+// references are preserved in the copy instead of recursing infinitely.
+func deepCopyRefs(o Object, seen map[objectRef]Object) Object {
+	switch v := o.(type) {
+	case Array:
+		return v.deepCopy(seen)
+	case Map:
+		return v.deepCopy(seen)
+	case *SyncMap:
+		return v.deepCopy(seen)
+	case *Frozen:
+		return v.deepCopy(seen)
+	case *Error:
+		return v.deepCopy(seen)
+	case *RuntimeError:
+		return v.deepCopy(seen)
+	case DeepCopier:
+		return v.DeepCopy()
+	case Copier:
+		return v.Copy()
+	}
+	return o
+}
+
 // IndexDeleter wraps the IndexDelete method to delete an index of an object.
 type IndexDeleter interface {
 	IndexDelete(Object) error
@@ -98,6 +145,11 @@ type LengthGetter interface {
 	Len() int
 }
 
+// CapGetter wraps the Cap method to get the capacity of an object.
+type CapGetter interface {
+	Cap() int
+}
+
 // ExCallerObject is an interface for objects that can be called with CallEx
 // method. It is an extended version of the Call method that can be used to
 // call an object with a Call struct. Objects implementing this interface is
@@ -391,6 +443,9 @@ switchpos:
 			}
 			return bval / v, nil
 		case token.Rem:
+			if v == 0 {
+				return nil, ErrZeroDivision
+			}
 			return bval % v, nil
 		case token.And:
 			return bval & v, nil
@@ -428,6 +483,9 @@ switchpos:
 			}
 			return bval / v, nil
 		case token.Rem:
+			if v == 0 {
+				return nil, ErrZeroDivision
+			}
 			return bval % v, nil
 		case token.And:
 			return bval & v, nil
@@ -482,6 +540,24 @@ type String string
 
 var _ LengthGetter = String("")
 
+// internedStrings holds single byte strings converted to Object interface
+// once to prevent allocations while slicing and iterating strings.
+var internedStrings = func() (arr [256]Object) {
+	for i := range arr {
+		arr[i] = String([]byte{byte(i)})
+	}
+	return
+}()
+
+// stringObject returns s as Object, shared interned values are returned for
+// single byte strings.
+func stringObject(s string) Object {
+	if len(s) == 1 {
+		return internedStrings[s[0]]
+	}
+	return String(s)
+}
+
 // TypeName implements Object interface.
 func (String) TypeName() string {
 	return "string"
@@ -764,11 +840,30 @@ func (o Bytes) Format(s fmt.State, verb rune) {
 }
 
 // Function represents a function object and implements Object interface.
+// If Params is not nil, number and types of arguments are validated against
+// it before calling Value or ValueEx so that wrong calls result in
+// WrongNumArgumentsError and TypeError like compiled functions.
+// If Pure is true, function has no side effects and returns the same result
+// for the same arguments, so optimizer can evaluate its calls with constant
+// arguments at compile time. Pure is only used for functions given in
+// CompilerOptions.ExtraBuiltins, it has no effect on module members.
 type Function struct {
 	ObjectImpl
 	Name    string
 	Value   func(args ...Object) (Object, error)
 	ValueEx func(Call) (Object, error)
+	Params  []FuncParam
+	Pure    bool
+}
+
+// FuncParam represents metadata of a Function parameter. Type is the type name
+// of accepted values or "|" separated type names like "int|uint", an empty
+// Type accepts any value. Only the last parameter can be Variadic which
+// accepts zero or more arguments of Type.
+type FuncParam struct {
+	Name     string
+	Type     string
+	Variadic bool
 }
 
 var _ Object = (*Function)(nil)
@@ -789,6 +884,8 @@ func (o *Function) Copy() Object {
 		Name:    o.Name,
 		Value:   o.Value,
 		ValueEx: o.ValueEx,
+		Params:  o.Params,
+		Pure:    o.Pure,
 	}
 }
 
@@ -809,16 +906,70 @@ func (*Function) CanCall() bool { return true }
 
 // Call implements Object interface.
 func (o *Function) Call(args ...Object) (Object, error) {
+	if o.Params != nil {
+		if err := o.checkArgs(&Call{args: args}); err != nil {
+			return Undefined, err
+		}
+	}
 	return o.Value(args...)
 }
 
 func (o *Function) CallEx(call Call) (Object, error) {
+	if o.Params != nil {
+		if err := o.checkArgs(&call); err != nil {
+			return Undefined, err
+		}
+	}
 	if o.ValueEx != nil {
 		return o.ValueEx(call)
 	}
 	return o.Value(call.callArgs()...)
 }
 
+// checkArgs validates the number and types of arguments against Params.
+func (o *Function) checkArgs(c *Call) error {
+	numParams := len(o.Params)
+	numArgs := c.Len()
+	if numParams > 0 && o.Params[numParams-1].Variadic {
+		if numArgs < numParams-1 {
+			return ErrWrongNumArguments.NewError(
+				wantGEqXGotY(numParams-1, numArgs),
+			)
+		}
+	} else if numArgs != numParams {
+		return ErrWrongNumArguments.NewError(wantEqXGotY(numParams, numArgs))
+	}
+
+	for i := 0; i < numArgs; i++ {
+		p := &o.Params[numParams-1]
+		if i < numParams {
+			p = &o.Params[i]
+		}
+		if p.Type == "" {
+			continue
+		}
+		arg := c.Get(i)
+		if !typeNameIn(arg.TypeName(), p.Type) {
+			return NewArgumentTypeError(ordinal(i+1), p.Type, arg.TypeName())
+		}
+	}
+	return nil
+}
+
+// typeNameIn reports whether name is one of "|" separated type names.
+func typeNameIn(name, types string) bool {
+	for {
+		i := strings.IndexByte(types, '|')
+		if i < 0 {
+			return types == name
+		}
+		if types[:i] == name {
+			return true
+		}
+		types = types[i+1:]
+	}
+}
+
 // BuiltinFunction represents a builtin function object and implements Object interface.
 type BuiltinFunction struct {
 	ObjectImpl
@@ -881,6 +1032,8 @@ type Array []Object
 var (
 	_ Object       = Array{}
 	_ LengthGetter = Array{}
+	_ Copier       = Array{}
+	_ DeepCopier   = Array{}
 )
 
 // TypeName implements Object interface.
@@ -891,39 +1044,61 @@ func (Array) TypeName() string {
 // String implements Object interface.
 func (o Array) String() string {
 	var sb strings.Builder
+	writeArrayString(&sb, o, nil)
+	return sb.String()
+}
+
+func writeArrayString(sb *strings.Builder, o Array, path []uintptr) {
+	if len(o) > 0 {
+		ptr := reflect.ValueOf(o).Pointer()
+		if inPath(path, ptr) {
+			sb.WriteString("[...]")
+			return
+		}
+		path = append(path, ptr)
+	}
+
 	sb.WriteString("[")
 	last := len(o) - 1
 
 	for i := range o {
-		switch v := o[i].(type) {
-		case String:
-			sb.WriteString(strconv.Quote(v.String()))
-		case Char:
-			sb.WriteString(strconv.QuoteRune(rune(v)))
-		case Bytes:
-			sb.WriteString(fmt.Sprint([]byte(v)))
-		default:
-			sb.WriteString(v.String())
-		}
+		writeElemString(sb, o[i], path)
 		if i != last {
 			sb.WriteString(", ")
 		}
 	}
 
 	sb.WriteString("]")
-	return sb.String()
 }
 
 // Copy implements Copier interface.
 func (o Array) Copy() Object {
 	cp := make(Array, len(o))
-	for i, v := range o {
-		if vv, ok := v.(Copier); ok {
-			cp[i] = vv.Copy()
-		} else {
-			cp[i] = v
+	copy(cp, o)
+	return cp
+}
+
+// DeepCopy implements DeepCopier interface. Shared and cyclic references of
+// nested arrays and maps are preserved in the copy.
+func (o Array) DeepCopy() Object {
+	return o.deepCopy(map[objectRef]Object{})
+}
+
+func (o Array) deepCopy(seen map[objectRef]Object) Object {
+	ref := refOf(o)
+	if ref.ptr != 0 {
+		if cp, ok := seen[ref]; ok {
+			return cp
 		}
 	}
+
+	cp := make(Array, len(o))
+	if ref.ptr != 0 {
+		seen[ref] = cp
+	}
+	for i, v := range o {
+		cp[i] = deepCopyRefs(v, seen)
+	}
 	return cp
 }
 
@@ -969,6 +1144,9 @@ func (o Array) IndexGet(index Object) (Object, error) {
 
 // Equal implements Object interface.
 func (o Array) Equal(right Object) bool {
+	if f, ok := right.(*Frozen); ok {
+		right = f.Value
+	}
 	v, ok := right.(Array)
 	if !ok {
 		return false
@@ -1110,6 +1288,7 @@ type Map map[string]Object
 var (
 	_ Object       = Map{}
 	_ Copier       = Map{}
+	_ DeepCopier   = Map{}
 	_ IndexDeleter = Map{}
 	_ LengthGetter = Map{}
 )
@@ -1122,6 +1301,20 @@ func (Map) TypeName() string {
 // String implements Object interface.
 func (o Map) String() string {
 	var sb strings.Builder
+	writeMapString(&sb, o, nil)
+	return sb.String()
+}
+
+func writeMapString(sb *strings.Builder, o Map, path []uintptr) {
+	if len(o) > 0 {
+		ptr := reflect.ValueOf(o).Pointer()
+		if inPath(path, ptr) {
+			sb.WriteString("{...}")
+			return
+		}
+		path = append(path, ptr)
+	}
+
 	sb.WriteString("{")
 	last := len(o) - 1
 	i := 0
@@ -1129,16 +1322,7 @@ func (o Map) String() string {
 	for k := range o {
 		sb.WriteString(strconv.Quote(k))
 		sb.WriteString(": ")
-		switch v := o[k].(type) {
-		case String:
-			sb.WriteString(strconv.Quote(v.String()))
-		case Char:
-			sb.WriteString(strconv.QuoteRune(rune(v)))
-		case Bytes:
-			sb.WriteString(fmt.Sprint([]byte(v)))
-		default:
-			sb.WriteString(v.String())
-		}
+		writeElemString(sb, o[k], path)
 		if i != last {
 			sb.WriteString(", ")
 		}
@@ -1146,19 +1330,72 @@ func (o Map) String() string {
 	}
 
 	sb.WriteString("}")
-	return sb.String()
+}
+
+// writeElemString writes the string representation of an element of an array
+// or a map to sb. Addresses of the arrays and maps being written are kept in
+// path to print cyclic references as "[...]" or "{...}" instead of recursing
+// infinitely.
+func writeElemString(sb *strings.Builder, v Object, path []uintptr) {
+	switch v := v.(type) {
+	case String:
+		sb.WriteString(strconv.Quote(v.String()))
+	case Char:
+		sb.WriteString(strconv.QuoteRune(rune(v)))
+	case Bytes:
+		sb.WriteString(fmt.Sprint([]byte(v)))
+	case Array:
+		writeArrayString(sb, v, path)
+	case Map:
+		writeMapString(sb, v, path)
+	case *SyncMap:
+		v.writeString(sb, path)
+	case *Frozen:
+		writeElemString(sb, v.Value, path)
+	default:
+		sb.WriteString(v.String())
+	}
+}
+
+func inPath(path []uintptr, ptr uintptr) bool {
+	for _, p := range path {
+		if p == ptr {
+			return true
+		}
+	}
+	return false
 }
 
 // Copy implements Copier interface.
 func (o Map) Copy() Object {
 	cp := make(Map, len(o))
 	for k, v := range o {
-		if vv, ok := v.(Copier); ok {
-			cp[k] = vv.Copy()
-		} else {
-			cp[k] = v
+		cp[k] = v
+	}
+	return cp
+}
+
+// DeepCopy implements DeepCopier interface. Shared and cyclic references of
+// nested arrays and maps are preserved in the copy.
+func (o Map) DeepCopy() Object {
+	return o.deepCopy(map[objectRef]Object{})
+}
+
+func (o Map) deepCopy(seen map[objectRef]Object) Object {
+	ref := refOf(o)
+	if ref.ptr != 0 {
+		if cp, ok := seen[ref]; ok {
+			return cp
 		}
 	}
+
+	cp := make(Map, len(o))
+	if ref.ptr != 0 {
+		seen[ref] = cp
+	}
+	for k, v := range o {
+		cp[k] = deepCopyRefs(v, seen)
+	}
 	return cp
 }
 
@@ -1179,6 +1416,9 @@ func (o Map) IndexGet(index Object) (Object, error) {
 
 // Equal implements Object interface.
 func (o Map) Equal(right Object) bool {
+	if f, ok := right.(*Frozen); ok {
+		right = f.Value
+	}
 	v, ok := right.(Map)
 	if !ok {
 		return false
@@ -1261,6 +1501,7 @@ type SyncMap struct {
 var (
 	_ Object       = (*SyncMap)(nil)
 	_ Copier       = (*SyncMap)(nil)
+	_ DeepCopier   = (*SyncMap)(nil)
 	_ IndexDeleter = (*SyncMap)(nil)
 	_ LengthGetter = (*SyncMap)(nil)
 )
@@ -1292,10 +1533,23 @@ func (*SyncMap) TypeName() string {
 
 // String implements Object interface.
 func (o *SyncMap) String() string {
+	var sb strings.Builder
+	o.writeString(&sb, nil)
+	return sb.String()
+}
+
+func (o *SyncMap) writeString(sb *strings.Builder, path []uintptr) {
+	// check the cycle before locking, not to lock the mutex recursively
+	ptr := reflect.ValueOf(o).Pointer()
+	if inPath(path, ptr) {
+		sb.WriteString("{...}")
+		return
+	}
+
 	o.mu.RLock()
 	defer o.mu.RUnlock()
 
-	return o.Value.String()
+	writeMapString(sb, o.Value, append(path, ptr))
 }
 
 // Copy implements Copier interface.
@@ -1308,6 +1562,30 @@ func (o *SyncMap) Copy() Object {
 	}
 }
 
+// DeepCopy implements DeepCopier interface. Shared and cyclic references of
+// nested values are preserved in the copy.
+func (o *SyncMap) DeepCopy() Object {
+	return o.deepCopy(map[objectRef]Object{})
+}
+
+func (o *SyncMap) deepCopy(seen map[objectRef]Object) Object {
+	ref := pointerRef(o)
+	if cp, ok := seen[ref]; ok {
+		return cp
+	}
+
+	// register the copy before locking so that cyclic references do not lock
+	// the same mutex recursively
+	cp := &SyncMap{}
+	seen[ref] = cp
+
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+
+	cp.Value = o.Value.deepCopy(seen).(Map)
+	return cp
+}
+
 // IndexSet implements Object interface.
 func (o *SyncMap) IndexSet(index, value Object) error {
 	o.mu.Lock()
@@ -1400,11 +1678,16 @@ type Error struct {
 	Name    string
 	Message string
 	Cause   error
+	// Data holds optional structured data of the error. Keys of Data can be
+	// accessed with selectors if they do not collide with Name, Message, New
+	// and Data itself.
+	Data Map
 }
 
 var (
-	_ Object = (*Error)(nil)
-	_ Copier = (*Error)(nil)
+	_ Object     = (*Error)(nil)
+	_ Copier     = (*Error)(nil)
+	_ DeepCopier = (*Error)(nil)
 )
 
 func (o *Error) Unwrap() error {
@@ -1423,13 +1706,41 @@ func (o *Error) String() string {
 
 // Copy implements Copier interface.
 func (o *Error) Copy() Object {
+	var data Map
+	if o.Data != nil {
+		data = o.Data.Copy().(Map)
+	}
 	return &Error{
 		Name:    o.Name,
 		Message: o.Message,
 		Cause:   o.Cause,
+		Data:    data,
 	}
 }
 
+// DeepCopy implements DeepCopier interface.
+func (o *Error) DeepCopy() Object {
+	return o.deepCopy(map[objectRef]Object{})
+}
+
+func (o *Error) deepCopy(seen map[objectRef]Object) Object {
+	ref := pointerRef(o)
+	if cp, ok := seen[ref]; ok {
+		return cp
+	}
+
+	cp := &Error{
+		Name:    o.Name,
+		Message: o.Message,
+		Cause:   o.Cause,
+	}
+	seen[ref] = cp
+	if o.Data != nil {
+		cp.Data = o.Data.deepCopy(seen).(Map)
+	}
+	return cp
+}
+
 // Error implements error interface.
 func (o *Error) Error() string {
 	name := o.Name
@@ -1450,7 +1761,8 @@ func (o *Error) Equal(right Object) bool {
 // IsFalsy implements Object interface.
 func (o *Error) IsFalsy() bool { return true }
 
-// IndexGet implements Object interface.
+// IndexGet implements Object interface. Name, Message, New and Data have
+// precedence over the keys of Data, which are looked up last.
 func (o *Error) IndexGet(index Object) (Object, error) {
 	s := index.String()
 	if s == "Name" {
@@ -1461,6 +1773,13 @@ func (o *Error) IndexGet(index Object) (Object, error) {
 		return String(o.Message), nil
 	}
 
+	if s == "Data" {
+		if o.Data == nil {
+			return Map{}, nil
+		}
+		return o.Data, nil
+	}
+
 	if s == "New" {
 		return &Function{
 			Name: "New",
@@ -1480,6 +1799,10 @@ func (o *Error) IndexGet(index Object) (Object, error) {
 			},
 		}, nil
 	}
+
+	if v, ok := o.Data[s]; ok {
+		return v, nil
+	}
 	return Undefined, nil
 }
 
@@ -1523,8 +1846,9 @@ type RuntimeError struct {
 }
 
 var (
-	_ Object = (*RuntimeError)(nil)
-	_ Copier = (*RuntimeError)(nil)
+	_ Object     = (*RuntimeError)(nil)
+	_ Copier     = (*RuntimeError)(nil)
+	_ DeepCopier = (*RuntimeError)(nil)
 )
 
 func (o *RuntimeError) Unwrap() error {
@@ -1567,6 +1891,28 @@ func (o *RuntimeError) Copy() Object {
 	}
 }
 
+// DeepCopy implements DeepCopier interface.
+func (o *RuntimeError) DeepCopy() Object {
+	return o.deepCopy(map[objectRef]Object{})
+}
+
+func (o *RuntimeError) deepCopy(seen map[objectRef]Object) Object {
+	ref := pointerRef(o)
+	if cp, ok := seen[ref]; ok {
+		return cp
+	}
+
+	cp := &RuntimeError{
+		fileSet: o.fileSet,
+		Trace:   append([]parser.Pos{}, o.Trace...),
+	}
+	seen[ref] = cp
+	if o.Err != nil {
+		cp.Err = o.Err.deepCopy(seen).(*Error)
+	}
+	return cp
+}
+
 // Error implements error interface.
 func (o *RuntimeError) Error() string {
 	if o.Err == nil {
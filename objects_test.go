@@ -178,6 +178,27 @@ func TestObjectString(t *testing.T) {
 	require.Equal(t, m.String(), (&SyncMap{Value: m}).String())
 	require.Equal(t, "{}", (&SyncMap{Value: Map{}}).String())
 
+	// cyclic references
+	cm := Map{}
+	cm["self"] = cm
+	require.Equal(t, `{"self": {...}}`, cm.String())
+	ca := Array{Int(1), nil}
+	ca[1] = ca
+	require.Equal(t, `[1, [...]]`, ca.String())
+	cm = Map{"a": Array{nil}}
+	cm["a"].(Array)[0] = cm
+	require.Equal(t, `{"a": [{...}]}`, cm.String())
+	require.Equal(t, `[{"a": [...]}]`, cm["a"].String())
+	sm := &SyncMap{Value: Map{}}
+	sm.Value["m"] = Map{"s": sm}
+	require.Equal(t, `{"m": {"s": {...}}}`, sm.String())
+	require.Equal(t, `{"s": {"m": {...}}}`, sm.Value["m"].String())
+	// shared references are not cycles
+	shared := Array{Int(1)}
+	require.Equal(t, `[[1], [1]]`, Array{shared, shared}.String())
+	require.Equal(t, `{"x": {"y": [1]}}`,
+		Map{"x": Map{"y": shared}}.String())
+
 	require.Equal(t, "<function:>", (&Function{}).String())
 	require.Equal(t, "<function:xyz>", (&Function{Name: "xyz"}).String())
 	require.Equal(t, "<builtinFunction:>", (&BuiltinFunction{}).String())
@@ -230,6 +251,76 @@ func TestObjectIsFalsy(t *testing.T) {
 	require.False(t, (&CompiledFunction{}).IsFalsy())
 }
 
+func TestObjectInterning(t *testing.T) {
+	allocs := func(fn func() Object) float64 {
+		var sink Object
+		n := testing.AllocsPerRun(100, func() { sink = fn() })
+		_ = sink
+		return n
+	}
+	binop := func(tok token.Token, x, y Int) func() Object {
+		return func() Object {
+			v, err := x.BinaryOp(tok, y)
+			require.NoError(t, err)
+			return v
+		}
+	}
+
+	// interned small integers are shared, no allocation is required
+	require.Equal(t, 0.0, allocs(binop(token.Add, 500, 523)))
+	require.Equal(t, 0.0, allocs(binop(token.Sub, 0, 128)))
+	require.Equal(t, 0.0, allocs(binop(token.Mul, 300, 3)))
+	require.Equal(t, 1.0, allocs(binop(token.Add, 1023, 1)))
+	require.Equal(t, 1.0, allocs(binop(token.Sub, 0, 129)))
+
+	v, err := Int(1000).BinaryOp(token.Add, Int(23))
+	require.NoError(t, err)
+	require.Equal(t, Int(1023), v)
+	require.True(t, v.Equal(Int(1023)))
+	v, err = Int(-100).BinaryOp(token.Sub, Int(28))
+	require.NoError(t, err)
+	require.Equal(t, Int(-128), v)
+	v, err = Int(1<<40).BinaryOp(token.Add, Int(1))
+	require.NoError(t, err)
+	require.Equal(t, Int(1<<40+1), v)
+
+	it := Map{"a": True}.Iterate()
+	require.True(t, it.Next())
+	require.Equal(t, 0.0, allocs(it.Key))
+	require.Equal(t, String("a"), it.Key())
+
+	expectRun(t, `return 5 == 5`, nil, True)
+	expectRun(t, `a := 1000 + 23; b := 1023; return [a == b, a, b]`,
+		nil, Array{True, Int(1023), Int(1023)})
+	expectRun(t, `a := 2; b := a; a++; return [a, b]`,
+		nil, Array{Int(3), Int(2)})
+	expectRun(t, `return "abc"[1:2] == "b"`, nil, True)
+	expectRun(t, `
+	s := ""
+	for c in "abc" { s += "abc"[c-'a':c-'a'+1] }
+	return s`, nil, String("abc"))
+}
+
+func BenchmarkIntArithmetic(b *testing.B) {
+	bc, err := Compile([]byte(`
+	sum := 0
+	for i := 0; i < 1000; i++ {
+		sum += (i * 3 + 7) % 1000 - i / 2
+	}
+	return sum`), DefaultCompilerOptions)
+	if err != nil {
+		b.Fatal(err)
+	}
+	vm := NewVM(bc)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := vm.Run(nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
 func TestObjectCopier(t *testing.T) {
 	objects := []Object{
 		Array{},
@@ -242,6 +333,125 @@ func TestObjectCopier(t *testing.T) {
 			t.Fatalf("%T must implement Copier interface", o)
 		}
 	}
+
+	deepCopiers := []Object{
+		Array{},
+		Map{},
+		&SyncMap{},
+		&Error{},
+		&RuntimeError{},
+	}
+	for _, o := range deepCopiers {
+		if _, ok := o.(DeepCopier); !ok {
+			t.Fatalf("%T must implement DeepCopier interface", o)
+		}
+	}
+
+	inner := Array{Int(1)}
+	arr := Array{inner, Map{"a": inner}}
+	cp := arr.Copy().(Array)
+	cp[1].(Map)["b"] = True
+	inner[0] = Int(2)
+	require.Equal(t, Array{Array{Int(2)}, Map{"a": Array{Int(2)}, "b": True}},
+		cp)
+	require.Equal(t, Array{Array{Int(2)}, Map{"a": Array{Int(2)}, "b": True}},
+		arr)
+
+	inner = Array{Int(1)}
+	arr = Array{inner, Map{"a": inner}}
+	cp = arr.DeepCopy().(Array)
+	cp[1].(Map)["b"] = True
+	inner[0] = Int(2)
+	require.Equal(t, Array{Array{Int(1)}, Map{"a": Array{Int(1)}, "b": True}},
+		cp)
+	require.Equal(t, Array{Array{Int(2)}, Map{"a": Array{Int(2)}}}, arr)
+
+	sm := &SyncMap{Value: Map{"a": inner}}
+	smc := sm.Copy().(*SyncMap)
+	smd := sm.DeepCopy().(*SyncMap)
+	inner[0] = Int(3)
+	require.Equal(t, Array{Int(3)}, smc.Value["a"])
+	require.Equal(t, Array{Int(2)}, smd.Value["a"])
+
+	e := &Error{Name: "E", Data: Map{"a": inner}}
+	ec := e.Copy().(*Error)
+	ed := e.DeepCopy().(*Error)
+	inner[0] = Int(4)
+	require.Equal(t, Array{Int(4)}, ec.Data["a"])
+	require.Equal(t, Array{Int(3)}, ed.Data["a"])
+
+	// cyclic references through sync maps, errors and frozen values
+	sm = &SyncMap{Value: Map{}}
+	sm.Value["self"] = sm
+	smd = sm.DeepCopy().(*SyncMap)
+	require.NotSame(t, sm, smd)
+	require.Same(t, smd, smd.Value["self"])
+
+	e = &Error{Name: "E", Data: Map{}}
+	e.Data["self"] = e
+	ed = e.DeepCopy().(*Error)
+	require.NotSame(t, e, ed)
+	require.Same(t, ed, ed.Data["self"])
+
+	re := &RuntimeError{Err: &Error{Name: "E", Data: Map{}}}
+	re.Err.Data["rt"] = re
+	red := re.DeepCopy().(*RuntimeError)
+	require.NotSame(t, re, red)
+	require.Same(t, red, red.Err.Data["rt"])
+
+	fr := Freeze(Map{"a": Array{Int(1)}}).(*Frozen)
+	require.Same(t, fr, Array{fr}.DeepCopy().(Array)[0])
+	sm = &SyncMap{Value: Map{}}
+	fr = Freeze(Map{"s": sm}).(*Frozen)
+	sm.Value["f"] = fr
+	frd := Array{fr}.DeepCopy().(Array)[0].(*Frozen)
+	require.NotSame(t, fr, frd)
+	smd = frd.Value.(Map)["s"].(*SyncMap)
+	require.NotSame(t, sm, smd)
+	require.Same(t, frd, smd.Value["f"])
+
+	// custom objects are copied with DeepCopy if implemented, otherwise Copy
+	// is used.
+	custom := &copierObject{}
+	deep := &deepCopierObject{}
+	arr = Array{custom, deep}
+	cp = arr.Copy().(Array)
+	require.Same(t, custom, cp[0])
+	require.Same(t, deep, cp[1])
+	require.Equal(t, 0, custom.copied)
+	require.Equal(t, 0, deep.copied)
+	cp = Map{"a": arr}.DeepCopy().(Map)["a"].(Array)
+	require.NotSame(t, custom, cp[0])
+	require.NotSame(t, deep, cp[1])
+	require.Equal(t, 1, custom.copied)
+	require.Equal(t, 1, deep.copied)
+	require.Equal(t, 0, deep.shallow)
+}
+
+type copierObject struct {
+	ObjectImpl
+	copied int
+}
+
+func (o *copierObject) Copy() Object {
+	o.copied++
+	return &copierObject{}
+}
+
+type deepCopierObject struct {
+	ObjectImpl
+	copied  int
+	shallow int
+}
+
+func (o *deepCopierObject) Copy() Object {
+	o.shallow++
+	return o
+}
+
+func (o *deepCopierObject) DeepCopy() Object {
+	o.copied++
+	return &deepCopierObject{}
 }
 
 func TestObjectImpl(t *testing.T) {
@@ -53,102 +53,111 @@ const (
 	OpTrue
 	OpFalse
 	OpCallName
+	OpStrictCompare
+	OpIterInitSorted
+	OpCopyValue
 )
 
 // OpcodeNames are string representation of opcodes.
 var OpcodeNames = [...]string{
-	OpNoOp:         "NOOP",
-	OpConstant:     "CONSTANT",
-	OpCall:         "CALL",
-	OpGetGlobal:    "GETGLOBAL",
-	OpSetGlobal:    "SETGLOBAL",
-	OpGetLocal:     "GETLOCAL",
-	OpSetLocal:     "SETLOCAL",
-	OpGetBuiltin:   "GETBUILTIN",
-	OpBinaryOp:     "BINARYOP",
-	OpUnary:        "UNARY",
-	OpEqual:        "EQUAL",
-	OpNotEqual:     "NOTEQUAL",
-	OpJump:         "JUMP",
-	OpJumpFalsy:    "JUMPFALSY",
-	OpAndJump:      "ANDJUMP",
-	OpOrJump:       "ORJUMP",
-	OpMap:          "MAP",
-	OpArray:        "ARRAY",
-	OpSliceIndex:   "SLICEINDEX",
-	OpGetIndex:     "GETINDEX",
-	OpSetIndex:     "SETINDEX",
-	OpNull:         "NULL",
-	OpPop:          "POP",
-	OpGetFree:      "GETFREE",
-	OpSetFree:      "SETFREE",
-	OpGetLocalPtr:  "GETLOCALPTR",
-	OpGetFreePtr:   "GETFREEPTR",
-	OpClosure:      "CLOSURE",
-	OpIterInit:     "ITERINIT",
-	OpIterNext:     "ITERNEXT",
-	OpIterKey:      "ITERKEY",
-	OpIterValue:    "ITERVALUE",
-	OpLoadModule:   "LOADMODULE",
-	OpStoreModule:  "STOREMODULE",
-	OpReturn:       "RETURN",
-	OpSetupTry:     "SETUPTRY",
-	OpSetupCatch:   "SETUPCATCH",
-	OpSetupFinally: "SETUPFINALLY",
-	OpThrow:        "THROW",
-	OpFinalizer:    "FINALIZER",
-	OpDefineLocal:  "DEFINELOCAL",
-	OpTrue:         "TRUE",
-	OpFalse:        "FALSE",
-	OpCallName:     "CALLNAME",
+	OpNoOp:           "NOOP",
+	OpConstant:       "CONSTANT",
+	OpCall:           "CALL",
+	OpGetGlobal:      "GETGLOBAL",
+	OpSetGlobal:      "SETGLOBAL",
+	OpGetLocal:       "GETLOCAL",
+	OpSetLocal:       "SETLOCAL",
+	OpGetBuiltin:     "GETBUILTIN",
+	OpBinaryOp:       "BINARYOP",
+	OpUnary:          "UNARY",
+	OpEqual:          "EQUAL",
+	OpNotEqual:       "NOTEQUAL",
+	OpJump:           "JUMP",
+	OpJumpFalsy:      "JUMPFALSY",
+	OpAndJump:        "ANDJUMP",
+	OpOrJump:         "ORJUMP",
+	OpMap:            "MAP",
+	OpArray:          "ARRAY",
+	OpSliceIndex:     "SLICEINDEX",
+	OpGetIndex:       "GETINDEX",
+	OpSetIndex:       "SETINDEX",
+	OpNull:           "NULL",
+	OpPop:            "POP",
+	OpGetFree:        "GETFREE",
+	OpSetFree:        "SETFREE",
+	OpGetLocalPtr:    "GETLOCALPTR",
+	OpGetFreePtr:     "GETFREEPTR",
+	OpClosure:        "CLOSURE",
+	OpIterInit:       "ITERINIT",
+	OpIterNext:       "ITERNEXT",
+	OpIterKey:        "ITERKEY",
+	OpIterValue:      "ITERVALUE",
+	OpLoadModule:     "LOADMODULE",
+	OpStoreModule:    "STOREMODULE",
+	OpReturn:         "RETURN",
+	OpSetupTry:       "SETUPTRY",
+	OpSetupCatch:     "SETUPCATCH",
+	OpSetupFinally:   "SETUPFINALLY",
+	OpThrow:          "THROW",
+	OpFinalizer:      "FINALIZER",
+	OpDefineLocal:    "DEFINELOCAL",
+	OpTrue:           "TRUE",
+	OpFalse:          "FALSE",
+	OpCallName:       "CALLNAME",
+	OpStrictCompare:  "STRICTCOMPARE",
+	OpIterInitSorted: "ITERINITSORTED",
+	OpCopyValue:      "COPYVALUE",
 }
 
 // OpcodeOperands is the number of operands.
 var OpcodeOperands = [...][]int{
-	OpNoOp:         {},
-	OpConstant:     {2},    // constant index
-	OpCall:         {1, 1}, // number of arguments, flags
-	OpGetGlobal:    {2},    // constant index
-	OpSetGlobal:    {2},    // constant index
-	OpGetLocal:     {1},    // local variable index
-	OpSetLocal:     {1},    // local variable index
-	OpGetBuiltin:   {1},    // builtin index
-	OpBinaryOp:     {1},    // operator
-	OpUnary:        {1},    // operator
-	OpEqual:        {},
-	OpNotEqual:     {},
-	OpJump:         {2}, // position
-	OpJumpFalsy:    {2}, // position
-	OpAndJump:      {2}, // position
-	OpOrJump:       {2}, // position
-	OpMap:          {2}, // number of keys and values
-	OpArray:        {2}, // number of items
-	OpSliceIndex:   {},
-	OpGetIndex:     {1}, // number of selectors
-	OpSetIndex:     {},
-	OpNull:         {},
-	OpPop:          {},
-	OpGetFree:      {1},    // index
-	OpSetFree:      {1},    // index
-	OpGetLocalPtr:  {1},    // index
-	OpGetFreePtr:   {1},    // index
-	OpClosure:      {2, 1}, // constant index, item count
-	OpIterInit:     {},
-	OpIterNext:     {},
-	OpIterKey:      {},
-	OpIterValue:    {},
-	OpLoadModule:   {2, 2}, // constant index, module index
-	OpStoreModule:  {2},    // module index
-	OpReturn:       {1},    // number of items (0 or 1)
-	OpSetupTry:     {2, 2},
-	OpSetupCatch:   {},
-	OpSetupFinally: {},
-	OpThrow:        {1}, // 0:re-throw (system), 1:throw <expression>
-	OpFinalizer:    {1}, // up to error handler index
-	OpDefineLocal:  {1},
-	OpTrue:         {},
-	OpFalse:        {},
-	OpCallName:     {1, 1}, // number of arguments, flags
+	OpNoOp:           {},
+	OpConstant:       {2},    // constant index
+	OpCall:           {1, 1}, // number of arguments, flags
+	OpGetGlobal:      {2},    // constant index
+	OpSetGlobal:      {2},    // constant index
+	OpGetLocal:       {1},    // local variable index
+	OpSetLocal:       {1},    // local variable index
+	OpGetBuiltin:     {1},    // builtin index
+	OpBinaryOp:       {1},    // operator
+	OpUnary:          {1},    // operator
+	OpEqual:          {},
+	OpNotEqual:       {},
+	OpJump:           {2}, // position
+	OpJumpFalsy:      {2}, // position
+	OpAndJump:        {2}, // position
+	OpOrJump:         {2}, // position
+	OpMap:            {2}, // number of keys and values
+	OpArray:          {2}, // number of items
+	OpSliceIndex:     {},
+	OpGetIndex:       {1}, // number of selectors
+	OpSetIndex:       {},
+	OpNull:           {},
+	OpPop:            {},
+	OpGetFree:        {1},    // index
+	OpSetFree:        {1},    // index
+	OpGetLocalPtr:    {1},    // index
+	OpGetFreePtr:     {1},    // index
+	OpClosure:        {2, 1}, // constant index, item count
+	OpIterInit:       {},
+	OpIterNext:       {},
+	OpIterKey:        {},
+	OpIterValue:      {},
+	OpLoadModule:     {2, 2}, // constant index, module index
+	OpStoreModule:    {2},    // module index
+	OpReturn:         {1},    // number of items (0 or 1)
+	OpSetupTry:       {2, 2},
+	OpSetupCatch:     {},
+	OpSetupFinally:   {},
+	OpThrow:          {1}, // 0:re-throw (system), 1:throw <expression>
+	OpFinalizer:      {1}, // up to error handler index
+	OpDefineLocal:    {1},
+	OpTrue:           {},
+	OpFalse:          {},
+	OpCallName:       {1, 1}, // number of arguments, flags
+	OpStrictCompare:  {1},    // operator
+	OpIterInitSorted: {},
+	OpCopyValue:      {},
 }
 
 // ReadOperands reads operands from the bytecode. Given operands slice is used to
@@ -167,3 +176,40 @@ func ReadOperands(numOperands []int, ins []byte, operands []int) ([]int, int) {
 	}
 	return operands, offset
 }
+
+// decodedInst holds the operands of an instruction to prevent VM decoding
+// variable width operands in each cycle.
+type decodedInst struct {
+	a, b uint16
+}
+
+// decodeInstructions returns decoded operands of all instructions of insts.
+// Operands of an instruction are stored at the index of its opcode so that
+// VM can use instruction pointer to access them, other elements are zero.
+func decodeInstructions(insts []byte) []decodedInst {
+	ops := make([]decodedInst, len(insts))
+	var operands [2]int
+	for i := 0; i < len(insts); i++ {
+		op := insts[i]
+		if int(op) >= len(OpcodeOperands) {
+			continue
+		}
+		numOperands := OpcodeOperands[op]
+		width := 0
+		for _, w := range numOperands {
+			width += w
+		}
+		if i+width >= len(insts) {
+			break
+		}
+		read, offset := ReadOperands(numOperands, insts[i+1:], operands[:0])
+		if len(read) > 0 {
+			ops[i].a = uint16(read[0])
+		}
+		if len(read) > 1 {
+			ops[i].b = uint16(read[1])
+		}
+		i += offset
+	}
+	return ops
+}
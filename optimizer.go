@@ -64,7 +64,10 @@ type SimpleOptimizer struct {
 	indent           int
 	optimConsts      bool
 	optimExpr        bool
+	strictCompare    bool
 	disabledBuiltins []string
+	pureBuiltins     map[string]Object
+	modulePath       string
 	constants        []Object
 	instructions     []byte
 	moduleStore      *moduleStore
@@ -83,9 +86,29 @@ func NewOptimizer(
 	opts CompilerOptions,
 ) *SimpleOptimizer {
 	var disabled []string
+	var extras []map[string]Object
 	if base != nil {
 		disabled = base.DisabledBuiltins()
 		disabled = append(disabled, base.ShadowedBuiltins()...)
+		extras = append(extras, base.ExtraBuiltins())
+	}
+	disabled = append(disabled, opts.DisabledBuiltins...)
+	extras = append(extras, opts.ExtraBuiltins)
+
+	// calls of pure extra builtins can be evaluated, other extra builtins
+	// and builtins overridden by them must not be evaluated
+	var pure map[string]Object
+	for _, m := range extras {
+		for name, obj := range m {
+			if f, ok := obj.(*Function); ok && f.Pure {
+				if pure == nil {
+					pure = make(map[string]Object)
+				}
+				pure[name] = obj
+				continue
+			}
+			disabled = append(disabled, name)
+		}
 	}
 
 	var trace io.Writer
@@ -99,7 +122,10 @@ func NewOptimizer(
 		maxCycle:         opts.OptimizerMaxCycle,
 		optimConsts:      opts.OptimizeConst,
 		optimExpr:        opts.OptimizeExpr,
+		strictCompare:    opts.StrictComparison,
 		disabledBuiltins: disabled,
+		pureBuiltins:     pure,
+		modulePath:       opts.ModulePath,
 		moduleStore:      newModuleStore(),
 		trace:            trace,
 	}
@@ -134,7 +160,7 @@ func canOptimizeInsts(constants []Object, insts []byte) bool {
 		OpNoOp: true, OpAndJump: true, OpOrJump: true, OpArray: true,
 		OpReturn: true, OpEqual: true, OpNotEqual: true, OpPop: true,
 		OpGetBuiltin: true, OpCall: true, OpSetLocal: true, OpDefineLocal: true,
-		OpTrue: true, OpFalse: true,
+		OpTrue: true, OpFalse: true, OpStrictCompare: true,
 		^byte(0): false,
 	}
 
@@ -161,7 +187,8 @@ func canOptimizeInsts(constants []Object, insts []byte) bool {
 			}
 
 			if opcode == OpConstant &&
-				!isObjectConstant(constants[operands[0]]) {
+				!isObjectConstant(constants[operands[0]]) &&
+				!isPureFunction(constants[operands[0]]) {
 				canOptimize = false
 				return false
 			}
@@ -216,16 +243,19 @@ func (so *SimpleOptimizer) evalExpr(expr parser.Expr) (parser.Expr, bool) {
 func (so *SimpleOptimizer) slowEvalExpr(expr parser.Expr) (parser.Expr, bool) {
 	st := NewSymbolTable().
 		EnableParams(false).
+		SetExtraBuiltins(so.pureBuiltins).
 		DisableBuiltin(so.disabledBuiltins...).
 		DisableBuiltin(so.scope.shadowedBuiltins()...)
 
 	compiler := NewCompiler(
 		so.file.InputFile,
 		CompilerOptions{
-			SymbolTable: st,
-			moduleStore: so.moduleStore.reset(),
-			Constants:   so.constants[:0],
-			Trace:       so.trace,
+			SymbolTable:      st,
+			ModulePath:       so.modulePath,
+			StrictComparison: so.strictCompare,
+			moduleStore:      so.moduleStore.reset(),
+			Constants:        so.constants[:0],
+			Trace:            so.trace,
 		},
 	)
 	compiler.instructions = so.instructions[:0]
@@ -405,6 +435,9 @@ func (so *SimpleOptimizer) binaryopInts(
 		}
 		val = left.Value / right.Value
 	case token.Rem:
+		if right.Value == 0 {
+			return nil, false
+		}
 		val = left.Value % right.Value
 	case token.And:
 		val = left.Value & right.Value
@@ -461,6 +494,10 @@ func (so *SimpleOptimizer) binaryop(
 		return nil, false
 	}
 
+	if op == token.LAnd || op == token.LOr {
+		return logicalop(op, left, right)
+	}
+
 	switch left := left.(type) {
 	case *parser.IntLit:
 		if right, ok := right.(*parser.IntLit); ok {
@@ -470,20 +507,75 @@ func (so *SimpleOptimizer) binaryop(
 		if right, ok := right.(*parser.FloatLit); ok {
 			return so.binaryopFloats(op, left, right)
 		}
-	case *parser.StringLit:
-		right, ok := right.(*parser.StringLit)
-		if ok && op == token.Add {
-			v := left.Value + right.Value
-			return &parser.StringLit{
-				Value:    v,
-				Literal:  strconv.Quote(v),
-				ValuePos: left.ValuePos,
-			}, true
+	case *parser.StringLit, *parser.CharLit:
+		if op == token.Add {
+			return concatLits(left, right)
 		}
 	}
 	return nil, false
 }
 
+// logicalop folds logical expression if left operand is a literal. Result is
+// the left operand if right operand is not evaluated at runtime, otherwise it
+// is the right operand which is kept as is to preserve its side effects.
+func logicalop(op token.Token, left, right parser.Expr) (parser.Expr, bool) {
+	falsy, ok := isLitFalsy(left)
+	if !ok {
+		return nil, false
+	}
+	if falsy == (op == token.LAnd) {
+		return left, true
+	}
+	return right, true
+}
+
+// concatLits folds addition of a string or char literal and another literal to
+// a string literal if the result of the addition is a string at runtime.
+func concatLits(left, right parser.Expr) (parser.Expr, bool) {
+	lhs, ok := litObject(left)
+	if !ok {
+		return nil, false
+	}
+	rhs, ok := litObject(right)
+	if !ok {
+		return nil, false
+	}
+	obj, err := lhs.BinaryOp(token.Add, rhs)
+	if err != nil {
+		return nil, false
+	}
+	v, ok := obj.(String)
+	if !ok {
+		return nil, false
+	}
+	return &parser.StringLit{
+		Value:    string(v),
+		Literal:  strconv.Quote(string(v)),
+		ValuePos: left.Pos(),
+	}, true
+}
+
+// litObject returns the Object value of given literal expression.
+func litObject(expr parser.Expr) (Object, bool) {
+	switch expr := expr.(type) {
+	case *parser.StringLit:
+		return String(expr.Value), true
+	case *parser.CharLit:
+		return Char(expr.Value), true
+	case *parser.IntLit:
+		return Int(expr.Value), true
+	case *parser.UintLit:
+		return Uint(expr.Value), true
+	case *parser.FloatLit:
+		return Float(expr.Value), true
+	case *parser.BoolLit:
+		return Bool(expr.Value), true
+	case *parser.UndefinedLit:
+		return Undefined, true
+	}
+	return nil, false
+}
+
 func (so *SimpleOptimizer) unaryop(
 	op token.Token,
 	expr parser.Expr,
@@ -671,6 +763,25 @@ func (so *SimpleOptimizer) optimize(node parser.Node) (parser.Expr, bool) {
 				node.Expr = expr
 			}
 		}
+	case *parser.CleanupStmt:
+		if expr, ok = so.optimize(node.Expr); ok {
+			node.Expr = expr
+		}
+		if expr, ok = so.evalExpr(node.Expr); ok {
+			node.Expr = expr
+		}
+	case *parser.MatchStmt:
+		if expr, ok = so.optimize(node.Subject); ok {
+			node.Subject = expr
+		}
+		if node.Ident != nil {
+			so.define(node.Ident.Name)
+		}
+		for _, cl := range node.Cases {
+			for _, stmt := range cl.Body {
+				_, _ = so.optimize(stmt)
+			}
+		}
 	case *parser.ForStmt:
 		if node.Init != nil {
 			_, _ = so.optimize(node.Init)
@@ -686,18 +797,39 @@ func (so *SimpleOptimizer) optimize(node parser.Node) (parser.Expr, bool) {
 		if node.Body != nil {
 			_, _ = so.optimize(node.Body)
 		}
+		if node.Else != nil {
+			_, _ = so.optimize(node.Else)
+		}
+	case *parser.DoWhileStmt:
+		if node.Body != nil {
+			_, _ = so.optimize(node.Body)
+		}
+		if expr, ok = so.optimize(node.Cond); ok {
+			node.Cond = expr
+		}
 	case *parser.ForInStmt:
 		if node.Body != nil {
 			_, _ = so.optimize(node.Body)
 		}
+		if node.Else != nil {
+			_, _ = so.optimize(node.Else)
+		}
 	case *parser.BlockStmt:
 		for _, stmt := range node.Stmts {
 			_, _ = so.optimize(stmt)
 		}
+	case *parser.LabeledStmt:
+		if node.Body != nil {
+			_, _ = so.optimize(node.Body)
+		}
+	case *parser.MultiStmt:
+		for _, stmt := range node.Stmts {
+			_, _ = so.optimize(stmt)
+		}
 	case *parser.AssignStmt:
 		for _, lhs := range node.LHS {
 			if ident, ok := lhs.(*parser.Ident); ok {
-				so.scope.define(ident.Name)
+				so.define(ident.Name)
 			}
 		}
 		for i, rhs := range node.RHS {
@@ -716,13 +848,13 @@ func (so *SimpleOptimizer) optimize(node parser.Node) (parser.Expr, bool) {
 		case token.Param, token.Global:
 			for _, sp := range decl.Specs {
 				spec := sp.(*parser.ParamSpec)
-				so.scope.define(spec.Ident.Name)
+				so.define(spec.Ident.Name)
 			}
 		case token.Var, token.Const:
 			for _, sp := range decl.Specs {
 				spec := sp.(*parser.ValueSpec)
 				for i := range spec.Idents {
-					so.scope.define(spec.Idents[i].Name)
+					so.define(spec.Idents[i].Name)
 					if i < len(spec.Values) && spec.Values[i] != nil {
 						v := spec.Values[i]
 						if expr, ok = so.optimize(v); ok {
@@ -782,7 +914,7 @@ func (so *SimpleOptimizer) optimize(node parser.Node) (parser.Expr, bool) {
 		so.enterScope()
 		defer so.leaveScope()
 		for _, ident := range node.Type.Params.List {
-			so.scope.define(ident.Name)
+			so.define(ident.Name)
 		}
 		if node.Body != nil {
 			_, _ = so.optimize(node.Body)
@@ -822,6 +954,21 @@ func (so *SimpleOptimizer) optimize(node parser.Node) (parser.Expr, bool) {
 				Literal:  strconv.FormatBool(!falsy),
 				ValuePos: node.Cond.Pos(),
 			}
+			if so.optimConsts {
+				// replace conditional expression with the selected one
+				expr = node.True
+				if falsy {
+					expr = node.False
+				}
+				if x, ok := so.optimize(expr); ok {
+					expr = x
+				}
+				if x, ok := so.evalExpr(expr); ok {
+					expr = x
+				}
+				so.count++
+				return expr, true
+			}
 		}
 
 		if expr, ok = so.optimize(node.True); ok {
@@ -840,6 +987,15 @@ func (so *SimpleOptimizer) optimize(node parser.Node) (parser.Expr, bool) {
 	return nil, false
 }
 
+// define records the builtins shadowed by ident in current scope.
+func (so *SimpleOptimizer) define(ident string) {
+	if _, ok := so.pureBuiltins[ident]; ok {
+		so.scope.shadowed = append(so.scope.shadowed, ident)
+		return
+	}
+	so.scope.define(ident)
+}
+
 func (so *SimpleOptimizer) enterScope() {
 	so.scope = &optimizerScope{parent: so.scope}
 }
@@ -898,6 +1054,11 @@ func isObjectConstant(obj Object) bool {
 	return false
 }
 
+func isPureFunction(obj Object) bool {
+	f, ok := obj.(*Function)
+	return ok && f.Pure
+}
+
 func isLitFalsy(expr parser.Expr) (bool, bool) {
 	if expr == nil {
 		return false, false
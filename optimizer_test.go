@@ -263,6 +263,73 @@ func TestOptimizer(t *testing.T) {
 	}
 }
 
+func TestOptimizerConcat(t *testing.T) {
+	opts := DefaultCompilerOptions
+	opts.OptimizeConst = true
+	opts.OptimizeExpr = false
+
+	single := compFunc(concatInsts(
+		makeInst(OpConstant, 0),
+		makeInst(OpReturn, 1),
+	))
+	testCases := []struct {
+		s string
+		c Object
+	}{
+		{s: `"a" + "b" + "c"`, c: String("abc")},
+		{s: `"a" + ("b" + "c")`, c: String("abc")},
+		{s: `"a" + 'b' + "c"`, c: String("abc")},
+		{s: `'a' + "b" + 'c'`, c: String("abc")},
+		{s: `"a" + 1 + "c"`, c: String("a1c")},
+		{s: `"a" + 1u + 2`, c: String("a12")},
+		{s: `"a" + 1.5 + 1e21`, c: String("a1.51e+21")},
+		{s: `"a" + true + undefined`, c: String("atrueundefined")},
+		{s: `"" + 'ç' + "九"`, c: String("ç九")},
+		{s: `"a" + (1 + 2)`, c: String("a3")},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.s, func(t *testing.T) {
+			expectCompileWithOpts(t, "return "+tC.s, opts,
+				bytecode(Array{tC.c}, single))
+		})
+	}
+
+	// chars and numbers are not concatenated
+	expectCompileWithOpts(t, `return 'a' + 1 + "b"`, opts,
+		bytecode(
+			Array{Char('a'), Int(1), String("b")},
+			compFunc(concatInsts(
+				makeInst(OpConstant, 0),
+				makeInst(OpConstant, 1),
+				makeInst(OpBinaryOp, int(token.Add)),
+				makeInst(OpConstant, 2),
+				makeInst(OpBinaryOp, int(token.Add)),
+				makeInst(OpReturn, 1),
+			)),
+		))
+	expectCompileWithOpts(t, `return 1 + "a"`, opts,
+		bytecode(
+			Array{Int(1), String("a")},
+			compFunc(concatInsts(
+				makeInst(OpConstant, 0),
+				makeInst(OpConstant, 1),
+				makeInst(OpBinaryOp, int(token.Add)),
+				makeInst(OpReturn, 1),
+			)),
+		))
+
+	// folded constants are identical to runtime results
+	for _, s := range []string{
+		`"a" + 'b' + 1 + 2u + 3.25 + false + undefined + "c"`,
+		`'x' + "y" + '\u00e7'`,
+	} {
+		bc, err := Compile([]byte("return "+s), opts)
+		require.NoError(t, err)
+		require.Equal(t, 1, len(bc.Constants))
+		expectRun(t, "return "+s, nil, bc.Constants[0])
+	}
+}
+
 func TestOptimizerIf(t *testing.T) {
 	expectEval(t, `if 1+2 {}`,
 		bytecode(
@@ -549,6 +616,100 @@ func TestOptimizerCondExpr(t *testing.T) {
 	}
 }
 
+func TestOptimizerLogical(t *testing.T) {
+	opts := DefaultCompilerOptions
+	opts.OptimizeExpr = false
+	st := NewSymbolTable()
+	require.NoError(t, st.SetParams("f"))
+	opts.SymbolTable = st
+
+	constF := compFunc(concatInsts(
+		makeInst(OpConstant, 0),
+		makeInst(OpReturn, 1),
+	),
+		withParams(1),
+		withLocals(1),
+	)
+	// call of f must be kept
+	callF := compFunc(concatInsts(
+		makeInst(OpGetLocal, 0),
+		makeInst(OpCall, 0, 0),
+		makeInst(OpReturn, 1),
+	),
+		withParams(1),
+		withLocals(1),
+	)
+	testCases := []struct {
+		s  string
+		c  Array
+		cf *CompiledFunction
+	}{
+		{s: `true && f()`, cf: callF},
+		{s: `1 && f()`, cf: callF},
+		{s: `"a" && (f())`, cf: callF},
+		{s: `false || f()`, cf: callF},
+		{s: `0 || f()`, cf: callF},
+		{s: `undefined || f()`, cf: callF},
+		{s: `true && true && f()`, cf: callF},
+		{s: `false || 0 || f()`, cf: callF},
+		{s: `true && (false || f())`, cf: callF},
+		{s: `(true ? true : false) && f()`, cf: callF},
+		{s: `false ? 1 : f()`, cf: callF},
+		{s: `"" ? 1 : f()`, cf: callF},
+		{s: `0 && f()`, c: Array{Int(0)}, cf: constF},
+		{s: `"" && f()`, c: Array{String("")}, cf: constF},
+		{s: `1u || f()`, c: Array{Uint(1)}, cf: constF},
+		{s: `"a" || f()`, c: Array{String("a")}, cf: constF},
+		{s: `1 && 0 && f()`, c: Array{Int(0)}, cf: constF},
+		{s: `0 || 2 || f()`, c: Array{Int(2)}, cf: constF},
+		{s: `1 ? 2 : f()`, c: Array{Int(2)}, cf: constF},
+		{s: `(1 ? 2 : f()) + 3`, c: Array{Int(5)}, cf: constF},
+		{s: `"a" + (0 ? f() : "b")`, c: Array{String("ab")}, cf: constF},
+	}
+	for _, tC := range testCases {
+		t.Run(tC.s, func(t *testing.T) {
+			expectCompileWithOpts(t, "return "+tC.s, opts,
+				bytecode(tC.c, tC.cf))
+		})
+	}
+
+	// constant right operand is not folded, left operand is evaluated
+	expectCompileWithOpts(t, `return f() && false`, opts,
+		bytecode(
+			Array{},
+			compFunc(concatInsts(
+				makeInst(OpGetLocal, 0),
+				makeInst(OpCall, 0, 0),
+				makeInst(OpAndJump, 9),
+				makeInst(OpFalse),
+				makeInst(OpReturn, 1),
+			),
+				withParams(1),
+				withLocals(1),
+			),
+		))
+
+	// results are same with and without optimization
+	for _, s := range []string{
+		`0 && f()`, `1 && f()`, `"" || f()`, `[] || f()`,
+		`1 && 0 || f()`, `(0 || "x") && f()`,
+	} {
+		expectRun(t, `
+		n := 0
+		f := func() { n++; return n }
+		return [`+s+`, n]`, nil, func() Object {
+			bc, err := Compile([]byte(`
+			n := 0
+			f := func() { n++; return n }
+			return [`+s+`, n]`), CompilerOptions{})
+			require.NoError(t, err)
+			ret, err := NewVM(bc).Run(nil)
+			require.NoError(t, err)
+			return ret
+		}())
+	}
+}
+
 func TestOptimizerShadowing(t *testing.T) {
 	// int is shadowed by a param declaration, should not evalute int("1") to 1
 	expectEval(t, `param int; return int("1")`,
@@ -706,6 +867,8 @@ func TestOptimizerError(t *testing.T) {
 	expectEvalError(t, `
 	try { 1 / 0 } catch err { } finally { }
 	`, "Optimizer Error: ZeroDivisionError: \n\tat")
+	expectEvalError(t, `1 % 0`, "Optimizer Error: ZeroDivisionError: \n\tat")
+	expectEvalError(t, `1u % 0u`, "Optimizer Error: ZeroDivisionError: \n\tat")
 
 	// two errors found by optimizer is reported as multipleErr but
 	// Error() method returns first error's message.
@@ -0,0 +1,323 @@
+// Copyright (c) 2020-2023 Ozan Hacıbekiroğlu.
+// Use of this source code is governed by a MIT License
+// that can be found in the LICENSE file.
+
+package ugo
+
+import (
+	"encoding/binary"
+	"math"
+	"strconv"
+)
+
+// maxPackSize is the maximum number of bytes of a pack format, which limits
+// the memory allocated for formats given by scripts.
+const maxPackSize = 1 << 30
+
+// packField represents a single field of a pack format.
+type packField struct {
+	code  byte
+	count int // repeat count, or length of 's' fields
+}
+
+// packFormat represents a parsed pack format like ">I H 4s".
+type packFormat struct {
+	order     binary.ByteOrder
+	fields    []packField
+	size      int // number of bytes required for the format
+	numValues int // number of values packed or unpacked by the format
+}
+
+func packCodeSize(code byte) int {
+	switch code {
+	case 'x', 'b', 'B', '?', 's':
+		return 1
+	case 'h', 'H':
+		return 2
+	case 'i', 'I', 'f':
+		return 4
+	case 'q', 'Q', 'd':
+		return 8
+	}
+	return 0
+}
+
+// parsePackFormat parses the format of pack and unpack builtins. Format can
+// start with a byte order character, '<' for little-endian, '>' or '!' for
+// big-endian, default is big-endian. Each field code can be preceded by a
+// decimal repeat count, which is the length of the bytes for 's' code.
+// Whitespace between fields is ignored. Formats requiring more than
+// maxPackSize bytes are rejected.
+func parsePackFormat(format string) (*packFormat, error) {
+	pf := &packFormat{order: binary.BigEndian}
+	if format != "" {
+		switch format[0] {
+		case '<':
+			pf.order = binary.LittleEndian
+			format = format[1:]
+		case '>', '!':
+			format = format[1:]
+		}
+	}
+
+	for i := 0; i < len(format); i++ {
+		c := format[i]
+		if c == ' ' || c == '\t' || c == '\n' || c == '\r' {
+			continue
+		}
+		count := -1
+		if c >= '0' && c <= '9' {
+			j := i
+			for j < len(format) && format[j] >= '0' && format[j] <= '9' {
+				j++
+			}
+			n, err := strconv.Atoi(format[i:j])
+			if err == nil && n > maxPackSize {
+				return nil, ErrType.NewError("pack format count is too large: " +
+					format[i:j])
+			}
+			if err != nil || j == len(format) {
+				return nil, ErrType.NewError("invalid pack format: " +
+					strconv.Quote(format))
+			}
+			count = n
+			i = j
+			c = format[i]
+		}
+		if packCodeSize(c) == 0 {
+			return nil, ErrType.NewError("invalid pack format character: " +
+				strconv.QuoteRune(rune(c)))
+		}
+		if count < 0 {
+			count = 1
+		}
+		// counts are not greater than maxPackSize, so sizes cannot overflow
+		switch c {
+		case 's':
+			pf.size += count
+			pf.numValues++
+		case 'x':
+			pf.size += count
+		default:
+			pf.size += count * packCodeSize(c)
+			pf.numValues += count
+		}
+		if pf.size > maxPackSize {
+			return nil, ErrType.NewError("pack format size is too large: " +
+				strconv.Quote(format))
+		}
+		pf.fields = append(pf.fields, packField{code: c, count: count})
+	}
+	return pf, nil
+}
+
+func builtinPackFunc(c Call) (Object, error) {
+	if c.Len() < 1 {
+		return Undefined, ErrWrongNumArguments.NewError(
+			"want>=1 got=" + strconv.Itoa(c.Len()))
+	}
+	format, ok := c.Get(0).(String)
+	if !ok {
+		return Undefined, NewArgumentTypeError(
+			"1st", "string", c.Get(0).TypeName())
+	}
+	pf, err := parsePackFormat(string(format))
+	if err != nil {
+		return Undefined, err
+	}
+	if n := c.Len() - 1; n != pf.numValues {
+		return &Error{
+			Message: "pack requires " + strconv.Itoa(pf.numValues) +
+				" values, got " + strconv.Itoa(n),
+		}, nil
+	}
+
+	out := make([]byte, pf.size)
+	buf := out
+	argIdx := 1
+	for _, f := range pf.fields {
+		switch f.code {
+		case 'x':
+			buf = buf[f.count:]
+			continue
+		case 's':
+			arg := c.Get(argIdx)
+			var b []byte
+			switch v := arg.(type) {
+			case Bytes:
+				b = v
+			case String:
+				b = []byte(v)
+			default:
+				return Undefined, NewArgumentTypeError(
+					ordinal(argIdx+1), "bytes|string", arg.TypeName())
+			}
+			argIdx++
+			// shorter values are padded with zero bytes
+			copy(buf[:f.count], b)
+			buf = buf[f.count:]
+			continue
+		}
+
+		for r := 0; r < f.count; r++ {
+			var err error
+			if buf, err = packValue(pf.order, f.code, buf, c.Get(argIdx),
+				ordinal(argIdx+1)); err != nil {
+				return Undefined, err
+			}
+			argIdx++
+		}
+	}
+	return Bytes(out), nil
+}
+
+// packValue puts arg of a numeric or bool field into buf and returns the rest
+// of buf.
+func packValue(
+	order binary.ByteOrder,
+	code byte,
+	buf []byte,
+	arg Object,
+	pos string,
+) ([]byte, error) {
+	switch code {
+	case '?':
+		if !arg.IsFalsy() {
+			buf[0] = 1
+		}
+		return buf[1:], nil
+	case 'f', 'd':
+		v, ok := packFloatArg(arg)
+		if !ok {
+			return nil, NewArgumentTypeError(
+				pos, "float|int|uint", arg.TypeName())
+		}
+		if code == 'f' {
+			order.PutUint32(buf, math.Float32bits(float32(v)))
+			return buf[4:], nil
+		}
+		order.PutUint64(buf, math.Float64bits(v))
+		return buf[8:], nil
+	}
+
+	v, ok := packIntArg(arg)
+	if !ok {
+		return nil, NewArgumentTypeError(
+			pos, "int|uint|char|bool", arg.TypeName())
+	}
+	// values are truncated to the size of the field
+	switch packCodeSize(code) {
+	case 1:
+		buf[0] = byte(v)
+	case 2:
+		order.PutUint16(buf, uint16(v))
+	case 4:
+		order.PutUint32(buf, uint32(v))
+	case 8:
+		order.PutUint64(buf, v)
+	}
+	return buf[packCodeSize(code):], nil
+}
+
+func packIntArg(o Object) (uint64, bool) {
+	switch v := o.(type) {
+	case Int:
+		return uint64(v), true
+	case Uint:
+		return uint64(v), true
+	case Char:
+		return uint64(v), true
+	case Bool:
+		if v {
+			return 1, true
+		}
+		return 0, true
+	}
+	return 0, false
+}
+
+func packFloatArg(o Object) (float64, bool) {
+	switch v := o.(type) {
+	case Float:
+		return float64(v), true
+	case Int:
+		return float64(v), true
+	case Uint:
+		return float64(v), true
+	}
+	return 0, false
+}
+
+func builtinUnpackFunc(c Call) (Object, error) {
+	if err := c.CheckLen(2); err != nil {
+		return Undefined, err
+	}
+	format, ok := c.Get(0).(String)
+	if !ok {
+		return Undefined, NewArgumentTypeError(
+			"1st", "string", c.Get(0).TypeName())
+	}
+	buf, ok := c.Get(1).(Bytes)
+	if !ok {
+		return Undefined, NewArgumentTypeError(
+			"2nd", "bytes", c.Get(1).TypeName())
+	}
+	pf, err := parsePackFormat(string(format))
+	if err != nil {
+		return Undefined, err
+	}
+	if size := pf.size; size != len(buf) {
+		return &Error{
+			Message: "unpack requires " + strconv.Itoa(size) +
+				" bytes, got " + strconv.Itoa(len(buf)),
+		}, nil
+	}
+
+	out := make(Array, 0, pf.numValues)
+	for _, f := range pf.fields {
+		switch f.code {
+		case 'x':
+			buf = buf[f.count:]
+			continue
+		case 's':
+			out = append(out, Bytes(buf[:f.count]).Copy())
+			buf = buf[f.count:]
+			continue
+		}
+		for r := 0; r < f.count; r++ {
+			out = append(out, unpackValue(pf.order, f.code, buf))
+			buf = buf[packCodeSize(f.code):]
+		}
+	}
+	return out, nil
+}
+
+// unpackValue returns the value of a numeric or bool field at start of buf.
+func unpackValue(order binary.ByteOrder, code byte, buf []byte) Object {
+	var v Object
+	switch code {
+	case '?':
+		v = Bool(buf[0] != 0)
+	case 'b':
+		v = Int(int8(buf[0]))
+	case 'B':
+		v = Uint(buf[0])
+	case 'h':
+		v = Int(int16(order.Uint16(buf)))
+	case 'H':
+		v = Uint(order.Uint16(buf))
+	case 'i':
+		v = Int(int32(order.Uint32(buf)))
+	case 'I':
+		v = Uint(order.Uint32(buf))
+	case 'q':
+		v = Int(int64(order.Uint64(buf)))
+	case 'Q':
+		v = Uint(order.Uint64(buf))
+	case 'f':
+		v = Float(math.Float32frombits(order.Uint32(buf)))
+	case 'd':
+		v = Float(math.Float64frombits(order.Uint64(buf)))
+	}
+	return v
+}
@@ -1,6 +1,7 @@
 package parser_test
 
 import (
+	"strings"
 	"testing"
 
 	"github.com/ozanh/ugo/parser"
@@ -37,3 +38,58 @@ func TestIdentListString(t *testing.T) {
 			identList, expected, str)
 	}
 }
+
+func TestInspect(t *testing.T) {
+	src := `
+param x
+a := [1, {k: f(x)}]
+for i := 0; i < 2; i++ { if x { g(-i) } else { throw "e" } }
+try { h() } catch err { match err { case TypeError: return } } finally {}
+return a[0:1], func(y) { return y ? x : 2 }`
+	fileSet := parser.NewFileSet()
+	srcFile := fileSet.AddFile("test", -1, len(src))
+	p := parser.NewParser(srcFile, []byte(src), nil)
+	file, err := p.ParseFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var idents []string
+	var depth, maxDepth int
+	parser.Inspect(file, func(node parser.Node) bool {
+		if node == nil {
+			depth--
+			return false
+		}
+		depth++
+		if depth > maxDepth {
+			maxDepth = depth
+		}
+		if ident, ok := node.(*parser.Ident); ok {
+			idents = append(idents, ident.Name)
+		}
+		return true
+	})
+	if depth != 0 {
+		t.Fatalf("expected balanced calls, got depth %d", depth)
+	}
+	if maxDepth < 5 {
+		t.Fatalf("expected nested nodes to be inspected, got depth %d", maxDepth)
+	}
+	expected := "x a f x i i i x g i h err err TypeError a y y x"
+	if got := strings.Join(idents, " "); got != expected {
+		t.Fatalf("expected identifiers %q, got %q", expected, got)
+	}
+
+	var count int
+	parser.Inspect(file, func(node parser.Node) bool {
+		if node != nil {
+			count++
+		}
+		_, ok := node.(*parser.File)
+		return ok
+	})
+	if want := len(file.Stmts) + 1; count != want {
+		t.Fatalf("expected %d nodes, got %d", want, count)
+	}
+}
@@ -244,13 +244,15 @@ func (e *FuncLit) End() Pos {
 }
 
 func (e *FuncLit) String() string {
-	return "func" + e.Type.Params.String() + " " + e.Body.String()
+	return e.Type.String() + " " + e.Body.String()
 }
 
-// FuncType represents a function type definition.
+// FuncType represents a function type definition. Results is nil unless
+// named results are declared.
 type FuncType struct {
 	FuncPos Pos
 	Params  *IdentList
+	Results *IdentList
 }
 
 func (e *FuncType) exprNode() {}
@@ -262,10 +264,16 @@ func (e *FuncType) Pos() Pos {
 
 // End returns the position of first character immediately after the node.
 func (e *FuncType) End() Pos {
+	if e.Results != nil {
+		return e.Results.End()
+	}
 	return e.Params.End()
 }
 
 func (e *FuncType) String() string {
+	if e.Results != nil {
+		return "func" + e.Params.String() + " " + e.Results.String()
+	}
 	return "func" + e.Params.String()
 }
 
@@ -34,6 +34,9 @@ const (
 
 type bailout struct{}
 
+const errMissingTryClause = "try statement requires a catch or finally block, " +
+	"add 'catch {}' or 'finally {}' after try block"
+
 var stmtStart = map[token.Token]bool{
 	token.Param:    true,
 	token.Global:   true,
@@ -42,10 +45,14 @@ var stmtStart = map[token.Token]bool{
 	token.Break:    true,
 	token.Continue: true,
 	token.For:      true,
+	token.While:    true,
+	token.Do:       true,
 	token.If:       true,
 	token.Return:   true,
 	token.Try:      true,
+	token.Match:    true,
 	token.Throw:    true,
+	token.Cleanup:  true,
 }
 
 // Error represents a parser error.
@@ -606,9 +613,18 @@ func (p *Parser) parseFuncType() *FuncType {
 
 	pos := p.expect(token.Func)
 	params := p.parseIdentList()
+
+	var results *IdentList
+	if p.token == token.LParen {
+		results = p.parseIdentList()
+		if results.VarArgs {
+			p.error(results.LParen, "variadic named result")
+		}
+	}
 	return &FuncType{
 		FuncPos: pos,
 		Params:  params,
+		Results: results,
 	}
 }
 
@@ -698,6 +714,11 @@ func (p *Parser) parseStmt() (stmt Stmt) {
 		token.LParen, token.LBrace, token.LBrack, token.Add, token.Sub,
 		token.Mul, token.And, token.Xor, token.Not, token.Import:
 		s := p.parseSimpleStmt(false)
+		if es, isExpr := s.(*ExprStmt); isExpr && p.token == token.Colon {
+			if label, isIdent := es.Expr.(*Ident); isIdent {
+				return p.parseLabeledStmt(label)
+			}
+		}
 		p.expectSemi()
 		return s
 	case token.Return:
@@ -706,10 +727,18 @@ func (p *Parser) parseStmt() (stmt Stmt) {
 		return p.parseIfStmt()
 	case token.For:
 		return p.parseForStmt()
+	case token.While:
+		return p.parseWhileStmt()
+	case token.Do:
+		return p.parseDoWhileStmt()
 	case token.Try:
 		return p.parseTryStmt()
 	case token.Throw:
 		return p.parseThrowStmt()
+	case token.Cleanup:
+		return p.parseCleanupStmt()
+	case token.Match:
+		return p.parseMatchStmt()
 	case token.Break, token.Continue:
 		return p.parseBranchStmt(p.token)
 	case token.Semicolon:
@@ -719,6 +748,11 @@ func (p *Parser) parseStmt() (stmt Stmt) {
 	case token.RBrace:
 		// semicolon may be omitted before a closing "}"
 		return &EmptyStmt{Semicolon: p.pos, Implicit: true}
+	case token.Catch, token.Finally:
+		pos := p.pos
+		p.error(pos, fmt.Sprintf("%s block without try statement", p.token))
+		p.advance(stmtStart)
+		return &BadStmt{From: pos, To: p.pos}
 	default:
 		pos := p.pos
 		p.errorExpected(pos, "statement")
@@ -852,11 +886,13 @@ func (p *Parser) parseForStmt() Stmt {
 	// for {}
 	if p.token == token.LBrace {
 		body := p.parseBlockStmt()
+		elseStmt := p.parseLoopElse()
 		p.expectSemi()
 
 		return &ForStmt{
 			ForPos: pos,
 			Body:   body,
+			Else:   elseStmt,
 		}
 	}
 
@@ -875,12 +911,16 @@ func (p *Parser) parseForStmt() Stmt {
 		forInStmt.ForPos = pos
 		p.exprLevel = prevLevel
 		forInStmt.Body = p.parseBlockStmt()
+		forInStmt.Else = p.parseLoopElse()
 		p.expectSemi()
 		return forInStmt
 	}
 
 	// for init; cond; post {}
 	var s2, s3 Stmt
+	if s1 != nil {
+		s1 = p.parseMultiStmt(s1)
+	}
 	if p.token == token.Semicolon {
 		p.next()
 		if p.token != token.Semicolon {
@@ -888,7 +928,7 @@ func (p *Parser) parseForStmt() Stmt {
 		}
 		p.expect(token.Semicolon)
 		if p.token != token.LBrace {
-			s3 = p.parseSimpleStmt(false) // post
+			s3 = p.parseMultiStmt(p.parseSimpleStmt(false)) // post
 		}
 	} else {
 		// for cond {}
@@ -899,6 +939,7 @@ func (p *Parser) parseForStmt() Stmt {
 	// body
 	p.exprLevel = prevLevel
 	body := p.parseBlockStmt()
+	elseStmt := p.parseLoopElse()
 	p.expectSemi()
 	cond := p.makeExpr(s2, "condition expression")
 	return &ForStmt{
@@ -907,6 +948,97 @@ func (p *Parser) parseForStmt() Stmt {
 		Cond:   cond,
 		Post:   s3,
 		Body:   body,
+		Else:   elseStmt,
+	}
+}
+
+// parseMultiStmt parses comma separated simple statements following the
+// first one, which are only allowed in init and post statements of a for
+// statement.
+func (p *Parser) parseMultiStmt(first Stmt) Stmt {
+	if p.token != token.Comma {
+		return first
+	}
+	list := []Stmt{first}
+	for p.token == token.Comma {
+		p.next()
+		list = append(list, p.parseSimpleStmt(false))
+	}
+	return &MultiStmt{Stmts: list}
+}
+
+// parseLoopElse parses optional else block of a loop which must be on the
+// same line with the closing brace of the loop body.
+func (p *Parser) parseLoopElse() *BlockStmt {
+	if p.token != token.Else {
+		return nil
+	}
+	p.next()
+	return p.parseBlockStmt()
+}
+
+// parseWhileStmt parses "while cond {}" which is lowered to "for cond {}".
+func (p *Parser) parseWhileStmt() Stmt {
+	if p.trace {
+		defer untracep(tracep(p, "WhileStmt"))
+	}
+
+	pos := p.expect(token.While)
+	if p.token == token.LBrace {
+		p.error(p.pos, "missing condition in while statement")
+	}
+
+	prevLevel := p.exprLevel
+	p.exprLevel = -1
+	var cond Expr
+	if p.token != token.LBrace {
+		cond = p.parseExpr()
+	} else {
+		cond = &BadExpr{From: p.pos, To: p.pos}
+	}
+	p.exprLevel = prevLevel
+
+	body := p.parseBlockStmt()
+	elseStmt := p.parseLoopElse()
+	p.expectSemi()
+	return &ForStmt{
+		ForPos: pos,
+		Cond:   cond,
+		Body:   body,
+		Else:   elseStmt,
+	}
+}
+
+func (p *Parser) parseDoWhileStmt() Stmt {
+	if p.trace {
+		defer untracep(tracep(p, "DoWhileStmt"))
+	}
+
+	pos := p.expect(token.Do)
+	body := p.parseBlockStmt()
+	whilePos := p.expect(token.While)
+	cond := p.parseExpr()
+	p.expectSemi()
+	return &DoWhileStmt{
+		DoPos:    pos,
+		Body:     body,
+		WhilePos: whilePos,
+		Cond:     cond,
+	}
+}
+
+func (p *Parser) parseLabeledStmt(label *Ident) Stmt {
+	if p.trace {
+		defer untracep(tracep(p, "LabeledStmt"))
+	}
+
+	colon := p.expect(token.Colon)
+	body := p.parseBlockStmt()
+	p.expectSemi()
+	return &LabeledStmt{
+		Label: label,
+		Colon: colon,
+		Body:  body,
 	}
 }
 
@@ -969,22 +1101,59 @@ func (p *Parser) parseTryStmt() Stmt {
 		defer untracep(tracep(p, "TryStmt"))
 	}
 	pos := p.expect(token.Try)
-	body := p.parseBlockStmt()
-	var catchStmt *CatchStmt
-	var finallyStmt *FinallyStmt
+	stmt := &TryStmt{TryPos: pos, Body: p.parseBlockStmt()}
+
+	// catch or finally on the next line is a common mistake, report it and
+	// parse the rest of the statement
+	if p.isNewline() {
+		semi := p.pos
+		p.next()
+		if p.token != token.Catch && p.token != token.Finally {
+			p.error(semi, errMissingTryClause)
+			return &BadStmt{From: pos, To: semi}
+		}
+		p.error(p.pos, fmt.Sprintf(
+			"%s must be on the same line as closing brace of try block",
+			p.token))
+	}
+
 	if p.token == token.Catch {
-		catchStmt = p.parseCatchStmt()
+		stmt.Catch = p.parseCatchStmt()
+		if p.isNewline() {
+			p.next()
+			if p.token != token.Finally {
+				return stmt
+			}
+			p.error(p.pos, "finally must be on the same line as closing "+
+				"brace of catch block")
+		}
 	}
-	if p.token == token.Finally || catchStmt == nil {
-		finallyStmt = p.parseFinallyStmt()
+	if p.token == token.Finally {
+		stmt.Finally = p.parseFinallyStmt()
 	}
-	p.expectSemi()
-	return &TryStmt{
-		TryPos:  pos,
-		Catch:   catchStmt,
-		Finally: finallyStmt,
-		Body:    body,
+	if stmt.Catch == nil && stmt.Finally == nil {
+		from := p.pos
+		p.error(from, errMissingTryClause)
+		p.expectSemi()
+		return &BadStmt{From: pos, To: from}
+	}
+
+	switch {
+	case p.token == token.Catch && stmt.Finally != nil:
+		p.error(p.pos, "catch block must come before finally block")
+	case p.token == token.Catch:
+		p.error(p.pos, "multiple catch blocks in try statement")
+	case p.token == token.Finally:
+		p.error(p.pos, "multiple finally blocks in try statement")
 	}
+	p.expectSemi()
+	return stmt
+}
+
+// isNewline reports whether current token is a semicolon inserted at the end
+// of a line.
+func (p *Parser) isNewline() bool {
+	return p.token == token.Semicolon && p.tokenLit == "\n"
 }
 
 func (p *Parser) parseCatchStmt() *CatchStmt {
@@ -1016,12 +1185,29 @@ func (p *Parser) parseFinallyStmt() *FinallyStmt {
 	}
 }
 
+func (p *Parser) parseCleanupStmt() Stmt {
+	if p.trace {
+		defer untracep(tracep(p, "CleanupStmt"))
+	}
+	pos := p.expect(token.Cleanup)
+	expr := p.parseExpr()
+	p.expectSemi()
+	return &CleanupStmt{
+		CleanupPos: pos,
+		Expr:       expr,
+	}
+}
+
 func (p *Parser) parseThrowStmt() Stmt {
 	if p.trace {
 		defer untracep(tracep(p, "ThrowStmt"))
 	}
 	pos := p.expect(token.Throw)
-	expr := p.parseExpr()
+	// bare throw re-throws the error caught by enclosing catch block
+	var expr Expr
+	if p.token != token.Semicolon && p.token != token.RBrace {
+		expr = p.parseExpr()
+	}
 	p.expectSemi()
 	return &ThrowStmt{
 		ThrowPos: pos,
@@ -1029,6 +1215,76 @@ func (p *Parser) parseThrowStmt() Stmt {
 	}
 }
 
+func (p *Parser) parseMatchStmt() Stmt {
+	if p.trace {
+		defer untracep(tracep(p, "MatchStmt"))
+	}
+	pos := p.expect(token.Match)
+	if p.token == token.LBrace {
+		p.error(p.pos, "missing subject in match statement")
+	}
+
+	outer := p.exprLevel
+	p.exprLevel = -1
+	var (
+		ident   *Ident
+		subject Expr
+	)
+	if p.token != token.LBrace {
+		subject = p.parseExpr()
+	} else {
+		subject = &BadExpr{From: p.pos, To: p.pos}
+	}
+	if p.token == token.Define {
+		// match e := subject { ... }
+		if id, ok := subject.(*Ident); ok {
+			ident = id
+		} else {
+			p.errorExpected(subject.Pos(), "identifier")
+		}
+		p.next()
+		subject = p.parseExpr()
+	}
+	p.exprLevel = outer
+
+	lbrace := p.expect(token.LBrace)
+	var cases []*CaseClause
+	for p.token == token.Case {
+		cases = append(cases, p.parseCaseClause())
+	}
+	rbrace := p.expect(token.RBrace)
+	p.expectSemi()
+	return &MatchStmt{
+		MatchPos: pos,
+		Ident:    ident,
+		Subject:  subject,
+		LBrace:   lbrace,
+		Cases:    cases,
+		RBrace:   rbrace,
+	}
+}
+
+func (p *Parser) parseCaseClause() *CaseClause {
+	if p.trace {
+		defer untracep(tracep(p, "CaseClause"))
+	}
+	pos := p.expect(token.Case)
+	kinds := p.parseExprList()
+	colon := p.expect(token.Colon)
+
+	var body []Stmt
+	for p.token != token.Case && p.token != token.RBrace &&
+		p.token != token.EOF {
+		body = append(body, p.parseStmt())
+	}
+	return &CaseClause{
+		CasePos: pos,
+		Kinds:   kinds,
+		Colon:   colon,
+		Body:    body,
+	}
+}
+
 func (p *Parser) parseBlockStmt() *BlockStmt {
 	if p.trace {
 		defer untracep(tracep(p, "BlockStmt"))
@@ -972,6 +972,19 @@ func TestParseCall(t *testing.T) {
 					p(1, 4), p(1, 5), NoPos)))
 	})
 
+	// keywords are allowed as selectors
+	expectParse(t, `a.match.case`, func(p pfn) []Stmt {
+		return stmts(
+			exprStmt(
+				selectorExpr(
+					selectorExpr(
+						ident("a", p(1, 1)),
+						stringLit("match", p(1, 3))),
+					stringLit("case", p(1, 9)))))
+	})
+	expectParseString(t, "a.while = a.do\nb := a.cleanup\nc := {match: a.if}",
+		`a.while = a.do; b := a.cleanup; c := {match: a.if}`)
+
 	expectParse(t, `a.b.c()`, func(p pfn) []Stmt {
 		return stmts(
 			exprStmt(
@@ -1272,6 +1285,206 @@ func TestParseFor(t *testing.T) {
 	// expectParseError(t, `for { break x }`)
 }
 
+func TestParseForMultiStmt(t *testing.T) {
+	expectParse(t, "for i, j := 0, 10; i < j; i++, j-- {}", func(p pfn) []Stmt {
+		return stmts(
+			forStmt(
+				assignStmt(
+					exprs(ident("i", p(1, 5)), ident("j", p(1, 8))),
+					exprs(intLit(0, p(1, 13)), intLit(10, p(1, 16))),
+					token.Define, p(1, 10)),
+				binaryExpr(
+					ident("i", p(1, 20)),
+					ident("j", p(1, 24)),
+					token.Less,
+					p(1, 22)),
+				multiStmt(
+					incDecStmt(ident("i", p(1, 27)), token.Inc, p(1, 28)),
+					incDecStmt(ident("j", p(1, 32)), token.Dec, p(1, 33))),
+				blockStmt(p(1, 36), p(1, 37)),
+				p(1, 1)))
+	})
+	expectParse(t, "for a++, b = 1; ; d += 2, c() {}", func(p pfn) []Stmt {
+		return stmts(
+			forStmt(
+				multiStmt(
+					incDecStmt(ident("a", p(1, 5)), token.Inc, p(1, 6)),
+					assignStmt(
+						exprs(ident("b", p(1, 10))),
+						exprs(intLit(1, p(1, 14))),
+						token.Assign, p(1, 12))),
+				nil,
+				multiStmt(
+					assignStmt(
+						exprs(ident("d", p(1, 19))),
+						exprs(intLit(2, p(1, 24))),
+						token.AddAssign, p(1, 21)),
+					exprStmt(callExpr(ident("c", p(1, 27)), p(1, 28), p(1, 29),
+						NoPos))),
+				blockStmt(p(1, 31), p(1, 32)),
+				p(1, 1)))
+	})
+	expectParseString(t, "for i, j := 0, 10; i < j; i++, j-- {}",
+		"for i, j := 0, 10 ; (i < j)  ; i++, j--{}")
+	expectParseError(t, `for a++, b {}`)
+	expectParseError(t, `for i := 0; i < 1; c(), d += 2 {}`)
+	expectParseError(t, `for i := 0; i < 1; i++, {}`)
+	expectParseError(t, `for i := 0; i < 1, j; i++ {}`)
+}
+
+func TestParseLabeled(t *testing.T) {
+	expectParse(t, "done: { break done }", func(p pfn) []Stmt {
+		return stmts(
+			labeledStmt(ident("done", p(1, 1)), p(1, 5),
+				blockStmt(p(1, 7), p(1, 20),
+					&BranchStmt{
+						Token:    token.Break,
+						TokenPos: p(1, 9),
+						Label:    ident("done", p(1, 15)),
+					})))
+	})
+	expectParse(t, `a := 1
+check: {
+	a++
+}`, func(p pfn) []Stmt {
+		return stmts(
+			assignStmt(
+				exprs(ident("a", p(1, 1))),
+				exprs(intLit(1, p(1, 6))),
+				token.Define, p(1, 3)),
+			labeledStmt(ident("check", p(2, 1)), p(2, 6),
+				blockStmt(p(2, 8), p(4, 1),
+					incDecStmt(ident("a", p(3, 2)), token.Inc, p(3, 3)))))
+	})
+	expectParseString(t, "x: { y: { break x }; f() }",
+		"x: {y: {break x}; f()}")
+	expectParseError(t, `x: 1`)
+	expectParseError(t, `x: for {}`)
+	expectParseError(t, `x:`)
+	expectParseError(t, `1: {}`)
+	expectParseError(t, `a.b: {}`)
+	expectParseError(t, `x, y: {}`)
+}
+
+func TestParseWhile(t *testing.T) {
+	expectParse(t, "while a < 5 {}", func(p pfn) []Stmt {
+		return stmts(
+			forStmt(
+				nil,
+				binaryExpr(
+					ident("a", p(1, 7)),
+					intLit(5, p(1, 11)),
+					token.Less,
+					p(1, 9)),
+				nil,
+				blockStmt(p(1, 13), p(1, 14)),
+				p(1, 1)))
+	})
+	expectParse(t, "while true { break }", func(p pfn) []Stmt {
+		return stmts(
+			forStmt(
+				nil,
+				boolLit(true, p(1, 7)),
+				nil,
+				blockStmt(p(1, 12), p(1, 20),
+					breakStmt(p(1, 14))),
+				p(1, 1)))
+	})
+	expectParseString(t, "while a {}", "for a {}")
+	expectParseError(t, `while {}`)
+	expectParseError(t, `while a`)
+	expectParseError(t, `while a; b {}`)
+}
+
+func TestParseDoWhile(t *testing.T) {
+	expectParse(t, "do {} while a < 5", func(p pfn) []Stmt {
+		return stmts(
+			doWhileStmt(
+				blockStmt(p(1, 4), p(1, 5)),
+				binaryExpr(
+					ident("a", p(1, 13)),
+					intLit(5, p(1, 17)),
+					token.Less,
+					p(1, 15)),
+				p(1, 1), p(1, 7)))
+	})
+	expectParse(t, `do {
+	a++
+} while a < 5`, func(p pfn) []Stmt {
+		return stmts(
+			doWhileStmt(
+				blockStmt(p(1, 4), p(3, 1),
+					incDecStmt(ident("a", p(2, 2)), token.Inc, p(2, 3))),
+				binaryExpr(
+					ident("a", p(3, 9)),
+					intLit(5, p(3, 13)),
+					token.Less,
+					p(3, 11)),
+				p(1, 1), p(3, 3)))
+	})
+	expectParseString(t, "do { x++ } while f()", "do {x++} while f()")
+	expectParseError(t, `do {}`)
+	expectParseError(t, `do {} while`)
+	expectParseError(t, `do while a {}`)
+	expectParseError(t, `do {}
+while a`)
+}
+
+func TestParseForElse(t *testing.T) {
+	expectParse(t, "for a < 5 {} else {}", func(p pfn) []Stmt {
+		return stmts(
+			&ForStmt{
+				ForPos: p(1, 1),
+				Cond: binaryExpr(
+					ident("a", p(1, 5)),
+					intLit(5, p(1, 9)),
+					token.Less,
+					p(1, 7)),
+				Body: blockStmt(p(1, 11), p(1, 12)),
+				Else: blockStmt(p(1, 19), p(1, 20)),
+			})
+	})
+	expectParse(t, "for {} else { x }", func(p pfn) []Stmt {
+		return stmts(
+			&ForStmt{
+				ForPos: p(1, 1),
+				Body:   blockStmt(p(1, 5), p(1, 6)),
+				Else: blockStmt(p(1, 13), p(1, 17),
+					exprStmt(ident("x", p(1, 15)))),
+			})
+	})
+	expectParse(t, "for x in y { break } else {}", func(p pfn) []Stmt {
+		return stmts(
+			&ForInStmt{
+				ForPos:   p(1, 1),
+				Key:      ident("_", p(1, 5)),
+				Value:    ident("x", p(1, 5)),
+				Iterable: ident("y", p(1, 10)),
+				Body: blockStmt(p(1, 12), p(1, 20),
+					breakStmt(p(1, 14))),
+				Else: blockStmt(p(1, 27), p(1, 28)),
+			})
+	})
+	expectParse(t, "while a {} else {}", func(p pfn) []Stmt {
+		return stmts(
+			&ForStmt{
+				ForPos: p(1, 1),
+				Cond:   ident("a", p(1, 7)),
+				Body:   blockStmt(p(1, 9), p(1, 10)),
+				Else:   blockStmt(p(1, 17), p(1, 18)),
+			})
+	})
+	expectParseString(t, "for i := 0; i < 3; i++ { f(i) } else { g() }",
+		"for i := 0 ; (i < 3)  ; i++{f(i)} else {g()}")
+	expectParseString(t, "for k, v in m {} else { g() }",
+		"for k, v in m {} else {g()}")
+	expectParseError(t, `for {} else`)
+	expectParseError(t, `for {} else if a {}`)
+	expectParseError(t, `for x in y {} else x`)
+	expectParseError(t, `for {}
+else {}`)
+}
+
 func TestParseFunction(t *testing.T) {
 	expectParse(t, "a = func(b, c, d) { return d }", func(p pfn) []Stmt {
 		return stmts(
@@ -1320,6 +1533,37 @@ func TestParseFunction(t *testing.T) {
 	expectParseError(t, "func(...a,b){}")
 }
 
+func TestParseNamedResults(t *testing.T) {
+	expectParse(t, "a = func(b) (c, d) { return }", func(p pfn) []Stmt {
+		typ := funcType(
+			identList(p(1, 9), p(1, 11), false,
+				ident("b", p(1, 10))),
+			p(1, 5))
+		typ.Results = identList(p(1, 13), p(1, 18), false,
+			ident("c", p(1, 14)),
+			ident("d", p(1, 17)))
+		return stmts(
+			assignStmt(
+				exprs(
+					ident("a", p(1, 1))),
+				exprs(
+					funcLit(typ,
+						blockStmt(p(1, 20), p(1, 29),
+							returnStmt(p(1, 22), nil)))),
+				token.Assign,
+				p(1, 3)))
+	})
+	expectParseString(t, "func()(a){}", "func() (a) {}")
+	expectParseString(t, "func(a, ...b) (c, d) { return }",
+		"func(a, ...b) (c, d) {return}")
+	expectParseString(t, "func()(\na,\nb,\n){}", "func() (a, b) {}")
+	expectParseString(t, "func()(){}", "func() () {}")
+
+	expectParseError(t, "func()(...a){}")
+	expectParseError(t, "func()(a, 1){}")
+	expectParseError(t, "func() a {}")
+}
+
 func TestParseVariadicFunction(t *testing.T) {
 	expectParse(t, "a = func(...args) { return args }", func(p pfn) []Stmt {
 		return stmts(
@@ -2176,8 +2420,92 @@ func TestParseTryThrow(t *testing.T) {
 	expectParseError(t, `try {
 	} catch {}
 	finally {}`)
-	expectParseError(t, `throw;`)
-	expectParseError(t, `throw`)
+	expectParse(t, `throw`, func(p pfn) []Stmt {
+		return stmts(
+			throwStmt(p(1, 1), nil),
+		)
+	})
+	expectParse(t, `try {} catch err { throw }`, func(p pfn) []Stmt {
+		return stmts(
+			tryStmt(p(1, 1),
+				blockStmt(p(1, 5), p(1, 6)),
+				catchStmt(p(1, 8), ident("err", p(1, 14)),
+					blockStmt(p(1, 18), p(1, 26),
+						throwStmt(p(1, 20), nil))),
+				nil,
+			),
+		)
+	})
+	expectParseString(t, `throw;`, "throw")
+	expectParseError(t, `throw,`)
+}
+
+func TestParseCleanup(t *testing.T) {
+	expectParse(t, `cleanup f()`, func(p pfn) []Stmt {
+		return stmts(
+			cleanupStmt(p(1, 1),
+				callExpr(ident("f", p(1, 9)), p(1, 10), p(1, 11), NoPos)),
+		)
+	})
+	expectParse(t, "cleanup x\ny", func(p pfn) []Stmt {
+		return stmts(
+			cleanupStmt(p(1, 1), ident("x", p(1, 9))),
+			exprStmt(ident("y", p(2, 1))),
+		)
+	})
+	expectParseString(t, `cleanup close(a, b)`, "cleanup close(a, b)")
+	expectParseError(t, `cleanup`)
+	expectParseError(t, `cleanup;`)
+	expectParseError(t, `cleanup := 1`)
+}
+
+func TestParseMatch(t *testing.T) {
+	expectParse(t, `match err { case A, B: x; case e: }`, func(p pfn) []Stmt {
+		return stmts(
+			matchStmt(p(1, 1), ident("err", p(1, 7)), p(1, 11), p(1, 35),
+				caseClause(p(1, 13), p(1, 22),
+					exprs(ident("A", p(1, 18)), ident("B", p(1, 21))),
+					exprStmt(ident("x", p(1, 24)))),
+				caseClause(p(1, 27), p(1, 33),
+					exprs(ident("e", p(1, 32)))),
+			),
+		)
+	})
+	expectParse(t, `match f() {
+case NotImplementedError:
+	return 1
+}`, func(p pfn) []Stmt {
+		return stmts(
+			matchStmt(p(1, 1), callExpr(ident("f", p(1, 7)), p(1, 8), p(1, 9),
+				NoPos), p(1, 11), p(4, 1),
+				caseClause(p(2, 1), p(2, 25),
+					exprs(ident("NotImplementedError", p(2, 6))),
+					returnStmt(p(3, 2), intLit(1, p(3, 9)))),
+			),
+		)
+	})
+	expectParse(t, `match x {}`, func(p pfn) []Stmt {
+		return stmts(
+			matchStmt(p(1, 1), ident("x", p(1, 7)), p(1, 9), p(1, 10)),
+		)
+	})
+	expectParse(t, `match e := err { case _: }`, func(p pfn) []Stmt {
+		stmt := matchStmt(p(1, 1), ident("err", p(1, 12)), p(1, 16), p(1, 26),
+			caseClause(p(1, 18), p(1, 24), exprs(ident("_", p(1, 23)))))
+		stmt.Ident = ident("e", p(1, 7))
+		return stmts(stmt)
+	})
+	expectParseString(t, `match err { case a.B, C: x = 1; y; case e: }`,
+		`match err {case a.B, C: x = 1; y; case e: }`)
+	expectParseString(t, `match e := f() { case _: e }`,
+		`match e := f() {case _: e}`)
+	expectParseError(t, `match a.b := err {}`)
+	expectParseError(t, `match {}`)
+	expectParseError(t, `match x { y }`)
+	expectParseError(t, `match x { case: }`)
+	expectParseError(t, `match x { case a }`)
+	expectParseError(t, `match x { case a: }}`)
+	expectParseError(t, `match x`)
 }
 
 func TestParseRBraceEOF(t *testing.T) {
@@ -2349,6 +2677,24 @@ func forStmt(
 	}
 }
 
+func labeledStmt(label *Ident, colon Pos, body *BlockStmt) *LabeledStmt {
+	return &LabeledStmt{Label: label, Colon: colon, Body: body}
+}
+
+func multiStmt(list ...Stmt) *MultiStmt {
+	return &MultiStmt{Stmts: list}
+}
+
+func doWhileStmt(
+	body *BlockStmt,
+	cond Expr,
+	doPos, whilePos Pos,
+) *DoWhileStmt {
+	return &DoWhileStmt{
+		Body: body, Cond: cond, DoPos: doPos, WhilePos: whilePos,
+	}
+}
+
 func forInStmt(
 	key, value *Ident,
 	seq Expr,
@@ -2417,6 +2763,28 @@ func throwStmt(
 	return &ThrowStmt{ThrowPos: throwPos, Expr: expr}
 }
 
+func cleanupStmt(cleanupPos Pos, expr Expr) *CleanupStmt {
+	return &CleanupStmt{CleanupPos: cleanupPos, Expr: expr}
+}
+
+func matchStmt(
+	matchPos Pos,
+	subject Expr,
+	lbrace, rbrace Pos,
+	cases ...*CaseClause,
+) *MatchStmt {
+	return &MatchStmt{MatchPos: matchPos, Subject: subject, LBrace: lbrace,
+		RBrace: rbrace, Cases: cases}
+}
+
+func caseClause(
+	casePos, colon Pos,
+	kinds []Expr,
+	body ...Stmt,
+) *CaseClause {
+	return &CaseClause{CasePos: casePos, Colon: colon, Kinds: kinds, Body: body}
+}
+
 func incDecStmt(
 	expr Expr,
 	tok token.Token,
@@ -2651,6 +3019,24 @@ func equalStmt(t *testing.T, expected, actual Stmt) {
 	case *ThrowStmt:
 		require.Equal(t, expected.ThrowPos, actual.(*ThrowStmt).ThrowPos)
 		equalExpr(t, expected.Expr, actual.(*ThrowStmt).Expr)
+	case *CleanupStmt:
+		require.Equal(t, expected.CleanupPos,
+			actual.(*CleanupStmt).CleanupPos)
+		equalExpr(t, expected.Expr, actual.(*CleanupStmt).Expr)
+	case *MatchStmt:
+		require.Equal(t, expected.MatchPos, actual.(*MatchStmt).MatchPos)
+		require.Equal(t, expected.LBrace, actual.(*MatchStmt).LBrace)
+		require.Equal(t, expected.RBrace, actual.(*MatchStmt).RBrace)
+		equalExpr(t, expected.Subject, actual.(*MatchStmt).Subject)
+		require.Equal(t, len(expected.Cases), len(actual.(*MatchStmt).Cases))
+		for i, cl := range expected.Cases {
+			equalStmt(t, cl, actual.(*MatchStmt).Cases[i])
+		}
+	case *CaseClause:
+		require.Equal(t, expected.CasePos, actual.(*CaseClause).CasePos)
+		require.Equal(t, expected.Colon, actual.(*CaseClause).Colon)
+		equalExprs(t, expected.Kinds, actual.(*CaseClause).Kinds)
+		equalStmts(t, expected.Body, actual.(*CaseClause).Body)
 	case *IncDecStmt:
 		equalExpr(t, expected.Expr,
 			actual.(*IncDecStmt).Expr)
@@ -2663,7 +3049,19 @@ func equalStmt(t *testing.T, expected, actual Stmt) {
 		equalExpr(t, expected.Cond, actual.(*ForStmt).Cond)
 		equalStmt(t, expected.Post, actual.(*ForStmt).Post)
 		equalStmt(t, expected.Body, actual.(*ForStmt).Body)
+		equalStmt(t, expected.Else, actual.(*ForStmt).Else)
 		require.Equal(t, expected.ForPos, actual.(*ForStmt).ForPos)
+	case *LabeledStmt:
+		equalExpr(t, expected.Label, actual.(*LabeledStmt).Label)
+		require.Equal(t, expected.Colon, actual.(*LabeledStmt).Colon)
+		equalStmt(t, expected.Body, actual.(*LabeledStmt).Body)
+	case *MultiStmt:
+		equalStmts(t, expected.Stmts, actual.(*MultiStmt).Stmts)
+	case *DoWhileStmt:
+		equalStmt(t, expected.Body, actual.(*DoWhileStmt).Body)
+		equalExpr(t, expected.Cond, actual.(*DoWhileStmt).Cond)
+		require.Equal(t, expected.DoPos, actual.(*DoWhileStmt).DoPos)
+		require.Equal(t, expected.WhilePos, actual.(*DoWhileStmt).WhilePos)
 	case *ForInStmt:
 		equalExpr(t, expected.Key,
 			actual.(*ForInStmt).Key)
@@ -2673,6 +3071,8 @@ func equalStmt(t *testing.T, expected, actual Stmt) {
 			actual.(*ForInStmt).Iterable)
 		equalStmt(t, expected.Body,
 			actual.(*ForInStmt).Body)
+		equalStmt(t, expected.Else,
+			actual.(*ForInStmt).Else)
 		require.Equal(t, expected.ForPos,
 			actual.(*ForInStmt).ForPos)
 	case *ReturnStmt:
@@ -2837,6 +3237,14 @@ func equalFuncType(t *testing.T, expected, actual *FuncType) {
 	require.Equal(t, expected.Params.LParen, actual.Params.LParen)
 	require.Equal(t, expected.Params.RParen, actual.Params.RParen)
 	equalIdents(t, expected.Params.List, actual.Params.List)
+	if expected.Results == nil {
+		require.Nil(t, actual.Results)
+		return
+	}
+	require.NotNil(t, actual.Results)
+	require.Equal(t, expected.Results.LParen, actual.Results.LParen)
+	require.Equal(t, expected.Results.RParen, actual.Results.RParen)
+	equalIdents(t, expected.Results.List, actual.Results.List)
 }
 
 func equalIdents(t *testing.T, expected, actual []*Ident) {
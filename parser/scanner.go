@@ -47,6 +47,7 @@ type Scanner struct {
 	readOffset   int                 // reading offset (position after current character)
 	lineOffset   int                 // current line offset
 	insertSemi   bool                // insert a semicolon before next newline
+	afterPeriod  bool                // last token is a period
 	errorHandler ScannerErrorHandler // error reporting; or nil
 	errorCount   int                 // number of errors encountered
 	mode         ScanMode
@@ -102,9 +103,13 @@ func (s *Scanner) Scan() (
 	case isLetter(ch):
 		literal = s.scanIdentifier()
 		tok = token.Lookup(literal)
+		if s.afterPeriod {
+			// keywords are allowed as selectors like m.match
+			tok = token.Ident
+		}
 		switch tok {
 		case token.Ident, token.Break, token.Continue, token.Return,
-			token.True, token.False, token.Undefined:
+			token.Throw, token.True, token.False, token.Undefined:
 			insertSemi = true
 		}
 	case '0' <= ch && ch <= '9':
@@ -243,6 +248,7 @@ func (s *Scanner) Scan() (
 	if s.mode&DontInsertSemis == 0 {
 		s.insertSemi = insertSemi
 	}
+	s.afterPeriod = tok == token.Period
 	return
 }
 
@@ -132,6 +132,11 @@ func TestScanner_Scan(t *testing.T) {
 		{token.Catch, "catch"},
 		{token.Finally, "finally"},
 		{token.Throw, "throw"},
+		{token.Match, "match"},
+		{token.Case, "case"},
+		{token.Do, "do"},
+		{token.While, "while"},
+		{token.Cleanup, "cleanup"},
 	}
 
 	// combine
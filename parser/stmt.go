@@ -197,6 +197,7 @@ type ForInStmt struct {
 	Value    *Ident
 	Iterable Expr
 	Body     *BlockStmt
+	Else     *BlockStmt // runs if loop is not terminated by break; or nil
 }
 
 func (s *ForInStmt) stmtNode() {}
@@ -208,16 +209,23 @@ func (s *ForInStmt) Pos() Pos {
 
 // End returns the position of first character immediately after the node.
 func (s *ForInStmt) End() Pos {
+	if s.Else != nil {
+		return s.Else.End()
+	}
 	return s.Body.End()
 }
 
 func (s *ForInStmt) String() string {
+	var elseStmt string
+	if s.Else != nil {
+		elseStmt = " else " + s.Else.String()
+	}
 	if s.Value != nil {
 		return "for " + s.Key.String() + ", " + s.Value.String() +
-			" in " + s.Iterable.String() + " " + s.Body.String()
+			" in " + s.Iterable.String() + " " + s.Body.String() + elseStmt
 	}
 	return "for " + s.Key.String() + " in " + s.Iterable.String() +
-		" " + s.Body.String()
+		" " + s.Body.String() + elseStmt
 }
 
 // ForStmt represents a for statement.
@@ -227,6 +235,7 @@ type ForStmt struct {
 	Cond   Expr
 	Post   Stmt
 	Body   *BlockStmt
+	Else   *BlockStmt // runs if loop is not terminated by break; or nil
 }
 
 func (s *ForStmt) stmtNode() {}
@@ -238,11 +247,14 @@ func (s *ForStmt) Pos() Pos {
 
 // End returns the position of first character immediately after the node.
 func (s *ForStmt) End() Pos {
+	if s.Else != nil {
+		return s.Else.End()
+	}
 	return s.Body.End()
 }
 
 func (s *ForStmt) String() string {
-	var init, cond, post string
+	var init, cond, post, elseStmt string
 	if s.Init != nil {
 		init = s.Init.String()
 	}
@@ -252,11 +264,40 @@ func (s *ForStmt) String() string {
 	if s.Post != nil {
 		post = s.Post.String()
 	}
+	if s.Else != nil {
+		elseStmt = " else " + s.Else.String()
+	}
 
 	if init != "" || post != "" {
-		return "for " + init + " ; " + cond + " ; " + post + s.Body.String()
+		return "for " + init + " ; " + cond + " ; " + post + s.Body.String() +
+			elseStmt
 	}
-	return "for " + cond + s.Body.String()
+	return "for " + cond + s.Body.String() + elseStmt
+}
+
+// DoWhileStmt represents a do-while statement which runs its body at least
+// once, before the condition is evaluated.
+type DoWhileStmt struct {
+	DoPos    Pos
+	Body     *BlockStmt
+	WhilePos Pos
+	Cond     Expr
+}
+
+func (s *DoWhileStmt) stmtNode() {}
+
+// Pos returns the position of first character belonging to the node.
+func (s *DoWhileStmt) Pos() Pos {
+	return s.DoPos
+}
+
+// End returns the position of first character immediately after the node.
+func (s *DoWhileStmt) End() Pos {
+	return s.Cond.End()
+}
+
+func (s *DoWhileStmt) String() string {
+	return "do " + s.Body.String() + " while " + s.Cond.String()
 }
 
 // IfStmt represents an if statement.
@@ -318,6 +359,56 @@ func (s *IncDecStmt) String() string {
 	return s.Expr.String() + s.Token.String()
 }
 
+// LabeledStmt represents a labeled block statement, which can be exited with
+// a break statement using the label.
+type LabeledStmt struct {
+	Label *Ident
+	Colon Pos
+	Body  *BlockStmt
+}
+
+func (s *LabeledStmt) stmtNode() {}
+
+// Pos returns the position of first character belonging to the node.
+func (s *LabeledStmt) Pos() Pos {
+	return s.Label.Pos()
+}
+
+// End returns the position of first character immediately after the node.
+func (s *LabeledStmt) End() Pos {
+	return s.Body.End()
+}
+
+func (s *LabeledStmt) String() string {
+	return s.Label.String() + ": " + s.Body.String()
+}
+
+// MultiStmt represents comma separated simple statements which can be used
+// as init and post statements of a for statement like "i++, j--".
+type MultiStmt struct {
+	Stmts []Stmt
+}
+
+func (s *MultiStmt) stmtNode() {}
+
+// Pos returns the position of first character belonging to the node.
+func (s *MultiStmt) Pos() Pos {
+	return s.Stmts[0].Pos()
+}
+
+// End returns the position of first character immediately after the node.
+func (s *MultiStmt) End() Pos {
+	return s.Stmts[len(s.Stmts)-1].End()
+}
+
+func (s *MultiStmt) String() string {
+	var list []string
+	for _, e := range s.Stmts {
+		list = append(list, e.String())
+	}
+	return strings.Join(list, ", ")
+}
+
 // ReturnStmt represents a return statement.
 type ReturnStmt struct {
 	ReturnPos Pos
@@ -432,7 +523,8 @@ func (s *FinallyStmt) String() string {
 	return "finally " + s.Body.String()
 }
 
-// ThrowStmt represents an throw statement.
+// ThrowStmt represents an throw statement. Expr is nil for bare throw which
+// re-throws the caught error in a catch block.
 type ThrowStmt struct {
 	ThrowPos Pos
 	Expr     Expr
@@ -447,13 +539,111 @@ func (s *ThrowStmt) Pos() Pos {
 
 // End returns the position of first character immediately after the node.
 func (s *ThrowStmt) End() Pos {
+	if s.Expr == nil {
+		return Pos(int(s.ThrowPos) + len(token.Throw.String()))
+	}
 	return s.Expr.End()
 }
 
 func (s *ThrowStmt) String() string {
-	var expr string
 	if s.Expr != nil {
-		expr = s.Expr.String()
+		return "throw " + s.Expr.String()
+	}
+	return "throw"
+}
+
+// CleanupStmt represents a cleanup statement. Expression is evaluated when
+// the enclosing block exits after the statement is run, like a finally block
+// wrapping the rest of the block.
+type CleanupStmt struct {
+	CleanupPos Pos
+	Expr       Expr
+}
+
+func (s *CleanupStmt) stmtNode() {}
+
+// Pos returns the position of first character belonging to the node.
+func (s *CleanupStmt) Pos() Pos {
+	return s.CleanupPos
+}
+
+// End returns the position of first character immediately after the node.
+func (s *CleanupStmt) End() Pos {
+	return s.Expr.End()
+}
+
+func (s *CleanupStmt) String() string {
+	return "cleanup " + s.Expr.String()
+}
+
+// MatchStmt represents a match statement which runs the first case clause
+// whose error kinds match the subject. If Ident is not nil, the subject is
+// bound to it in case clauses.
+type MatchStmt struct {
+	MatchPos Pos
+	Ident    *Ident
+	Subject  Expr
+	LBrace   Pos
+	Cases    []*CaseClause
+	RBrace   Pos
+}
+
+func (s *MatchStmt) stmtNode() {}
+
+// Pos returns the position of first character belonging to the node.
+func (s *MatchStmt) Pos() Pos {
+	return s.MatchPos
+}
+
+// End returns the position of first character immediately after the node.
+func (s *MatchStmt) End() Pos {
+	return s.RBrace + 1
+}
+
+func (s *MatchStmt) String() string {
+	var list []string
+	for _, c := range s.Cases {
+		list = append(list, c.String())
+	}
+	subject := s.Subject.String()
+	if s.Ident != nil {
+		subject = s.Ident.String() + " := " + subject
+	}
+	return "match " + subject + " {" + strings.Join(list, "; ") + "}"
+}
+
+// CaseClause represents a case clause of a match statement. If Kinds holds
+// a single "_" identifier, the clause matches any value.
+type CaseClause struct {
+	CasePos Pos
+	Kinds   []Expr
+	Colon   Pos
+	Body    []Stmt
+}
+
+func (s *CaseClause) stmtNode() {}
+
+// Pos returns the position of first character belonging to the node.
+func (s *CaseClause) Pos() Pos {
+	return s.CasePos
+}
+
+// End returns the position of first character immediately after the node.
+func (s *CaseClause) End() Pos {
+	if n := len(s.Body); n > 0 {
+		return s.Body[n-1].End()
+	}
+	return s.Colon + 1
+}
+
+func (s *CaseClause) String() string {
+	var kinds, body []string
+	for _, e := range s.Kinds {
+		kinds = append(kinds, e.String())
+	}
+	for _, e := range s.Body {
+		body = append(body, e.String())
 	}
-	return "throw " + expr
+	return "case " + strings.Join(kinds, ", ") + ": " +
+		strings.Join(body, "; ")
 }
@@ -0,0 +1,189 @@
+// Copyright (c) 2020-2023 Ozan Hacıbekiroğlu.
+// Use of this source code is governed by a MIT License
+// that can be found in the LICENSE file.
+
+package parser
+
+// Inspect traverses an AST in depth-first order. It starts by calling f(node),
+// if f returns true, Inspect invokes f recursively for each of the non-nil
+// children of node, followed by a call of f(nil).
+func Inspect(node Node, f func(Node) bool) {
+	if node == nil || !f(node) {
+		return
+	}
+
+	switch n := node.(type) {
+	case *File:
+		inspectStmts(n.Stmts, f)
+	case *ArrayLit:
+		inspectExprs(n.Elements, f)
+	case *BinaryExpr:
+		Inspect(n.LHS, f)
+		Inspect(n.RHS, f)
+	case *CallExpr:
+		Inspect(n.Func, f)
+		inspectExprs(n.Args, f)
+	case *CondExpr:
+		Inspect(n.Cond, f)
+		Inspect(n.True, f)
+		Inspect(n.False, f)
+	case *FuncLit:
+		if n.Type != nil {
+			Inspect(n.Type, f)
+		}
+		if n.Body != nil {
+			Inspect(n.Body, f)
+		}
+	case *FuncType:
+		if n.Params != nil {
+			Inspect(n.Params, f)
+		}
+		if n.Results != nil {
+			Inspect(n.Results, f)
+		}
+	case *IdentList:
+		for _, ident := range n.List {
+			Inspect(ident, f)
+		}
+	case *IndexExpr:
+		Inspect(n.Expr, f)
+		Inspect(n.Index, f)
+	case *MapElementLit:
+		Inspect(n.Value, f)
+	case *MapLit:
+		for _, elem := range n.Elements {
+			Inspect(elem, f)
+		}
+	case *ParenExpr:
+		Inspect(n.Expr, f)
+	case *SelectorExpr:
+		Inspect(n.Expr, f)
+		Inspect(n.Sel, f)
+	case *SliceExpr:
+		Inspect(n.Expr, f)
+		Inspect(n.Low, f)
+		Inspect(n.High, f)
+	case *UnaryExpr:
+		Inspect(n.Expr, f)
+	case *AssignStmt:
+		inspectExprs(n.LHS, f)
+		inspectExprs(n.RHS, f)
+	case *BlockStmt:
+		inspectStmts(n.Stmts, f)
+	case *BranchStmt:
+		if n.Label != nil {
+			Inspect(n.Label, f)
+		}
+	case *ExprStmt:
+		Inspect(n.Expr, f)
+	case *ForInStmt:
+		if n.Key != nil {
+			Inspect(n.Key, f)
+		}
+		if n.Value != nil {
+			Inspect(n.Value, f)
+		}
+		Inspect(n.Iterable, f)
+		if n.Body != nil {
+			Inspect(n.Body, f)
+		}
+		if n.Else != nil {
+			Inspect(n.Else, f)
+		}
+	case *ForStmt:
+		Inspect(n.Init, f)
+		Inspect(n.Cond, f)
+		Inspect(n.Post, f)
+		if n.Body != nil {
+			Inspect(n.Body, f)
+		}
+		if n.Else != nil {
+			Inspect(n.Else, f)
+		}
+	case *DoWhileStmt:
+		if n.Body != nil {
+			Inspect(n.Body, f)
+		}
+		Inspect(n.Cond, f)
+	case *IfStmt:
+		Inspect(n.Init, f)
+		Inspect(n.Cond, f)
+		if n.Body != nil {
+			Inspect(n.Body, f)
+		}
+		Inspect(n.Else, f)
+	case *IncDecStmt:
+		Inspect(n.Expr, f)
+	case *LabeledStmt:
+		Inspect(n.Label, f)
+		if n.Body != nil {
+			Inspect(n.Body, f)
+		}
+	case *MultiStmt:
+		inspectStmts(n.Stmts, f)
+	case *ReturnStmt:
+		Inspect(n.Result, f)
+	case *TryStmt:
+		if n.Body != nil {
+			Inspect(n.Body, f)
+		}
+		if n.Catch != nil {
+			Inspect(n.Catch, f)
+		}
+		if n.Finally != nil {
+			Inspect(n.Finally, f)
+		}
+	case *CatchStmt:
+		if n.Ident != nil {
+			Inspect(n.Ident, f)
+		}
+		if n.Body != nil {
+			Inspect(n.Body, f)
+		}
+	case *FinallyStmt:
+		if n.Body != nil {
+			Inspect(n.Body, f)
+		}
+	case *ThrowStmt:
+		Inspect(n.Expr, f)
+	case *CleanupStmt:
+		Inspect(n.Expr, f)
+	case *MatchStmt:
+		if n.Ident != nil {
+			Inspect(n.Ident, f)
+		}
+		Inspect(n.Subject, f)
+		for _, cl := range n.Cases {
+			Inspect(cl, f)
+		}
+	case *CaseClause:
+		inspectExprs(n.Kinds, f)
+		inspectStmts(n.Body, f)
+	case *DeclStmt:
+		Inspect(n.Decl, f)
+	case *GenDecl:
+		for _, spec := range n.Specs {
+			Inspect(spec, f)
+		}
+	case *ValueSpec:
+		for _, ident := range n.Idents {
+			Inspect(ident, f)
+		}
+		inspectExprs(n.Values, f)
+	case *ParamSpec:
+		Inspect(n.Ident, f)
+	}
+	f(nil)
+}
+
+func inspectExprs(list []Expr, f func(Node) bool) {
+	for _, expr := range list {
+		Inspect(expr, f)
+	}
+}
+
+func inspectStmts(list []Stmt, f func(Node) bool) {
+	for _, stmt := range list {
+		Inspect(stmt, f)
+	}
+}
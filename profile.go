@@ -0,0 +1,104 @@
+// Copyright (c) 2020-2023 Ozan Hacıbekiroğlu.
+// Use of this source code is governed by a MIT License
+// that can be found in the LICENSE file.
+
+package ugo
+
+import (
+	"sort"
+	"sync/atomic"
+
+	"github.com/ozanh/ugo/parser"
+)
+
+// Profile is the number of executed instructions attributed to compiled
+// functions and their source lines, which is reported by VM.Profile method.
+type Profile struct {
+	// Total is the number of executed instructions of all functions.
+	Total uint64
+	// Functions are the profiles of executed functions sorted by Count in
+	// descending order.
+	Functions []*FunctionProfile
+}
+
+// FunctionProfile is the number of executed instructions of a compiled
+// function. Closures created from the same function are counted together.
+type FunctionProfile struct {
+	Fn *CompiledFunction
+	// Pos is the position of the first instruction having a source position.
+	Pos parser.SourceFilePos
+	// Count is the number of executed instructions of the function.
+	Count uint64
+	// Lines are the number of executed instructions by line numbers of the
+	// source file of the function. Instructions without a source position
+	// are only counted in Count.
+	Lines map[int]uint64
+}
+
+// SetProfiling enables or disables counting of executed instructions to
+// report a profile with Profile method. Enabling profiling resets the counts,
+// which are kept across runs until it is disabled or bytecode is changed.
+// Counting makes execution slower, it is intended to find hot spots of
+// scripts.
+func (vm *VM) SetProfiling(v bool) *VM {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+	vm.prof = nil
+	if v && vm.bytecode != nil && vm.bytecode.Main != nil {
+		vm.prof = newExecCounter(vm.bytecode)
+	}
+	vm.resetInstrumentation()
+	return vm
+}
+
+// Profile returns the profile of instructions executed since profiling is
+// enabled. It returns nil if profiling is not enabled.
+func (vm *VM) Profile() *Profile {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+	if vm.prof == nil {
+		return nil
+	}
+	return vm.prof.profile()
+}
+
+func (c *execCounter) profile() *Profile {
+	p := &Profile{}
+	fileSet := c.bc.FileSet
+	if fileSet == nil {
+		fileSet = parser.NewFileSet()
+	}
+
+	for _, fc := range c.funcs {
+		fp := &FunctionProfile{Fn: fc.fn, Lines: make(map[int]uint64)}
+		IterateInstructions(fc.fn.Instructions,
+			func(ip int, _ Opcode, _ []int, _ int) bool {
+				fp.Count += atomic.LoadUint64(&fc.counts[ip])
+				return true
+			},
+		)
+		if fp.Count == 0 {
+			continue
+		}
+		fc.lines(fileSet,
+			func(pos parser.SourceFilePos, _ parser.Pos, count uint64) {
+				if !fp.Pos.IsValid() {
+					fp.Pos = pos
+				}
+				if count > 0 {
+					fp.Lines[pos.Line] += count
+				}
+			},
+		)
+		p.Total += fp.Count
+		p.Functions = append(p.Functions, fp)
+	}
+
+	sort.Slice(p.Functions, func(i, j int) bool {
+		if p.Functions[i].Count != p.Functions[j].Count {
+			return p.Functions[i].Count > p.Functions[j].Count
+		}
+		return p.Functions[i].Pos.Offset < p.Functions[j].Pos.Offset
+	})
+	return p
+}
@@ -0,0 +1,93 @@
+package ugo_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ozanh/ugo"
+)
+
+func TestVMProfile(t *testing.T) {
+	script := `hot := func(n) {
+	s := 0
+	for i := 0; i < n; i++ {
+		s += i
+	}
+	return s
+}
+cold := func() {
+	return 1
+}
+total := cold()
+for i := 0; i < 10; i++ {
+	total += hot(100)
+}
+return total`
+	bc, err := Compile([]byte(script), DefaultCompilerOptions)
+	require.NoError(t, err)
+
+	vm := NewVM(bc)
+	require.Nil(t, vm.Profile())
+
+	vm.SetProfiling(true)
+	p := vm.Profile()
+	require.NotNil(t, p)
+	require.Equal(t, uint64(0), p.Total)
+	require.Empty(t, p.Functions)
+
+	ret, err := vm.Run(nil)
+	require.NoError(t, err)
+	require.Equal(t, Int(1+10*4950), ret)
+
+	p = vm.Profile()
+	require.Len(t, p.Functions, 3)
+	var sum uint64
+	for _, fp := range p.Functions {
+		sum += fp.Count
+	}
+	require.Equal(t, p.Total, sum)
+
+	hot := p.Functions[0]
+	require.Greater(t, hot.Count, p.Total/2)
+	require.Equal(t, 2, hot.Pos.Line)
+	require.Greater(t, hot.Lines[4], hot.Lines[2])
+	require.NotContains(t, hot.Lines, 9)
+	require.Same(t, bc.Main, p.Functions[1].Fn)
+
+	cold := p.Functions[2]
+	require.Equal(t, 9, cold.Pos.Line)
+	require.Equal(t, map[int]uint64{9: cold.Count}, cold.Lines)
+
+	// counts are accumulated across runs
+	_, err = vm.Run(nil)
+	require.NoError(t, err)
+	require.Equal(t, 2*p.Total, vm.Profile().Total)
+
+	vm.SetProfiling(false)
+	require.Nil(t, vm.Profile())
+}
+
+func TestVMProfileWithCoverage(t *testing.T) {
+	bc, err := Compile([]byte(`
+f := func(x) { return x * 2 }
+arr := []
+for v in [1, 2, 3] { arr = append(arr, f(v)) }
+return arr`), DefaultCompilerOptions)
+	require.NoError(t, err)
+
+	vm := NewVM(bc).SetCoverage(true).SetProfiling(true)
+	ret, err := vm.Run(nil)
+	require.NoError(t, err)
+	require.Equal(t, Array{Int(2), Int(4), Int(6)}, ret)
+	require.True(t, vm.Coverage()[2])
+	p := vm.Profile()
+	require.Len(t, p.Functions, 2)
+
+	// disabling coverage keeps profiling enabled
+	vm.SetCoverage(false)
+	_, err = vm.Run(nil)
+	require.NoError(t, err)
+	require.Nil(t, vm.Coverage())
+	require.Equal(t, 2*p.Total, vm.Profile().Total)
+}
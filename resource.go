@@ -0,0 +1,52 @@
+// Copyright (c) 2020-2023 Ozan Hacıbekiroğlu.
+// Use of this source code is governed by a MIT License
+// that can be found in the LICENSE file.
+
+package ugo
+
+// Resource kinds passed to resource hooks by builtin and standard library
+// functions. Embedders can define their own kinds for their functions.
+const (
+	ResourceFile      = "file"
+	ResourceNetwork   = "network"
+	ResourceGoroutine = "goroutine"
+	ResourceTimer     = "timer"
+)
+
+// SetResourceHooks sets the functions called when a resource is acquired or
+// released by functions called by the VM. If acquire returns an error, the
+// resource is not created and the error is thrown in the script, which allows
+// embedders to enforce quotas like maximum number of open files or
+// goroutines. Hooks may be called concurrently from different goroutines.
+// Either of the hooks can be nil.
+func (vm *VM) SetResourceHooks(
+	acquire func(kind string) error,
+	release func(kind string),
+) *VM {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+	vm.onAcquire = acquire
+	vm.onRelease = release
+	return vm
+}
+
+// AcquireResource must be called by functions before creating a resource of
+// given kind. Returned error must be returned to the VM without creating the
+// resource. ReleaseResource must be called once the resource is released if
+// AcquireResource returns no error. It is safe to call this method on a nil VM.
+func (vm *VM) AcquireResource(kind string) error {
+	if vm == nil || vm.onAcquire == nil {
+		return nil
+	}
+	return vm.onAcquire(kind)
+}
+
+// ReleaseResource must be called by functions after releasing a resource of
+// given kind which is acquired with AcquireResource. It is safe to call this
+// method on a nil VM.
+func (vm *VM) ReleaseResource(kind string) {
+	if vm == nil || vm.onRelease == nil {
+		return
+	}
+	vm.onRelease(kind)
+}
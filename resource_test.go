@@ -0,0 +1,140 @@
+package ugo_test
+
+import (
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ozanh/ugo"
+)
+
+type quota struct {
+	mu       sync.Mutex
+	max      int
+	used     map[string]int
+	acquired int
+	released int
+}
+
+func (q *quota) acquire(kind string) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.used[kind] >= q.max {
+		return errors.New("quota exceeded: " + kind)
+	}
+	q.used[kind]++
+	q.acquired++
+	return nil
+}
+
+func (q *quota) release(kind string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.used[kind]--
+	q.released++
+}
+
+func TestVMResourceHooks(t *testing.T) {
+	// spawn runs the given function in a new goroutine and waits for all of
+	// them with wait, like a go statement.
+	var wg sync.WaitGroup
+	var errs []error
+	var errsMu sync.Mutex
+	release := make(chan struct{})
+	spawn := &Function{
+		Name: "spawn",
+		ValueEx: func(c Call) (Object, error) {
+			if err := c.CheckLen(1); err != nil {
+				return nil, err
+			}
+			vm := c.VM()
+			if err := vm.AcquireResource(ResourceGoroutine); err != nil {
+				return nil, err
+			}
+			inv := NewInvoker(vm, c.Get(0))
+			inv.Acquire()
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				defer inv.Release()
+				defer vm.ReleaseResource(ResourceGoroutine)
+				<-release
+				if _, err := inv.Invoke(); err != nil {
+					errsMu.Lock()
+					errs = append(errs, err)
+					errsMu.Unlock()
+				}
+			}()
+			return Undefined, nil
+		},
+	}
+
+	bc, err := Compile([]byte(`
+	param spawn
+	results := []
+	for i := 0; i < 4; i++ {
+		try {
+			spawn(func() {})
+			results = append(results, "ok")
+		} catch err {
+			results = append(results, string(err))
+		}
+	}
+	return results`), DefaultCompilerOptions)
+	require.NoError(t, err)
+
+	q := &quota{max: 3, used: map[string]int{}}
+	ret, err := NewVM(bc).SetResourceHooks(q.acquire, q.release).
+		Run(nil, spawn)
+	require.NoError(t, err)
+	require.Equal(t, Array{String("ok"), String("ok"), String("ok"),
+		String("error: quota exceeded: goroutine")}, ret)
+
+	close(release)
+	wg.Wait()
+	require.Empty(t, errs)
+	require.Equal(t, 0, q.used[ResourceGoroutine])
+	require.Equal(t, 3, q.acquired)
+	require.Equal(t, 3, q.released)
+
+	// hooks are inherited by the VMs running compiled functions
+	open := &Function{
+		Name: "open",
+		ValueEx: func(c Call) (Object, error) {
+			if err := c.VM().AcquireResource(ResourceFile); err != nil {
+				return nil, err
+			}
+			c.VM().ReleaseResource(ResourceFile)
+			return Undefined, nil
+		},
+	}
+	bc, err = Compile([]byte(`
+	param open
+	return countBy([1, 2, 3], func(x) {
+		open()
+		return x % 2
+	})`), DefaultCompilerOptions)
+	require.NoError(t, err)
+
+	q = &quota{max: 1, used: map[string]int{}}
+	ret, err = NewVM(bc).SetResourceHooks(q.acquire, q.release).Run(nil, open)
+	require.NoError(t, err)
+	require.Equal(t, Map{"0": Int(1), "1": Int(2)}, ret)
+	require.Equal(t, 3, q.acquired)
+	require.Equal(t, 3, q.released)
+
+	q = &quota{max: 0, used: map[string]int{}}
+	_, err = NewVM(bc).SetResourceHooks(q.acquire, nil).Run(nil, open)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "quota exceeded: file")
+
+	// no hooks
+	_, err = NewVM(bc).Run(nil, open)
+	require.NoError(t, err)
+
+	var vm *VM
+	require.NoError(t, vm.AcquireResource(ResourceFile))
+	vm.ReleaseResource(ResourceFile)
+}
@@ -0,0 +1,109 @@
+// Copyright (c) 2020-2023 Ozan Hacıbekiroğlu.
+// Use of this source code is governed by a MIT License
+// that can be found in the LICENSE file.
+
+package ugo
+
+import (
+	"reflect"
+	"unsafe"
+)
+
+// Approximate sizes of Go values in bytes used by sizeOf builtin function.
+const (
+	sizeOfObject    = int(unsafe.Sizeof(Object(nil)))
+	sizeOfString    = int(unsafe.Sizeof(""))
+	sizeOfSlice     = int(unsafe.Sizeof([]byte(nil)))
+	sizeOfMapHeader = 48
+)
+
+// sizer estimates the number of bytes used by an object graph. Arrays and maps
+// are counted once, so shared and cyclic references do not grow the size.
+type sizer struct {
+	visited map[objectRef]struct{}
+}
+
+// visit reports whether the referenced object is already counted or marks it
+// as counted.
+func (s *sizer) visit(ref objectRef) bool {
+	if ref.ptr == 0 {
+		return false
+	}
+	if _, ok := s.visited[ref]; ok {
+		return true
+	}
+	if s.visited == nil {
+		s.visited = make(map[objectRef]struct{})
+	}
+	s.visited[ref] = struct{}{}
+	return false
+}
+
+// size returns the size of the object excluding the interface value holding
+// it, which is counted by the containers.
+func (s *sizer) size(o Object) int {
+	switch v := o.(type) {
+	case nil, *UndefinedType:
+		return 0
+	case Bool:
+		return 1
+	case Int, Uint, Float:
+		return 8
+	case Char:
+		return 4
+	case String:
+		return sizeOfString + len(v)
+	case Bytes:
+		return sizeOfSlice + cap(v)
+	case Array:
+		if s.visit(refOf(v)) {
+			return 0
+		}
+		n := sizeOfSlice + cap(v)*sizeOfObject
+		for _, e := range v {
+			n += s.size(e)
+		}
+		return n
+	case Map:
+		return s.mapSize(v)
+	case *SyncMap:
+		v.mu.RLock()
+		defer v.mu.RUnlock()
+		return int(unsafe.Sizeof(*v)) + s.mapSize(v.Value)
+	case *Frozen:
+		return sizeOfObject + s.size(v.Value)
+	case *Buffer:
+		return int(unsafe.Sizeof(*v)) + v.Value.Cap()
+	case *Error:
+		n := int(unsafe.Sizeof(*v)) + len(v.Name) + len(v.Message)
+		if cause, ok := v.Cause.(Object); ok {
+			n += s.size(cause)
+		}
+		return n + s.mapSize(v.Data)
+	}
+
+	t := reflect.TypeOf(o)
+	if t.Kind() == reflect.Ptr {
+		return int(t.Elem().Size())
+	}
+	return int(t.Size())
+}
+
+func (s *sizer) mapSize(m Map) int {
+	if m == nil {
+		return 0
+	}
+	if s.visit(refOf(m)) {
+		return 0
+	}
+	n := sizeOfMapHeader
+	for k, v := range m {
+		n += sizeOfString + len(k) + sizeOfObject + s.size(v)
+	}
+	return n
+}
+
+func builtinSizeOfFunc(arg Object) Object {
+	var s sizer
+	return Int(s.size(arg))
+}
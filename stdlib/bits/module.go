@@ -0,0 +1,175 @@
+// Copyright (c) 2020-2023 Ozan Hacıbekiroğlu.
+// Use of this source code is governed by a MIT License
+// that can be found in the LICENSE file.
+
+// Package bits provides bits module implementing bit counting and
+// manipulation functions for uGO script language. It wraps Go's math/bits
+// package.
+package bits
+
+import (
+	"math/bits"
+
+	"github.com/ozanh/ugo"
+	"github.com/ozanh/ugo/stdlib"
+)
+
+// Module represents bits module.
+var Module = map[string]ugo.Object{
+	// ugo:doc
+	// # bits Module
+	//
+	// ## Functions
+	// Functions operate on 64 bits two's complement representation of int
+	// values and 64 bits representation of uint values.
+	//
+	// OnesCount(x int|uint) -> int
+	// Returns the number of one bits ("population count") in x.
+	"OnesCount": &ugo.Function{
+		Name:    "OnesCount",
+		Value:   stdlib.FuncPOROe(onesCountFunc),
+		ValueEx: stdlib.FuncPOROeEx(onesCountFunc),
+	},
+	// ugo:doc
+	// LeadingZeros(x int|uint) -> int
+	// Returns the number of leading zero bits in x, the result is 64 for 0.
+	"LeadingZeros": &ugo.Function{
+		Name:    "LeadingZeros",
+		Value:   stdlib.FuncPOROe(leadingZerosFunc),
+		ValueEx: stdlib.FuncPOROeEx(leadingZerosFunc),
+	},
+	// ugo:doc
+	// TrailingZeros(x int|uint) -> int
+	// Returns the number of trailing zero bits in x, the result is 64 for 0.
+	"TrailingZeros": &ugo.Function{
+		Name:    "TrailingZeros",
+		Value:   stdlib.FuncPOROe(trailingZerosFunc),
+		ValueEx: stdlib.FuncPOROeEx(trailingZerosFunc),
+	},
+	// ugo:doc
+	// Len(x int|uint) -> int
+	// Returns the minimum number of bits required to represent x, the result
+	// is 0 for 0.
+	"Len": &ugo.Function{
+		Name:    "Len",
+		Value:   stdlib.FuncPOROe(lenFunc),
+		ValueEx: stdlib.FuncPOROeEx(lenFunc),
+	},
+	// ugo:doc
+	// Reverse(x int|uint) -> int|uint
+	// Returns the value of x with its bits in reversed order. Type of the
+	// result is the type of x.
+	"Reverse": &ugo.Function{
+		Name:    "Reverse",
+		Value:   stdlib.FuncPOROe(reverseFunc),
+		ValueEx: stdlib.FuncPOROeEx(reverseFunc),
+	},
+	// ugo:doc
+	// ReverseBytes(x int|uint) -> int|uint
+	// Returns the value of x with its bytes in reversed order. Type of the
+	// result is the type of x.
+	"ReverseBytes": &ugo.Function{
+		Name:    "ReverseBytes",
+		Value:   stdlib.FuncPOROe(reverseBytesFunc),
+		ValueEx: stdlib.FuncPOROeEx(reverseBytesFunc),
+	},
+	// ugo:doc
+	// RotateLeft(x int|uint, k int) -> int|uint
+	// Returns the value of x rotated left by (k mod 64) bits. To rotate x
+	// right by k bits, call RotateLeft(x, -k). Type of the result is the type
+	// of x.
+	"RotateLeft": &ugo.Function{
+		Name:    "RotateLeft",
+		Value:   stdlib.FuncPOi64ROe(rotateLeftFunc),
+		ValueEx: stdlib.FuncPOi64ROeEx(rotateLeftFunc),
+	},
+	// ugo:doc
+	// RotateRight(x int|uint, k int) -> int|uint
+	// Returns the value of x rotated right by (k mod 64) bits. Type of the
+	// result is the type of x.
+	"RotateRight": &ugo.Function{
+		Name:    "RotateRight",
+		Value:   stdlib.FuncPOi64ROe(rotateRightFunc),
+		ValueEx: stdlib.FuncPOi64ROeEx(rotateRightFunc),
+	},
+}
+
+// toUint64 returns the bits of o and whether o is a signed value.
+func toUint64(o ugo.Object) (v uint64, signed bool, err error) {
+	switch o := o.(type) {
+	case ugo.Int:
+		return uint64(o), true, nil
+	case ugo.Uint:
+		return uint64(o), false, nil
+	}
+	return 0, false, ugo.NewArgumentTypeError("1st", "int|uint", o.TypeName())
+}
+
+// fromUint64 converts v to the type of the argument.
+func fromUint64(v uint64, signed bool) ugo.Object {
+	if signed {
+		return ugo.Int(v)
+	}
+	return ugo.Uint(v)
+}
+
+func onesCountFunc(o ugo.Object) (ugo.Object, error) {
+	v, _, err := toUint64(o)
+	if err != nil {
+		return ugo.Undefined, err
+	}
+	return ugo.Int(bits.OnesCount64(v)), nil
+}
+
+func leadingZerosFunc(o ugo.Object) (ugo.Object, error) {
+	v, _, err := toUint64(o)
+	if err != nil {
+		return ugo.Undefined, err
+	}
+	return ugo.Int(bits.LeadingZeros64(v)), nil
+}
+
+func trailingZerosFunc(o ugo.Object) (ugo.Object, error) {
+	v, _, err := toUint64(o)
+	if err != nil {
+		return ugo.Undefined, err
+	}
+	return ugo.Int(bits.TrailingZeros64(v)), nil
+}
+
+func lenFunc(o ugo.Object) (ugo.Object, error) {
+	v, _, err := toUint64(o)
+	if err != nil {
+		return ugo.Undefined, err
+	}
+	return ugo.Int(bits.Len64(v)), nil
+}
+
+func reverseFunc(o ugo.Object) (ugo.Object, error) {
+	v, signed, err := toUint64(o)
+	if err != nil {
+		return ugo.Undefined, err
+	}
+	return fromUint64(bits.Reverse64(v), signed), nil
+}
+
+func reverseBytesFunc(o ugo.Object) (ugo.Object, error) {
+	v, signed, err := toUint64(o)
+	if err != nil {
+		return ugo.Undefined, err
+	}
+	return fromUint64(bits.ReverseBytes64(v), signed), nil
+}
+
+func rotateLeftFunc(o ugo.Object, k int64) (ugo.Object, error) {
+	v, signed, err := toUint64(o)
+	if err != nil {
+		return ugo.Undefined, err
+	}
+	// reduce k to avoid overflow while converting to int
+	return fromUint64(bits.RotateLeft64(v, int(k%64)), signed), nil
+}
+
+func rotateRightFunc(o ugo.Object, k int64) (ugo.Object, error) {
+	return rotateLeftFunc(o, -(k % 64))
+}
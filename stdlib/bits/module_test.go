@@ -0,0 +1,109 @@
+package bits_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ozanh/ugo"
+	. "github.com/ozanh/ugo/stdlib/bits"
+)
+
+func TestModule(t *testing.T) {
+	testCases := []struct {
+		s string
+		e Object
+	}{
+		{s: `bits.OnesCount(0xFF)`, e: Int(8)},
+		{s: `bits.OnesCount(uint(0xFF))`, e: Int(8)},
+		{s: `bits.OnesCount(0)`, e: Int(0)},
+		{s: `bits.OnesCount(-1)`, e: Int(64)},
+		{s: `bits.OnesCount(-2)`, e: Int(63)},
+		{s: `bits.OnesCount(18446744073709551615u)`, e: Int(64)},
+		{s: `bits.OnesCount(11)`, e: Int(3)},
+
+		{s: `bits.LeadingZeros(1)`, e: Int(63)},
+		{s: `bits.LeadingZeros(1u)`, e: Int(63)},
+		{s: `bits.LeadingZeros(0)`, e: Int(64)},
+		{s: `bits.LeadingZeros(0xFF)`, e: Int(56)},
+		{s: `bits.LeadingZeros(-1)`, e: Int(0)},
+		{s: `bits.LeadingZeros(9223372036854775807)`, e: Int(1)},
+
+		{s: `bits.TrailingZeros(8)`, e: Int(3)},
+		{s: `bits.TrailingZeros(8u)`, e: Int(3)},
+		{s: `bits.TrailingZeros(0)`, e: Int(64)},
+		{s: `bits.TrailingZeros(-8)`, e: Int(3)},
+		{s: `bits.TrailingZeros(-9223372036854775807-1)`, e: Int(63)},
+
+		{s: `bits.Len(0)`, e: Int(0)},
+		{s: `bits.Len(8)`, e: Int(4)},
+		{s: `bits.Len(255u)`, e: Int(8)},
+		{s: `bits.Len(-1)`, e: Int(64)},
+
+		{s: `bits.Reverse(1)`, e: Int(-9223372036854775808)},
+		{s: `bits.Reverse(1u)`, e: Uint(1 << 63)},
+		{s: `bits.Reverse(0)`, e: Int(0)},
+		{s: `bits.Reverse(-1)`, e: Int(-1)},
+		{s: `bits.Reverse(uint(0xF0))`, e: Uint(0x0F00000000000000)},
+		{s: `bits.Reverse(bits.Reverse(12345))`, e: Int(12345)},
+
+		{s: `bits.ReverseBytes(uint(0x0102))`, e: Uint(0x0201000000000000)},
+		{s: `bits.ReverseBytes(0x0102)`, e: Int(0x0201000000000000)},
+		{s: `bits.ReverseBytes(0xFF)`, e: Int(-72057594037927936)},
+
+		{s: `bits.RotateLeft(1, 1)`, e: Int(2)},
+		{s: `bits.RotateLeft(1u, 63)`, e: Uint(1 << 63)},
+		{s: `bits.RotateLeft(1, 63)`, e: Int(-9223372036854775808)},
+		{s: `bits.RotateLeft(1u, 64)`, e: Uint(1)},
+		{s: `bits.RotateLeft(1u, 65)`, e: Uint(2)},
+		{s: `bits.RotateLeft(1u, -1)`, e: Uint(1 << 63)},
+		{s: `bits.RotateLeft(-1, 5)`, e: Int(-1)},
+		{s: `bits.RotateLeft(3u, -9223372036854775807-1)`, e: Uint(3)},
+
+		{s: `bits.RotateRight(2, 1)`, e: Int(1)},
+		{s: `bits.RotateRight(1u, 1)`, e: Uint(1 << 63)},
+		{s: `bits.RotateRight(1, 1)`, e: Int(-9223372036854775808)},
+		{s: `bits.RotateRight(1u, -1)`, e: Uint(2)},
+		{s: `bits.RotateRight(1u, 129)`, e: Uint(1 << 63)},
+		{s: `bits.RotateRight(bits.RotateLeft(12345, 17), 17)`, e: Int(12345)},
+		{s: `bits.RotateRight(3u, -9223372036854775807-1)`, e: Uint(3)},
+
+		{s: `bits.OnesCount()`, e: String(ErrWrongNumArguments.NewError(
+			"want=1 got=0").String())},
+		{s: `bits.RotateLeft(1)`, e: String(ErrWrongNumArguments.NewError(
+			"want=2 got=1").String())},
+		{s: `bits.OnesCount(1.5)`, e: String(NewArgumentTypeError(
+			"1st", "int|uint", "float").String())},
+		{s: `bits.Reverse("1")`, e: String(NewArgumentTypeError(
+			"1st", "int|uint", "string").String())},
+		{s: `bits.RotateLeft('a', 1)`, e: String(NewArgumentTypeError(
+			"1st", "int|uint", "char").String())},
+		{s: `bits.RotateLeft(1, [])`, e: String(NewArgumentTypeError(
+			"2nd", "int", "array").String())},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.s, func(t *testing.T) {
+			expectRun(t, fmt.Sprintf(`
+			bits := import("bits")
+			try {
+				return %s
+			} catch err {
+				return string(err)
+			}`, tt.s), tt.e)
+		})
+	}
+}
+
+func expectRun(t *testing.T, script string, expected Object) {
+	t.Helper()
+	mm := NewModuleMap()
+	mm.AddBuiltinModule("bits", Module)
+	c := DefaultCompilerOptions
+	c.ModuleMap = mm
+	bc, err := Compile([]byte(script), c)
+	require.NoError(t, err)
+	ret, err := NewVM(bc).Run(nil)
+	require.NoError(t, err)
+	require.Equal(t, expected, ret)
+}
@@ -0,0 +1,57 @@
+// Copyright (c) 2020-2023 Ozan Hacıbekiroğlu.
+// Use of this source code is governed by a MIT License
+// that can be found in the LICENSE file.
+
+// Package debug provides debug module implementing functions to inspect the
+// running uGO script.
+package debug
+
+import (
+	"strconv"
+
+	"github.com/ozanh/ugo"
+)
+
+// Module represents debug module.
+var Module = map[string]ugo.Object{
+	// ugo:doc
+	// # debug Module
+	//
+	// ## Functions
+	// Stack() -> array
+	// Returns the call stack of the running script in caller to callee order.
+	// Each frame is a map having `file`, `line` and `column` keys which
+	// point to the call positions in the source code, the last frame is the
+	// position of the Stack call. Functions called by builtins and Go
+	// functions run in a separate VM so their callers are not included.
+	"Stack": &ugo.Function{
+		Name: "Stack",
+		Value: func(args ...ugo.Object) (ugo.Object, error) {
+			return stackFunc(ugo.NewCall(nil, args))
+		},
+		ValueEx: stackFunc,
+	},
+}
+
+func stackFunc(c ugo.Call) (ugo.Object, error) {
+	if c.Len() != 0 {
+		return ugo.Undefined, ugo.ErrWrongNumArguments.NewError(
+			"want=0 got=" + strconv.Itoa(c.Len()))
+	}
+
+	vm := c.VM()
+	if vm == nil {
+		return ugo.Array{}, nil
+	}
+
+	frames := vm.CallStack()
+	arr := make(ugo.Array, 0, len(frames))
+	for _, f := range frames {
+		arr = append(arr, ugo.Map{
+			"file":   ugo.String(f.Pos.Filename),
+			"line":   ugo.Int(f.Pos.Line),
+			"column": ugo.Int(f.Pos.Column),
+		})
+	}
+	return arr, nil
+}
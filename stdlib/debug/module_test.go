@@ -0,0 +1,76 @@
+package debug_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ozanh/ugo"
+	. "github.com/ozanh/ugo/stdlib/debug"
+)
+
+func frame(file string, line, column int) Map {
+	return Map{"file": String(file), "line": Int(line), "column": Int(column)}
+}
+
+func TestStack(t *testing.T) {
+	expectRun(t, `return import("debug").Stack()`, nil,
+		Array{frame("(main)", 1, 8)})
+
+	expectRun(t, `
+debug := import("debug")
+f := func() {
+	return debug.Stack()
+}
+g := func() {
+	x := f()
+	return x
+}
+return g()`, nil, Array{
+		frame("(main)", 10, 8),
+		frame("(main)", 7, 7),
+		frame("(main)", 4, 9),
+	})
+
+	// source modules are called in the same VM
+	mm := NewModuleMap().AddSourceModule("mod1", []byte(`
+debug := import("debug")
+return func() { return debug.Stack() }`))
+	expectRun(t, `f := import("mod1")
+return f()`, mm, Array{
+		frame("(main)", 2, 8),
+		frame("mod1", 3, 24),
+	})
+
+	// functions called by builtins run in a separate VM
+	expectRun(t, `
+debug := import("debug")
+return groupBy([1], func(x) { return len(debug.Stack()) })`, nil,
+		Map{"1": Array{Int(1)}})
+
+	ret, err := Module["Stack"].Call()
+	require.NoError(t, err)
+	require.Equal(t, Array{}, ret)
+
+	_, err = Module["Stack"].Call(Int(1))
+	require.ErrorIs(t, err, ErrWrongNumArguments)
+}
+
+func expectRun(t *testing.T, script string, mm *ModuleMap, expected Object) {
+	t.Helper()
+	if mm == nil {
+		mm = NewModuleMap()
+	}
+	mm.AddBuiltinModule("debug", Module)
+	for _, optimize := range []bool{false, true} {
+		c := DefaultCompilerOptions
+		c.ModuleMap = mm
+		c.OptimizeConst = optimize
+		c.OptimizeExpr = optimize
+		bc, err := Compile([]byte(script), c)
+		require.NoError(t, err)
+		ret, err := NewVM(bc).Run(nil)
+		require.NoError(t, err)
+		require.Equal(t, expected, ret)
+	}
+}
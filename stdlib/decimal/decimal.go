@@ -0,0 +1,578 @@
+// Copyright (c) 2020-2023 Ozan Hacıbekiroğlu.
+// Use of this source code is governed by a MIT License
+// that can be found in the LICENSE file.
+
+package decimal
+
+import (
+	"errors"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/ozanh/ugo"
+	"github.com/ozanh/ugo/token"
+)
+
+// DivisionPrecision is the number of digits after the decimal point of the
+// result of division operator if it cannot be represented exactly. Quo method
+// can be used for other precisions.
+const DivisionPrecision = 16
+
+// MaxScale is the maximum absolute value of exponents and places accepted by
+// parsing, rounding and division, which limits the size of decimals created
+// from untrusted input.
+const MaxScale = 10000
+
+var (
+	bigOne = big.NewInt(1)
+	bigTen = big.NewInt(10)
+)
+
+// ugo:doc
+// ## Types
+// ### decimal
+//
+// Go Type
+//
+// ```go
+// // Decimal represents fixed-point decimal values and implements ugo.Object
+// // interface. Decimal values are immutable.
+// type Decimal struct {
+//   // contains filtered or unexported fields
+// }
+// ```
+
+// Decimal represents fixed-point decimal values and implements ugo.Object
+// interface. Decimal values are immutable.
+type Decimal struct {
+	// value is coef * 10^-scale, scale is never negative.
+	coef  *big.Int
+	scale int
+}
+
+var _ ugo.NameCallerObject = (*Decimal)(nil)
+
+// New returns a new Decimal with the value of coef * 10^-scale. If scale is
+// negative, it is normalized to zero.
+func New(coef *big.Int, scale int) *Decimal {
+	c := new(big.Int).Set(coef)
+	if scale < 0 {
+		c.Mul(c, pow10(-scale))
+		scale = 0
+	}
+	return &Decimal{coef: c, scale: scale}
+}
+
+// NewFromInt returns a new Decimal with the value of v.
+func NewFromInt(v int64) *Decimal {
+	return &Decimal{coef: big.NewInt(v)}
+}
+
+// NewFromFloat returns a new Decimal from the shortest decimal representation
+// of v. It returns an error if v is NaN or infinity.
+func NewFromFloat(v float64) (*Decimal, error) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil, errors.New("invalid decimal: " +
+			strconv.FormatFloat(v, 'g', -1, 64))
+	}
+	return Parse(strconv.FormatFloat(v, 'f', -1, 64))
+}
+
+// Parse parses s as a decimal number. s can have an optional sign, decimal
+// point and an exponent like "-1.5", "0.25e2" or "1E-3". It returns an error
+// if the resulting scale is out of [-MaxScale, MaxScale] range.
+func Parse(s string) (*Decimal, error) {
+	invalid := func() (*Decimal, error) {
+		return nil, errors.New("invalid decimal: " + strconv.Quote(s))
+	}
+
+	str := s
+	exp := 0
+	if i := strings.IndexAny(str, "eE"); i >= 0 {
+		e, err := strconv.Atoi(str[i+1:])
+		if err != nil {
+			return invalid()
+		}
+		exp = e
+		str = str[:i]
+	}
+
+	var neg bool
+	if str != "" && (str[0] == '-' || str[0] == '+') {
+		neg = str[0] == '-'
+		str = str[1:]
+	}
+
+	intPart, fracPart := str, ""
+	if i := strings.IndexByte(str, '.'); i >= 0 {
+		intPart, fracPart = str[:i], str[i+1:]
+	}
+	if intPart == "" && fracPart == "" {
+		return invalid()
+	}
+
+	digits := intPart + fracPart
+	for i := 0; i < len(digits); i++ {
+		if digits[i] < '0' || digits[i] > '9' {
+			return invalid()
+		}
+	}
+
+	coef, ok := new(big.Int).SetString(digits, 10)
+	if !ok {
+		return invalid()
+	}
+	if neg {
+		coef.Neg(coef)
+	}
+	// check exponent first to prevent overflow while computing the scale
+	scale := len(fracPart) - exp
+	if exp < -MaxScale || exp > MaxScale || scale < -MaxScale || scale > MaxScale {
+		return nil, errors.New("decimal exponent out of range: " + strconv.Quote(s))
+	}
+	return New(coef, scale), nil
+}
+
+// ToDecimal will try to convert given ugo.Object to *Decimal value. int, uint,
+// float, string and decimal values are converted.
+func ToDecimal(o ugo.Object) (ret *Decimal, ok bool) {
+	var err error
+	switch v := o.(type) {
+	case *Decimal:
+		return v, true
+	case ugo.Int:
+		return NewFromInt(int64(v)), true
+	case ugo.Uint:
+		return &Decimal{coef: new(big.Int).SetUint64(uint64(v))}, true
+	case ugo.Float:
+		ret, err = NewFromFloat(float64(v))
+	case ugo.String:
+		ret, err = Parse(string(v))
+	default:
+		return nil, false
+	}
+	return ret, err == nil
+}
+
+// TypeName implements ugo.Object interface.
+func (*Decimal) TypeName() string {
+	return "decimal"
+}
+
+// String implements ugo.Object interface. Trailing zeros after the decimal
+// point are not written.
+func (o *Decimal) String() string {
+	s := o.format(o.scale)
+	if o.scale > 0 {
+		s = strings.TrimRight(s, "0")
+		s = strings.TrimSuffix(s, ".")
+	}
+	return s
+}
+
+// StringFixed returns the string representation of the decimal rounded to
+// given places after the decimal point. Trailing zeros are kept.
+func (o *Decimal) StringFixed(places int) string {
+	if places < 0 {
+		places = 0
+	}
+	return o.Round(places).format(places)
+}
+
+// format formats decimal having given scale which must be greater than or
+// equal to the scale of the decimal.
+func (o *Decimal) format(scale int) string {
+	coef := o.coef
+	if scale > o.scale {
+		coef = new(big.Int).Mul(coef, pow10(scale-o.scale))
+	}
+
+	digits := new(big.Int).Abs(coef).String()
+	if scale > 0 {
+		if len(digits) <= scale {
+			digits = strings.Repeat("0", scale-len(digits)+1) + digits
+		}
+		i := len(digits) - scale
+		digits = digits[:i] + "." + digits[i:]
+	}
+	if coef.Sign() < 0 {
+		digits = "-" + digits
+	}
+	return digits
+}
+
+// IsFalsy implements ugo.Object interface.
+func (o *Decimal) IsFalsy() bool {
+	return o.coef.Sign() == 0
+}
+
+// Equal implements ugo.Object interface. Decimals are compared numerically,
+// so 1.0 and 1.00 are equal.
+func (o *Decimal) Equal(right ugo.Object) bool {
+	switch v := right.(type) {
+	case *Decimal:
+		return o.Cmp(v) == 0
+	case ugo.Int, ugo.Uint:
+		d, _ := ToDecimal(v)
+		return o.Cmp(d) == 0
+	}
+	return false
+}
+
+// CanCall implements ugo.Object interface.
+func (*Decimal) CanCall() bool { return false }
+
+// Call implements ugo.Object interface.
+func (*Decimal) Call(args ...ugo.Object) (ugo.Object, error) {
+	return nil, ugo.ErrNotCallable
+}
+
+// CanIterate implements ugo.Object interface.
+func (*Decimal) CanIterate() bool { return false }
+
+// Iterate implements ugo.Object interface.
+func (*Decimal) Iterate() ugo.Iterator { return nil }
+
+// IndexSet implements ugo.Object interface.
+func (*Decimal) IndexSet(_, _ ugo.Object) error { return ugo.ErrNotIndexAssignable }
+
+// IndexGet implements ugo.Object interface.
+func (*Decimal) IndexGet(index ugo.Object) (ugo.Object, error) {
+	return nil, ugo.ErrNotIndexable
+}
+
+// ugo:doc
+// #### Overloaded decimal Operators
+//
+// - `decimal + decimal|int|uint` -> decimal
+// - `decimal - decimal|int|uint` -> decimal
+// - `decimal * decimal|int|uint` -> decimal
+// - `decimal / decimal|int|uint` -> decimal
+// - `decimal % decimal|int|uint` -> decimal
+// - `decimal < decimal|int|uint` -> bool
+// - `decimal > decimal|int|uint` -> bool
+// - `decimal <= decimal|int|uint` -> bool
+// - `decimal >= decimal|int|uint` -> bool
+//
+// Note that, decimal value must be the left hand side operand. Division
+// result is rounded to 16 digits after the decimal point if it cannot be
+// represented exactly. Division by zero throws ZeroDivisionError.
+
+// BinaryOp implements ugo.Object interface.
+func (o *Decimal) BinaryOp(tok token.Token,
+	right ugo.Object) (ugo.Object, error) {
+
+	switch v := right.(type) {
+	case *Decimal, ugo.Int, ugo.Uint:
+		d, _ := ToDecimal(v)
+		switch tok {
+		case token.Add:
+			return o.Add(d), nil
+		case token.Sub:
+			return o.Sub(d), nil
+		case token.Mul:
+			return o.Mul(d), nil
+		case token.Quo:
+			if d.coef.Sign() == 0 {
+				return nil, ugo.ErrZeroDivision
+			}
+			return o.Quo(d, DivisionPrecision), nil
+		case token.Rem:
+			if d.coef.Sign() == 0 {
+				return nil, ugo.ErrZeroDivision
+			}
+			return o.Rem(d), nil
+		case token.Less:
+			return ugo.Bool(o.Cmp(d) < 0), nil
+		case token.LessEq:
+			return ugo.Bool(o.Cmp(d) <= 0), nil
+		case token.Greater:
+			return ugo.Bool(o.Cmp(d) > 0), nil
+		case token.GreaterEq:
+			return ugo.Bool(o.Cmp(d) >= 0), nil
+		}
+	case *ugo.UndefinedType:
+		switch tok {
+		case token.Less, token.LessEq:
+			return ugo.False, nil
+		case token.Greater, token.GreaterEq:
+			return ugo.True, nil
+		}
+	}
+	return nil, ugo.NewOperandTypeError(
+		tok.String(),
+		o.TypeName(),
+		right.TypeName())
+}
+
+// ugo:doc
+// #### decimal Methods
+//
+// | Method                      | Return Type |
+// |:----------------------------|:------------|
+// |.Round(places int)           | decimal     |
+// |.Truncate(places int)        | decimal     |
+// |.StringFixed(places int)     | string      |
+// |.Abs()                       | decimal     |
+// |.Neg()                       | decimal     |
+// |.Sign()                      | int         |
+// |.Cmp(d2 decimal)             | int         |
+// |.Float()                     | float       |
+// |.Int()                       | int / error |
+// |.Quo(d2 decimal, places int) | decimal     |
+//
+// Round rounds half away from zero, e.g. 2.5 -> 3, -2.5 -> -3. Negative
+// places round to the left of the decimal point. places must be in
+// [-10000, 10000] range. Int truncates fractional part and returns an error
+// if the result overflows int. Quo divides the decimal by d2 rounding the
+// result to given places, it throws ZeroDivisionError if d2 is zero.
+
+// CallName implements ugo.NameCallerObject interface.
+func (o *Decimal) CallName(name string, c ugo.Call) (ugo.Object, error) {
+	fn, ok := methodTable[name]
+	if !ok {
+		return ugo.Undefined, ugo.ErrInvalidIndex.NewError(name)
+	}
+	return fn(o, &c)
+}
+
+var methodTable = map[string]func(*Decimal, *ugo.Call) (ugo.Object, error){
+	"Round": func(o *Decimal, c *ugo.Call) (ugo.Object, error) {
+		places, err := placesArg(c)
+		if err != nil {
+			return ugo.Undefined, err
+		}
+		return o.Round(places), nil
+	},
+	"Truncate": func(o *Decimal, c *ugo.Call) (ugo.Object, error) {
+		places, err := placesArg(c)
+		if err != nil {
+			return ugo.Undefined, err
+		}
+		return o.Truncate(places), nil
+	},
+	"StringFixed": func(o *Decimal, c *ugo.Call) (ugo.Object, error) {
+		places, err := placesArg(c)
+		if err != nil {
+			return ugo.Undefined, err
+		}
+		return ugo.String(o.StringFixed(places)), nil
+	},
+	"Abs": func(o *Decimal, c *ugo.Call) (ugo.Object, error) {
+		if err := c.CheckLen(0); err != nil {
+			return ugo.Undefined, err
+		}
+		return &Decimal{coef: new(big.Int).Abs(o.coef), scale: o.scale}, nil
+	},
+	"Neg": func(o *Decimal, c *ugo.Call) (ugo.Object, error) {
+		if err := c.CheckLen(0); err != nil {
+			return ugo.Undefined, err
+		}
+		return &Decimal{coef: new(big.Int).Neg(o.coef), scale: o.scale}, nil
+	},
+	"Sign": func(o *Decimal, c *ugo.Call) (ugo.Object, error) {
+		if err := c.CheckLen(0); err != nil {
+			return ugo.Undefined, err
+		}
+		return ugo.Int(o.coef.Sign()), nil
+	},
+	"Cmp": func(o *Decimal, c *ugo.Call) (ugo.Object, error) {
+		if err := c.CheckLen(1); err != nil {
+			return ugo.Undefined, err
+		}
+		d, ok := ToDecimal(c.Get(0))
+		if !ok {
+			return ugo.Undefined, ugo.NewArgumentTypeError(
+				"1st", "decimal", c.Get(0).TypeName())
+		}
+		return ugo.Int(o.Cmp(d)), nil
+	},
+	"Float": func(o *Decimal, c *ugo.Call) (ugo.Object, error) {
+		if err := c.CheckLen(0); err != nil {
+			return ugo.Undefined, err
+		}
+		f, _ := o.Rat().Float64()
+		return ugo.Float(f), nil
+	},
+	"Int": func(o *Decimal, c *ugo.Call) (ugo.Object, error) {
+		if err := c.CheckLen(0); err != nil {
+			return ugo.Undefined, err
+		}
+		v := o.Truncate(0).coef
+		if !v.IsInt64() {
+			return &ugo.Error{
+				Message: "decimal " + o.String() + " overflows int",
+			}, nil
+		}
+		return ugo.Int(v.Int64()), nil
+	},
+	"Quo": func(o *Decimal, c *ugo.Call) (ugo.Object, error) {
+		if err := c.CheckLen(2); err != nil {
+			return ugo.Undefined, err
+		}
+		d, ok := ToDecimal(c.Get(0))
+		if !ok {
+			return ugo.Undefined, ugo.NewArgumentTypeError(
+				"1st", "decimal", c.Get(0).TypeName())
+		}
+		places, err := placesValue(c.Get(1), "2nd")
+		if err != nil {
+			return ugo.Undefined, err
+		}
+		if d.coef.Sign() == 0 {
+			return ugo.Undefined, ugo.ErrZeroDivision
+		}
+		return o.Quo(d, places), nil
+	},
+}
+
+func placesArg(c *ugo.Call) (int, error) {
+	if err := c.CheckLen(1); err != nil {
+		return 0, err
+	}
+	return placesValue(c.Get(0), "1st")
+}
+
+func placesValue(o ugo.Object, pos string) (int, error) {
+	places, ok := ugo.ToGoInt(o)
+	if !ok {
+		return 0, ugo.NewArgumentTypeError(pos, "int", o.TypeName())
+	}
+	if places < -MaxScale || places > MaxScale {
+		return 0, ugo.ErrType.NewError("places out of range: " +
+			strconv.Itoa(places))
+	}
+	return places, nil
+}
+
+// Add returns o + d.
+func (o *Decimal) Add(d *Decimal) *Decimal {
+	x, y, scale := align(o, d)
+	return &Decimal{coef: x.Add(x, y), scale: scale}
+}
+
+// Sub returns o - d.
+func (o *Decimal) Sub(d *Decimal) *Decimal {
+	x, y, scale := align(o, d)
+	return &Decimal{coef: x.Sub(x, y), scale: scale}
+}
+
+// Mul returns o * d.
+func (o *Decimal) Mul(d *Decimal) *Decimal {
+	return &Decimal{
+		coef:  new(big.Int).Mul(o.coef, d.coef),
+		scale: o.scale + d.scale,
+	}
+}
+
+// Quo returns o / d rounded half away from zero to given places after the
+// decimal point. It panics if d is zero.
+func (o *Decimal) Quo(d *Decimal, places int) *Decimal {
+	if places < 0 {
+		places = 0
+	}
+	// o/d = o.coef*10^d.scale / (d.coef*10^o.scale)
+	num := new(big.Int).Mul(o.coef, pow10(d.scale+places))
+	den := new(big.Int).Mul(d.coef, pow10(o.scale))
+	return (&Decimal{coef: quoRound(num, den), scale: places}).reduce()
+}
+
+// Rem returns the remainder of o / d which has the sign of o. It panics if d
+// is zero.
+func (o *Decimal) Rem(d *Decimal) *Decimal {
+	x, y, scale := align(o, d)
+	return &Decimal{coef: x.Rem(x, y), scale: scale}
+}
+
+// Cmp compares o and d and returns -1 if o < d, 0 if o == d and +1 if o > d.
+func (o *Decimal) Cmp(d *Decimal) int {
+	x, y, _ := align(o, d)
+	return x.Cmp(y)
+}
+
+// Round rounds the decimal half away from zero to given places after the
+// decimal point.
+func (o *Decimal) Round(places int) *Decimal {
+	return o.rescale(places, true)
+}
+
+// Truncate truncates the decimal to given places after the decimal point.
+func (o *Decimal) Truncate(places int) *Decimal {
+	return o.rescale(places, false)
+}
+
+// Rat returns the decimal as *big.Rat.
+func (o *Decimal) Rat() *big.Rat {
+	return new(big.Rat).SetFrac(o.coef, pow10(o.scale))
+}
+
+// MarshalJSON implements json.Marshaler interface. Decimal is encoded as a
+// JSON number without loss of precision.
+func (o *Decimal) MarshalJSON() ([]byte, error) {
+	return []byte(o.String()), nil
+}
+
+func (o *Decimal) rescale(places int, round bool) *Decimal {
+	if places >= o.scale {
+		return o
+	}
+
+	den := pow10(o.scale - places)
+	var coef *big.Int
+	if round {
+		coef = quoRound(o.coef, den)
+	} else {
+		coef = new(big.Int).Quo(o.coef, den)
+	}
+	return New(coef, places)
+}
+
+// reduce removes trailing zeros of the coefficient after the decimal point.
+func (o *Decimal) reduce() *Decimal {
+	r := new(big.Int)
+	for o.scale > 0 {
+		q, m := new(big.Int).QuoRem(o.coef, bigTen, r)
+		if m.Sign() != 0 {
+			break
+		}
+		o.coef = q
+		o.scale--
+	}
+	return o
+}
+
+// quoRound returns x/y rounded half away from zero.
+func quoRound(x, y *big.Int) *big.Int {
+	q, r := new(big.Int).QuoRem(x, y, new(big.Int))
+	if r.Sign() == 0 {
+		return q
+	}
+	if r.Abs(r).Lsh(r, 1).CmpAbs(y) >= 0 {
+		if x.Sign()*y.Sign() < 0 {
+			q.Sub(q, bigOne)
+		} else {
+			q.Add(q, bigOne)
+		}
+	}
+	return q
+}
+
+// align returns the copies of coefficients of a and b scaled to the same
+// scale.
+func align(a, b *Decimal) (x, y *big.Int, scale int) {
+	x, y = new(big.Int).Set(a.coef), new(big.Int).Set(b.coef)
+	switch {
+	case a.scale < b.scale:
+		x.Mul(x, pow10(b.scale-a.scale))
+		return x, y, b.scale
+	case a.scale > b.scale:
+		y.Mul(y, pow10(a.scale-b.scale))
+	}
+	return x, y, a.scale
+}
+
+func pow10(n int) *big.Int {
+	return new(big.Int).Exp(bigTen, big.NewInt(int64(n)), nil)
+}
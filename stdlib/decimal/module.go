@@ -0,0 +1,67 @@
+// Copyright (c) 2020-2023 Ozan Hacıbekiroğlu.
+// Use of this source code is governed by a MIT License
+// that can be found in the LICENSE file.
+
+// Package decimal provides decimal module implementing a fixed-point decimal
+// type for uGO script language. Decimal values do not have rounding errors of
+// floats, they are suitable for monetary calculations.
+package decimal
+
+import (
+	"github.com/ozanh/ugo"
+	"github.com/ozanh/ugo/stdlib"
+)
+
+// Module represents decimal module.
+var Module = map[string]ugo.Object{
+	// ugo:doc
+	// # decimal Module
+	//
+	// ## Functions
+	// Decimal(v int|uint|float|string|decimal) -> decimal
+	// Returns a decimal value converted from v. Strings can have an optional
+	// sign, decimal point and an exponent like "-1.5", "0.25e2". Floats are
+	// converted using their shortest decimal representation. It throws an error
+	// if v cannot be converted or the exponent is out of [-10000, 10000] range.
+	"Decimal": &ugo.Function{
+		Name:    "Decimal",
+		Value:   stdlib.FuncPOROe(decimalFunc),
+		ValueEx: stdlib.FuncPOROeEx(decimalFunc),
+	},
+	// ugo:doc
+	// IsDecimal(v any) -> bool
+	// Reports whether v is a decimal value.
+	"IsDecimal": &ugo.Function{
+		Name:    "IsDecimal",
+		Value:   stdlib.FuncPORO(isDecimalFunc),
+		ValueEx: stdlib.FuncPOROEx(isDecimalFunc),
+	},
+}
+
+func decimalFunc(o ugo.Object) (ugo.Object, error) {
+	var (
+		d   *Decimal
+		err error
+	)
+	switch v := o.(type) {
+	case ugo.Float:
+		d, err = NewFromFloat(float64(v))
+	case ugo.String:
+		d, err = Parse(string(v))
+	default:
+		var ok bool
+		if d, ok = ToDecimal(o); !ok {
+			err = ugo.NewArgumentTypeError(
+				"1st", "int|uint|float|string|decimal", o.TypeName())
+		}
+	}
+	if err != nil {
+		return ugo.Undefined, err
+	}
+	return d, nil
+}
+
+func isDecimalFunc(o ugo.Object) ugo.Object {
+	_, ok := o.(*Decimal)
+	return ugo.Bool(ok)
+}
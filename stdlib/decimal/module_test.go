@@ -0,0 +1,184 @@
+package decimal_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ozanh/ugo"
+	. "github.com/ozanh/ugo/stdlib/decimal"
+)
+
+func TestParse(t *testing.T) {
+	testCases := []struct {
+		s, e string
+	}{
+		{"0", "0"},
+		{"-0", "0"},
+		{"1", "1"},
+		{"+1", "1"},
+		{"-1.50", "-1.5"},
+		{".5", "0.5"},
+		{"5.", "5"},
+		{"0.001", "0.001"},
+		{"-0.001", "-0.001"},
+		{"1e3", "1000"},
+		{"1.5E-3", "0.0015"},
+		{"-12.345e2", "-1234.5"},
+		{"123456789012345678901234567890.123456789",
+			"123456789012345678901234567890.123456789"},
+	}
+	for _, tt := range testCases {
+		d, err := Parse(tt.s)
+		require.NoError(t, err, tt.s)
+		require.Equal(t, tt.e, d.String(), tt.s)
+	}
+
+	for _, s := range []string{"", ".", "-", "+.", "1e", "a", "1.2.3", " 1",
+		"1_000", "0x10", "1e1.5"} {
+		_, err := Parse(s)
+		require.Error(t, err, s)
+	}
+
+	require.Equal(t, "-1.23", New(big.NewInt(-123), 2).String())
+	require.Equal(t, "1200", New(big.NewInt(12), -2).String())
+	require.Equal(t, "7", NewFromInt(7).String())
+	d, err := NewFromFloat(0.1)
+	require.NoError(t, err)
+	require.Equal(t, "0.1", d.String())
+	require.Equal(t, "0.10", d.StringFixed(2))
+	require.Equal(t, big.NewRat(1, 10), d.Rat())
+
+	b, err := json.Marshal(map[string]interface{}{"v": New(big.NewInt(-1050), 3)})
+	require.NoError(t, err)
+	require.Equal(t, `{"v":-1.05}`, string(b))
+}
+
+func TestScript(t *testing.T) {
+	catch := func(s string) string {
+		return fmt.Sprintf(`
+		decimal := import("decimal")
+		d := decimal.Decimal
+		try {
+			return %s
+		} catch err {
+			return string(err)
+		}`, s)
+	}
+	testCases := []struct {
+		s string
+		e Object
+	}{
+		{s: `0.1 + 0.2 == 0.3`, e: False},
+		{s: `d("0.1") + d("0.2") == d("0.3")`, e: True},
+		{s: `string(d("0.1") + d("0.2"))`, e: String("0.3")},
+		{s: `string(d(0.1) + d(0.2))`, e: String("0.3")},
+		{s: `d("1.0") == d("1.00")`, e: True},
+		{s: `d("1.0") == 1`, e: True},
+		{s: `d("1.5") == 1`, e: False},
+		{s: `d("1.5") == "1.5"`, e: False},
+		{s: `typeName(d(1))`, e: String("decimal")},
+		{s: `decimal.IsDecimal(d(1))`, e: True},
+		{s: `decimal.IsDecimal(1)`, e: False},
+		{s: `string(d(1u))`, e: String("1")},
+		{s: `string(d(d("2.5")))`, e: String("2.5")},
+		{s: `string(d(-2))`, e: String("-2")},
+		{s: `bool(d("0.00"))`, e: False},
+		{s: `bool(d("0.01"))`, e: True},
+
+		{s: `string(d("1.10") - d("0.2"))`, e: String("0.9")},
+		{s: `string(d("1.10") * d("0.2"))`, e: String("0.22")},
+		{s: `string(d("19.99") * 3)`, e: String("59.97")},
+		{s: `string(d("1.5") + 1u)`, e: String("2.5")},
+		{s: `string(d("10") / d("4"))`, e: String("2.5")},
+		{s: `string(d("1") / 3)`, e: String("0.3333333333333333")},
+		{s: `string(d("2") / 3)`, e: String("0.6666666666666667")},
+		{s: `string(d("-2") / 3)`, e: String("-0.6666666666666667")},
+		{s: `string(d("7.5") % 2)`, e: String("1.5")},
+		{s: `string(d("-7.5") % 2)`, e: String("-1.5")},
+		{s: `d("1.1") < d("1.11")`, e: True},
+		{s: `d("1.1") <= 1`, e: False},
+		{s: `d("1.1") > 1`, e: True},
+		{s: `d("-1.1") >= d("-1.10")`, e: True},
+		{s: `d(1) > undefined`, e: True},
+		{s: `d(1) / 0`, e: String(ErrZeroDivision.String())},
+		{s: `d(1) % d(0)`, e: String(ErrZeroDivision.String())},
+		{s: `d(1) + 1.5`, e: String(NewOperandTypeError("+", "decimal", "float").String())},
+		{s: `1 + d(1)`, e: String(NewOperandTypeError("+", "int", "decimal").String())},
+
+		{s: `string(d("2.345").Round(2))`, e: String("2.35")},
+		{s: `string(d("2.344").Round(2))`, e: String("2.34")},
+		{s: `string(d("-2.345").Round(2))`, e: String("-2.35")},
+		{s: `string(d("2.5").Round(0))`, e: String("3")},
+		{s: `string(d("-2.5").Round(0))`, e: String("-3")},
+		{s: `string(d("1.5").Round(3))`, e: String("1.5")},
+		{s: `string(d("1250").Round(-2))`, e: String("1300")},
+		{s: `string(d("2.349").Truncate(2))`, e: String("2.34")},
+		{s: `string(d("-2.349").Truncate(0))`, e: String("-2")},
+		{s: `d("2.5").StringFixed(2)`, e: String("2.50")},
+		{s: `d("2.005").StringFixed(2)`, e: String("2.01")},
+		{s: `d("-0.004").StringFixed(2)`, e: String("0.00")},
+		{s: `d("0.05").StringFixed(0)`, e: String("0")},
+		{s: `string(d("-1.5").Abs())`, e: String("1.5")},
+		{s: `string(d("1.5").Neg())`, e: String("-1.5")},
+		{s: `d("-1.5").Sign()`, e: Int(-1)},
+		{s: `d("0").Sign()`, e: Int(0)},
+		{s: `d("1.5").Cmp("1.50")`, e: Int(0)},
+		{s: `d("1.5").Cmp(2)`, e: Int(-1)},
+		{s: `d("1.25").Float()`, e: Float(1.25)},
+		{s: `d("-1.75").Int()`, e: Int(-1)},
+		{s: `string(d("1e30").Int())`,
+			e: String("error: decimal 1000000000000000000000000000000 overflows int")},
+		{s: `string(d(1).Quo(3, 4))`, e: String("0.3333")},
+		{s: `string(d(2).Quo(d("0.5"), 0))`, e: String("4")},
+		{s: `d(1).Quo(0, 2)`, e: String(ErrZeroDivision.String())},
+		{s: `d(1).Quo(3, "a")`, e: String(NewArgumentTypeError(
+			"2nd", "int", "string").String())},
+		{s: `d(1).StringFixed(1000000000000)`, e: String(ErrType.NewError(
+			"places out of range: 1000000000000").String())},
+		{s: `d(1).Round(-10001)`, e: String(ErrType.NewError(
+			"places out of range: -10001").String())},
+		{s: `d("1.5").Round()`, e: String(ErrWrongNumArguments.NewError(
+			"want=1 got=0").String())},
+		{s: `d("1.5").Round("a")`, e: String(NewArgumentTypeError(
+			"1st", "int", "string").String())},
+		{s: `d("1.5").Cmp([])`, e: String(NewArgumentTypeError(
+			"1st", "decimal", "array").String())},
+		{s: `d("1.5").Foo()`, e: String(ErrInvalidIndex.NewError("Foo").String())},
+
+		{s: `d("abc")`, e: String(`error: invalid decimal: "abc"`)},
+		{s: `d(float("nan"))`, e: String(`error: invalid decimal: NaN`)},
+		{s: `d("1e100000000")`,
+			e: String(`error: decimal exponent out of range: "1e100000000"`)},
+		{s: `d("1e-9223372036854775808")`,
+			e: String(`error: decimal exponent out of range: "1e-9223372036854775808"`)},
+		{s: `d("0.5e-10000")`,
+			e: String(`error: decimal exponent out of range: "0.5e-10000"`)},
+		{s: `string(d("1e10000") / d("1e10000"))`, e: String("1")},
+		{s: `d([])`, e: String(NewArgumentTypeError(
+			"1st", "int|uint|float|string|decimal", "array").String())},
+		{s: `d()`, e: String(ErrWrongNumArguments.NewError(
+			"want=1 got=0").String())},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.s, func(t *testing.T) {
+			expectRun(t, catch(tt.s), tt.e)
+		})
+	}
+}
+
+func expectRun(t *testing.T, script string, expected Object) {
+	t.Helper()
+	mm := NewModuleMap()
+	mm.AddBuiltinModule("decimal", Module)
+	c := DefaultCompilerOptions
+	c.ModuleMap = mm
+	bc, err := Compile([]byte(script), c)
+	require.NoError(t, err)
+	ret, err := NewVM(bc).Run(nil)
+	require.NoError(t, err)
+	require.Equal(t, expected, ret)
+}
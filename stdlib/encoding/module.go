@@ -0,0 +1,153 @@
+// Copyright (c) 2020-2023 Ozan Hacıbekiroğlu.
+// Use of this source code is governed by a MIT License
+// that can be found in the LICENSE file.
+
+// Package encoding provides encoding module implementing functions to detect
+// and convert character encodings of text for uGO script language.
+package encoding
+
+import (
+	"strconv"
+	"strings"
+	"unicode/utf16"
+	"unicode/utf8"
+
+	"github.com/ozanh/ugo"
+	"github.com/ozanh/ugo/stdlib"
+)
+
+// Module represents encoding module.
+var Module = map[string]ugo.Object{
+	// ugo:doc
+	// # encoding Module
+	//
+	// ## Functions
+	// ToUTF8(p bytes, charset string) -> string|error
+	// Decodes p encoded in charset and returns it as UTF-8 encoded string. It
+	// returns an error if p is not valid in charset or charset is not
+	// supported. Charset names are case insensitive, "-" and "_" characters
+	// are ignored. Supported charsets are "utf-8", "ascii" ("us-ascii"),
+	// "latin1" ("iso-8859-1"), "windows-1252" ("cp1252"), "utf-16le",
+	// "utf-16be" and "utf-16" which uses the byte order mark to detect the
+	// byte order and defaults to big endian. Unpaired surrogates of UTF-16
+	// are replaced with the Unicode replacement character U+FFFD.
+	"ToUTF8": &ugo.Function{
+		Name:    "ToUTF8",
+		Value:   stdlib.FuncPb2sRO(toUTF8Func),
+		ValueEx: stdlib.FuncPb2sROEx(toUTF8Func),
+	},
+	// ugo:doc
+	// IsValidUTF8(p bytes) -> bool
+	// Reports whether p consists entirely of valid UTF-8 encoded runes.
+	"IsValidUTF8": &ugo.Function{
+		Name:    "IsValidUTF8",
+		Value:   stdlib.FuncPb2RO(isValidUTF8Func),
+		ValueEx: stdlib.FuncPb2ROEx(isValidUTF8Func),
+	},
+}
+
+// windows1252 holds the code points of bytes in range 0x80-0x9F of
+// windows-1252 charset, others are same as latin1. Zero values are undefined
+// bytes.
+var windows1252 = [32]rune{
+	0x20AC, 0, 0x201A, 0x0192, 0x201E, 0x2026, 0x2020, 0x2021,
+	0x02C6, 0x2030, 0x0160, 0x2039, 0x0152, 0, 0x017D, 0,
+	0, 0x2018, 0x2019, 0x201C, 0x201D, 0x2022, 0x2013, 0x2014,
+	0x02DC, 0x2122, 0x0161, 0x203A, 0x0153, 0, 0x017E, 0x0178,
+}
+
+func toUTF8Func(p []byte, charset string) ugo.Object {
+	name := strings.NewReplacer("-", "", "_", "").Replace(
+		strings.ToLower(charset))
+
+	switch name {
+	case "utf8":
+		if !utf8.Valid(p) {
+			return invalidError(charset, firstInvalidUTF8(p))
+		}
+		return ugo.String(p)
+	case "ascii", "usascii":
+		for i, b := range p {
+			if b >= utf8.RuneSelf {
+				return invalidError(charset, i)
+			}
+		}
+		return ugo.String(p)
+	case "latin1", "iso88591":
+		var sb strings.Builder
+		sb.Grow(len(p))
+		for _, b := range p {
+			sb.WriteRune(rune(b))
+		}
+		return ugo.String(sb.String())
+	case "windows1252", "cp1252":
+		var sb strings.Builder
+		sb.Grow(len(p))
+		for i, b := range p {
+			r := rune(b)
+			if b >= 0x80 && b <= 0x9F {
+				if r = windows1252[b-0x80]; r == 0 {
+					return invalidError(charset, i)
+				}
+			}
+			sb.WriteRune(r)
+		}
+		return ugo.String(sb.String())
+	case "utf16", "utf16le", "utf16be":
+		return decodeUTF16(p, charset, name)
+	}
+	return &ugo.Error{
+		Message: "unsupported charset: " + strconv.Quote(charset),
+	}
+}
+
+func decodeUTF16(p []byte, charset, name string) ugo.Object {
+	if len(p)%2 != 0 {
+		return &ugo.Error{
+			Message: "invalid " + charset + " data: odd number of bytes",
+		}
+	}
+
+	bigEndian := name != "utf16le"
+	if name == "utf16" && len(p) >= 2 {
+		switch {
+		case p[0] == 0xFE && p[1] == 0xFF:
+			p = p[2:]
+		case p[0] == 0xFF && p[1] == 0xFE:
+			bigEndian = false
+			p = p[2:]
+		}
+	}
+
+	u := make([]uint16, len(p)/2)
+	for i := range u {
+		if bigEndian {
+			u[i] = uint16(p[2*i])<<8 | uint16(p[2*i+1])
+		} else {
+			u[i] = uint16(p[2*i+1])<<8 | uint16(p[2*i])
+		}
+	}
+	return ugo.String(string(utf16.Decode(u)))
+}
+
+func invalidError(charset string, offset int) ugo.Object {
+	return &ugo.Error{
+		Message: "invalid " + charset + " data at offset " +
+			strconv.Itoa(offset),
+	}
+}
+
+func firstInvalidUTF8(p []byte) int {
+	for i := 0; i < len(p); {
+		r, size := utf8.DecodeRune(p[i:])
+		if r == utf8.RuneError && size == 1 {
+			return i
+		}
+		i += size
+	}
+	return -1
+}
+
+func isValidUTF8Func(p []byte) ugo.Object {
+	return ugo.Bool(utf8.Valid(p))
+}
@@ -0,0 +1,95 @@
+package encoding_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ozanh/ugo"
+	. "github.com/ozanh/ugo/stdlib/encoding"
+)
+
+func TestModule(t *testing.T) {
+	testCases := []struct {
+		s string
+		e Object
+	}{
+		{s: `encoding.IsValidUTF8(bytes("çağrı 九"))`, e: True},
+		{s: `encoding.IsValidUTF8("abc")`, e: True},
+		{s: `encoding.IsValidUTF8(bytes())`, e: True},
+		{s: `encoding.IsValidUTF8(bytes(0xE7, 0x61))`, e: False},
+		{s: `encoding.IsValidUTF8(bytes(0xED, 0xA0, 0x80))`, e: False},
+		{s: `encoding.IsValidUTF8(bytes("abc") + bytes(0xFF))`, e: False},
+		{s: `chars(bytes(0xE7, 0x61))`, e: Undefined},
+
+		{s: `encoding.ToUTF8(bytes(0xE7, 0x61, 0x21), "latin1")`, e: String("ça!")},
+		{s: `encoding.ToUTF8(bytes(0x43, 0x61, 0x66, 0xE9), "ISO-8859-1")`,
+			e: String("Café")},
+		{s: `encoding.ToUTF8(bytes(0xFF, 0x80, 0x00), "iso_8859_1")`,
+			e: String("ÿ\u0080\x00")},
+		{s: `encoding.IsValidUTF8(encoding.ToUTF8(bytes(0xE7, 0xFF), "latin1"))`,
+			e: True},
+		{s: `encoding.ToUTF8(bytes(0x80, 0x41, 0x99, 0xE9), "windows-1252")`,
+			e: String("€A™é")},
+		{s: `encoding.ToUTF8(bytes(0x41, 0x81), "cp1252")`,
+			e: String("error: invalid cp1252 data at offset 1")},
+		{s: `encoding.ToUTF8(bytes("abc"), "ascii")`, e: String("abc")},
+		{s: `encoding.ToUTF8(bytes(0x61, 0xE7), "US-ASCII")`,
+			e: String("error: invalid US-ASCII data at offset 1")},
+		{s: `encoding.ToUTF8("九x", "UTF-8")`, e: String("九x")},
+		{s: `encoding.ToUTF8(bytes(0x61, 0x62, 0xE7, 0x61), "utf8")`,
+			e: String("error: invalid utf8 data at offset 2")},
+		{s: `encoding.ToUTF8(bytes(0x00, 0x61, 0x4E, 0x5D), "utf-16be")`,
+			e: String("a九")},
+		{s: `encoding.ToUTF8(bytes(0x61, 0x00, 0x5D, 0x4E), "UTF-16LE")`,
+			e: String("a九")},
+		{s: `encoding.ToUTF8(bytes(0xFF, 0xFE, 0x61, 0x00), "utf-16")`,
+			e: String("a")},
+		{s: `encoding.ToUTF8(bytes(0xFE, 0xFF, 0x00, 0x61), "utf-16")`,
+			e: String("a")},
+		{s: `encoding.ToUTF8(bytes(0x00, 0x61), "utf-16")`, e: String("a")},
+		{s: `encoding.ToUTF8(bytes(0xD8, 0x3D, 0xDE, 0x00), "utf-16be")`,
+			e: String("😀")},
+		{s: `encoding.ToUTF8(bytes(0xD8, 0x3D, 0x00, 0x61), "utf-16be")`,
+			e: String("�a")},
+		{s: `encoding.ToUTF8(bytes(0x00), "utf-16le")`,
+			e: String("error: invalid utf-16le data: odd number of bytes")},
+		{s: `encoding.ToUTF8(bytes(), "utf-16")`, e: String("")},
+		{s: `encoding.ToUTF8(bytes(0x61), "ebcdic")`,
+			e: String(`error: unsupported charset: "ebcdic"`)},
+		{s: `isError(encoding.ToUTF8(bytes(0x61), ""))`, e: True},
+
+		{s: `encoding.ToUTF8(bytes())`, e: String(ErrWrongNumArguments.NewError(
+			"want=2 got=1").String())},
+		{s: `encoding.ToUTF8(1, "utf-8")`, e: String(NewArgumentTypeError(
+			"1st", "bytes", "int").String())},
+		{s: `encoding.IsValidUTF8(1)`, e: String(NewArgumentTypeError(
+			"1st", "bytes", "int").String())},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.s, func(t *testing.T) {
+			expectRun(t, fmt.Sprintf(`
+			encoding := import("encoding")
+			try {
+				v := %s
+				return isError(v) ? string(v) : v
+			} catch err {
+				return string(err)
+			}`, tt.s), tt.e)
+		})
+	}
+}
+
+func expectRun(t *testing.T, script string, expected Object) {
+	t.Helper()
+	mm := NewModuleMap()
+	mm.AddBuiltinModule("encoding", Module)
+	c := DefaultCompilerOptions
+	c.ModuleMap = mm
+	bc, err := Compile([]byte(script), c)
+	require.NoError(t, err)
+	ret, err := NewVM(bc).Run(nil)
+	require.NoError(t, err)
+	require.Equal(t, expected, ret)
+}
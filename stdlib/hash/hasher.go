@@ -0,0 +1,116 @@
+// Copyright (c) 2020-2023 Ozan Hacıbekiroğlu.
+// Use of this source code is governed by a MIT License
+// that can be found in the LICENSE file.
+
+package hash
+
+import (
+	"hash"
+
+	"github.com/ozanh/ugo"
+)
+
+// ugo:doc
+// ## Types
+// ### hasher
+//
+// Go Type
+//
+// ```go
+// // Hasher represents incremental hash functions and implements ugo.Object
+// // interface.
+// type Hasher struct {
+//    ugo.ObjectImpl
+//    Name  string
+//    Value hash.Hash
+// }
+// ```
+
+// Hasher represents incremental hash functions and implements ugo.Object
+// interface.
+type Hasher struct {
+	ugo.ObjectImpl
+	Name  string
+	Value hash.Hash
+}
+
+var _ ugo.NameCallerObject = (*Hasher)(nil)
+
+// TypeName implements ugo.Object interface.
+func (*Hasher) TypeName() string {
+	return "hasher"
+}
+
+// String implements ugo.Object interface.
+func (o *Hasher) String() string {
+	return "<hasher:" + o.Name + ">"
+}
+
+// IsFalsy implements ugo.Object interface.
+func (o *Hasher) IsFalsy() bool {
+	return o.Value == nil
+}
+
+// Equal implements ugo.Object interface.
+func (o *Hasher) Equal(right ugo.Object) bool {
+	v, ok := right.(*Hasher)
+	return ok && v == o
+}
+
+// ugo:doc
+// #### hasher Methods
+//
+// | Method                      | Return Type |
+// |:----------------------------|:------------|
+// |.Write(data bytes)           | undefined   |
+// |.Sum()                       | bytes       |
+// |.Reset()                     | undefined   |
+// |.Size()                      | int         |
+//
+// Write accepts string data as well. Sum returns the checksum of the data
+// written so far, it does not change the state of the hasher so more data
+// can be written after Sum.
+
+// CallName implements ugo.NameCallerObject interface.
+func (o *Hasher) CallName(name string, c ugo.Call) (ugo.Object, error) {
+	fn, ok := methodTable[name]
+	if !ok {
+		return ugo.Undefined, ugo.ErrInvalidIndex.NewError(name)
+	}
+	return fn(o, &c)
+}
+
+var methodTable = map[string]func(*Hasher, *ugo.Call) (ugo.Object, error){
+	"Write": func(o *Hasher, c *ugo.Call) (ugo.Object, error) {
+		if err := c.CheckLen(1); err != nil {
+			return ugo.Undefined, err
+		}
+		b, ok := ugo.ToGoByteSlice(c.Get(0))
+		if !ok {
+			return ugo.Undefined, ugo.NewArgumentTypeError(
+				"1st", "bytes|string", c.Get(0).TypeName())
+		}
+		// Write of hash.Hash never returns an error.
+		_, _ = o.Value.Write(b)
+		return ugo.Undefined, nil
+	},
+	"Sum": func(o *Hasher, c *ugo.Call) (ugo.Object, error) {
+		if err := c.CheckLen(0); err != nil {
+			return ugo.Undefined, err
+		}
+		return ugo.Bytes(o.Value.Sum(nil)), nil
+	},
+	"Reset": func(o *Hasher, c *ugo.Call) (ugo.Object, error) {
+		if err := c.CheckLen(0); err != nil {
+			return ugo.Undefined, err
+		}
+		o.Value.Reset()
+		return ugo.Undefined, nil
+	},
+	"Size": func(o *Hasher, c *ugo.Call) (ugo.Object, error) {
+		if err := c.CheckLen(0); err != nil {
+			return ugo.Undefined, err
+		}
+		return ugo.Int(o.Value.Size()), nil
+	},
+}
@@ -0,0 +1,127 @@
+// Copyright (c) 2020-2023 Ozan Hacıbekiroğlu.
+// Use of this source code is governed by a MIT License
+// that can be found in the LICENSE file.
+
+// Package hash provides hash module implementing cryptographic hash functions
+// for uGO script language. It wraps Go's crypto/md5, crypto/sha1,
+// crypto/sha256 and crypto/sha512 packages.
+package hash
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+
+	"github.com/ozanh/ugo"
+	"github.com/ozanh/ugo/stdlib"
+)
+
+// Module represents hash module.
+var Module = map[string]ugo.Object{
+	// ugo:doc
+	// # hash Module
+	//
+	// ## Functions
+	// MD5(data bytes|string) -> bytes
+	// Returns the MD5 checksum of the data.
+	"MD5": &ugo.Function{
+		Name:    "MD5",
+		Value:   stdlib.FuncPb2RO(md5Func),
+		ValueEx: stdlib.FuncPb2ROEx(md5Func),
+	},
+	// ugo:doc
+	// SHA1(data bytes|string) -> bytes
+	// Returns the SHA1 checksum of the data.
+	"SHA1": &ugo.Function{
+		Name:    "SHA1",
+		Value:   stdlib.FuncPb2RO(sha1Func),
+		ValueEx: stdlib.FuncPb2ROEx(sha1Func),
+	},
+	// ugo:doc
+	// SHA256(data bytes|string) -> bytes
+	// Returns the SHA256 checksum of the data.
+	"SHA256": &ugo.Function{
+		Name:    "SHA256",
+		Value:   stdlib.FuncPb2RO(sha256Func),
+		ValueEx: stdlib.FuncPb2ROEx(sha256Func),
+	},
+	// ugo:doc
+	// SHA512(data bytes|string) -> bytes
+	// Returns the SHA512 checksum of the data.
+	"SHA512": &ugo.Function{
+		Name:    "SHA512",
+		Value:   stdlib.FuncPb2RO(sha512Func),
+		ValueEx: stdlib.FuncPb2ROEx(sha512Func),
+	},
+	// ugo:doc
+	// NewMD5() -> hasher
+	// Returns a new hasher computing the MD5 checksum.
+	"NewMD5": &ugo.Function{
+		Name:    "NewMD5",
+		Value:   stdlib.FuncPRO(newMD5Func),
+		ValueEx: stdlib.FuncPROEx(newMD5Func),
+	},
+	// ugo:doc
+	// NewSHA1() -> hasher
+	// Returns a new hasher computing the SHA1 checksum.
+	"NewSHA1": &ugo.Function{
+		Name:    "NewSHA1",
+		Value:   stdlib.FuncPRO(newSHA1Func),
+		ValueEx: stdlib.FuncPROEx(newSHA1Func),
+	},
+	// ugo:doc
+	// NewSHA256() -> hasher
+	// Returns a new hasher computing the SHA256 checksum. Data can be written
+	// to the hasher in chunks, the result is the same as hashing whole data at
+	// once.
+	"NewSHA256": &ugo.Function{
+		Name:    "NewSHA256",
+		Value:   stdlib.FuncPRO(newSHA256Func),
+		ValueEx: stdlib.FuncPROEx(newSHA256Func),
+	},
+	// ugo:doc
+	// NewSHA512() -> hasher
+	// Returns a new hasher computing the SHA512 checksum.
+	"NewSHA512": &ugo.Function{
+		Name:    "NewSHA512",
+		Value:   stdlib.FuncPRO(newSHA512Func),
+		ValueEx: stdlib.FuncPROEx(newSHA512Func),
+	},
+}
+
+func md5Func(b []byte) ugo.Object {
+	sum := md5.Sum(b)
+	return ugo.Bytes(sum[:])
+}
+
+func sha1Func(b []byte) ugo.Object {
+	sum := sha1.Sum(b)
+	return ugo.Bytes(sum[:])
+}
+
+func sha256Func(b []byte) ugo.Object {
+	sum := sha256.Sum256(b)
+	return ugo.Bytes(sum[:])
+}
+
+func sha512Func(b []byte) ugo.Object {
+	sum := sha512.Sum512(b)
+	return ugo.Bytes(sum[:])
+}
+
+func newMD5Func() ugo.Object {
+	return &Hasher{Name: "md5", Value: md5.New()}
+}
+
+func newSHA1Func() ugo.Object {
+	return &Hasher{Name: "sha1", Value: sha1.New()}
+}
+
+func newSHA256Func() ugo.Object {
+	return &Hasher{Name: "sha256", Value: sha256.New()}
+}
+
+func newSHA512Func() ugo.Object {
+	return &Hasher{Name: "sha512", Value: sha512.New()}
+}
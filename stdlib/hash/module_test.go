@@ -0,0 +1,113 @@
+package hash_test
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ozanh/ugo"
+	. "github.com/ozanh/ugo/stdlib/hash"
+)
+
+func TestModule(t *testing.T) {
+	data := strings.Repeat("uGO hash module ", 1000)
+	md5Sum := md5.Sum([]byte(data))
+	sha1Sum := sha1.Sum([]byte(data))
+	sha256Sum := sha256.Sum256([]byte(data))
+	sha512Sum := sha512.Sum512([]byte(data))
+	emptySum := sha256.Sum256(nil)
+
+	testCases := []struct {
+		s string
+		e Object
+	}{
+		{s: `hash.MD5(data)`, e: Bytes(md5Sum[:])},
+		{s: `hash.SHA1(data)`, e: Bytes(sha1Sum[:])},
+		{s: `hash.SHA256(data)`, e: Bytes(sha256Sum[:])},
+		{s: `hash.SHA256(bytes(data))`, e: Bytes(sha256Sum[:])},
+		{s: `hash.SHA512(data)`, e: Bytes(sha512Sum[:])},
+		{s: `hash.SHA256("")`, e: Bytes(emptySum[:])},
+		{s: `hash.NewSHA256().Sum()`, e: Bytes(emptySum[:])},
+		{s: `typeName(hash.NewSHA256())`, e: String("hasher")},
+		{s: `string(hash.NewMD5())`, e: String("<hasher:md5>")},
+		{s: `hash.NewSHA1().Size()`, e: Int(sha1.Size)},
+		{s: `hash.NewSHA512().Size()`, e: Int(sha512.Size)},
+		{s: `func() { h := hash.NewSHA256(); return h == h }()`, e: True},
+		{s: `hash.NewSHA256() == hash.NewSHA256()`, e: False},
+		{s: `chunked(hash.NewMD5(), 7)`, e: Bytes(md5Sum[:])},
+		{s: `chunked(hash.NewSHA1(), 64)`, e: Bytes(sha1Sum[:])},
+		{s: `chunked(hash.NewSHA256(), 1)`, e: Bytes(sha256Sum[:])},
+		{s: `chunked(hash.NewSHA256(), 100)`, e: Bytes(sha256Sum[:])},
+		{s: `chunked(hash.NewSHA256(), 100000)`, e: Bytes(sha256Sum[:])},
+		{s: `chunked(hash.NewSHA512(), 333)`, e: Bytes(sha512Sum[:])},
+		{s: `func() {
+			h := hash.NewSHA256()
+			h.Write(data[:10])
+			h.Sum()
+			h.Write(bytes(data[10:]))
+			return h.Sum()
+		}()`, e: Bytes(sha256Sum[:])},
+		{s: `func() {
+			h := hash.NewSHA256()
+			h.Write("garbage")
+			h.Reset()
+			h.Write(data)
+			return h.Sum()
+		}()`, e: Bytes(sha256Sum[:])},
+		{s: `hash.NewSHA256().Write(data)`, e: Undefined},
+
+		{s: `hash.SHA256()`, e: String(ErrWrongNumArguments.NewError(
+			"want=1 got=0").String())},
+		{s: `hash.SHA256(1)`, e: String(NewArgumentTypeError(
+			"1st", "bytes", "int").String())},
+		{s: `hash.NewSHA256(1)`, e: String(ErrWrongNumArguments.NewError(
+			"want=0 got=1").String())},
+		{s: `hash.NewSHA256().Write()`, e: String(ErrWrongNumArguments.NewError(
+			"want=1 got=0").String())},
+		{s: `hash.NewSHA256().Write(1)`, e: String(NewArgumentTypeError(
+			"1st", "bytes|string", "int").String())},
+		{s: `hash.NewSHA256().Sum(1)`, e: String(ErrWrongNumArguments.NewError(
+			"want=0 got=1").String())},
+		{s: `hash.NewSHA256().Foo()`, e: String(
+			ErrInvalidIndex.NewError("Foo").String())},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.s, func(t *testing.T) {
+			expectRun(t, fmt.Sprintf(`
+			param data
+			hash := import("hash")
+			chunked := func(h, n) {
+				for i := 0; i < len(data); i += n {
+					end := i + n
+					if end > len(data) { end = len(data) }
+					h.Write(data[i:end])
+				}
+				return h.Sum()
+			}
+			try {
+				return %s
+			} catch err {
+				return string(err)
+			}`, tt.s), String(data), tt.e)
+		})
+	}
+}
+
+func expectRun(t *testing.T, script string, arg, expected Object) {
+	t.Helper()
+	mm := NewModuleMap()
+	mm.AddBuiltinModule("hash", Module)
+	c := DefaultCompilerOptions
+	c.ModuleMap = mm
+	bc, err := Compile([]byte(script), c)
+	require.NoError(t, err)
+	ret, err := NewVM(bc).Run(nil, arg)
+	require.NoError(t, err)
+	require.Equal(t, expected, ret)
+}
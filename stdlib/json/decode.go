@@ -35,6 +35,20 @@ func Unmarshal(data []byte) (ugo.Object, error) {
 	return d.init(data).unmarshal()
 }
 
+// Unmarshaler is the interface implemented by types that
+// can unmarshal a JSON description of themselves.
+type Unmarshaler = ugo.JSONUnmarshaler
+
+// UnmarshalTo checks the JSON-encoded data and calls UnmarshalJSON method of v
+// with the data.
+func UnmarshalTo(data []byte, v Unmarshaler) error {
+	var d decodeState
+	if err := checkValid(data, &d.scan); err != nil {
+		return err
+	}
+	return v.UnmarshalJSON(data)
+}
+
 func (d *decodeState) unmarshal() (ugo.Object, error) {
 	d.scan.reset()
 	d.scanWhile(scanSkipSpace)
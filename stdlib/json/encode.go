@@ -55,9 +55,7 @@ func MarshalIndent(v ugo.Object, prefix, indent string) ([]byte, error) {
 
 // Marshaler is the interface implemented by types that
 // can marshal themselves into valid JSON.
-type Marshaler interface {
-	MarshalJSON() ([]byte, error)
-}
+type Marshaler = ugo.JSONMarshaler
 
 // An UnsupportedValueError is returned by Marshal when attempting
 // to encode an unsupported value.
@@ -173,6 +171,8 @@ func objectEncoder(v ugo.Object) encoderFunc {
 		return optionsEncoder
 	case *ugo.ObjectPtr:
 		return objectPtrEncoder
+	case *ugo.Frozen:
+		return frozenEncoder
 	case *ugo.UndefinedType:
 		return invalidValueEncoder
 	case encoding.TextMarshaler:
@@ -442,6 +442,14 @@ func objectPtrEncoder(e *encodeState, v ugo.Object, opts encOpts) {
 	e.ptrLevel--
 }
 
+func frozenEncoder(e *encodeState, v ugo.Object, opts encOpts) {
+	if f := v.(*ugo.Frozen); f != nil {
+		e.encode(f.Value, opts)
+	} else {
+		e.WriteString("null")
+	}
+}
+
 func textMarshalerEncoder(e *encodeState, v ugo.Object, opts encOpts) {
 	if v == nil {
 		e.WriteString("null")
@@ -6,6 +6,7 @@ package json
 
 import (
 	"bytes"
+	"strconv"
 
 	"github.com/ozanh/ugo"
 	"github.com/ozanh/ugo/stdlib"
@@ -85,12 +86,17 @@ var Module = map[string]ugo.Object{
 		ValueEx: stdlib.FuncPOROEx(noEscapeFunc),
 	},
 	// ugo:doc
-	// Unmarshal(p bytes) -> any
+	// Unmarshal(p bytes[, v any]) -> any
 	// Unmarshal parses the JSON-encoded p and returns the result or error.
+	// If v is given, it must implement ugo.JSONUnmarshaler interface, e.g. an
+	// object provided by the embedder, its UnmarshalJSON method is called with p
+	// and v is returned.
 	"Unmarshal": &ugo.Function{
-		Name:    "Unmarshal",
-		Value:   stdlib.FuncPb2RO(unmarshalFunc),
-		ValueEx: stdlib.FuncPb2ROEx(unmarshalFunc),
+		Name: "Unmarshal",
+		Value: func(args ...ugo.Object) (ugo.Object, error) {
+			return unmarshalFunc(ugo.NewCall(nil, args))
+		},
+		ValueEx: unmarshalFunc,
 	},
 	// ugo:doc
 	// Valid(p bytes) -> bool
@@ -162,12 +168,35 @@ func noEscapeFunc(o ugo.Object) ugo.Object {
 	return &EncoderOptions{Value: o}
 }
 
-func unmarshalFunc(b []byte) ugo.Object {
-	v, err := Unmarshal(b)
-	if err != nil {
-		return &ugo.Error{Message: err.Error(), Cause: err}
+func unmarshalFunc(c ugo.Call) (ugo.Object, error) {
+	size := c.Len()
+	if size != 1 && size != 2 {
+		return ugo.Undefined, ugo.ErrWrongNumArguments.NewError(
+			"want=1..2 got=" + strconv.Itoa(size))
+	}
+	b, ok := ugo.ToGoByteSlice(c.Get(0))
+	if !ok {
+		return ugo.Undefined, ugo.NewArgumentTypeError(
+			"1st", "bytes", c.Get(0).TypeName())
+	}
+
+	if size == 1 {
+		v, err := Unmarshal(b)
+		if err != nil {
+			return &ugo.Error{Message: err.Error(), Cause: err}, nil
+		}
+		return v, nil
+	}
+
+	target, ok := c.Get(1).(Unmarshaler)
+	if !ok {
+		return ugo.Undefined, ugo.NewArgumentTypeError(
+			"2nd", "json unmarshaler", c.Get(1).TypeName())
+	}
+	if err := UnmarshalTo(b, target); err != nil {
+		return &ugo.Error{Message: err.Error(), Cause: err}, nil
 	}
-	return v
+	return c.Get(1), nil
 }
 
 func validFunc(b []byte) ugo.Object { return ugo.Bool(valid(b)) }
@@ -107,7 +107,8 @@ func TestScript(t *testing.T) {
 	expectRun(t, catchf(`string(json.Marshal(json.Quote([1,2,{a:json.NoQuote("x")}])))`),
 		nil, String(`["1","2",{"a":"x"}]`))
 
-	expectRun(t, catchf(`json.Unmarshal()`), nil, errnarg(1, 0))
+	expectRun(t, catchf(`json.Unmarshal()`), nil, String(ErrWrongNumArguments.
+		NewError("want=1..2 got=0").String()))
 	expectRun(t, catchf(`json.Unmarshal("[1,true,false,\"x\",{\"a\":\"b\"}]")`),
 		nil, Array{Float(1), True, False, String("x"), Map{"a": String("b")}})
 
@@ -147,6 +148,72 @@ func TestCycle(t *testing.T) {
 	require.Contains(t, err.Error(), `json: unsupported value: encountered a cycle via objectPtr`)
 }
 
+type point struct {
+	ObjectImpl
+	X, Y int
+}
+
+func (p *point) TypeName() string { return "point" }
+
+func (p *point) String() string { return fmt.Sprintf("point(%d, %d)", p.X, p.Y) }
+
+func (p *point) MarshalJSON() ([]byte, error) {
+	return []byte(fmt.Sprintf("[%d,%d]", p.X, p.Y)), nil
+}
+
+func (p *point) UnmarshalJSON(b []byte) error {
+	var v [2]int
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	p.X, p.Y = v[0], v[1]
+	return nil
+}
+
+func TestMarshalerInterop(t *testing.T) {
+	p := &point{X: 1, Y: 2}
+	expectRun(t, `
+	param p
+	json := import("json")
+	return string(json.Marshal({p: p, list: [p], f: freeze({a: 'a'})}))`,
+		newOpts().Args(p), String(`{"f":{"a":97},"list":[[1,2]],"p":[1,2]}`))
+
+	expectRun(t, `
+	param p
+	json := import("json")
+	return [same(json.Unmarshal("[3, 4]", p), p), string(p)]`,
+		newOpts().Args(p), Array{True, String("point(3, 4)")})
+	require.Equal(t, &point{X: 3, Y: 4}, p)
+
+	expectRun(t, `
+	param p
+	json := import("json")
+	return [isError(json.Unmarshal("[\"a\"]", p)), string(p)]`,
+		newOpts().Args(p), Array{True, String("point(3, 4)")})
+	expectRun(t, `
+	param p
+	json := import("json")
+	return string(json.Unmarshal("[1", p))`, newOpts().Args(p),
+		String("error: unexpected end of JSON input"))
+	expectRun(t, `
+	json := import("json")
+	try { json.Unmarshal("1", {}) } catch err { return string(err) }`, nil,
+		String("TypeError: invalid type for argument '2nd': "+
+			"expected json unmarshaler, found map"))
+
+	// Marshaler is the same interface as ugo.JSONMarshaler, builtin types
+	// implement it consistently with the module
+	for _, o := range []Object{Undefined, Char('x'), Float(3.4), Bytes{0, 1},
+		Map{"b": Array{Int(1), Uint(2)}, "a": String("<")},
+		Freeze(Array{True, Undefined})} {
+		b1, err := Marshal(o)
+		require.NoError(t, err)
+		b2, err := o.(Marshaler).MarshalJSON()
+		require.NoError(t, err)
+		require.Equal(t, string(b1), string(b2))
+	}
+}
+
 type Opts struct {
 	global Object
 	args   []Object
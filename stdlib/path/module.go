@@ -0,0 +1,151 @@
+// Copyright (c) 2020-2023 Ozan Hacıbekiroğlu.
+// Use of this source code is governed by a MIT License
+// that can be found in the LICENSE file.
+
+// Package path provides path module implementing functions to manipulate
+// slash-separated paths for uGO script language. It wraps Go's path package
+// functionalities and it does not access to the file system.
+package path
+
+import (
+	"path"
+	"strconv"
+
+	"github.com/ozanh/ugo"
+	"github.com/ozanh/ugo/stdlib"
+)
+
+// Module represents path module.
+var Module = map[string]ugo.Object{
+	// ugo:doc
+	// # path Module
+	//
+	// ## Functions
+	// Base(path string) -> string
+	// Returns the last element of path. Trailing slashes are removed before
+	// extracting the last element. If the path is empty, Base returns ".".
+	// If the path consists entirely of slashes, Base returns "/".
+	"Base": &ugo.Function{
+		Name:    "Base",
+		Value:   stdlib.FuncPsRO(baseFunc),
+		ValueEx: stdlib.FuncPsROEx(baseFunc),
+	},
+	// ugo:doc
+	// Clean(path string) -> string
+	// Returns the shortest path name equivalent to path by purely lexical
+	// processing. If the result of this process is an empty string, Clean
+	// returns the string ".".
+	"Clean": &ugo.Function{
+		Name:    "Clean",
+		Value:   stdlib.FuncPsRO(cleanFunc),
+		ValueEx: stdlib.FuncPsROEx(cleanFunc),
+	},
+	// ugo:doc
+	// Dir(path string) -> string
+	// Returns all but the last element of path, typically the path's
+	// directory. The path is cleaned after dropping the final element.
+	"Dir": &ugo.Function{
+		Name:    "Dir",
+		Value:   stdlib.FuncPsRO(dirFunc),
+		ValueEx: stdlib.FuncPsROEx(dirFunc),
+	},
+	// ugo:doc
+	// Ext(path string) -> string
+	// Returns the file name extension used by path. The extension is the
+	// suffix beginning at the final dot in the final slash-separated element of
+	// path; it is empty if there is no dot.
+	"Ext": &ugo.Function{
+		Name:    "Ext",
+		Value:   stdlib.FuncPsRO(extFunc),
+		ValueEx: stdlib.FuncPsROEx(extFunc),
+	},
+	// ugo:doc
+	// IsAbs(path string) -> bool
+	// Reports whether the path is absolute.
+	"IsAbs": &ugo.Function{
+		Name:    "IsAbs",
+		Value:   stdlib.FuncPsRO(isAbsFunc),
+		ValueEx: stdlib.FuncPsROEx(isAbsFunc),
+	},
+	// ugo:doc
+	// Join(...elem string) -> string
+	// Joins any number of path elements into a single path, separating them
+	// with slashes. Empty elements are ignored. The result is cleaned. If all
+	// elements are empty, Join returns an empty string.
+	"Join": &ugo.Function{
+		Name:    "Join",
+		Value:   joinFunc,
+		ValueEx: joinFuncEx,
+	},
+	// ugo:doc
+	// Match(pattern string, name string) -> bool
+	// Reports whether name matches the shell pattern. Pattern syntax is same as
+	// Go's path.Match function, `*` matches any sequence of non-slash chars
+	// and `?` matches any single non-slash char. It throws an error if pattern
+	// is malformed.
+	"Match": &ugo.Function{
+		Name:    "Match",
+		Value:   stdlib.FuncPssROe(matchFunc),
+		ValueEx: stdlib.FuncPssROeEx(matchFunc),
+	},
+	// ugo:doc
+	// Split(path string) -> [dir string, file string]
+	// Splits path immediately following the final slash, separating it into a
+	// directory and file name component. If there is no slash in path, Split
+	// returns an empty dir and file set to path.
+	"Split": &ugo.Function{
+		Name:    "Split",
+		Value:   stdlib.FuncPsRO(splitFunc),
+		ValueEx: stdlib.FuncPsROEx(splitFunc),
+	},
+}
+
+func baseFunc(s string) ugo.Object {
+	return ugo.String(path.Base(s))
+}
+
+func cleanFunc(s string) ugo.Object {
+	return ugo.String(path.Clean(s))
+}
+
+func dirFunc(s string) ugo.Object {
+	return ugo.String(path.Dir(s))
+}
+
+func extFunc(s string) ugo.Object {
+	return ugo.String(path.Ext(s))
+}
+
+func isAbsFunc(s string) ugo.Object {
+	return ugo.Bool(path.IsAbs(s))
+}
+
+func joinFunc(args ...ugo.Object) (ugo.Object, error) {
+	return joinFuncEx(ugo.NewCall(nil, args))
+}
+
+func joinFuncEx(c ugo.Call) (ugo.Object, error) {
+	elems := make([]string, c.Len())
+	for i := range elems {
+		s, ok := c.Get(i).(ugo.String)
+		if !ok {
+			return ugo.Undefined, ugo.NewArgumentTypeError(
+				strconv.Itoa(i+1), "string", c.Get(i).TypeName())
+		}
+		elems[i] = string(s)
+	}
+	return ugo.String(path.Join(elems...)), nil
+}
+
+func matchFunc(pattern, name string) (ugo.Object, error) {
+	ok, err := path.Match(pattern, name)
+	if err != nil {
+		return ugo.Undefined, &ugo.Error{Message: err.Error(), Cause: err}
+	}
+	return ugo.Bool(ok), nil
+}
+
+func splitFunc(s string) ugo.Object {
+	dir, file := path.Split(s)
+	return ugo.Array{ugo.String(dir), ugo.String(file)}
+}
@@ -0,0 +1,95 @@
+package path_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ozanh/ugo"
+	. "github.com/ozanh/ugo/stdlib/path"
+)
+
+func TestScript(t *testing.T) {
+	catch := func(s string) string {
+		return fmt.Sprintf(`
+		path := import("path")
+		try {
+			return %s
+		} catch err {
+			return string(err)
+		}`, s)
+	}
+	wrongArgs := func(want, got int) String {
+		return String(ErrWrongNumArguments.NewError(
+			fmt.Sprintf("want=%d got=%d", want, got),
+		).String())
+	}
+	typeErr := func(pos, expected, got string) String {
+		return String(NewArgumentTypeError(pos, expected, got).String())
+	}
+	testCases := []struct {
+		s string
+		e Object
+	}{
+		{s: `path.Base("a/b/c.txt")`, e: String("c.txt")},
+		{s: `path.Base("a/b/")`, e: String("b")},
+		{s: `path.Base("")`, e: String(".")},
+		{s: `path.Base("///")`, e: String("/")},
+		{s: `path.Base()`, e: wrongArgs(1, 0)},
+
+		{s: `path.Clean("a//b/./c/..")`, e: String("a/b")},
+		{s: `path.Clean("/../a")`, e: String("/a")},
+		{s: `path.Clean("../../a/")`, e: String("../../a")},
+		{s: `path.Clean("")`, e: String(".")},
+
+		{s: `path.Dir("a/b/c.txt")`, e: String("a/b")},
+		{s: `path.Dir("c.txt")`, e: String(".")},
+		{s: `path.Dir("/")`, e: String("/")},
+
+		{s: `path.Ext("a/b/c.tar.gz")`, e: String(".gz")},
+		{s: `path.Ext("a.b/c")`, e: String("")},
+
+		{s: `path.IsAbs("/a")`, e: True},
+		{s: `path.IsAbs("a")`, e: False},
+
+		{s: `path.Join("a", "b", "c")`, e: String("a/b/c")},
+		{s: `path.Join("a", "", "c")`, e: String("a/c")},
+		{s: `path.Join("a/", "/b/", "../c")`, e: String("a/c")},
+		{s: `path.Join("/", "a")`, e: String("/a")},
+		{s: `path.Join("", "")`, e: String("")},
+		{s: `path.Join()`, e: String("")},
+		{s: `path.Join(...["a", "b"])`, e: String("a/b")},
+		{s: `path.Join("a", 1)`, e: typeErr("2", "string", "int")},
+
+		{s: `path.Match("*.txt", "a.txt")`, e: True},
+		{s: `path.Match("*.txt", "a/b.txt")`, e: False},
+		{s: `path.Match("a/*/c", "a/b/c")`, e: True},
+		{s: `path.Match("?.go", "a.go")`, e: True},
+		{s: `path.Match("?.go", "ab.go")`, e: False},
+		{s: `path.Match("[a-c]?", "bz")`, e: True},
+		{s: `path.Match("[", "a")`, e: String("error: syntax error in pattern")},
+		{s: `path.Match("a")`, e: wrongArgs(2, 1)},
+
+		{s: `path.Split("a/b/c.txt")`, e: Array{String("a/b/"), String("c.txt")}},
+		{s: `path.Split("c.txt")`, e: Array{String(""), String("c.txt")}},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.s, func(t *testing.T) {
+			expectRun(t, catch(tt.s), tt.e)
+		})
+	}
+}
+
+func expectRun(t *testing.T, script string, expected Object) {
+	t.Helper()
+	mm := NewModuleMap()
+	mm.AddBuiltinModule("path", Module)
+	c := DefaultCompilerOptions
+	c.ModuleMap = mm
+	bc, err := Compile([]byte(script), c)
+	require.NoError(t, err)
+	ret, err := NewVM(bc).Run(nil)
+	require.NoError(t, err)
+	require.Equal(t, expected, ret)
+}
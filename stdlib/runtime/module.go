@@ -0,0 +1,73 @@
+// Copyright (c) 2020-2023 Ozan Hacıbekiroğlu.
+// Use of this source code is governed by a MIT License
+// that can be found in the LICENSE file.
+
+// Package runtime provides runtime module implementing functions to get
+// memory statistics of the Go runtime and to run garbage collector for uGO
+// script language.
+package runtime
+
+import (
+	"runtime"
+
+	"github.com/ozanh/ugo"
+	"github.com/ozanh/ugo/stdlib"
+)
+
+// Module represents runtime module.
+var Module = map[string]ugo.Object{
+	// ugo:doc
+	// # runtime Module
+	//
+	// ## Functions
+	// MemStats() -> map
+	// Returns the memory allocator statistics of the Go runtime. Returned map
+	// has following keys with uint values: Alloc, TotalAlloc, Sys, Mallocs,
+	// Frees, HeapAlloc, HeapSys, HeapIdle, HeapInuse, HeapReleased,
+	// HeapObjects, StackInuse, StackSys, NextGC, LastGC, PauseTotalNs and
+	// NumGC. See https://golang.org/pkg/runtime/#MemStats for details.
+	"MemStats": &ugo.Function{
+		Name:    "MemStats",
+		Value:   stdlib.FuncPRO(memStatsFunc),
+		ValueEx: stdlib.FuncPROEx(memStatsFunc),
+	},
+	// ugo:doc
+	// GC() -> undefined
+	// Runs a garbage collection and blocks the caller until the garbage
+	// collection is complete.
+	"GC": &ugo.Function{
+		Name:    "GC",
+		Value:   stdlib.FuncPRO(gcFunc),
+		ValueEx: stdlib.FuncPROEx(gcFunc),
+	},
+}
+
+func memStatsFunc() ugo.Object {
+	var m runtime.MemStats
+	runtime.ReadMemStats(&m)
+
+	return ugo.Map{
+		"Alloc":        ugo.Uint(m.Alloc),
+		"TotalAlloc":   ugo.Uint(m.TotalAlloc),
+		"Sys":          ugo.Uint(m.Sys),
+		"Mallocs":      ugo.Uint(m.Mallocs),
+		"Frees":        ugo.Uint(m.Frees),
+		"HeapAlloc":    ugo.Uint(m.HeapAlloc),
+		"HeapSys":      ugo.Uint(m.HeapSys),
+		"HeapIdle":     ugo.Uint(m.HeapIdle),
+		"HeapInuse":    ugo.Uint(m.HeapInuse),
+		"HeapReleased": ugo.Uint(m.HeapReleased),
+		"HeapObjects":  ugo.Uint(m.HeapObjects),
+		"StackInuse":   ugo.Uint(m.StackInuse),
+		"StackSys":     ugo.Uint(m.StackSys),
+		"NextGC":       ugo.Uint(m.NextGC),
+		"LastGC":       ugo.Uint(m.LastGC),
+		"PauseTotalNs": ugo.Uint(m.PauseTotalNs),
+		"NumGC":        ugo.Uint(m.NumGC),
+	}
+}
+
+func gcFunc() ugo.Object {
+	runtime.GC()
+	return ugo.Undefined
+}
@@ -0,0 +1,55 @@
+package runtime_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ozanh/ugo"
+	. "github.com/ozanh/ugo/stdlib/runtime"
+)
+
+func TestMemStats(t *testing.T) {
+	ret := run(t, `return import("runtime").MemStats()`)
+	m, ok := ret.(Map)
+	require.True(t, ok, "%T", ret)
+
+	keys := []string{"Alloc", "TotalAlloc", "Sys", "Mallocs", "Frees",
+		"HeapAlloc", "HeapSys", "HeapIdle", "HeapInuse", "HeapReleased",
+		"HeapObjects", "StackInuse", "StackSys", "NextGC", "LastGC",
+		"PauseTotalNs", "NumGC"}
+	require.Equal(t, len(keys), len(m))
+	for _, k := range keys {
+		require.IsType(t, Uint(0), m[k], k)
+	}
+	require.NotZero(t, m["HeapAlloc"])
+	require.NotZero(t, m["Sys"])
+
+	_, err := Module["MemStats"].Call(Int(1))
+	require.ErrorIs(t, err, ErrWrongNumArguments)
+}
+
+func TestGC(t *testing.T) {
+	ret := run(t, `
+	runtime := import("runtime")
+	before := runtime.MemStats().NumGC
+	r := runtime.GC()
+	return [r, runtime.MemStats().NumGC > before]`)
+	require.Equal(t, Array{Undefined, True}, ret)
+
+	_, err := Module["GC"].Call(Int(1))
+	require.ErrorIs(t, err, ErrWrongNumArguments)
+}
+
+func run(t *testing.T, script string) Object {
+	t.Helper()
+	mm := NewModuleMap()
+	mm.AddBuiltinModule("runtime", Module)
+	c := DefaultCompilerOptions
+	c.ModuleMap = mm
+	bc, err := Compile([]byte(script), c)
+	require.NoError(t, err)
+	ret, err := NewVM(bc).Run(nil)
+	require.NoError(t, err)
+	return ret
+}
@@ -3,6 +3,7 @@ package stdlib
 //go:generate go run ../cmd/mkcallable -export -output zfuncs.go stdlib.go
 
 // time module IsTime
+// decimal module IsDecimal
 // json module Marshal, Quote, NoQuote, NoEscape
 //
 //ugo:callable func(o ugo.Object) (ret ugo.Object)
@@ -30,6 +31,7 @@ package stdlib
 //ugo:callable func(s string, i1 int) (ret ugo.Object)
 
 // time module Time, Now
+// hash module NewMD5, NewSHA1, NewSHA256, NewSHA512
 //
 //ugo:callable func() (ret ugo.Object)
 
@@ -38,6 +40,8 @@ package stdlib
 //ugo:callable func(i1 int64, i2 int64) (ret ugo.Object)
 
 // json module Unmarshal, RawMessage, Valid
+// hash module MD5, SHA1, SHA256, SHA512
+// encoding module IsValidUTF8
 //
 //ugo:callable func(b []byte) (ret ugo.Object)
 
@@ -74,3 +78,15 @@ package stdlib
 // misc. functions
 //
 //ugo:callable func(o ugo.Object, i int64) (ret ugo.Object, err error)
+
+// path module Match
+//
+//ugo:callable func(s1 string, s2 string) (ret ugo.Object, err error)
+
+// decimal module Decimal
+//
+//ugo:callable func(o ugo.Object) (ret ugo.Object, err error)
+
+// encoding module ToUTF8
+//
+//ugo:callable func(p []byte, s string) (ret ugo.Object)
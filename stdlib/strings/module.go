@@ -8,6 +8,7 @@
 package strings
 
 import (
+	"html"
 	"strconv"
 	"strings"
 	"unicode/utf8"
@@ -86,6 +87,25 @@ var Module = map[string]ugo.Object{
 		ValueEx: fieldsFuncInv,
 	},
 	// ugo:doc
+	// HTMLEscape(s string) -> string
+	// Escapes special characters like "<" to become "&lt;". It escapes only
+	// five such characters: <, >, &, ' and ".
+	"HTMLEscape": &ugo.Function{
+		Name:    "HTMLEscape",
+		Value:   stdlib.FuncPsRO(htmlEscapeFunc),
+		ValueEx: stdlib.FuncPsROEx(htmlEscapeFunc),
+	},
+	// ugo:doc
+	// HTMLUnescape(s string) -> string
+	// Unescapes entities like "&lt;" to become "<". It unescapes a larger
+	// range of entities than HTMLEscape escapes. For example, "&aacute;"
+	// unescapes to "á", as does "&#225;" and "&#xE1;".
+	"HTMLUnescape": &ugo.Function{
+		Name:    "HTMLUnescape",
+		Value:   stdlib.FuncPsRO(htmlUnescapeFunc),
+		ValueEx: stdlib.FuncPsROEx(htmlUnescapeFunc),
+	},
+	// ugo:doc
 	// HasPrefix(s string, prefix string) -> bool
 	// Reports whether the string s begins with prefix.
 	"HasPrefix": &ugo.Function{
@@ -260,6 +280,17 @@ var Module = map[string]ugo.Object{
 		ValueEx: replaceFunc,
 	},
 	// ugo:doc
+	// ShellQuote(s string) -> string
+	// Returns a shell-escaped version of s which can be safely used as a
+	// single argument in a POSIX shell command line. s is returned as is if
+	// it consists of only letters, digits and "_@%+=:,./-" characters,
+	// otherwise it is enclosed in single quotes.
+	"ShellQuote": &ugo.Function{
+		Name:    "ShellQuote",
+		Value:   stdlib.FuncPsRO(shellQuoteFunc),
+		ValueEx: stdlib.FuncPsROEx(shellQuoteFunc),
+	},
+	// ugo:doc
 	// Split(s string, sep string[, n int]) -> [string]
 	// Splits s into substrings separated by sep and returns an array of
 	// the substrings between those separators.
@@ -294,6 +325,26 @@ var Module = map[string]ugo.Object{
 		ValueEx: newSplitFunc(strings.SplitAfterN),
 	},
 	// ugo:doc
+	// SplitIter(s string|bytes, sep string|bytes) -> splitIterator
+	// Returns an iterable object yielding the substrings of s separated by sep
+	// in for-in loops without creating an array of all substrings, which is
+	// useful for large inputs. Keys are indices of substrings and values are
+	// substrings of the same type as s. Iterating the returned object yields
+	// the same elements as Split(s, sep) each time.
+	//
+	// ```go
+	// for i, line in strings.SplitIter(text, "\n") {
+	//   // ...
+	// }
+	// ```
+	"SplitIter": &ugo.Function{
+		Name: "SplitIter",
+		Value: func(args ...ugo.Object) (ugo.Object, error) {
+			return splitIterFunc(ugo.NewCall(nil, args))
+		},
+		ValueEx: splitIterFunc,
+	},
+	// ugo:doc
 	// Title(s string) -> string
 	// Deprecated: Returns a copy of the string s with all Unicode letters that
 	// begin words mapped to their Unicode title case.
@@ -483,6 +534,14 @@ func fieldsFuncInv(c ugo.Call) (ugo.Object, error) {
 	)
 }
 
+func htmlEscapeFunc(s string) ugo.Object {
+	return ugo.String(html.EscapeString(s))
+}
+
+func htmlUnescapeFunc(s string) ugo.Object {
+	return ugo.String(html.UnescapeString(s))
+}
+
 func hasPrefixFunc(s, prefix string) ugo.Object {
 	return ugo.Bool(strings.HasPrefix(s, prefix))
 }
@@ -624,6 +683,24 @@ func replaceFunc(c ugo.Call) (ugo.Object, error) {
 	return ugo.String(strings.Replace(s, old, news, n)), nil
 }
 
+func shellQuoteFunc(s string) ugo.Object {
+	if s == "" {
+		return ugo.String("''")
+	}
+	if strings.IndexFunc(s, isShellUnsafe) < 0 {
+		return ugo.String(s)
+	}
+	return ugo.String("'" + strings.ReplaceAll(s, "'", `'"'"'`) + "'")
+}
+
+func isShellUnsafe(r rune) bool {
+	switch {
+	case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9':
+		return false
+	}
+	return !strings.ContainsRune("_@%+=:,./-", r)
+}
+
 func titleFunc(s string) ugo.Object {
 	//lint:ignore SA1019 Keep it for backward compatibility.
 	return ugo.String(strings.Title(s)) //nolint staticcheck Keep it for backward compatibility
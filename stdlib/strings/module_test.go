@@ -2,6 +2,8 @@ package strings_test
 
 import (
 	"fmt"
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/require"
@@ -380,6 +382,18 @@ func TestScript(t *testing.T) {
 		{s: `strings.FieldsFunc("axbxcx", func(c){ return false })`,
 			e: Array{String("axbxcx")}},
 
+		{s: `strings.HTMLEscape()`, m: catch, e: wrongArgs(1, 0)},
+		{s: `strings.HTMLEscape(1, 2)`, m: catch, e: wrongArgs(1, 2)},
+		{s: `strings.HTMLEscape("")`, e: String("")},
+		{s: `strings.HTMLEscape("<a href='x'>\"b\" & c</a>")`,
+			e: String("&lt;a href=&#39;x&#39;&gt;&#34;b&#34; &amp; c&lt;/a&gt;")},
+		{s: `strings.HTMLUnescape()`, m: catch, e: wrongArgs(1, 0)},
+		{s: `strings.HTMLUnescape("&lt;b&gt; &amp;amp; &quot;&#39;&aacute;&#xE1;")`,
+			e: String("<b> &amp; \"'áá")},
+		{s: `strings.HTMLUnescape(strings.HTMLEscape("<'&\">x"))`,
+			e: String("<'&\">x")},
+		{s: `strings.HTMLUnescape("&unknown; & x")`, e: String("&unknown; & x")},
+
 		{s: `strings.HasPrefix()`, m: catch, e: wrongArgs(2, 0)},
 		{s: `strings.HasPrefix(1)`, m: catch, e: wrongArgs(2, 1)},
 		{s: `strings.HasPrefix(1, 2, 3)`, m: catch, e: wrongArgs(2, 3)},
@@ -562,6 +576,18 @@ func TestScript(t *testing.T) {
 		{s: `strings.Replace("abbc", "b", "a", 0)`, e: String("abbc")},
 		{s: `strings.Replace("abbc", "b", "a", 1)`, e: String("aabc")},
 
+		{s: `strings.ShellQuote()`, m: catch, e: wrongArgs(1, 0)},
+		{s: `strings.ShellQuote(1, 2)`, m: catch, e: wrongArgs(1, 2)},
+		{s: `strings.ShellQuote("")`, e: String("''")},
+		{s: `strings.ShellQuote("a-b_c/d.e=f@g:1,2+3%")`,
+			e: String("a-b_c/d.e=f@g:1,2+3%")},
+		{s: `strings.ShellQuote("a b")`, e: String("'a b'")},
+		{s: `strings.ShellQuote("$(rm -rf /); echo")`,
+			e: String("'$(rm -rf /); echo'")},
+		{s: `strings.ShellQuote("it's")`, e: String(`'it'"'"'s'`)},
+		{s: `strings.ShellQuote("<a>&\"b\"")`, e: String(`'<a>&"b"'`)},
+		{s: `strings.ShellQuote("çağrı")`, e: String("'çağrı'")},
+
 		{s: `strings.Split()`, m: catch, e: nwrongArgs(2, 3, 0)},
 		{s: `strings.Split(1)`, m: catch, e: nwrongArgs(2, 3, 1)},
 		{s: `strings.Split(1, 2, 3, 4)`, m: catch, e: nwrongArgs(2, 3, 4)},
@@ -713,6 +739,98 @@ func TestScript(t *testing.T) {
 	}
 }
 
+func TestSplitIter(t *testing.T) {
+	collect := `
+	param (s, sep)
+	strings := import("strings")
+	out := []
+	for i, v in strings.SplitIter(s, sep) {
+		if i != len(out) {
+			throw "invalid index"
+		}
+		out = append(out, v)
+	}
+	return [out, strings.Split(s, sep)]`
+
+	var large strings.Builder
+	for i := 0; i < 10000; i++ {
+		if i > 0 {
+			large.WriteString("\n")
+		}
+		large.WriteString("line ")
+		large.WriteString(strconv.Itoa(i))
+	}
+
+	for _, tt := range []struct{ s, sep string }{
+		{"a,b,c", ","},
+		{"a,b,c,", ","},
+		{",", ","},
+		{"", ","},
+		{"", ""},
+		{"abc", ""},
+		{"çağ九", ""},
+		{"a--b----c", "--"},
+		{"abc", "x"},
+		{large.String(), "\n"},
+	} {
+		ret, err := runScript(collect, String(tt.s), String(tt.sep))
+		require.NoError(t, err)
+		arr := ret.(Array)
+		require.Equal(t, arr[1], arr[0], "s=%q sep=%q", tt.s, tt.sep)
+	}
+	ret, err := runScript(collect, String(large.String()), String("\n"))
+	require.NoError(t, err)
+	require.Len(t, ret.(Array)[0], 10000)
+
+	expectRun(t, `
+	strings := import("strings")
+	out := []
+	for v in strings.SplitIter(bytes("a b"), " ") { out = append(out, v) }
+	return out`, Array{Bytes("a"), Bytes("b")})
+	expectRun(t, `
+	strings := import("strings")
+	it := strings.SplitIter("a.b", bytes("."))
+	n := 0
+	for v in it { n++ }
+	for v in it { n++ }
+	return [n, typeName(it), isIterable(it), string(it)]`,
+		Array{Int(4), String("splitIterator"), True, String("<splitIterator>")})
+	expectRun(t, `
+	strings := import("strings")
+	b := bytes("a,b")
+	it := strings.SplitIter(b, ",")
+	b[0] = 120
+	for v in it { return v }`, Bytes("a"))
+
+	catch := func(s string) string {
+		return fmt.Sprintf(`
+		strings := import("strings")
+		try {
+			return %s
+		} catch err {
+			return string(err)
+		}`, s)
+	}
+	expectRun(t, catch(`strings.SplitIter("a")`), String(
+		ErrWrongNumArguments.NewError("want=2 got=1").String()))
+	expectRun(t, catch(`strings.SplitIter(1, ",")`), String(
+		NewArgumentTypeError("1st", "string|bytes", "int").String()))
+	expectRun(t, catch(`strings.SplitIter("a", 1)`), String(
+		NewArgumentTypeError("2nd", "string|bytes", "int").String()))
+}
+
+func runScript(script string, args ...Object) (Object, error) {
+	mm := NewModuleMap()
+	mm.AddBuiltinModule("strings", Module)
+	c := DefaultCompilerOptions
+	c.ModuleMap = mm
+	bc, err := Compile([]byte(script), c)
+	if err != nil {
+		return nil, err
+	}
+	return NewVM(bc).Run(nil, args...)
+}
+
 func expectRun(t *testing.T, script string, expected Object) {
 	t.Helper()
 	mm := NewModuleMap()
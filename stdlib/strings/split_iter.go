@@ -0,0 +1,139 @@
+// Copyright (c) 2020-2023 Ozan Hacıbekiroğlu.
+// Use of this source code is governed by a MIT License
+// that can be found in the LICENSE file.
+
+package strings
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ozanh/ugo"
+)
+
+// SplitIterator is an iterable object yielding the substrings of a string or
+// bytes separated by a separator one by one without creating an array of all
+// substrings. It implements ugo.Object interface.
+type SplitIterator struct {
+	ugo.ObjectImpl
+	s       string
+	sep     string
+	isBytes bool
+}
+
+var _ ugo.Object = (*SplitIterator)(nil)
+
+// NewSplitIterator returns a new SplitIterator yielding the substrings of s
+// separated by sep like strings.Split. If isBytes is true, substrings are
+// yielded as ugo.Bytes values, otherwise as ugo.String values.
+func NewSplitIterator(s, sep string, isBytes bool) *SplitIterator {
+	return &SplitIterator{s: s, sep: sep, isBytes: isBytes}
+}
+
+// TypeName implements ugo.Object interface.
+func (*SplitIterator) TypeName() string {
+	return "splitIterator"
+}
+
+// String implements ugo.Object interface.
+func (*SplitIterator) String() string {
+	return "<splitIterator>"
+}
+
+// IsFalsy implements ugo.Object interface.
+func (*SplitIterator) IsFalsy() bool {
+	return false
+}
+
+// Equal implements ugo.Object interface.
+func (o *SplitIterator) Equal(right ugo.Object) bool {
+	v, ok := right.(*SplitIterator)
+	return ok && v == o
+}
+
+// CanIterate implements ugo.Object interface.
+func (*SplitIterator) CanIterate() bool {
+	return true
+}
+
+// Iterate implements ugo.Object interface. Each call returns a new iterator
+// starting from the first substring.
+func (o *SplitIterator) Iterate() ugo.Iterator {
+	return &splitIter{o: o, rest: o.s, i: -1}
+}
+
+type splitIter struct {
+	o    *SplitIterator
+	rest string
+	cur  string
+	i    int
+	done bool
+}
+
+var _ ugo.Iterator = (*splitIter)(nil)
+
+func (it *splitIter) Next() bool {
+	if it.done {
+		return false
+	}
+	it.i++
+	if it.o.sep == "" {
+		// split after each UTF-8 sequence like strings.Split
+		if it.rest == "" {
+			it.done = true
+			return false
+		}
+		_, size := utf8.DecodeRuneInString(it.rest)
+		it.cur, it.rest = it.rest[:size], it.rest[size:]
+		return true
+	}
+	if i := strings.Index(it.rest, it.o.sep); i >= 0 {
+		it.cur, it.rest = it.rest[:i], it.rest[i+len(it.o.sep):]
+		return true
+	}
+	it.cur, it.rest = it.rest, ""
+	it.done = true
+	return true
+}
+
+func (it *splitIter) Key() ugo.Object {
+	return ugo.Int(it.i)
+}
+
+func (it *splitIter) Value() ugo.Object {
+	if it.o.isBytes {
+		return ugo.Bytes(it.cur)
+	}
+	return ugo.String(it.cur)
+}
+
+func splitIterFunc(c ugo.Call) (ugo.Object, error) {
+	if err := c.CheckLen(2); err != nil {
+		return ugo.Undefined, err
+	}
+	var (
+		s       string
+		isBytes bool
+	)
+	switch v := c.Get(0).(type) {
+	case ugo.String:
+		s = string(v)
+	case ugo.Bytes:
+		// copy bytes so that changes to the source are not reflected
+		s, isBytes = string(v), true
+	default:
+		return ugo.Undefined, ugo.NewArgumentTypeError(
+			"1st", "string|bytes", v.TypeName())
+	}
+	var sep string
+	switch v := c.Get(1).(type) {
+	case ugo.String:
+		sep = string(v)
+	case ugo.Bytes:
+		sep = string(v)
+	default:
+		return ugo.Undefined, ugo.NewArgumentTypeError(
+			"2nd", "string|bytes", v.TypeName())
+	}
+	return NewSplitIterator(s, sep, isBytes), nil
+}
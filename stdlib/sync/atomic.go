@@ -0,0 +1,126 @@
+// Copyright (c) 2020-2023 Ozan Hacıbekiroğlu.
+// Use of this source code is governed by a MIT License
+// that can be found in the LICENSE file.
+
+package sync
+
+import (
+	"strconv"
+	"sync/atomic"
+
+	"github.com/ozanh/ugo"
+)
+
+// ugo:doc
+// ## Types
+// ### atomicInt
+//
+// Go Type
+//
+// ```go
+// // AtomicInt represents an integer which is updated atomically and
+// // implements ugo.Object interface.
+// type AtomicInt struct {
+//    ugo.ObjectImpl
+//    Value int64
+// }
+// ```
+
+// AtomicInt represents an integer which is updated atomically and implements
+// ugo.Object interface. Value must only be accessed with sync/atomic functions
+// while the object is in use.
+type AtomicInt struct {
+	ugo.ObjectImpl
+	Value int64
+}
+
+var _ ugo.NameCallerObject = (*AtomicInt)(nil)
+
+// TypeName implements ugo.Object interface.
+func (*AtomicInt) TypeName() string {
+	return "atomicInt"
+}
+
+// String implements ugo.Object interface.
+func (o *AtomicInt) String() string {
+	return "<atomicInt:" + strconv.FormatInt(o.load(), 10) + ">"
+}
+
+// IsFalsy implements ugo.Object interface.
+func (o *AtomicInt) IsFalsy() bool {
+	return o.load() == 0
+}
+
+// Equal implements ugo.Object interface.
+func (o *AtomicInt) Equal(right ugo.Object) bool {
+	v, ok := right.(*AtomicInt)
+	return ok && v == o
+}
+
+func (o *AtomicInt) load() int64 {
+	return atomic.LoadInt64(&o.Value)
+}
+
+// ugo:doc
+// #### atomicInt Methods
+//
+// | Method                      | Return Type |
+// |:----------------------------|:------------|
+// |.Add(delta int)              | int         |
+// |.Get()                       | int         |
+// |.CompareAndSwap(old, new int)| bool        |
+//
+// Add adds delta to the value and returns the new value. CompareAndSwap sets
+// the value to new if the current value is equal to old and reports whether
+// the value is swapped.
+
+// CallName implements ugo.NameCallerObject interface.
+func (o *AtomicInt) CallName(name string, c ugo.Call) (ugo.Object, error) {
+	fn, ok := atomicIntMethods[name]
+	if !ok {
+		return ugo.Undefined, ugo.ErrInvalidIndex.NewError(name)
+	}
+	return fn(o, &c)
+}
+
+var atomicIntMethods = map[string]func(*AtomicInt, *ugo.Call) (ugo.Object, error){
+	"Add": func(o *AtomicInt, c *ugo.Call) (ugo.Object, error) {
+		if err := c.CheckLen(1); err != nil {
+			return ugo.Undefined, err
+		}
+		delta, err := intArg(c, 0, "1st")
+		if err != nil {
+			return ugo.Undefined, err
+		}
+		return ugo.Int(atomic.AddInt64(&o.Value, delta)), nil
+	},
+	"Get": func(o *AtomicInt, c *ugo.Call) (ugo.Object, error) {
+		if err := c.CheckLen(0); err != nil {
+			return ugo.Undefined, err
+		}
+		return ugo.Int(o.load()), nil
+	},
+	"CompareAndSwap": func(o *AtomicInt, c *ugo.Call) (ugo.Object, error) {
+		if err := c.CheckLen(2); err != nil {
+			return ugo.Undefined, err
+		}
+		oldValue, err := intArg(c, 0, "1st")
+		if err != nil {
+			return ugo.Undefined, err
+		}
+		newValue, err := intArg(c, 1, "2nd")
+		if err != nil {
+			return ugo.Undefined, err
+		}
+		swapped := atomic.CompareAndSwapInt64(&o.Value, oldValue, newValue)
+		return ugo.Bool(swapped), nil
+	},
+}
+
+func intArg(c *ugo.Call, i int, pos string) (int64, error) {
+	v, ok := ugo.ToGoInt64(c.Get(i))
+	if !ok {
+		return 0, ugo.NewArgumentTypeError(pos, "int", c.Get(i).TypeName())
+	}
+	return v, nil
+}
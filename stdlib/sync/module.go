@@ -0,0 +1,85 @@
+// Copyright (c) 2020-2023 Ozan Hacıbekiroğlu.
+// Use of this source code is governed by a MIT License
+// that can be found in the LICENSE file.
+
+// Package sync provides sync module implementing synchronization primitives
+// for uGO script language. Objects of the module are safe for concurrent use
+// by functions run in different goroutines by the embedder.
+package sync
+
+import (
+	"github.com/ozanh/ugo"
+	"github.com/ozanh/ugo/stdlib"
+)
+
+// Module represents sync module.
+var Module = map[string]ugo.Object{
+	// ugo:doc
+	// # sync Module
+	//
+	// ## Functions
+	// Counter() -> atomicInt
+	// Returns a new atomicInt with initial value 0.
+	"Counter": &ugo.Function{
+		Name:    "Counter",
+		Value:   stdlib.FuncPRO(counterFunc),
+		ValueEx: stdlib.FuncPROEx(counterFunc),
+	},
+	// ugo:doc
+	// AtomicInt(n int) -> atomicInt
+	// Returns a new atomicInt with initial value n.
+	"AtomicInt": &ugo.Function{
+		Name:    "AtomicInt",
+		Value:   stdlib.FuncPi64RO(atomicIntFunc),
+		ValueEx: stdlib.FuncPi64ROEx(atomicIntFunc),
+	},
+	// ugo:doc
+	// Mutex() -> mutex
+	// Returns a new unlocked mutex.
+	"Mutex": &ugo.Function{
+		Name:    "Mutex",
+		Value:   stdlib.FuncPRO(mutexFunc),
+		ValueEx: stdlib.FuncPROEx(mutexFunc),
+	},
+	// ugo:doc
+	// Once(fn callable) -> once
+	// Returns a new once object which calls fn at most once with its Do
+	// method.
+	"Once": &ugo.Function{
+		Name:    "Once",
+		Value:   stdlib.FuncPOROe(onceFunc),
+		ValueEx: stdlib.FuncPOROeEx(onceFunc),
+	},
+	// ugo:doc
+	// WaitGroup() -> waitGroup
+	// Returns a new waitGroup with zero counter.
+	"WaitGroup": &ugo.Function{
+		Name:    "WaitGroup",
+		Value:   stdlib.FuncPRO(waitGroupFunc),
+		ValueEx: stdlib.FuncPROEx(waitGroupFunc),
+	},
+}
+
+func counterFunc() ugo.Object {
+	return &AtomicInt{}
+}
+
+func atomicIntFunc(n int64) ugo.Object {
+	return &AtomicInt{Value: n}
+}
+
+func mutexFunc() ugo.Object {
+	return NewMutex()
+}
+
+func onceFunc(fn ugo.Object) (ugo.Object, error) {
+	if !fn.CanCall() {
+		return ugo.Undefined, ugo.NewArgumentTypeError(
+			"1st", "callable", fn.TypeName())
+	}
+	return NewOnce(fn), nil
+}
+
+func waitGroupFunc() ugo.Object {
+	return NewWaitGroup()
+}
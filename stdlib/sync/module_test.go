@@ -0,0 +1,350 @@
+package sync_test
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ozanh/ugo"
+	. "github.com/ozanh/ugo/stdlib/sync"
+)
+
+func TestModule(t *testing.T) {
+	testCases := []struct {
+		s string
+		e Object
+	}{
+		{s: `sync.Counter().Get()`, e: Int(0)},
+		{s: `sync.AtomicInt(5).Get()`, e: Int(5)},
+		{s: `sync.AtomicInt(-5).Add(2)`, e: Int(-3)},
+		{s: `func() { c := sync.Counter(); c.Add(3); c.Add(-1); return c.Get() }()`,
+			e: Int(2)},
+		{s: `sync.AtomicInt(1).CompareAndSwap(1, 2)`, e: True},
+		{s: `sync.AtomicInt(1).CompareAndSwap(0, 2)`, e: False},
+		{s: `func() {
+			c := sync.AtomicInt(1)
+			c.CompareAndSwap(0, 3)
+			c.CompareAndSwap(1, 2)
+			return c.Get()
+		}()`, e: Int(2)},
+		{s: `typeName(sync.Counter())`, e: String("atomicInt")},
+		{s: `string(sync.AtomicInt(7))`, e: String("<atomicInt:7>")},
+		{s: `bool(sync.Counter())`, e: False},
+		{s: `bool(sync.AtomicInt(1))`, e: True},
+		{s: `func() { c := sync.Counter(); return c == c }()`, e: True},
+		{s: `sync.Counter() == sync.Counter()`, e: False},
+
+		{s: `sync.AtomicInt()`, e: String(ErrWrongNumArguments.NewError(
+			"want=1 got=0").String())},
+		{s: `sync.AtomicInt("x")`, e: String(NewArgumentTypeError(
+			"1st", "int", "string").String())},
+		{s: `sync.Counter().Add()`, e: String(ErrWrongNumArguments.NewError(
+			"want=1 got=0").String())},
+		{s: `sync.Counter().Add([])`, e: String(NewArgumentTypeError(
+			"1st", "int", "array").String())},
+		{s: `sync.Counter().Get(1)`, e: String(ErrWrongNumArguments.NewError(
+			"want=0 got=1").String())},
+		{s: `sync.Counter().CompareAndSwap(0, {})`, e: String(
+			NewArgumentTypeError("2nd", "int", "map").String())},
+		{s: `sync.Counter().Foo()`, e: String(
+			ErrInvalidIndex.NewError("Foo").String())},
+
+		{s: `typeName(sync.Mutex())`, e: String("mutex")},
+		{s: `string(sync.Mutex())`, e: String("<mutex>")},
+		{s: `sync.Mutex().Lock()`, e: Undefined},
+		{s: `func() { mu := sync.Mutex(); mu.Lock(); return mu.Unlock() }()`,
+			e: Undefined},
+		{s: `func() {
+			mu := sync.Mutex()
+			mu.Lock()
+			mu.Unlock()
+			mu.Lock()
+			mu.Unlock()
+			return mu == mu
+		}()`, e: True},
+		{s: `sync.Mutex().Unlock()`, e: String("error: unlock of unlocked mutex")},
+		{s: `sync.Mutex().Lock(1)`, e: String(ErrWrongNumArguments.NewError(
+			"want=0 got=1").String())},
+		{s: `sync.Mutex().Foo()`, e: String(
+			ErrInvalidIndex.NewError("Foo").String())},
+
+		{s: `typeName(sync.Once(func() {}))`, e: String("once")},
+		{s: `string(sync.Once(func() {}))`, e: String("<once>")},
+		{s: `sync.Once(func() { return 3 }).Do()`, e: Int(3)},
+		{s: `sync.Once(func() {}).Do()`, e: Undefined},
+		{s: `sync.Once(len).Do()`, e: String(ErrWrongNumArguments.NewError(
+			"want=1 got=0").String())},
+		{s: `func() {
+			calls := 0
+			o := sync.Once(func() { calls++; return calls * 10 })
+			return [o.Done(), o.Do(), o.Do(), o.Done(), calls]
+		}()`, e: Array{False, Int(10), Int(10), True, Int(1)}},
+		{s: `func() {
+			calls := 0
+			o := sync.Once(func() { calls++; throw "x" })
+			r := []
+			for i := 0; i < 2; i++ {
+				try { o.Do() } catch err { r = append(r, string(err)) }
+			}
+			return append(r, calls)
+		}()`, e: Array{String("error: x"), String("error: x"), Int(1)}},
+		{s: `sync.Once()`, e: String(ErrWrongNumArguments.NewError(
+			"want=1 got=0").String())},
+		{s: `sync.Once(1)`, e: String(NewArgumentTypeError(
+			"1st", "callable", "int").String())},
+		{s: `sync.Once(func() {}).Do(1)`, e: String(
+			ErrWrongNumArguments.NewError("want=0 got=1").String())},
+		{s: `sync.Once(func() {}).Foo()`, e: String(
+			ErrInvalidIndex.NewError("Foo").String())},
+
+		{s: `typeName(sync.WaitGroup())`, e: String("waitGroup")},
+		{s: `string(sync.WaitGroup())`, e: String("<waitGroup>")},
+		{s: `sync.WaitGroup().Wait()`, e: Undefined},
+		{s: `func() {
+			wg := sync.WaitGroup()
+			wg.Add(2)
+			wg.Done()
+			wg.Add(-1)
+			return wg.Wait()
+		}()`, e: Undefined},
+		{s: `sync.WaitGroup().Done()`, e: String(
+			"error: negative waitGroup counter")},
+		{s: `sync.WaitGroup().Add(-1)`, e: String(
+			"error: negative waitGroup counter")},
+		{s: `sync.WaitGroup().Add()`, e: String(ErrWrongNumArguments.NewError(
+			"want=1 got=0").String())},
+		{s: `sync.WaitGroup().Add("")`, e: String(NewArgumentTypeError(
+			"1st", "int", "string").String())},
+		{s: `sync.WaitGroup().Wait(1)`, e: String(ErrWrongNumArguments.NewError(
+			"want=0 got=1").String())},
+		{s: `sync.WaitGroup().Foo()`, e: String(
+			ErrInvalidIndex.NewError("Foo").String())},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.s, func(t *testing.T) {
+			expectRun(t, fmt.Sprintf(`
+			sync := import("sync")
+			try {
+				return %s
+			} catch err {
+				return string(err)
+			}`, tt.s), nil, tt.e)
+		})
+	}
+}
+
+func TestAtomicIntConcurrent(t *testing.T) {
+	const (
+		workers = 8
+		loops   = 1000
+	)
+	bc := compile(t, `
+	param (counter, cas)
+	for i := 0; i < 1000; i++ {
+		counter.Add(1)
+		for {
+			v := cas.Get()
+			if cas.CompareAndSwap(v, v + 2) {
+				break
+			}
+		}
+	}`)
+
+	counter := &AtomicInt{}
+	cas := &AtomicInt{Value: 10}
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := NewVM(bc).Run(nil, counter, cas)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+	require.Equal(t, int64(workers*loops), counter.Value)
+	require.Equal(t, int64(10+2*workers*loops), cas.Value)
+}
+
+func TestMutexConcurrent(t *testing.T) {
+	const (
+		workers = 8
+		loops   = 1000
+	)
+	bc := compile(t, `
+	param (mu, m)
+	for i := 0; i < 1000; i++ {
+		mu.Lock()
+		m.n += 1
+		m[string(m.n)] = true
+		mu.Unlock()
+	}`)
+
+	mu := NewMutex()
+	m := Map{"n": Int(0)}
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, err := NewVM(bc).Run(nil, mu, m)
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+	require.Equal(t, Int(workers*loops), m["n"])
+	require.Equal(t, workers*loops+1, len(m))
+}
+
+func TestMutexAbort(t *testing.T) {
+	bc := compile(t, `param mu; mu.Lock(); return "locked"`)
+	mu := NewMutex()
+	ret, err := NewVM(bc).Run(nil, mu)
+	require.NoError(t, err)
+	require.Equal(t, String("locked"), ret)
+
+	vm := NewVM(bc)
+	done := make(chan error, 1)
+	go func() {
+		_, err := vm.Run(nil, mu)
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	vm.Abort()
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, ErrVMAborted)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Lock is not stopped by Abort")
+	}
+}
+
+func TestOnceConcurrent(t *testing.T) {
+	const workers = 8
+	var calls int64
+	once := NewOnce(&Function{
+		Value: func(args ...Object) (Object, error) {
+			time.Sleep(10 * time.Millisecond)
+			return Int(atomic.AddInt64(&calls, 1)), nil
+		},
+	})
+	bc := compile(t, `param once; return once.Do()`)
+
+	var wg sync.WaitGroup
+	rets := make(chan Object, workers)
+	errs := make(chan error, workers)
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ret, err := NewVM(bc).Run(nil, once)
+			rets <- ret
+			errs <- err
+		}()
+	}
+	wg.Wait()
+	close(rets)
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+	for ret := range rets {
+		require.Equal(t, Int(1), ret)
+	}
+	require.Equal(t, int64(1), atomic.LoadInt64(&calls))
+}
+
+func TestWaitGroup(t *testing.T) {
+	worker := compile(t, `
+	param (wg, results, i)
+	results[string(i)] = i * i
+	wg.Done()`)
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	spawn := &Function{
+		Value: func(args ...Object) (Object, error) {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				_, err := NewVM(worker).Run(nil, args...)
+				errs <- err
+			}()
+			return Undefined, nil
+		},
+	}
+
+	bc := compile(t, `
+	param spawn
+	sync := import("sync")
+	results := syncMap({})
+	wg := sync.WaitGroup()
+	wg.Add(10)
+	for i := 0; i < 10; i++ {
+		spawn(wg, results, i)
+	}
+	wg.Wait()
+	total := 0
+	for _, v in results {
+		total += v
+	}
+	return [len(results), total]`)
+	ret, err := NewVM(bc).Run(nil, spawn)
+	require.NoError(t, err)
+	require.Equal(t, Array{Int(10), Int(285)}, ret)
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		require.NoError(t, err)
+	}
+}
+
+func TestWaitGroupAbort(t *testing.T) {
+	bc := compile(t, `param wg; wg.Add(1); wg.Wait()`)
+	vm := NewVM(bc)
+	done := make(chan error, 1)
+	go func() {
+		_, err := vm.Run(nil, NewWaitGroup())
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	vm.Abort()
+	select {
+	case err := <-done:
+		require.ErrorIs(t, err, ErrVMAborted)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Wait is not stopped by Abort")
+	}
+}
+
+func compile(t *testing.T, script string) *Bytecode {
+	t.Helper()
+	mm := NewModuleMap()
+	mm.AddBuiltinModule("sync", Module)
+	c := DefaultCompilerOptions
+	c.ModuleMap = mm
+	bc, err := Compile([]byte(script), c)
+	require.NoError(t, err)
+	return bc
+}
+
+func expectRun(t *testing.T, script string, args []Object, expected Object) {
+	t.Helper()
+	ret, err := NewVM(compile(t, script)).Run(nil, args...)
+	require.NoError(t, err)
+	require.Equal(t, expected, ret)
+}
@@ -0,0 +1,236 @@
+// Copyright (c) 2020-2023 Ozan Hacıbekiroğlu.
+// Use of this source code is governed by a MIT License
+// that can be found in the LICENSE file.
+
+package sync
+
+import (
+	"sync/atomic"
+	"time"
+
+	"github.com/ozanh/ugo"
+)
+
+// abortCheckInterval is the interval to check whether the VM is aborted while
+// waiting for a lock.
+const abortCheckInterval = 10 * time.Millisecond
+
+// lock acquires the lock represented by the given channel. If the lock cannot
+// be acquired immediately, it periodically checks whether the VM is aborted
+// and returns ErrVMAborted to avoid deadlocks on cancellation.
+func lock(ch chan struct{}, vm *ugo.VM) error {
+	select {
+	case ch <- struct{}{}:
+		return nil
+	default:
+	}
+
+	ticker := time.NewTicker(abortCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case ch <- struct{}{}:
+			return nil
+		case <-ticker.C:
+			if vm != nil && vm.Aborted() {
+				return ugo.ErrVMAborted
+			}
+		}
+	}
+}
+
+// ugo:doc
+// ### mutex
+//
+// Go Type
+//
+// ```go
+// // Mutex represents a mutual exclusion lock and implements ugo.Object
+// // interface.
+// type Mutex struct {
+//    ugo.ObjectImpl
+//    // contains filtered or unexported fields
+// }
+// ```
+
+// Mutex represents a mutual exclusion lock and implements ugo.Object
+// interface. Use NewMutex to create a Mutex.
+type Mutex struct {
+	ugo.ObjectImpl
+	ch chan struct{}
+}
+
+var _ ugo.NameCallerObject = (*Mutex)(nil)
+
+// NewMutex returns a new unlocked Mutex.
+func NewMutex() *Mutex {
+	return &Mutex{ch: make(chan struct{}, 1)}
+}
+
+// TypeName implements ugo.Object interface.
+func (*Mutex) TypeName() string {
+	return "mutex"
+}
+
+// String implements ugo.Object interface.
+func (*Mutex) String() string {
+	return "<mutex>"
+}
+
+// IsFalsy implements ugo.Object interface.
+func (o *Mutex) IsFalsy() bool {
+	return o.ch == nil
+}
+
+// Equal implements ugo.Object interface.
+func (o *Mutex) Equal(right ugo.Object) bool {
+	v, ok := right.(*Mutex)
+	return ok && v == o
+}
+
+// ugo:doc
+// #### mutex Methods
+//
+// | Method                      | Return Type |
+// |:----------------------------|:------------|
+// |.Lock()                      | undefined   |
+// |.Unlock()                    | undefined   |
+//
+// Lock blocks until the mutex is available. If the VM is aborted while
+// waiting, Lock throws a VMAbortedError. Unlocking an unlocked mutex throws an
+// error.
+
+// CallName implements ugo.NameCallerObject interface.
+func (o *Mutex) CallName(name string, c ugo.Call) (ugo.Object, error) {
+	switch name {
+	case "Lock":
+		if err := c.CheckLen(0); err != nil {
+			return ugo.Undefined, err
+		}
+		return ugo.Undefined, lock(o.ch, c.VM())
+	case "Unlock":
+		if err := c.CheckLen(0); err != nil {
+			return ugo.Undefined, err
+		}
+		select {
+		case <-o.ch:
+			return ugo.Undefined, nil
+		default:
+			return ugo.Undefined, &ugo.Error{
+				Message: "unlock of unlocked mutex",
+			}
+		}
+	}
+	return ugo.Undefined, ugo.ErrInvalidIndex.NewError(name)
+}
+
+// ugo:doc
+// ### once
+//
+// Go Type
+//
+// ```go
+// // Once represents an object calling a function at most once and
+// // implements ugo.Object interface.
+// type Once struct {
+//    ugo.ObjectImpl
+//    Fn ugo.Object
+//    // contains filtered or unexported fields
+// }
+// ```
+
+// Once represents an object calling a function at most once and implements
+// ugo.Object interface. Use NewOnce to create a Once.
+type Once struct {
+	ugo.ObjectImpl
+	Fn ugo.Object
+
+	done   uint32
+	ch     chan struct{}
+	result ugo.Object
+	err    error
+}
+
+var _ ugo.NameCallerObject = (*Once)(nil)
+
+// NewOnce returns a new Once calling fn at most once.
+func NewOnce(fn ugo.Object) *Once {
+	return &Once{Fn: fn, ch: make(chan struct{}, 1)}
+}
+
+// TypeName implements ugo.Object interface.
+func (*Once) TypeName() string {
+	return "once"
+}
+
+// String implements ugo.Object interface.
+func (*Once) String() string {
+	return "<once>"
+}
+
+// IsFalsy implements ugo.Object interface.
+func (o *Once) IsFalsy() bool {
+	return o.Fn == nil
+}
+
+// Equal implements ugo.Object interface.
+func (o *Once) Equal(right ugo.Object) bool {
+	v, ok := right.(*Once)
+	return ok && v == o
+}
+
+// ugo:doc
+// #### once Methods
+//
+// | Method                      | Return Type |
+// |:----------------------------|:------------|
+// |.Do()                        | any         |
+// |.Done()                      | bool        |
+//
+// Do calls the function only for the first time it is called and returns its
+// result, subsequent calls return the same result without calling the
+// function. If the function throws an error, the error is thrown by all Do
+// calls. Concurrent Do calls wait until the first call returns, waiting is
+// stopped if the VM is aborted. Done reports whether the function is called.
+
+// CallName implements ugo.NameCallerObject interface.
+func (o *Once) CallName(name string, c ugo.Call) (ugo.Object, error) {
+	switch name {
+	case "Do":
+		if err := c.CheckLen(0); err != nil {
+			return ugo.Undefined, err
+		}
+		return o.do(c.VM())
+	case "Done":
+		if err := c.CheckLen(0); err != nil {
+			return ugo.Undefined, err
+		}
+		return ugo.Bool(atomic.LoadUint32(&o.done) == 1), nil
+	}
+	return ugo.Undefined, ugo.ErrInvalidIndex.NewError(name)
+}
+
+func (o *Once) do(vm *ugo.VM) (ugo.Object, error) {
+	if atomic.LoadUint32(&o.done) == 1 {
+		return o.result, o.err
+	}
+	if vm == nil {
+		if _, ok := o.Fn.(*ugo.CompiledFunction); ok {
+			return ugo.Undefined, ugo.ErrNotCallable
+		}
+	}
+
+	if err := lock(o.ch, vm); err != nil {
+		return ugo.Undefined, err
+	}
+	defer func() { <-o.ch }()
+
+	if o.done == 0 {
+		defer atomic.StoreUint32(&o.done, 1)
+		o.result, o.err = ugo.NewInvoker(vm, o.Fn).Invoke()
+		if o.result == nil {
+			o.result = ugo.Undefined
+		}
+	}
+	return o.result, o.err
+}
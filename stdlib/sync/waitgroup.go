@@ -0,0 +1,144 @@
+// Copyright (c) 2020-2023 Ozan Hacıbekiroğlu.
+// Use of this source code is governed by a MIT License
+// that can be found in the LICENSE file.
+
+package sync
+
+import (
+	gosync "sync"
+	"time"
+
+	"github.com/ozanh/ugo"
+)
+
+// ugo:doc
+// ### waitGroup
+//
+// Go Type
+//
+// ```go
+// // WaitGroup waits for a collection of functions to finish and implements
+// // ugo.Object interface.
+// type WaitGroup struct {
+//    ugo.ObjectImpl
+//    // contains filtered or unexported fields
+// }
+// ```
+
+// WaitGroup waits for a collection of functions to finish and implements
+// ugo.Object interface. Zero value is ready to use.
+type WaitGroup struct {
+	ugo.ObjectImpl
+	mu gosync.Mutex
+	n  int64
+	ch chan struct{}
+}
+
+var _ ugo.NameCallerObject = (*WaitGroup)(nil)
+
+// NewWaitGroup returns a new WaitGroup.
+func NewWaitGroup() *WaitGroup {
+	return &WaitGroup{}
+}
+
+// TypeName implements ugo.Object interface.
+func (*WaitGroup) TypeName() string {
+	return "waitGroup"
+}
+
+// String implements ugo.Object interface.
+func (*WaitGroup) String() string {
+	return "<waitGroup>"
+}
+
+// IsFalsy implements ugo.Object interface.
+func (*WaitGroup) IsFalsy() bool {
+	return false
+}
+
+// Equal implements ugo.Object interface.
+func (o *WaitGroup) Equal(right ugo.Object) bool {
+	v, ok := right.(*WaitGroup)
+	return ok && v == o
+}
+
+// ugo:doc
+// #### waitGroup Methods
+//
+// | Method                      | Return Type |
+// |:----------------------------|:------------|
+// |.Add(delta int)              | undefined   |
+// |.Done()                      | undefined   |
+// |.Wait()                      | undefined   |
+//
+// Add adds delta, which may be negative, to the counter. Done decrements the
+// counter by one. Wait blocks until the counter is zero. If the VM is aborted
+// while waiting, Wait throws a VMAbortedError. If the counter becomes
+// negative, Add and Done throw an error.
+
+// CallName implements ugo.NameCallerObject interface.
+func (o *WaitGroup) CallName(name string, c ugo.Call) (ugo.Object, error) {
+	switch name {
+	case "Add":
+		if err := c.CheckLen(1); err != nil {
+			return ugo.Undefined, err
+		}
+		delta, err := intArg(&c, 0, "1st")
+		if err != nil {
+			return ugo.Undefined, err
+		}
+		return ugo.Undefined, o.add(delta)
+	case "Done":
+		if err := c.CheckLen(0); err != nil {
+			return ugo.Undefined, err
+		}
+		return ugo.Undefined, o.add(-1)
+	case "Wait":
+		if err := c.CheckLen(0); err != nil {
+			return ugo.Undefined, err
+		}
+		return ugo.Undefined, o.wait(c.VM())
+	}
+	return ugo.Undefined, ugo.ErrInvalidIndex.NewError(name)
+}
+
+func (o *WaitGroup) add(delta int64) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if o.n+delta < 0 {
+		return &ugo.Error{Message: "negative waitGroup counter"}
+	}
+	o.n += delta
+	if o.n == 0 && o.ch != nil {
+		close(o.ch)
+		o.ch = nil
+	}
+	return nil
+}
+
+func (o *WaitGroup) wait(vm *ugo.VM) error {
+	o.mu.Lock()
+	if o.n == 0 {
+		o.mu.Unlock()
+		return nil
+	}
+	if o.ch == nil {
+		o.ch = make(chan struct{})
+	}
+	ch := o.ch
+	o.mu.Unlock()
+
+	ticker := time.NewTicker(abortCheckInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ch:
+			return nil
+		case <-ticker.C:
+			if vm != nil && vm.Aborted() {
+				return ugo.ErrVMAborted
+			}
+		}
+	}
+}
@@ -8,7 +8,9 @@
 package time
 
 import (
+	"errors"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ozanh/ugo"
@@ -224,8 +226,31 @@ var Module = map[string]ugo.Object{
 		ValueEx: sleepFunc,
 	},
 	// ugo:doc
+	// Tick(interval int) -> ticker
+	// Returns a new ticker delivering the current time after each interval
+	// duration, which is received with `.Recv()` method of the ticker.
+	// Interval must be greater than zero. Ticker must be stopped with
+	// `.Stop()` method or `StopTick` function to release its resources.
+	"Tick": &ugo.Function{
+		Name:    "Tick",
+		Value:   tickFunc,
+		ValueEx: tickFuncEx,
+	},
+	// ugo:doc
+	// StopTick(t ticker) -> undefined
+	// Stops the ticker t, no more ticks are delivered after StopTick returns.
+	"StopTick": &ugo.Function{
+		Name:    "StopTick",
+		Value:   stdlib.FuncPOROe(stopTickFunc),
+		ValueEx: stdlib.FuncPOROeEx(stopTickFunc),
+	},
+	// ugo:doc
 	// ParseDuration(s string) -> duration int
-	// Parses duration s and returns duration as int or error.
+	// Parses duration s and returns duration as int or error. Durations are
+	// int values in nanoseconds so they support arithmetic and comparison
+	// operators of int, e.g. `2 * time.ParseDuration("30m")` equals to
+	// `time.Hour`. Note that multiplying with a float results in a float which
+	// must be converted to int with `int` builtin to be used as a duration.
 	"ParseDuration": &ugo.Function{
 		Name:    "ParseDuration",
 		Value:   stdlib.FuncPsROe(parseDurationFunc),
@@ -258,7 +283,10 @@ var Module = map[string]ugo.Object{
 	},
 	// ugo:doc
 	// LoadLocation(name string) -> location
-	// Returns the Location with the given name.
+	// Returns the Location with the given name. Name is "UTC" or "" for UTC,
+	// "Local" for local time zone, otherwise it is taken to be a location name
+	// of the IANA Time Zone database like "Europe/Istanbul". An error is
+	// thrown if the location cannot be found.
 	"LoadLocation": &ugo.Function{
 		Name:    "LoadLocation",
 		Value:   stdlib.FuncPsROe(loadLocationFunc),
@@ -325,6 +353,16 @@ var Module = map[string]ugo.Object{
 		ValueEx: parseFuncEx,
 	},
 	// ugo:doc
+	// ParseAny(value string, ...layouts string) -> time
+	// Parses a formatted string with given layouts in order and returns the
+	// time value of the first successful parse. If value cannot be parsed
+	// with any of the layouts, an error listing all parse errors is thrown.
+	"ParseAny": &ugo.Function{
+		Name:    "ParseAny",
+		Value:   parseAnyFunc,
+		ValueEx: parseAnyFuncEx,
+	},
+	// ugo:doc
 	// Unix(sec int[, nsec int]) -> time
 	// Returns the local time corresponding to the given Unix time,
 	// sec seconds and nsec nanoseconds since January 1, 1970 UTC.
@@ -607,6 +645,50 @@ func parseFuncEx(c ugo.Call) (ugo.Object, error) {
 	return &Time{Value: tm}, nil
 }
 
+func parseAnyFunc(args ...ugo.Object) (ugo.Object, error) {
+	return parseAnyFuncEx(ugo.NewCall(nil, args))
+}
+
+func parseAnyFuncEx(c ugo.Call) (ugo.Object, error) {
+	size := c.Len()
+	if size < 2 {
+		return ugo.Undefined, ugo.ErrWrongNumArguments.NewError(
+			"want>=2 got=" + strconv.Itoa(size))
+	}
+	value, ok := ugo.ToGoString(c.Get(0))
+	if !ok {
+		return newArgTypeErr("1st", "string", c.Get(0).TypeName())
+	}
+
+	msgs := make([]string, 0, size-1)
+	for i := 1; i < size; i++ {
+		layout, ok := ugo.ToGoString(c.Get(i))
+		if !ok {
+			pos := strconv.Itoa(i + 1)
+			switch n := (i + 1) % 100; {
+			case n >= 11 && n <= 13:
+				pos += "th"
+			case n%10 == 1:
+				pos += "st"
+			case n%10 == 2:
+				pos += "nd"
+			case n%10 == 3:
+				pos += "rd"
+			default:
+				pos += "th"
+			}
+			return newArgTypeErr(pos, "string", c.Get(i).TypeName())
+		}
+		tm, err := time.Parse(layout, value)
+		if err == nil {
+			return &Time{Value: tm}, nil
+		}
+		msgs = append(msgs, err.Error())
+	}
+	return ugo.Undefined, errors.New("no layout matches: " +
+		strings.Join(msgs, "; "))
+}
+
 func unixFunc(args ...ugo.Object) (ugo.Object, error) {
 	return unixFuncEx(ugo.NewCall(nil, args))
 }
@@ -3,7 +3,10 @@ package time_test
 import (
 	"bytes"
 	"encoding/gob"
+	"errors"
 	"fmt"
+	"runtime"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -580,6 +583,26 @@ func TestScript(t *testing.T) {
 		nil, String("error: parsing time \"1\": extra text: \"1\""))
 	expectRun(t, catch(`time.Parse("", "", 1)`),
 		nil, typeErr("3rd", "location", "int"))
+	expectRun(t, catch(`time.ParseAny("2021-03-04T05:06:07Z", time.RFC3339)`),
+		nil, &Time{Value: time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)})
+	expectRun(t, catch(`time.ParseAny("04/03/2021 05:06",
+		time.RFC3339, "02/01/2006 15:04", time.Kitchen)`),
+		nil, &Time{Value: time.Date(2021, 3, 4, 5, 6, 0, 0, time.UTC)})
+	expectRun(t, catch(`time.ParseAny("2021-03-04T05:06:07Z",
+		...["2006-01-02", time.RFC3339])`),
+		nil, &Time{Value: time.Date(2021, 3, 4, 5, 6, 7, 0, time.UTC)})
+	expectRun(t, catch(`time.ParseAny("x", "2006-01-02", time.Kitchen)`),
+		nil, String(`error: no layout matches: `+
+			`parsing time "x" as "2006-01-02": cannot parse "x" as "2006"; `+
+			`parsing time "x" as "3:04PM": cannot parse "x" as "3"`))
+	expectRun(t, catch(`time.ParseAny("x")`), nil, String(
+		ErrWrongNumArguments.NewError("want>=2 got=1").String()))
+	expectRun(t, catch(`time.ParseAny(undefined, "")`),
+		nil, typeErr("1st", "string", "undefined"))
+	expectRun(t, catch(`time.ParseAny("", "2006", undefined)`),
+		nil, typeErr("3rd", "string", "undefined"))
+	expectRun(t, catch(`time.ParseAny("", undefined)`),
+		nil, typeErr("2nd", "string", "undefined"))
 	expectRun(t, catch(`time.Unix("")`),
 		nil, typeErr("1st", "int", "string"))
 	expectRun(t, catch(`time.Unix(1, "")`),
@@ -723,6 +746,32 @@ func TestScript(t *testing.T) {
 	expectRun(t, catch(`time.Time().In(1, 2)`), nil, nwrongArgs(1, -1, 2))
 	expectRun(t, catch(`time.Time().In(undefined)`), nil, typeErr("1st", "location", "undefined"))
 
+	// durations
+	expectRun(t, `time := import("time")
+	return time.ParseDuration("1h30m15.5s")`, nil,
+		Int(time.Hour+30*time.Minute+15*time.Second+500*time.Millisecond))
+	expectRun(t, `time := import("time")
+	return 2 * time.ParseDuration("30m") == time.Hour`, nil, True)
+	expectRun(t, `time := import("time")
+	d := time.ParseDuration("1h") - time.ParseDuration("-45m") + time.Second
+	return [d, time.DurationString(d), d / time.Minute, d > time.Hour]`, nil,
+		Array{Int(time.Hour + 45*time.Minute + time.Second),
+			String("1h45m1s"), Int(105), True})
+	expectRun(t, `time := import("time")
+	return time.DurationString(int(1.5 * time.Hour))`, nil, String("1h30m0s"))
+	expectRun(t, catch(`time.ParseDuration("1x")`),
+		nil, String(`error: time: unknown unit "x" in duration "1x"`))
+	expectRun(t, `time := import("time")
+	t := time.Date(2021, 7, 1, 12, 0, 0, 0, time.UTC())
+	ist := t.In(time.LoadLocation("Europe/Istanbul"))
+	ny := ist.In(time.LoadLocation("America/New_York"))
+	return [ist.Hour(), ist.Zone().offset, ny.Hour(), ny.Zone().offset,
+		ny.UTC().Hour(), ny.UTC().Equal(t), string(ist.Location)]`,
+		nil, Array{Int(15), Int(3 * 3600), Int(8), Int(-4 * 3600),
+			Int(12), True, String("Europe/Istanbul")})
+	expectRun(t, catch(`time.LoadLocation("Mars/Olympus_Mons")`),
+		nil, String("error: unknown time zone Mars/Olympus_Mons"))
+
 	// .Round
 	expectRun(t, `param p1; time := import("time"); return p1.Round(time.Second)`,
 		newOpts().Args(&Time{Value: tm}), &Time{Value: tm.Round(time.Second)})
@@ -836,6 +885,132 @@ func TestScript(t *testing.T) {
 	expectRun(t, catch(`time.Time().Zone(1)`), nil, nwrongArgs(0, -1, 1))
 }
 
+func TestTicker(t *testing.T) {
+	expectRun(t, `
+	time := import("time")
+	t := time.Tick(time.Millisecond)
+	n := 0
+	for n < 3 {
+		if isUndefined(t.Recv()) { break }
+		n++
+	}
+	time.StopTick(t)
+	return [n, t.Recv(), bool(t), typeName(t), string(t)]`, nil,
+		Array{Int(3), Undefined, False, String("ticker"), String("<ticker>")})
+	expectRun(t, `
+	time := import("time")
+	t := time.Tick(time.Millisecond)
+	v := t.Recv()
+	t.Stop(); t.Stop()
+	return [typeName(v), t == t, t == time.Tick(time.Hour)]`, nil,
+		Array{String("time"), True, False})
+
+	// no ticks are delivered after stop even if the interval elapsed
+	tick := Module["Tick"].(*Function)
+	stopTick := Module["StopTick"].(*Function)
+	ret, err := tick.Call(Int(time.Millisecond))
+	require.NoError(t, err)
+	ticker := ret.(*Ticker)
+	for i := 0; i < 3; i++ {
+		v, err := ticker.Recv(nil)
+		require.NoError(t, err)
+		require.IsType(t, (*Time)(nil), v)
+	}
+	_, err = stopTick.Call(ticker)
+	require.NoError(t, err)
+	time.Sleep(5 * time.Millisecond)
+	for i := 0; i < 3; i++ {
+		v, err := ticker.Recv(nil)
+		require.NoError(t, err)
+		require.Equal(t, Undefined, v)
+	}
+	require.True(t, ticker.IsFalsy())
+
+	catch := func(s string) string {
+		return fmt.Sprintf(`
+		time := import("time")
+		try {
+			return %s
+		} catch err {
+			return string(err)
+		}`, s)
+	}
+	expectRun(t, catch(`time.Tick(0)`), nil,
+		String("error: non-positive interval for Tick: 0"))
+	expectRun(t, catch(`time.Tick("x")`), nil,
+		String(NewArgumentTypeError("1st", "int", "string").String()))
+	expectRun(t, catch(`time.Tick()`), nil, String(
+		ErrWrongNumArguments.NewError("want=1 got=0").String()))
+	expectRun(t, catch(`time.StopTick(1)`), nil,
+		String(NewArgumentTypeError("1st", "ticker", "int").String()))
+	expectRun(t, catch(`time.Tick(time.Hour).Recv(1)`), nil, String(
+		ErrWrongNumArguments.NewError("want=0 got=1").String()))
+	expectRun(t, catch(`time.Tick(time.Hour).Foo()`), nil,
+		String(ErrInvalidIndex.NewError("Foo").String()))
+}
+
+func TestTickerAbortAndResources(t *testing.T) {
+	mm := NewModuleMap()
+	mm.AddBuiltinModule("time", Module)
+	c := DefaultCompilerOptions
+	c.ModuleMap = mm
+	bc, err := Compile([]byte(`
+	time := import("time")
+	t := time.Tick(time.Hour)
+	t.Recv()`), c)
+	require.NoError(t, err)
+
+	var acquired, released int64
+	vm := NewVM(bc).SetResourceHooks(
+		func(kind string) error {
+			require.Equal(t, ResourceTimer, kind)
+			atomic.AddInt64(&acquired, 1)
+			return nil
+		},
+		func(kind string) {
+			require.Equal(t, ResourceTimer, kind)
+			atomic.AddInt64(&released, 1)
+		},
+	)
+	done := make(chan error, 1)
+	go func() {
+		_, err := vm.Run(nil)
+		done <- err
+	}()
+	time.Sleep(20 * time.Millisecond)
+	vm.Abort()
+	select {
+	case err = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("ticker did not return after abort")
+	}
+	require.True(t, errors.Is(err, ErrVMAborted), err)
+	require.EqualValues(t, 1, atomic.LoadInt64(&acquired))
+	require.EqualValues(t, 1, atomic.LoadInt64(&released))
+
+	// tickers which are not stopped release their resources when collected
+	vm = NewVM(nil).SetResourceHooks(nil, func(kind string) {
+		atomic.AddInt64(&released, 1)
+	})
+	func() {
+		_, err := NewTicker(vm, time.Hour)
+		require.NoError(t, err)
+	}()
+	for i := 0; i < 100 && atomic.LoadInt64(&released) == 1; i++ {
+		runtime.GC()
+		time.Sleep(time.Millisecond)
+	}
+	require.EqualValues(t, 2, atomic.LoadInt64(&released))
+
+	quotaErr := errors.New("too many timers")
+	bc, err = Compile([]byte(`time := import("time"); time.Tick(1)`), c)
+	require.NoError(t, err)
+	_, err = NewVM(bc).SetResourceHooks(
+		func(string) error { return quotaErr }, nil,
+	).Run(nil)
+	require.True(t, errors.Is(err, quotaErr), err)
+}
+
 type illegalDur struct {
 	ObjectImpl
 	Value time.Duration
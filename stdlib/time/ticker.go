@@ -0,0 +1,204 @@
+// Copyright (c) 2020-2023 Ozan Hacıbekiroğlu.
+// Use of this source code is governed by a MIT License
+// that can be found in the LICENSE file.
+
+package time
+
+import (
+	"runtime"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/ozanh/ugo"
+)
+
+// abortCheckInterval is the interval to check whether the VM is aborted while
+// waiting for a tick.
+const abortCheckInterval = 10 * time.Millisecond
+
+// ugo:doc
+// ### ticker
+//
+// Go Type
+//
+// ```go
+// // Ticker delivers ticks at intervals and implements ugo.Object interface.
+// type Ticker struct {
+//    ugo.ObjectImpl
+//    // contains filtered or unexported fields
+// }
+// ```
+
+// Ticker delivers ticks at intervals and implements ugo.Object interface.
+// Use NewTicker to create a Ticker.
+type Ticker struct {
+	ugo.ObjectImpl
+	ticker *time.Ticker
+	vm     *ugo.VM
+
+	mu      sync.Mutex
+	done    chan struct{}
+	stopped bool
+}
+
+var _ ugo.NameCallerObject = (*Ticker)(nil)
+
+// NewTicker returns a new Ticker delivering ticks after each interval. If vm
+// is not nil, a ugo.ResourceTimer resource is acquired from vm which is
+// released when the Ticker is stopped or garbage collected without stopping,
+// the release hook of vm is called by the finalizer goroutine in the latter.
+func NewTicker(vm *ugo.VM, interval time.Duration) (*Ticker, error) {
+	if err := vm.AcquireResource(ugo.ResourceTimer); err != nil {
+		return nil, err
+	}
+	t := &Ticker{
+		ticker: time.NewTicker(interval),
+		vm:     vm,
+		done:   make(chan struct{}),
+	}
+	// Stop the ticker and release the resource if the script loses the
+	// reference to the ticker without stopping it. Stop runs only once.
+	runtime.SetFinalizer(t, (*Ticker).Stop)
+	return t, nil
+}
+
+// TypeName implements ugo.Object interface.
+func (*Ticker) TypeName() string {
+	return "ticker"
+}
+
+// String implements ugo.Object interface.
+func (*Ticker) String() string {
+	return "<ticker>"
+}
+
+// IsFalsy implements ugo.Object interface. Stopped tickers are falsy.
+func (o *Ticker) IsFalsy() bool {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	return o.stopped
+}
+
+// Equal implements ugo.Object interface.
+func (o *Ticker) Equal(right ugo.Object) bool {
+	v, ok := right.(*Ticker)
+	return ok && v == o
+}
+
+// Stop turns off the ticker, no more ticks are delivered after Stop returns
+// and waiting receivers return undefined. It is safe to call Stop multiple
+// times.
+func (o *Ticker) Stop() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.stopped {
+		return
+	}
+	o.stopped = true
+	o.ticker.Stop()
+	close(o.done)
+	// drop the pending tick if any
+	select {
+	case <-o.ticker.C:
+	default:
+	}
+	o.vm.ReleaseResource(ugo.ResourceTimer)
+}
+
+// Recv waits for the next tick and returns its time. If the ticker is stopped,
+// it returns undefined. While waiting, it periodically checks whether vm is
+// aborted and returns ErrVMAborted after stopping the ticker.
+func (o *Ticker) Recv(vm *ugo.VM) (ugo.Object, error) {
+	select {
+	case <-o.done:
+		return ugo.Undefined, nil
+	default:
+	}
+	select {
+	case tm := <-o.ticker.C:
+		return &Time{Value: tm}, nil
+	default:
+	}
+
+	poll := time.NewTicker(abortCheckInterval)
+	defer poll.Stop()
+	for {
+		select {
+		case <-o.done:
+			return ugo.Undefined, nil
+		case tm := <-o.ticker.C:
+			return &Time{Value: tm}, nil
+		case <-poll.C:
+			if vm != nil && vm.Aborted() {
+				o.Stop()
+				return ugo.Undefined, ugo.ErrVMAborted
+			}
+		}
+	}
+}
+
+// ugo:doc
+// #### ticker Methods
+//
+// | Method                      | Return Type      |
+// |:----------------------------|:-----------------|
+// |.Recv()                      | time\|undefined  |
+// |.Stop()                      | undefined        |
+//
+// Recv waits for the next tick and returns the time of the tick, it returns
+// undefined if the ticker is stopped. Ticks are dropped if Recv is not called
+// fast enough. Stop turns off the ticker, no more ticks are delivered after
+// Stop. Tickers must be stopped to release their resources.
+
+// CallName implements ugo.NameCallerObject interface.
+func (o *Ticker) CallName(name string, c ugo.Call) (ugo.Object, error) {
+	switch name {
+	case "Recv":
+		if err := c.CheckLen(0); err != nil {
+			return ugo.Undefined, err
+		}
+		return o.Recv(c.VM())
+	case "Stop":
+		if err := c.CheckLen(0); err != nil {
+			return ugo.Undefined, err
+		}
+		o.Stop()
+		return ugo.Undefined, nil
+	}
+	return ugo.Undefined, ugo.ErrInvalidIndex.NewError(name)
+}
+
+func tickFunc(args ...ugo.Object) (ugo.Object, error) {
+	return tickFuncEx(ugo.NewCall(nil, args))
+}
+
+func tickFuncEx(c ugo.Call) (ugo.Object, error) {
+	if err := c.CheckLen(1); err != nil {
+		return ugo.Undefined, err
+	}
+	d, ok := ugo.ToGoInt64(c.Get(0))
+	if !ok {
+		return newArgTypeErr("1st", "int", c.Get(0).TypeName())
+	}
+	if d <= 0 {
+		return ugo.Undefined, &ugo.Error{
+			Message: "non-positive interval for Tick: " +
+				strconv.FormatInt(d, 10),
+		}
+	}
+	t, err := NewTicker(c.VM(), time.Duration(d))
+	if err != nil {
+		return ugo.Undefined, err
+	}
+	return t, nil
+}
+
+func stopTickFunc(o ugo.Object) (ugo.Object, error) {
+	t, ok := o.(*Ticker)
+	if !ok {
+		return newArgTypeErr("1st", "ticker", o.TypeName())
+	}
+	t.Stop()
+	return ugo.Undefined, nil
+}
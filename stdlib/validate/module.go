@@ -0,0 +1,239 @@
+// Copyright (c) 2020-2023 Ozan Hacıbekiroğlu.
+// Use of this source code is governed by a MIT License
+// that can be found in the LICENSE file.
+
+// Package validate provides validate module implementing schema validation of
+// values like decoded JSON inputs for uGO script language.
+package validate
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ozanh/ugo"
+)
+
+// Module represents validate module.
+var Module = map[string]ugo.Object{
+	// ugo:doc
+	// # validate Module
+	//
+	// ## Functions
+	// Validate(value any, schema string|map) -> [ok bool, errors array]
+	// Validates value against schema and returns an array whose first element
+	// reports whether value conforms to schema and second element is an array
+	// of error messages in "path: message" format. Paths are dotted paths of
+	// nested map keys and array indices like "users.0.name", path of value
+	// itself is "(root)". It throws an error if schema is invalid.
+	//
+	// A string schema is a type name returned by typeName builtin function,
+	// multiple type names can be separated by "|" like "int|float". "any"
+	// matches all types. A map schema can have the following keys:
+	//
+	// - type: string schema of the value, default is "map" if fields or
+	//   required is set, "array" if items is set, otherwise "any".
+	// - fields: map of schemas for the keys of a map value, keys are
+	//   optional unless they are required.
+	// - required: array of the required keys of a map value.
+	// - items: schema of the elements of an array value.
+	//
+	// ```go
+	// validate := import("validate")
+	// schema := {
+	//   required: ["name", "db"],
+	//   fields: {
+	//     name: "string",
+	//     db: {required: ["port"], fields: {host: "string", port: "int"}},
+	//     tags: {items: "string"},
+	//   },
+	// }
+	// ok, errs := validate.Validate({name: 1, db: {}, tags: ["a", 2]}, schema)
+	// // ok == false
+	// // errs == ["db: missing required key \"port\"",
+	// //          "name: expected string, found int",
+	// //          "tags.1: expected string, found int"]
+	// ```
+	"Validate": &ugo.Function{
+		Name:    "Validate",
+		Value:   validateFunc,
+		ValueEx: validateFuncEx,
+	},
+}
+
+func validateFunc(args ...ugo.Object) (ugo.Object, error) {
+	return validateFuncEx(ugo.NewCall(nil, args))
+}
+
+func validateFuncEx(c ugo.Call) (ugo.Object, error) {
+	if err := c.CheckLen(2); err != nil {
+		return ugo.Undefined, err
+	}
+
+	var v validator
+	if err := v.validate("", c.Get(0), c.Get(1)); err != nil {
+		return ugo.Undefined, err
+	}
+
+	errs := make(ugo.Array, len(v.errs))
+	for i := range v.errs {
+		errs[i] = ugo.String(v.errs[i])
+	}
+	return ugo.Array{ugo.Bool(len(errs) == 0), errs}, nil
+}
+
+type validator struct {
+	errs []string
+}
+
+func (v *validator) addError(path, msg string) {
+	if path == "" {
+		path = "(root)"
+	}
+	v.errs = append(v.errs, path+": "+msg)
+}
+
+// validate validates value at path against schema. Validation errors are
+// collected, returned error is only for invalid schemas.
+func (v *validator) validate(path string, value, schema ugo.Object) error {
+	var s ugo.Map
+	switch sc := unfreeze(schema).(type) {
+	case ugo.String:
+		v.checkType(path, value, string(sc))
+		return nil
+	case ugo.Map:
+		s = sc
+	default:
+		return schemaError(path, "expected string|map, found "+
+			schema.TypeName())
+	}
+
+	var (
+		typ      = "any"
+		fields   ugo.Map
+		required ugo.Array
+		items    ugo.Object
+	)
+	for _, k := range sortedKeys(s) {
+		switch val := s[k]; k {
+		case "type":
+			t, ok := val.(ugo.String)
+			if !ok {
+				return schemaError(path, "type: expected string, found "+
+					val.TypeName())
+			}
+			typ = string(t)
+		case "fields":
+			m, ok := unfreeze(val).(ugo.Map)
+			if !ok {
+				return schemaError(path, "fields: expected map, found "+
+					val.TypeName())
+			}
+			fields = m
+		case "required":
+			arr, ok := unfreeze(val).(ugo.Array)
+			if !ok {
+				return schemaError(path, "required: expected array, found "+
+					val.TypeName())
+			}
+			for _, key := range arr {
+				if _, ok := key.(ugo.String); !ok {
+					return schemaError(path,
+						"required: expected array of strings, found "+
+							key.TypeName())
+				}
+			}
+			required = arr
+		case "items":
+			items = val
+		default:
+			return schemaError(path, "unknown key "+strconv.Quote(k))
+		}
+	}
+	if _, ok := s["type"]; !ok {
+		if fields != nil || required != nil {
+			typ = "map"
+		} else if items != nil {
+			typ = "array"
+		}
+	}
+
+	if !v.checkType(path, value, typ) {
+		return nil
+	}
+
+	// fields, required and items are ignored if type allows other values
+	value = unfreeze(value)
+	if m, ok := value.(ugo.Map); ok {
+		for _, key := range required {
+			k := string(key.(ugo.String))
+			if _, ok := m[k]; !ok {
+				v.addError(path, "missing required key "+strconv.Quote(k))
+			}
+		}
+		for _, k := range sortedKeys(fields) {
+			if val, ok := m[k]; ok {
+				if err := v.validate(joinPath(path, k), val,
+					fields[k]); err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	if arr, ok := value.(ugo.Array); ok && items != nil {
+		for i, elem := range arr {
+			if err := v.validate(joinPath(path, strconv.Itoa(i)), elem,
+				items); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// checkType reports whether type name of value is one of the "|" separated
+// type names, otherwise it adds an error. Frozen arrays and maps match "array"
+// and "map" types as well.
+func (v *validator) checkType(path string, value ugo.Object, types string) bool {
+	name := value.TypeName()
+	base := unfreeze(value).TypeName()
+	for _, t := range strings.Split(types, "|") {
+		if t = strings.TrimSpace(t); t == "any" || t == name || t == base {
+			return true
+		}
+	}
+	v.addError(path, "expected "+types+", found "+name)
+	return false
+}
+
+// unfreeze returns the underlying value of o if it is frozen, otherwise o.
+func unfreeze(o ugo.Object) ugo.Object {
+	if f, ok := o.(*ugo.Frozen); ok {
+		return f.Value
+	}
+	return o
+}
+
+func schemaError(path, msg string) error {
+	if path == "" {
+		path = "(root)"
+	}
+	return ugo.ErrType.NewError("invalid schema at " + path + ": " + msg)
+}
+
+func sortedKeys(m ugo.Map) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
@@ -0,0 +1,124 @@
+package validate_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ozanh/ugo"
+	. "github.com/ozanh/ugo/stdlib/validate"
+)
+
+func TestModule(t *testing.T) {
+	schema := `{
+		required: ["name", "db"],
+		fields: {
+			name: "string",
+			version: "int|float",
+			db: {
+				required: ["port"],
+				fields: {host: "string", port: "int", opts: "map|undefined"},
+			},
+			tags: {items: "string"},
+			users: {items: {required: ["id"], fields: {id: "int"}}},
+			extra: "any",
+		},
+	}`
+	testCases := []struct {
+		s string
+		e Object
+	}{
+		{s: `validate.Validate({
+			name: "app",
+			version: 1.2,
+			db: {host: "localhost", port: 5432},
+			tags: ["a", "b"],
+			users: [{id: 1}, {id: 2, name: "x"}],
+			extra: [1],
+			unknown: true,
+		}, schema)`, e: Array{True, Array{}}},
+		{s: `validate.Validate({name: "", db: {port: 1}}, schema)`,
+			e: Array{True, Array{}}},
+		{s: `validate.Validate({
+			version: "1",
+			db: {host: 1, opts: []},
+			tags: ["a", 2, 3.0],
+			users: [{id: 1}, {}, 3],
+		}, schema)`, e: Array{False, Array{
+			String(`(root): missing required key "name"`),
+			String(`db: missing required key "port"`),
+			String(`db.host: expected string, found int`),
+			String(`db.opts: expected map|undefined, found array`),
+			String(`tags.1: expected string, found int`),
+			String(`tags.2: expected string, found float`),
+			String(`users.1: missing required key "id"`),
+			String(`users.2: expected map, found int`),
+			String(`version: expected int|float, found string`),
+		}}},
+		{s: `validate.Validate([], schema)`, e: Array{False, Array{
+			String(`(root): expected map, found array`),
+		}}},
+		{s: `validate.Validate(1, "int | uint")`, e: Array{True, Array{}}},
+		{s: `validate.Validate(1, "string")`, e: Array{False, Array{
+			String(`(root): expected string, found int`),
+		}}},
+		{s: `validate.Validate(undefined, "any")`, e: Array{True, Array{}}},
+		{s: `validate.Validate(1, {})`, e: Array{True, Array{}}},
+		{s: `validate.Validate([1, "a"], {items: "int"})`, e: Array{False,
+			Array{String(`1: expected int, found string`)}}},
+		{s: `validate.Validate(undefined, {type: "array|undefined", items: "int"})`,
+			e: Array{True, Array{}}},
+		{s: `validate.Validate({a: 1}, {type: "map", fields: {a: "int"}})`,
+			e: Array{True, Array{}}},
+		{s: `validate.Validate(freeze({a: [1, "b"]}), "map")`,
+			e: Array{True, Array{}}},
+		{s: `validate.Validate(freeze({a: [1, "b"]}), "frozenMap")`,
+			e: Array{True, Array{}}},
+		{s: `validate.Validate(freeze({a: [1, "b"]}), {fields: {a: {items: "int"}}})`,
+			e: Array{False, Array{String(`a.1: expected int, found string`)}}},
+		{s: `validate.Validate({a: 1}, freeze({required: ["a", "b"]}))`,
+			e: Array{False, Array{String(`(root): missing required key "b"`)}}},
+
+		{s: `validate.Validate(1, 2)`, e: String(ErrType.NewError(
+			"invalid schema at (root): expected string|map, found int").String())},
+		{s: `validate.Validate({a: 1}, {fields: {a: {typ: "int"}}})`,
+			e: String(ErrType.NewError(
+				`invalid schema at a: unknown key "typ"`).String())},
+		{s: `validate.Validate({}, {required: "a"})`, e: String(ErrType.NewError(
+			"invalid schema at (root): required: expected array, found string").String())},
+		{s: `validate.Validate({}, {required: [1]})`, e: String(ErrType.NewError(
+			"invalid schema at (root): required: expected array of strings, found int").String())},
+		{s: `validate.Validate({}, {fields: []})`, e: String(ErrType.NewError(
+			"invalid schema at (root): fields: expected map, found array").String())},
+		{s: `validate.Validate({}, {type: 1})`, e: String(ErrType.NewError(
+			"invalid schema at (root): type: expected string, found int").String())},
+		{s: `validate.Validate({})`, e: String(ErrWrongNumArguments.NewError(
+			"want=2 got=1").String())},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.s, func(t *testing.T) {
+			expectRun(t, fmt.Sprintf(`
+			validate := import("validate")
+			schema := %s
+			try {
+				return %s
+			} catch err {
+				return string(err)
+			}`, schema, tt.s), tt.e)
+		})
+	}
+}
+
+func expectRun(t *testing.T, script string, expected Object) {
+	t.Helper()
+	mm := NewModuleMap()
+	mm.AddBuiltinModule("validate", Module)
+	c := DefaultCompilerOptions
+	c.ModuleMap = mm
+	bc, err := Compile([]byte(script), c)
+	require.NoError(t, err)
+	ret, err := NewVM(bc).Run(nil)
+	require.NoError(t, err)
+	require.Equal(t, expected, ret)
+}
@@ -345,6 +345,65 @@ func FuncPOi64ROeEx(fn func(ugo.Object, int64) (ugo.Object, error)) ugo.Callable
 	}
 }
 
+// FuncPssROeEx is a generated function to make ugo.CallableExFunc.
+// Source: func(s1 string, s2 string) (ret ugo.Object, err error)
+func FuncPssROeEx(fn func(string, string) (ugo.Object, error)) ugo.CallableExFunc {
+	return func(args ugo.Call) (ret ugo.Object, err error) {
+		if err := args.CheckLen(2); err != nil {
+			return ugo.Undefined, err
+		}
+
+		s1, ok := ugo.ToGoString(args.Get(0))
+		if !ok {
+			return ugo.Undefined, ugo.NewArgumentTypeError("1st", "string", args.Get(0).TypeName())
+		}
+		s2, ok := ugo.ToGoString(args.Get(1))
+		if !ok {
+			return ugo.Undefined, ugo.NewArgumentTypeError("2nd", "string", args.Get(1).TypeName())
+		}
+
+		ret, err = fn(s1, s2)
+		return
+	}
+}
+
+// FuncPOROeEx is a generated function to make ugo.CallableExFunc.
+// Source: func(o ugo.Object) (ret ugo.Object, err error)
+func FuncPOROeEx(fn func(ugo.Object) (ugo.Object, error)) ugo.CallableExFunc {
+	return func(args ugo.Call) (ret ugo.Object, err error) {
+		if err := args.CheckLen(1); err != nil {
+			return ugo.Undefined, err
+		}
+
+		o := args.Get(0)
+
+		ret, err = fn(o)
+		return
+	}
+}
+
+// FuncPb2sROEx is a generated function to make ugo.CallableExFunc.
+// Source: func(p []byte, s string) (ret ugo.Object)
+func FuncPb2sROEx(fn func([]byte, string) ugo.Object) ugo.CallableExFunc {
+	return func(args ugo.Call) (ret ugo.Object, err error) {
+		if err := args.CheckLen(2); err != nil {
+			return ugo.Undefined, err
+		}
+
+		p, ok := ugo.ToGoByteSlice(args.Get(0))
+		if !ok {
+			return ugo.Undefined, ugo.NewArgumentTypeError("1st", "bytes", args.Get(0).TypeName())
+		}
+		s, ok := ugo.ToGoString(args.Get(1))
+		if !ok {
+			return ugo.Undefined, ugo.NewArgumentTypeError("2nd", "string", args.Get(1).TypeName())
+		}
+
+		ret = fn(p, s)
+		return
+	}
+}
+
 // FuncPORO is a generated function to make ugo.CallableFunc.
 // Source: func(o ugo.Object) (ret ugo.Object)
 func FuncPORO(fn func(ugo.Object) ugo.Object) ugo.CallableFunc {
@@ -681,3 +740,62 @@ func FuncPOi64ROe(fn func(ugo.Object, int64) (ugo.Object, error)) ugo.CallableFu
 		return
 	}
 }
+
+// FuncPssROe is a generated function to make ugo.CallableFunc.
+// Source: func(s1 string, s2 string) (ret ugo.Object, err error)
+func FuncPssROe(fn func(string, string) (ugo.Object, error)) ugo.CallableFunc {
+	return func(args ...ugo.Object) (ret ugo.Object, err error) {
+		if len(args) != 2 {
+			return ugo.Undefined, ugo.ErrWrongNumArguments.NewError("want=2 got=" + strconv.Itoa(len(args)))
+		}
+
+		s1, ok := ugo.ToGoString(args[0])
+		if !ok {
+			return ugo.Undefined, ugo.NewArgumentTypeError("1st", "string", args[0].TypeName())
+		}
+		s2, ok := ugo.ToGoString(args[1])
+		if !ok {
+			return ugo.Undefined, ugo.NewArgumentTypeError("2nd", "string", args[1].TypeName())
+		}
+
+		ret, err = fn(s1, s2)
+		return
+	}
+}
+
+// FuncPOROe is a generated function to make ugo.CallableFunc.
+// Source: func(o ugo.Object) (ret ugo.Object, err error)
+func FuncPOROe(fn func(ugo.Object) (ugo.Object, error)) ugo.CallableFunc {
+	return func(args ...ugo.Object) (ret ugo.Object, err error) {
+		if len(args) != 1 {
+			return ugo.Undefined, ugo.ErrWrongNumArguments.NewError("want=1 got=" + strconv.Itoa(len(args)))
+		}
+
+		o := args[0]
+
+		ret, err = fn(o)
+		return
+	}
+}
+
+// FuncPb2sRO is a generated function to make ugo.CallableFunc.
+// Source: func(p []byte, s string) (ret ugo.Object)
+func FuncPb2sRO(fn func([]byte, string) ugo.Object) ugo.CallableFunc {
+	return func(args ...ugo.Object) (ret ugo.Object, err error) {
+		if len(args) != 2 {
+			return ugo.Undefined, ugo.ErrWrongNumArguments.NewError("want=2 got=" + strconv.Itoa(len(args)))
+		}
+
+		p, ok := ugo.ToGoByteSlice(args[0])
+		if !ok {
+			return ugo.Undefined, ugo.NewArgumentTypeError("1st", "bytes", args[0].TypeName())
+		}
+		s, ok := ugo.ToGoString(args[1])
+		if !ok {
+			return ugo.Undefined, ugo.NewArgumentTypeError("2nd", "string", args[1].TypeName())
+		}
+
+		ret = fn(p, s)
+		return
+	}
+}
@@ -45,6 +45,7 @@ type SymbolTable struct {
 	numParams        int
 	store            map[string]*Symbol
 	disabledBuiltins map[string]struct{}
+	extraBuiltins    map[string]Object
 	frees            []*Symbol
 	block            bool
 	disableParams    bool
@@ -150,6 +151,15 @@ func (st *SymbolTable) Resolve(name string) (symbol *Symbol, ok bool) {
 	}
 
 	if !ok && st.parent == nil && !st.isBuiltinDisabled(name) {
+		if _, exists := st.extraBuiltins[name]; exists {
+			symbol = &Symbol{
+				Name:  name,
+				Index: -1,
+				Scope: ScopeBuiltin,
+			}
+			st.store[name] = symbol
+			return symbol, true
+		}
 		if idx, exists := BuiltinsMap[name]; exists {
 			symbol = &Symbol{
 				Name:  name,
@@ -315,6 +325,33 @@ func (st *SymbolTable) DisabledBuiltins() []string {
 	return names
 }
 
+// SetExtraBuiltins sets the objects resolved as builtins with given names in
+// addition to the builtins in BuiltinsMap. Extra builtins take precedence over
+// the builtins with same name and they can be disabled like builtins. Symbols
+// of extra builtins have ScopeBuiltin scope and -1 index.
+func (st *SymbolTable) SetExtraBuiltins(extras map[string]Object) *SymbolTable {
+	if st.parent != nil {
+		return st.parent.SetExtraBuiltins(extras)
+	}
+
+	st.extraBuiltins = extras
+	return st
+}
+
+// ExtraBuiltins returns the extra builtins set with SetExtraBuiltins.
+func (st *SymbolTable) ExtraBuiltins() map[string]Object {
+	if st.parent != nil {
+		return st.parent.ExtraBuiltins()
+	}
+	return st.extraBuiltins
+}
+
+// extraBuiltin returns the extra builtin object with given name.
+func (st *SymbolTable) extraBuiltin(name string) (Object, bool) {
+	obj, ok := st.ExtraBuiltins()[name]
+	return obj, ok
+}
+
 // isBuiltinDisabled returns true if builtin name marked as disabled.
 func (st *SymbolTable) isBuiltinDisabled(name string) bool {
 	if st.parent != nil {
@@ -94,6 +94,11 @@ const (
 	Catch
 	Finally
 	Throw
+	Match
+	Case
+	Do
+	While
+	Cleanup
 	_keywordEnd
 )
 
@@ -174,6 +179,11 @@ var tokens = [...]string{
 	Catch:        "catch",
 	Finally:      "finally",
 	Throw:        "throw",
+	Match:        "match",
+	Case:         "case",
+	Do:           "do",
+	While:        "while",
+	Cleanup:      "cleanup",
 }
 
 func (tok Token) String() string {
@@ -0,0 +1,260 @@
+// Copyright (c) 2020-2023 Ozan Hacıbekiroğlu.
+// Use of this source code is governed by a MIT License
+// that can be found in the LICENSE file.
+
+package ugo
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/ozanh/ugo/parser"
+	"github.com/ozanh/ugo/token"
+)
+
+// Warning represents a compile time warning reported for code which compiles
+// but which is most likely a mistake.
+type Warning struct {
+	FilePos parser.SourceFilePos
+	Node    parser.Node
+	Message string
+}
+
+func (w *Warning) String() string {
+	return fmt.Sprintf("Warning: %s\n\tat %s", w.Message, w.FilePos)
+}
+
+// typeCheck reports operations on constant values which always fail at
+// runtime, like subtracting strings or calling a constant. Operations on
+// values which are not known at compile time are left to runtime.
+func typeCheck(file *parser.File, opts CompilerOptions) []*Warning {
+	var warnings []*Warning
+	warn := func(node parser.Node, msg string) {
+		warnings = append(warnings, &Warning{
+			FilePos: file.InputFile.Set().Position(node.Pos()),
+			Node:    node,
+			Message: msg,
+		})
+	}
+
+	parser.Inspect(file, func(node parser.Node) bool {
+		switch n := node.(type) {
+		case *parser.BinaryExpr:
+			left, ok := constValue(n.LHS, opts)
+			if !ok {
+				break
+			}
+			right, ok := constValue(n.RHS, opts)
+			if !ok || !isCheckedBinaryOp(n.Token, opts) {
+				break
+			}
+			if _, err := left.BinaryOp(n.Token, right); isTypeCheckErr(err) {
+				warn(n, err.Error())
+				// do not report same error for enclosing expressions
+				return false
+			}
+		case *parser.UnaryExpr:
+			operand, ok := constValue(n.Expr, opts)
+			if ok && !isValidUnaryOperand(n.Token, operand) {
+				warn(n, ErrType.NewError(
+					fmt.Sprintf("invalid type for unary '%s': '%s'",
+						n.Token.String(), operand.TypeName())).Error())
+				return false
+			}
+		case *parser.CallExpr:
+			if name, ok := litTypeName(n.Func); ok {
+				warn(n, ErrNotCallable.NewError(name).Error())
+			}
+		}
+		return true
+	})
+	return warnings
+}
+
+// keywordLikeNames are valid identifiers which are keywords or predeclared
+// names in other languages, mapped to their uGO equivalents.
+var keywordLikeNames = map[string]string{
+	"nil":  "undefined",
+	"null": "undefined",
+}
+
+// shadowCheck reports declarations of variables and parameters shadowing
+// builtins or having keyword-like names. Disabled builtins can be used as
+// variable names, so they are not reported.
+func shadowCheck(file *parser.File, opts CompilerOptions) []*Warning {
+	disabled := make(map[string]bool)
+	for _, name := range opts.DisabledBuiltins {
+		disabled[name] = true
+	}
+	var extras map[string]Object
+	if opts.SymbolTable != nil {
+		for _, name := range opts.SymbolTable.DisabledBuiltins() {
+			disabled[name] = true
+		}
+		extras = opts.SymbolTable.ExtraBuiltins()
+	}
+
+	isBuiltin := func(name string) bool {
+		if disabled[name] {
+			return false
+		}
+		if _, ok := opts.ExtraBuiltins[name]; ok {
+			return true
+		}
+		if _, ok := extras[name]; ok {
+			return true
+		}
+		_, ok := BuiltinsMap[name]
+		return ok
+	}
+
+	var warnings []*Warning
+	check := func(ident *parser.Ident) {
+		if ident == nil {
+			return
+		}
+		var msg string
+		if isBuiltin(ident.Name) {
+			msg = fmt.Sprintf("declaration of %q shadows builtin", ident.Name)
+		} else if alt, ok := keywordLikeNames[ident.Name]; ok {
+			msg = fmt.Sprintf("declaration of %q looks like a keyword, use %s",
+				ident.Name, alt)
+		} else {
+			return
+		}
+		warnings = append(warnings, &Warning{
+			FilePos: file.InputFile.Set().Position(ident.Pos()),
+			Node:    ident,
+			Message: msg,
+		})
+	}
+
+	parser.Inspect(file, func(node parser.Node) bool {
+		switch n := node.(type) {
+		case *parser.AssignStmt:
+			if n.Token != token.Define {
+				break
+			}
+			for _, lhs := range n.LHS {
+				if ident, ok := lhs.(*parser.Ident); ok {
+					check(ident)
+				}
+			}
+		case *parser.ValueSpec:
+			for _, ident := range n.Idents {
+				check(ident)
+			}
+		case *parser.ParamSpec:
+			check(n.Ident)
+		case *parser.FuncType:
+			if n.Params != nil {
+				for _, ident := range n.Params.List {
+					check(ident)
+				}
+			}
+		case *parser.ForInStmt:
+			check(n.Key)
+			check(n.Value)
+		case *parser.CatchStmt:
+			check(n.Ident)
+		case *parser.MatchStmt:
+			check(n.Ident)
+		}
+		return true
+	})
+	return warnings
+}
+
+// constValue returns the value of expr if it is a literal or an operation on
+// literals, which can be evaluated at compile time.
+func constValue(expr parser.Expr, opts CompilerOptions) (Object, bool) {
+	switch e := expr.(type) {
+	case *parser.IntLit:
+		return Int(e.Value), true
+	case *parser.UintLit:
+		return Uint(e.Value), true
+	case *parser.FloatLit:
+		return Float(e.Value), true
+	case *parser.CharLit:
+		return Char(e.Value), true
+	case *parser.StringLit:
+		return String(e.Value), true
+	case *parser.BoolLit:
+		return Bool(e.Value), true
+	case *parser.UndefinedLit:
+		return Undefined, true
+	case *parser.ParenExpr:
+		return constValue(e.Expr, opts)
+	case *parser.BinaryExpr:
+		if !isCheckedBinaryOp(e.Token, opts) {
+			return nil, false
+		}
+		left, ok := constValue(e.LHS, opts)
+		if !ok {
+			return nil, false
+		}
+		right, ok := constValue(e.RHS, opts)
+		if !ok {
+			return nil, false
+		}
+		v, err := left.BinaryOp(e.Token, right)
+		if err != nil {
+			return nil, false
+		}
+		return v, true
+	}
+	return nil, false
+}
+
+// litTypeName returns the type name of the value of expr if it is a literal
+// of a value which is never callable.
+func litTypeName(expr parser.Expr) (string, bool) {
+	switch e := expr.(type) {
+	case *parser.ArrayLit:
+		return "array", true
+	case *parser.MapLit:
+		return "map", true
+	case *parser.ParenExpr:
+		return litTypeName(e.Expr)
+	case *parser.IntLit, *parser.UintLit, *parser.FloatLit, *parser.CharLit,
+		*parser.StringLit, *parser.BoolLit, *parser.UndefinedLit:
+		v, _ := constValue(e, CompilerOptions{})
+		return v.TypeName(), true
+	}
+	return "", false
+}
+
+// isCheckedBinaryOp reports whether tok is evaluated with BinaryOp method of
+// the left operand at runtime.
+func isCheckedBinaryOp(tok token.Token, opts CompilerOptions) bool {
+	switch tok {
+	case token.Equal, token.NotEqual, token.LAnd, token.LOr:
+		return false
+	case token.Less, token.LessEq, token.Greater, token.GreaterEq:
+		return !opts.StrictComparison
+	}
+	return tok.IsBinaryOperator()
+}
+
+func isTypeCheckErr(err error) bool {
+	return errors.Is(err, ErrType) || errors.Is(err, ErrInvalidOperator) ||
+		errors.Is(err, ErrZeroDivision)
+}
+
+func isValidUnaryOperand(tok token.Token, operand Object) bool {
+	switch tok {
+	case token.Sub, token.Add:
+		switch operand.(type) {
+		case Int, Uint, Float, Char, Bool:
+			return true
+		}
+		return false
+	case token.Xor:
+		switch operand.(type) {
+		case Int, Uint, Char, Bool:
+			return true
+		}
+		return false
+	}
+	return true
+}
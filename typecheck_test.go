@@ -0,0 +1,159 @@
+package ugo_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	. "github.com/ozanh/ugo"
+)
+
+func TestTypeCheck(t *testing.T) {
+	testCases := []struct {
+		s        string
+		warnings []string
+	}{
+		{s: `"foo" - "bar"`, warnings: []string{
+			"TypeError: unsupported operand types for '-': 'string' and 'string' at (main):1:1",
+		}},
+		{s: `x := 1
+y := ("a" + "b") * 2`, warnings: []string{
+			"TypeError: unsupported operand types for '*': 'string' and 'int' at (main):2:6",
+		}},
+		{s: `1 / 0`, warnings: []string{
+			"ZeroDivisionError:  at (main):1:1",
+		}},
+		{s: `f := func() { return ("a" - 1) - 2 }`, warnings: []string{
+			"TypeError: unsupported operand types for '-': 'string' and 'int' at (main):1:23",
+		}},
+		{s: `-"a"`, warnings: []string{
+			"TypeError: invalid type for unary '-': 'string' at (main):1:2",
+		}},
+		{s: `^1.5`, warnings: []string{
+			"TypeError: invalid type for unary '^': 'float' at (main):1:2",
+		}},
+		{s: `1()`, warnings: []string{
+			"NotCallableError: int at (main):1:1",
+		}},
+		{s: `"a"(1); [1](); ({})()`, warnings: []string{
+			"NotCallableError: string at (main):1:1",
+			"NotCallableError: array at (main):1:9",
+			"NotCallableError: map at (main):1:16",
+		}},
+		{s: `if true { a := 1 + "x" } else { b := "x" - 1 }`, warnings: []string{
+			"TypeError: unsupported operand types for '+': 'int' and 'string' at (main):1:16",
+			"TypeError: unsupported operand types for '-': 'string' and 'int' at (main):1:38",
+		}},
+		// valid or dynamic cases are left to runtime
+		{s: `"a" + 1; 1 + 2; "a" < "b"; 1 == "a"; "a" && 1; -1; !"a"`},
+		{s: `param x; x - "a"; x(); -x; 1 / x`},
+		{s: `f := func(a) { return a - "b" }; f(1)`},
+		{s: `a := "foo"; a - "bar"`},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.s, func(t *testing.T) {
+			var warnings []string
+			opts := DefaultCompilerOptions
+			opts.OptimizeConst = false
+			opts.OptimizeExpr = false
+			opts.TypeCheck = true
+			opts.OnWarning = func(w *Warning) {
+				warnings = append(warnings, w.Message+" at "+w.FilePos.String())
+			}
+			_, err := Compile([]byte(tt.s), opts)
+			require.NoError(t, err)
+			require.Equal(t, tt.warnings, warnings)
+		})
+	}
+
+	// disabled by default
+	var called bool
+	opts := DefaultCompilerOptions
+	opts.OptimizeConst = false
+	opts.OptimizeExpr = false
+	opts.OnWarning = func(*Warning) { called = true }
+	_, err := Compile([]byte(`"foo" - "bar"`), opts)
+	require.NoError(t, err)
+	require.False(t, called)
+
+	w := &Warning{Message: "TypeError: x"}
+	require.Contains(t, w.String(), "Warning: TypeError: x\n\tat ")
+}
+
+func TestShadowCheck(t *testing.T) {
+	testCases := []struct {
+		s        string
+		warnings []string
+	}{
+		{s: `append := func(a, b) { return b }; return append([], 1)`,
+			warnings: []string{
+				`declaration of "append" shadows builtin at (main):1:1`,
+			}},
+		{s: `var copy = 1; const len = 2; global string; param int`,
+			warnings: []string{
+				`declaration of "copy" shadows builtin at (main):1:5`,
+				`declaration of "len" shadows builtin at (main):1:21`,
+				`declaration of "string" shadows builtin at (main):1:37`,
+				`declaration of "int" shadows builtin at (main):1:51`,
+			}},
+		{s: `f := func(x, ...error) {}; for sort, bool in {} {}`,
+			warnings: []string{
+				`declaration of "error" shadows builtin at (main):1:17`,
+				`declaration of "sort" shadows builtin at (main):1:32`,
+				`declaration of "bool" shadows builtin at (main):1:38`,
+			}},
+		{s: `try {} catch sort {}`, warnings: []string{
+			`declaration of "sort" shadows builtin at (main):1:14`,
+		}},
+		{s: `nil := undefined; if true { null := 1 }`, warnings: []string{
+			`declaration of "nil" looks like a keyword, use undefined at (main):1:1`,
+			`declaration of "null" looks like a keyword, use undefined at (main):1:29`,
+		}},
+		// assignments and labels are not declarations
+		{s: `x := 1; x = len("a"); copy: { break copy }; f := func(a) {}`},
+	}
+	for _, tt := range testCases {
+		t.Run(tt.s, func(t *testing.T) {
+			var warnings []string
+			opts := DefaultCompilerOptions
+			opts.WarnShadowing = true
+			opts.OnWarning = func(w *Warning) {
+				warnings = append(warnings, w.Message+" at "+w.FilePos.String())
+			}
+			_, err := Compile([]byte(tt.s), opts)
+			require.NoError(t, err)
+			require.Equal(t, tt.warnings, warnings)
+		})
+	}
+
+	// shadowing builtin still compiles and uses the local
+	var warnings []*Warning
+	opts := DefaultCompilerOptions
+	opts.WarnShadowing = true
+	opts.OnWarning = func(w *Warning) { warnings = append(warnings, w) }
+	bc, err := Compile(
+		[]byte(`append := func(a, b) { return b }; return append([], 1)`), opts)
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+	ret, err := NewVM(bc).Run(nil)
+	require.NoError(t, err)
+	require.Equal(t, Int(1), ret)
+
+	// disabled builtins are not reported, extra builtins are reported
+	warnings = nil
+	opts.DisabledBuiltins = []string{"append"}
+	opts.ExtraBuiltins = map[string]Object{"double": &Function{Name: "double"}}
+	_, err = Compile([]byte(`append := 1; double := 2`), opts)
+	require.NoError(t, err)
+	require.Len(t, warnings, 1)
+	require.Equal(t, `declaration of "double" shadows builtin`,
+		warnings[0].Message)
+
+	// disabled by default
+	warnings = nil
+	opts = DefaultCompilerOptions
+	opts.OnWarning = func(w *Warning) { warnings = append(warnings, w) }
+	_, err = Compile([]byte(`copy := 1`), opts)
+	require.NoError(t, err)
+	require.Nil(t, warnings)
+}
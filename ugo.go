@@ -104,6 +104,12 @@ func ToObject(v interface{}) (ret Object, err error) {
 		} else {
 			ret = Undefined
 		}
+	case CallableExFunc:
+		if v != nil {
+			ret = &Function{Value: callExAdapter(v), ValueEx: v}
+		} else {
+			ret = Undefined
+		}
 	case error:
 		ret = &Error{Message: v.Error(), Cause: v}
 	default:
@@ -118,6 +124,27 @@ func ToObject(v interface{}) (ret Object, err error) {
 	return
 }
 
+// NewCapabilityMap creates a Map from given Go values using ToObject to pass
+// it to scripts as a single global. Scripts can only do what is granted by the
+// values of the map instead of relying on global builtins and modules. Names
+// of the created Function objects are set to their keys.
+func NewCapabilityMap(caps map[string]interface{}) (Map, error) {
+	m := make(Map, len(caps))
+	for k, v := range caps {
+		obj, err := ToObject(v)
+		if err != nil {
+			return nil, fmt.Errorf("capability %q: %w", k, err)
+		}
+		if f, ok := obj.(*Function); ok && f.Name == "" {
+			fn := f.Copy().(*Function)
+			fn.Name = k
+			obj = fn
+		}
+		m[k] = obj
+	}
+	return m, nil
+}
+
 // ToObjectAlt is analogous to ToObject but it will always convert signed integers to
 // Int and unsigned integers to Uint. It is an alternative to ToObject.
 // Note that, this function is subject to change in the future.
@@ -205,6 +232,12 @@ func ToObjectAlt(v interface{}) (ret Object, err error) {
 		} else {
 			ret = Undefined
 		}
+	case CallableExFunc:
+		if v != nil {
+			ret = &Function{Value: callExAdapter(v), ValueEx: v}
+		} else {
+			ret = Undefined
+		}
 	case error:
 		ret = &Error{Message: v.Error(), Cause: v}
 	default:
@@ -530,9 +563,9 @@ func ToGoBool(o Object) (v bool, ok bool) {
 //
 //ugo:callable func(o Object, k string) (err error)
 
-// builtin copy, len, error, typeName, bool, string, isInt, isUint
-// isFloat, isChar, isBool, isString, isBytes, isMap, isSyncMap, isArray
-// isUndefined, isFunction, isCallable, isIterable
+// builtin copy, clone, freeze, len, error, typeName, bool, string, isInt
+// isUint, isFloat, isChar, isValidChar, isBool, isString, isBytes, isMap
+// isSyncMap, isArray, isUndefined, isFunction, isCallable, isIterable
 //
 //ugo:callable func(o Object) (ret Object)
 
@@ -544,11 +577,24 @@ func ToGoBool(o Object) (v bool, ok bool) {
 //
 //ugo:callable func(n int, o Object) (ret Object, err error)
 
-// builtin contains
+// builtin getPath
+//
+//ugo:callable func(o Object, k string) (ret Object)
+
+// builtin setPath
+//
+//ugo:callable func(o Object, k string, v Object) (err error)
+
+// builtin contains, pick, omit, tryDiv, tryMod
 //
 //ugo:callable func(o Object, v Object) (ret Object, err error)
 
-// builtin sort, sortReverse, int, uint, float, char, chars
+// builtin same
+//
+//ugo:callable func(o Object, v Object) (ret Object)
+
+// builtin sort, sortReverse, int, uint, float, char, chars, syncMap
+// unsyncMap
 //
 //ugo:callable func(o Object) (ret Object, err error)
 
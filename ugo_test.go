@@ -14,6 +14,7 @@ import (
 func TestToObject(t *testing.T) {
 	err := errors.New("test error")
 	fn := func(...Object) (Object, error) { return nil, nil }
+	exfn := func(Call) (Object, error) { return True, nil }
 
 	testCases := []struct {
 		iface   interface{}
@@ -47,6 +48,8 @@ func TestToObject(t *testing.T) {
 		{iface: String("a"), want: String("a")},
 		{iface: CallableFunc(nil), want: Undefined},
 		{iface: fn, want: &Function{Value: fn}},
+		{iface: CallableExFunc(nil), want: Undefined},
+		{iface: exfn, want: &Function{ValueEx: exfn}},
 		{iface: err, want: &Error{Message: err.Error(), Cause: err}},
 		{iface: error(nil), want: Undefined},
 		{iface: uint16(1), wantErr: true},
@@ -63,6 +66,14 @@ func TestToObject(t *testing.T) {
 				require.NotNil(t, tC.want.(*Function).Value)
 				return
 			}
+			if fn, ok := tC.iface.(CallableExFunc); ok && fn != nil {
+				f := got.(*Function)
+				require.NotNil(t, f.ValueEx)
+				ret, err := f.Call()
+				require.NoError(t, err)
+				require.Equal(t, True, ret)
+				return
+			}
 			if !reflect.DeepEqual(got, tC.want) {
 				t.Errorf("ToObject() = %v, want %v", got, tC.want)
 			}
@@ -115,6 +126,7 @@ func TestToInterface(t *testing.T) {
 func TestToObjectAlt(t *testing.T) {
 	err := errors.New("test error")
 	fn := func(...Object) (Object, error) { return nil, nil }
+	exfn := func(Call) (Object, error) { return True, nil }
 
 	testCases := []struct {
 		iface   interface{}
@@ -154,6 +166,8 @@ func TestToObjectAlt(t *testing.T) {
 		{iface: String("a"), want: String("a")},
 		{iface: CallableFunc(nil), want: Undefined},
 		{iface: fn, want: &Function{Value: fn}},
+		{iface: CallableExFunc(nil), want: Undefined},
+		{iface: exfn, want: &Function{ValueEx: exfn}},
 		{iface: err, want: &Error{Message: err.Error(), Cause: err}},
 		{iface: error(nil), want: Undefined},
 		{iface: struct{}{}, wantErr: true},
@@ -170,9 +184,78 @@ func TestToObjectAlt(t *testing.T) {
 				require.NotNil(t, tC.want.(*Function).Value)
 				return
 			}
+			if fn, ok := tC.iface.(CallableExFunc); ok && fn != nil {
+				f := got.(*Function)
+				require.NotNil(t, f.ValueEx)
+				ret, err := f.Call()
+				require.NoError(t, err)
+				require.Equal(t, True, ret)
+				return
+			}
 			if !reflect.DeepEqual(got, tC.want) {
 				t.Errorf("ToObjectAlt() = %[1]v (%[1]T), want %[2]v (%[2]T)", got, tC.want)
 			}
 		})
 	}
 }
+
+func TestCapabilityMap(t *testing.T) {
+	files := map[string]string{"a.txt": "content of a"}
+	var reads []string
+	caps, err := NewCapabilityMap(map[string]interface{}{
+		"readFile": func(args ...Object) (Object, error) {
+			if len(args) != 1 {
+				return nil, ErrWrongNumArguments
+			}
+			name := args[0].String()
+			reads = append(reads, name)
+			data, ok := files[name]
+			if !ok {
+				return nil, fmt.Errorf("file not found: %s", name)
+			}
+			return String(data), nil
+		},
+		"now": func(c Call) (Object, error) {
+			return Int(42), c.CheckLen(0)
+		},
+		"version": "1.0",
+	})
+	require.NoError(t, err)
+	require.Equal(t, "readFile", caps["readFile"].(*Function).Name)
+	require.Equal(t, "now", caps["now"].(*Function).Name)
+	require.Equal(t, String("1.0"), caps["version"])
+
+	run := func(script string) (Object, error) {
+		bc, err := Compile([]byte(script), DefaultCompilerOptions)
+		if err != nil {
+			return nil, err
+		}
+		return NewVM(bc).Run(Map{"caps": caps})
+	}
+
+	ret, err := run(`global caps; return [caps.readFile("a.txt"), caps.now()]`)
+	require.NoError(t, err)
+	require.Equal(t, Array{String("content of a"), Int(42)}, ret)
+
+	ret, err = run(`
+	global caps
+	try {
+		return caps.readFile("/etc/passwd")
+	} catch err {
+		return string(err)
+	}`)
+	require.NoError(t, err)
+	require.Equal(t, String("error: file not found: /etc/passwd"), ret)
+	require.Equal(t, []string{"a.txt", "/etc/passwd"}, reads)
+
+	// there is no ambient access to anything not granted
+	_, err = run(`global caps; return caps.writeFile("a.txt", "x")`)
+	require.Error(t, err)
+	_, err = run(`os := import("os"); return os.ReadFile("a.txt")`)
+	require.Error(t, err)
+	require.Contains(t, err.Error(), "module 'os' not found")
+
+	_, err = NewCapabilityMap(map[string]interface{}{"bad": struct{}{}})
+	require.EqualError(t, err,
+		`capability "bad": cannot convert to object: struct {}`)
+}
@@ -27,6 +27,7 @@ type VM struct {
 	sp           int
 	ip           int
 	curInsts     []byte
+	curOps       []decodedInst
 	constants    []Object
 	stack        [stackSize]Object
 	frames       [frameSize]frame
@@ -39,6 +40,17 @@ type VM struct {
 	mu           sync.Mutex
 	err          error
 	noPanic      bool
+	logConfig    atomic.Value // *logConfig
+	onAcquire    func(kind string) error
+	onRelease    func(kind string)
+	abortMu      sync.Mutex
+	abortErr     error
+	instrumented bool
+	cover        *execCounter
+	prof         *execCounter
+	execFn       *CompiledFunction
+	coverCounts  []uint64
+	profCounts   []uint64
 }
 
 // NewVM creates a VM object.
@@ -71,14 +83,27 @@ func (vm *VM) SetBytecode(bc *Bytecode) *VM {
 	vm.bytecode = bc
 	vm.constants = bc.Constants
 	vm.modulesCache = nil
+	if vm.cover != nil {
+		vm.cover = newExecCounter(bc)
+	}
+	if vm.prof != nil {
+		vm.prof = newExecCounter(bc)
+	}
+	vm.resetInstrumentation()
 	return vm
 }
 
-// Clear clears stack by setting nil to stack indexes and removes modules cache.
+// Clear clears stack by setting nil to stack indexes, removes modules cache
+// and resets the aborted state.
 func (vm *VM) Clear() *VM {
 	vm.mu.Lock()
 	defer vm.mu.Unlock()
 
+	vm.abortMu.Lock()
+	vm.abortErr = nil
+	vm.abortMu.Unlock()
+	atomic.StoreInt64(&vm.abort, 0)
+
 	for i := range vm.stack {
 		vm.stack[i] = nil
 	}
@@ -88,11 +113,39 @@ func (vm *VM) Clear() *VM {
 	return vm
 }
 
-// GetGlobals returns global variables.
+// GetGlobals returns global variables of the last run. Changes made to global
+// variables before an abort or an error are visible in the returned object
+// until Clear is called.
 func (vm *VM) GetGlobals() Object {
 	return vm.globals
 }
 
+// RestoreGlobals restores global variables to the given snapshot, which is a
+// deep copy of globals taken with globalsSnapshot builtin or by the embedder
+// to roll back the changes of a run. If both globals and snapshot are maps,
+// entries of the globals map are replaced in place with copies of the snapshot
+// entries so that the map passed to Run reflects the restored state. Otherwise
+// a copy of the snapshot is set as globals, which is returned by GetGlobals.
+// Snapshot is copied so that it can be restored multiple times.
+func (vm *VM) RestoreGlobals(snapshot Object) *VM {
+	vm.mu.Lock()
+	defer vm.mu.Unlock()
+
+	src, ok := snapshot.(Map)
+	dst, ok2 := vm.globals.(Map)
+	if !ok || !ok2 {
+		vm.globals = deepCopy(snapshot)
+		return vm
+	}
+	for k := range dst {
+		delete(dst, k)
+	}
+	for k, v := range src {
+		dst[k] = deepCopy(v)
+	}
+	return vm
+}
+
 // GetLocals returns variables from stack up to the NumLocals of given Bytecode.
 // This must be called after Run() before Clear().
 func (vm *VM) GetLocals(locals []Object) []Object {
@@ -126,12 +179,15 @@ func (vm *VM) RunCompiledFunction(
 		return nil, errors.New("invalid Bytecode")
 	}
 
-	vm.bytecode = &Bytecode{
+	bc := &Bytecode{
 		FileSet:    vm.bytecode.FileSet,
 		Constants:  vm.constants,
 		Main:       f,
 		NumModules: vm.bytecode.NumModules,
 	}
+	// constants are not changed, share decoded instructions of functions
+	bc.decoded.Store(vm.bytecode.decodedMap())
+	vm.bytecode = bc
 
 	for i := range vm.stack {
 		vm.stack[i] = nil
@@ -146,8 +202,21 @@ func (vm *VM) Abort() {
 	atomic.StoreInt64(&vm.abort, 1)
 }
 
-// Aborted reports whether VM is aborted. It is safe to call this method from
-// another goroutine.
+// AbortWith aborts the VM execution like Abort but Run returns given err
+// instead of ErrVMAborted, so that the reason of the abort can be
+// distinguished from other errors like context cancellation. If err is nil,
+// it is same as Abort. It is safe to call this method from another goroutine.
+func (vm *VM) AbortWith(err error) {
+	vm.abortMu.Lock()
+	vm.abortErr = err
+	vm.abortMu.Unlock()
+	vm.Abort()
+}
+
+// Aborted reports whether VM is aborted. After Run returns, it reports whether
+// the run was aborted until the next run or Clear call, so that an aborted run
+// can be distinguished from a completed or failed one. It is safe to call this
+// method from another goroutine.
 func (vm *VM) Aborted() bool {
 	return atomic.LoadInt64(&vm.abort) == 1
 }
@@ -166,6 +235,9 @@ func (vm *VM) init(globals Object, args ...Object) (Object, error) {
 	}
 
 	vm.err = nil
+	vm.abortMu.Lock()
+	vm.abortErr = nil
+	vm.abortMu.Unlock()
 	atomic.StoreInt64(&vm.abort, 0)
 	vm.initGlobals(globals)
 	vm.initLocals(args)
@@ -186,6 +258,13 @@ func (vm *VM) init(globals Object, args ...Object) (Object, error) {
 		run = vm.run()
 	}
 	if vm.err != nil {
+		if errors.Is(vm.err, ErrVMAborted) {
+			vm.abortMu.Lock()
+			defer vm.abortMu.Unlock()
+			if vm.abortErr != nil {
+				return nil, vm.abortErr
+			}
+		}
 		return nil, vm.err
 	}
 
@@ -217,15 +296,18 @@ func (vm *VM) loop() {
 VMLoop:
 	for atomic.LoadInt64(&vm.abort) == 0 {
 		vm.ip++
+		if vm.instrumented {
+			vm.recordExec()
+		}
 		switch vm.curInsts[vm.ip] {
 		case OpConstant:
-			cidx := int(vm.curInsts[vm.ip+2]) | int(vm.curInsts[vm.ip+1])<<8
+			cidx := int(vm.curOps[vm.ip].a)
 			obj := vm.constants[cidx]
 			vm.stack[vm.sp] = obj
 			vm.sp++
 			vm.ip += 2
 		case OpGetLocal:
-			localIdx := int(vm.curInsts[vm.ip+1])
+			localIdx := int(vm.curOps[vm.ip].a)
 			value := vm.stack[vm.curFrame.basePointer+localIdx]
 			if v, ok := value.(*ObjectPtr); ok {
 				value = *v.Value
@@ -234,7 +316,7 @@ VMLoop:
 			vm.sp++
 			vm.ip++
 		case OpSetLocal:
-			localIndex := int(vm.curInsts[vm.ip+1])
+			localIndex := int(vm.curOps[vm.ip].a)
 			value := vm.stack[vm.sp-1]
 			index := vm.curFrame.basePointer + localIndex
 			if v, ok := vm.stack[index].(*ObjectPtr); ok {
@@ -246,9 +328,22 @@ VMLoop:
 			vm.stack[vm.sp] = nil
 			vm.ip++
 		case OpBinaryOp:
-			tok := token.Token(vm.curInsts[vm.ip+1])
+			tok := token.Token(vm.curOps[vm.ip].a)
 			left, right := vm.stack[vm.sp-2], vm.stack[vm.sp-1]
 
+			// fast path for int operands, general dispatch is used for errors
+			if x, ok := left.(Int); ok {
+				if y, ok := right.(Int); ok {
+					if value := intBinaryOp(tok, x, y); value != nil {
+						vm.stack[vm.sp-2] = value
+						vm.sp--
+						vm.stack[vm.sp] = nil
+						vm.ip++
+						continue
+					}
+				}
+			}
+
 			var value Object
 			var err error
 			switch left := left.(type) {
@@ -283,7 +378,7 @@ VMLoop:
 			}
 		case OpAndJump:
 			if vm.stack[vm.sp-1].IsFalsy() {
-				pos := int(vm.curInsts[vm.ip+2]) | int(vm.curInsts[vm.ip+1])<<8
+				pos := int(vm.curOps[vm.ip].a)
 				vm.ip = pos - 1
 				continue
 			}
@@ -297,7 +392,7 @@ VMLoop:
 				vm.ip += 2
 				continue
 			}
-			pos := int(vm.curInsts[vm.ip+2]) | int(vm.curInsts[vm.ip+1])<<8
+			pos := int(vm.curOps[vm.ip].a)
 			vm.ip = pos - 1
 		case OpEqual:
 			left, right := vm.stack[vm.sp-2], vm.stack[vm.sp-1]
@@ -366,7 +461,7 @@ VMLoop:
 				return
 			}
 		case OpReturn:
-			numRet := vm.curInsts[vm.ip+1]
+			numRet := vm.curOps[vm.ip].a
 			bp := vm.curFrame.basePointer
 			if bp == 0 {
 				bp = vm.curFrame.fn.NumLocals + 1
@@ -391,15 +486,16 @@ VMLoop:
 			vm.ip = parent.ip
 			vm.curFrame = parent
 			vm.curInsts = vm.curFrame.fn.Instructions
+			vm.curOps = vm.curFrame.ops
 		case OpGetBuiltin:
-			builtinIndex := BuiltinType(int(vm.curInsts[vm.ip+1]))
+			builtinIndex := BuiltinType(int(vm.curOps[vm.ip].a))
 			vm.stack[vm.sp] = BuiltinObjects[builtinIndex]
 			vm.sp++
 			vm.ip++
 		case OpClosure:
-			constIdx := int(vm.curInsts[vm.ip+2]) | int(vm.curInsts[vm.ip+1])<<8
+			constIdx := int(vm.curOps[vm.ip].a)
 			fn := vm.constants[constIdx].(*CompiledFunction)
-			numFree := int(vm.curInsts[vm.ip+3])
+			numFree := int(vm.curOps[vm.ip].b)
 			free := make([]*ObjectPtr, numFree)
 			for i := 0; i < numFree; i++ {
 				switch freeVar := (vm.stack[vm.sp-numFree+i]).(type) {
@@ -426,7 +522,7 @@ VMLoop:
 			vm.sp++
 			vm.ip += 3
 		case OpJump:
-			vm.ip = (int(vm.curInsts[vm.ip+2]) | int(vm.curInsts[vm.ip+1])<<8) - 1
+			vm.ip = int(vm.curOps[vm.ip].a) - 1
 		case OpJumpFalsy:
 			vm.sp--
 			obj := vm.stack[vm.sp]
@@ -448,12 +544,12 @@ VMLoop:
 				falsy = obj.IsFalsy()
 			}
 			if falsy {
-				vm.ip = (int(vm.curInsts[vm.ip+2]) | int(vm.curInsts[vm.ip+1])<<8) - 1
+				vm.ip = int(vm.curOps[vm.ip].a) - 1
 				continue
 			}
 			vm.ip += 2
 		case OpGetGlobal:
-			cidx := int(vm.curInsts[vm.ip+2]) | int(vm.curInsts[vm.ip+1])<<8
+			cidx := int(vm.curOps[vm.ip].a)
 			index := vm.constants[cidx]
 			var ret Object
 			var err error
@@ -476,7 +572,7 @@ VMLoop:
 			vm.ip += 2
 			vm.sp++
 		case OpSetGlobal:
-			cidx := int(vm.curInsts[vm.ip+2]) | int(vm.curInsts[vm.ip+1])<<8
+			cidx := int(vm.curOps[vm.ip].a)
 			index := vm.constants[cidx]
 			value := vm.stack[vm.sp-1]
 
@@ -496,7 +592,7 @@ VMLoop:
 			vm.sp--
 			vm.stack[vm.sp] = nil
 		case OpArray:
-			numItems := int(vm.curInsts[vm.ip+2]) | int(vm.curInsts[vm.ip+1])<<8
+			numItems := int(vm.curOps[vm.ip].a)
 			arr := make(Array, numItems)
 			copy(arr, vm.stack[vm.sp-numItems:vm.sp])
 			vm.sp -= numItems
@@ -509,7 +605,7 @@ VMLoop:
 			vm.sp++
 			vm.ip += 2
 		case OpMap:
-			numItems := int(vm.curInsts[vm.ip+2]) | int(vm.curInsts[vm.ip+1])<<8
+			numItems := int(vm.curOps[vm.ip].a)
 			kv := make(Map)
 
 			for i := vm.sp - numItems; i < vm.sp; i += 2 {
@@ -525,7 +621,7 @@ VMLoop:
 			vm.sp++
 			vm.ip += 2
 		case OpGetIndex:
-			numSel := int(vm.curInsts[vm.ip+1])
+			numSel := int(vm.curOps[vm.ip].a)
 			tp := vm.sp - 1 - numSel
 			target := vm.stack[tp]
 			value := Undefined
@@ -589,18 +685,18 @@ VMLoop:
 				return
 			}
 		case OpGetFree:
-			freeIndex := int(vm.curInsts[vm.ip+1])
+			freeIndex := int(vm.curOps[vm.ip].a)
 			vm.stack[vm.sp] = *vm.curFrame.freeVars[freeIndex].Value
 			vm.sp++
 			vm.ip++
 		case OpSetFree:
-			freeIndex := int(vm.curInsts[vm.ip+1])
+			freeIndex := int(vm.curOps[vm.ip].a)
 			*vm.curFrame.freeVars[freeIndex].Value = vm.stack[vm.sp-1]
 			vm.sp--
 			vm.stack[vm.sp] = nil
 			vm.ip++
 		case OpGetLocalPtr:
-			localIndex := int(vm.curInsts[vm.ip+1])
+			localIndex := int(vm.curOps[vm.ip].a)
 			var freeVar *ObjectPtr
 			value := vm.stack[vm.curFrame.basePointer+localIndex]
 
@@ -615,13 +711,13 @@ VMLoop:
 			vm.sp++
 			vm.ip++
 		case OpGetFreePtr:
-			freeIndex := int(vm.curInsts[vm.ip+1])
+			freeIndex := int(vm.curOps[vm.ip].a)
 			value := vm.curFrame.freeVars[freeIndex]
 			vm.stack[vm.sp] = value
 			vm.sp++
 			vm.ip++
 		case OpDefineLocal:
-			localIndex := int(vm.curInsts[vm.ip+1])
+			localIndex := int(vm.curOps[vm.ip].a)
 			vm.stack[vm.curFrame.basePointer+localIndex] = vm.stack[vm.sp-1]
 			vm.sp--
 			vm.stack[vm.sp] = nil
@@ -632,11 +728,21 @@ VMLoop:
 		case OpPop:
 			vm.sp--
 			vm.stack[vm.sp] = nil
-		case OpIterInit:
+		case OpCopyValue:
+			switch v := vm.stack[vm.sp-1].(type) {
+			case Array:
+				vm.stack[vm.sp-1] = v.DeepCopy()
+			case Map:
+				vm.stack[vm.sp-1] = v.DeepCopy()
+			}
+		case OpIterInit, OpIterInitSorted:
 			dst := vm.stack[vm.sp-1]
 
 			if dst.CanIterate() {
 				it := dst.Iterate()
+				if vm.curInsts[vm.ip] == OpIterInitSorted {
+					sortIteratorKeys(it)
+				}
 				vm.stack[vm.sp-1] = &iteratorObject{Iterator: it}
 				continue
 			}
@@ -659,8 +765,8 @@ VMLoop:
 			val := iterator.(Iterator).Value()
 			vm.stack[vm.sp-1] = val
 		case OpLoadModule:
-			cidx := int(vm.curInsts[vm.ip+2]) | int(vm.curInsts[vm.ip+1])<<8
-			midx := int(vm.curInsts[vm.ip+4]) | int(vm.curInsts[vm.ip+3])<<8
+			cidx := int(vm.curOps[vm.ip].a)
+			midx := int(vm.curOps[vm.ip].b)
 			value := vm.modulesCache[midx]
 
 			if value == nil {
@@ -682,14 +788,12 @@ VMLoop:
 
 			vm.ip += 4
 		case OpStoreModule:
-			midx := int(vm.curInsts[vm.ip+2]) | int(vm.curInsts[vm.ip+1])<<8
+			midx := int(vm.curOps[vm.ip].a)
 			value := vm.stack[vm.sp-1]
 
-			if v, ok := value.(Copier); ok {
-				// store deep copy of the module if supported
-				value = v.Copy()
-				vm.stack[vm.sp-1] = value
-			}
+			// store deep copy of the module if supported
+			value = deepCopy(value)
+			vm.stack[vm.sp-1] = value
 
 			vm.modulesCache[midx] = value
 			vm.ip += 2
@@ -706,7 +810,7 @@ VMLoop:
 				return
 			}
 		case OpFinalizer:
-			upto := int(vm.curInsts[vm.ip+1])
+			upto := int(vm.curOps[vm.ip].a)
 
 			pos := vm.curFrame.errHandlers.findFinally(upto)
 			if pos <= 0 {
@@ -723,6 +827,15 @@ VMLoop:
 			vm.curFrame.errHandlers.err = nil
 			// set ip to finally's position
 			vm.ip = pos - 1
+		case OpStrictCompare:
+			err := vm.xOpStrictCompare()
+			if err == nil {
+				continue
+			}
+			if err = vm.throwGenErr(err); err != nil {
+				vm.err = err
+				return
+			}
 		case OpUnary:
 			err := vm.xOpUnary()
 			if err == nil {
@@ -784,6 +897,8 @@ func (vm *VM) initCurrentFrame() {
 	vm.curInsts = vm.bytecode.Main.Instructions
 	vm.curFrame = &(vm.frames[0])
 	vm.curFrame.fn = vm.bytecode.Main
+	vm.curFrame.ops = vm.bytecode.decodedInsts(vm.bytecode.Main)
+	vm.curOps = vm.curFrame.ops
 
 	if vm.curFrame.fn.Free != nil {
 		// Assign free variables if exists in compiled function.
@@ -798,6 +913,7 @@ func (vm *VM) initCurrentFrame() {
 func (vm *VM) clearCurrentFrame() {
 	vm.curFrame.freeVars = nil
 	vm.curFrame.fn = nil
+	vm.curFrame.ops = nil
 	vm.curFrame.errHandlers = nil
 }
 
@@ -826,8 +942,8 @@ func (vm *VM) handlePanic(r interface{}) {
 }
 
 func (vm *VM) xOpSetupTry() {
-	catch := int(vm.curInsts[vm.ip+2]) | int(vm.curInsts[vm.ip+1])<<8
-	finally := int(vm.curInsts[vm.ip+4]) | int(vm.curInsts[vm.ip+3])<<8
+	catch := int(vm.curOps[vm.ip].a)
+	finally := int(vm.curOps[vm.ip].b)
 
 	ptrs := errHandler{
 		sp:      vm.sp,
@@ -878,7 +994,7 @@ func (vm *VM) xOpSetupFinally() {
 }
 
 func (vm *VM) xOpThrow() error {
-	op := vm.curInsts[vm.ip+1]
+	op := vm.curOps[vm.ip].a
 	vm.ip++
 
 	switch op {
@@ -903,6 +1019,10 @@ func (vm *VM) xOpThrow() error {
 			}
 			vm.sp = handler.sp
 			vm.ip = pos - 1
+		} else {
+			// try statement is completed, remove its handler so that enclosing
+			// try statements' handlers are on top of the handler stack.
+			errHandlers.pop()
 		}
 	case 1: // user
 		obj := vm.stack[vm.sp-1]
@@ -955,6 +1075,7 @@ func (vm *VM) throw(err *RuntimeError, noTrace bool) error {
 		}
 		f.freeVars = nil
 		f.fn = nil
+		f.ops = nil
 		index--
 	}
 
@@ -972,6 +1093,7 @@ func (vm *VM) throw(err *RuntimeError, noTrace bool) error {
 	vm.curFrame = frame
 	vm.curFrame.fn = frame.fn
 	vm.curInsts = frame.fn.Instructions
+	vm.curOps = frame.ops
 
 	return nil
 }
@@ -981,7 +1103,8 @@ func (vm *VM) handleThrownError(frame *frame, err *RuntimeError) error {
 	handler := frame.errHandlers.last()
 
 	// if we have catch>0 goto catch else follow finally (one of them must be set)
-	if handler.catch > 0 {
+	// exit errors cannot be caught, only finally is followed for them.
+	if handler.catch > 0 && !errors.Is(err, ErrExit) {
 		vm.ip = handler.catch - 1
 	} else if handler.finally > 0 {
 		vm.ip = handler.finally - 1
@@ -1001,8 +1124,8 @@ func (vm *VM) handleThrownError(frame *frame, err *RuntimeError) error {
 }
 
 func (vm *VM) xOpCallName() error {
-	numArgs := int(vm.curInsts[vm.ip+1])
-	flags := int(vm.curInsts[vm.ip+2]) // 0 or 1
+	numArgs := int(vm.curOps[vm.ip].a)
+	flags := int(vm.curOps[vm.ip].b) // 0 or 1
 	obj := vm.stack[vm.sp-numArgs-2]
 	name := vm.stack[vm.sp-1]
 
@@ -1051,8 +1174,8 @@ func (vm *VM) xOpCallName() error {
 }
 
 func (vm *VM) xOpCall() error {
-	numArgs := int(vm.curInsts[vm.ip+1])
-	flags := int(vm.curInsts[vm.ip+2]) // 0 or 1
+	numArgs := int(vm.curOps[vm.ip].a)
+	flags := int(vm.curOps[vm.ip].b) // 0 or 1
 	callee := vm.stack[vm.sp-numArgs-1]
 	return vm.xOpCallAny(callee, numArgs, flags)
 }
@@ -1176,12 +1299,14 @@ func (vm *VM) xOpCallCompiled(cfunc *CompiledFunction, numArgs, flags int) error
 	}
 
 	frame.fn = cfunc
+	frame.ops = vm.bytecode.decodedInsts(cfunc)
 	frame.freeVars = cfunc.Free
 	frame.errHandlers = nil
 	frame.basePointer = basePointer
 
 	vm.curFrame.ip = vm.ip + 2
 	vm.curInsts = cfunc.Instructions
+	vm.curOps = frame.ops
 	vm.curFrame = frame
 	vm.sp = basePointer + numLocals
 	vm.ip = -1
@@ -1270,8 +1395,38 @@ func lastAsSlice(vm *VM) ([]Object, error) {
 	}
 }
 
+func (vm *VM) xOpStrictCompare() error {
+	tok := token.Token(vm.curOps[vm.ip].a)
+	left, right := vm.stack[vm.sp-2], vm.stack[vm.sp-1]
+	// frozen values are compared like their mutable versions
+	sameType := unfreeze(left).TypeName() == unfreeze(right).TypeName()
+
+	var value Object
+	switch tok {
+	case token.Equal:
+		value = Bool(sameType && left.Equal(right))
+	case token.NotEqual:
+		value = Bool(!sameType || !left.Equal(right))
+	default:
+		if !sameType {
+			return NewOperandTypeError(
+				tok.String(), left.TypeName(), right.TypeName())
+		}
+		var err error
+		if value, err = left.BinaryOp(tok, right); err != nil {
+			return err
+		}
+	}
+
+	vm.stack[vm.sp-2] = value
+	vm.sp--
+	vm.stack[vm.sp] = nil
+	vm.ip++
+	return nil
+}
+
 func (vm *VM) xOpUnary() error {
-	tok := token.Token(vm.curInsts[vm.ip+1])
+	tok := token.Token(vm.curOps[vm.ip].a)
 	right := vm.stack[vm.sp-1]
 	var value Object
 
@@ -1411,7 +1566,7 @@ func (vm *VM) xOpSliceIndex() error {
 	case Array:
 		vm.stack[vm.sp] = obj[low:high]
 	case String:
-		vm.stack[vm.sp] = obj[low:high]
+		vm.stack[vm.sp] = stringObject(string(obj[low:high]))
 	case Bytes:
 		vm.stack[vm.sp] = obj[low:high]
 	}
@@ -1452,6 +1607,47 @@ func (vm *VM) getSourcePos() parser.Pos {
 	return vm.curFrame.fn.SourcePos(vm.ip)
 }
 
+// CallFrame represents a frame in the call stack of VM.
+type CallFrame struct {
+	Fn  *CompiledFunction
+	Pos parser.SourceFilePos
+}
+
+// CallStack returns the frames of the call stack of the running VM in caller to
+// callee order, last frame holds the current position. It must be called in
+// the goroutine running the VM, e.g. by a function called by the VM. Note that
+// callables invoked by Go functions like builtins run in a separate VM so the
+// frames of the caller VM are not included.
+func (vm *VM) CallStack() []CallFrame {
+	if vm.curFrame == nil || vm.frameIndex < 1 {
+		return nil
+	}
+
+	var fileSet *parser.SourceFileSet
+	if vm.bytecode != nil {
+		fileSet = vm.bytecode.FileSet
+	}
+
+	position := func(pos parser.Pos) parser.SourceFilePos {
+		if fileSet == nil {
+			return parser.SourceFilePos{Offset: int(pos)}
+		}
+		return fileSet.Position(pos)
+	}
+
+	frames := make([]CallFrame, vm.frameIndex)
+	for i := 0; i < vm.frameIndex-1; i++ {
+		// saved ip points to the last operand of call instruction
+		f := &(vm.frames[i])
+		frames[i] = CallFrame{Fn: f.fn, Pos: position(f.fn.SourcePos(f.ip))}
+	}
+	frames[len(frames)-1] = CallFrame{
+		Fn:  vm.curFrame.fn,
+		Pos: position(vm.getSourcePos()),
+	}
+	return frames
+}
+
 type errHandler struct {
 	sp       int
 	catch    int
@@ -1515,6 +1711,7 @@ func (t *errHandlers) hasReturnTo() int {
 
 type frame struct {
 	fn          *CompiledFunction
+	ops         []decodedInst
 	freeVars    []*ObjectPtr
 	ip          int
 	basePointer int
@@ -1600,6 +1797,7 @@ func (inv *Invoker) Acquire() {
 func (inv *Invoker) acquire(usePool bool) {
 	if !inv.isCompiled {
 		inv.child = inv.vm
+		return
 	}
 	if inv.child != nil {
 		return
@@ -1627,7 +1825,7 @@ func (inv *Invoker) Invoke(args ...Object) (Object, error) {
 	if inv.child == nil {
 		inv.acquire(false)
 	}
-	if inv.child.Aborted() {
+	if inv.child != nil && inv.child.Aborted() {
 		return Undefined, ErrVMAborted
 	}
 	if inv.isCompiled {
@@ -1684,12 +1882,20 @@ func (v *vmPool) _acquire(vm *VM, cf *CompiledFunction) *VM {
 	vm.bytecode.Constants = v.root.bytecode.Constants
 	vm.bytecode.NumModules = v.root.bytecode.NumModules
 	vm.bytecode.Main = cf
+	vm.bytecode.decoded.Store(v.root.bytecode.decodedMap())
 	vm.constants = v.root.bytecode.Constants
 	vm.modulesCache = v.root.modulesCache
 	vm.pool = vmPool{
 		root: v.root,
 	}
 	vm.noPanic = v.root.noPanic
+	cfg := v.root.loadLogConfig()
+	vm.logConfig.Store(&cfg)
+	vm.onAcquire = v.root.onAcquire
+	vm.onRelease = v.root.onRelease
+	vm.cover = v.root.cover
+	vm.prof = v.root.prof
+	vm.resetInstrumentation()
 
 	if v.vms == nil {
 		v.vms = make(map[*VM]struct{})
@@ -599,6 +599,45 @@ func TestVMLoop(t *testing.T) {
 	}
 	return x
 	`, nil, Int(1))
+
+	// finally blocks of completed nested try statements are not run again
+	expectRun(t, `
+	var out = []
+	for i := 0; i < 3; i++ {
+		try {
+			if i == 1 {
+				continue
+			}
+			try {
+				out = append(out, "b" + i)
+			} finally {
+				out = append(out, "d" + i)
+			}
+		} finally {
+			out = append(out, "c" + i)
+		}
+	}
+	return out
+	`, nil, Array{String("b0"), String("d0"), String("c0"), String("c1"),
+		String("b2"), String("d2"), String("c2")})
+
+	expectRun(t, `
+	var out = []
+	var f = func() {
+		for i := 0; i < 2; i++ {
+			try {
+				try {} finally { out = append(out, "i" + i) }
+				if i == 1 {
+					return
+				}
+			} finally {
+				out = append(out, "o" + i)
+			}
+		}
+	}
+	f()
+	return out
+	`, nil, Array{String("i0"), String("o0"), String("i1"), String("o1")})
 }
 
 func TestVMErrorUnwrap(t *testing.T) {
@@ -639,6 +678,290 @@ func TestVMErrorUnwrap(t *testing.T) {
 	expectErrIs(t, `throw TypeError.New("foo")`, newOpts().Globals(g), ErrType)
 }
 
+func TestVMThrowf(t *testing.T) {
+	var g Object = Map{"fn": &Function{
+		Value: func(args ...Object) (Object, error) {
+			if len(args) != 1 {
+				return nil, Throwf(ErrWrongNumArguments,
+					"want=1 got=%d", len(args))
+			}
+			if kind, ok := args[0].(*Error); ok {
+				return nil, Throwf(kind, "%s-%d", "x", 1)
+			}
+			return nil, Throwf(ErrType, "unexpected %s", args[0].TypeName())
+		},
+	}}
+	expectRun(t, `
+	global fn
+	try {
+		fn(1)
+	} catch err {
+		return [isError(err, TypeError), err.Name, err.Message]
+	}`, newOpts().Globals(g).Skip2Pass(),
+		Array{True, String("TypeError"), String("unexpected int")})
+	expectRun(t, `
+	global fn
+	try {
+		fn()
+	} catch err {
+		return [isError(err, WrongNumArgumentsError), err.Message]
+	}`, newOpts().Globals(g).Skip2Pass(),
+		Array{True, String("want=1 got=0")})
+	expectRun(t, `
+	global fn
+	MyError := errorKind("MyError")
+	try {
+		fn(MyError)
+	} catch err {
+		match err {
+		case TypeError:
+			return "type"
+		case MyError:
+			return string(err)
+		}
+	}`, newOpts().Globals(g).Skip2Pass(), String("MyError: x-1"))
+	expectErrIs(t, `global fn; fn(1)`, newOpts().Globals(g), ErrType)
+
+	// position of the call is added to the stack trace
+	bc, err := Compile([]byte("global fn\n\nfn(2)"), DefaultCompilerOptions)
+	require.NoError(t, err)
+	_, err = NewVM(bc).Run(g)
+	var re *RuntimeError
+	require.True(t, errors.As(err, &re))
+	require.Equal(t, 1, len(re.StackTrace()))
+	require.Equal(t, "(main):3:1", re.StackTrace()[0].String())
+
+	require.Equal(t, &Error{Name: "TypeError", Message: "a 1", Cause: ErrType},
+		Throwf(ErrType, "a %d", 1))
+	require.Equal(t, &Error{Message: "b"}, Throwf(nil, "b"))
+}
+
+func TestVMBareThrow(t *testing.T) {
+	// bare throw re-throws the caught error which is caught by outer try
+	expectRun(t, `
+	try {
+		try {
+			throw error("inner")
+		} catch err {
+			throw
+		}
+	} catch err {
+		return string(err)
+	}`, nil, String("error: inner"))
+	expectRun(t, `
+	try {
+		try {
+			1/0
+		} catch {
+			throw
+		}
+	} catch err {
+		return isError(err, ZeroDivisionError)
+	}`, nil, True)
+
+	// caught error is re-thrown even if catch variable is reassigned
+	expectRun(t, `
+	try {
+		try {
+			throw "x"
+		} catch err {
+			err = "y"
+			throw
+		}
+	} catch err {
+		return err.Message
+	}`, nil, String("x"))
+
+	// bare throw belongs to the innermost catch block
+	expectRun(t, `
+	try {
+		try {
+			throw "outer"
+		} catch err {
+			try {
+				throw "inner"
+			} catch {
+			}
+			throw
+		}
+	} catch err {
+		return err.Message
+	}`, nil, String("outer"))
+	expectRun(t, `
+	try {
+		try {
+			throw "outer"
+		} catch err {
+			try {
+				throw "inner"
+			} catch {
+				throw
+			}
+		}
+	} catch err {
+		return err.Message
+	}`, nil, String("inner"))
+
+	// finally block runs before re-throw
+	expectRun(t, `
+	x := 0
+	try {
+		try {
+			throw "a"
+		} catch {
+			throw
+		} finally {
+			x = 1
+		}
+	} catch err {
+		return [x, err.Message]
+	}`, nil, Array{Int(1), String("a")})
+
+	// finally block in a catch block can re-throw the caught error
+	expectRun(t, `
+	try {
+		try {
+			throw "a"
+		} catch {
+			try {} finally { throw }
+		}
+	} catch err {
+		return err.Message
+	}`, nil, String("a"))
+
+	expectErrIs(t, `try { throw TypeError.New("x") } catch { throw }`,
+		nil, ErrType)
+	expectErrHas(t, `throw`, newOpts().CompilerError(),
+		`Compile Error: throw without expression outside of catch block`)
+	expectErrHas(t, `try {} catch err { f := func() { throw } }`,
+		newOpts().CompilerError(),
+		`Compile Error: throw without expression outside of catch block`)
+	expectErrHas(t, `try { throw } catch {}`, newOpts().CompilerError(),
+		`Compile Error: throw without expression outside of catch block`)
+}
+
+func TestVMCleanup(t *testing.T) {
+	// cleanups run in LIFO order at the end of the enclosing block
+	expectRun(t, `
+	log := []
+	push := func(v) { log = append(log, v) }
+	if true {
+		log = append(log, 1)
+		cleanup push("c1")
+		log = append(log, 2)
+		cleanup push("c2")
+		log = append(log, 3)
+	}
+	log = append(log, 4)
+	return log`, nil,
+		Array{Int(1), Int(2), Int(3), String("c2"), String("c1"), Int(4)})
+
+	// break and continue
+	expectRun(t, `
+	log := []
+	push := func(v) { log = append(log, v) }
+	for i := 0; i < 3; i++ {
+		cleanup push("c" + i)
+		if i == 0 {
+			continue
+		}
+		if i == 1 {
+			break
+		}
+	}
+	return log`, nil, Array{String("c0"), String("c1")})
+	expectRun(t, `
+	log := []
+	push := func(v) { log = append(log, v) }
+	for i := 0; i < 3; i++ {
+		cleanup push("c" + i)
+		if i == 1 {
+			continue
+		}
+		cleanup push("d" + i)
+	}
+	return log`, nil, Array{String("d0"), String("c0"), String("c1"),
+		String("d2"), String("c2")})
+
+	// throw within the block
+	expectRun(t, `
+	log := []
+	push := func(v) { log = append(log, v) }
+	try {
+		cleanup push("c1")
+		cleanup push("c2")
+		throw "x"
+		log = append(log, "unreachable")
+	} catch err {
+		log = append(log, err.Message)
+	}
+	return log`, nil, Array{String("c2"), String("c1"), String("x")})
+
+	// return from function
+	expectRun(t, `
+	log := []
+	push := func(v) { log = append(log, v) }
+	f := func() {
+		cleanup push("c")
+		return "r"
+	}
+	return [f(), log]`, nil, Array{String("r"), Array{String("c")}})
+
+	// cleanups which are not reached do not run
+	expectRun(t, `
+	log := []
+	push := func(v) { log = append(log, v) }
+	f := func(early) {
+		cleanup push("c1")
+		if early {
+			return
+		}
+		cleanup push("c2")
+	}
+	f(true)
+	f(false)
+	return log`, nil, Array{String("c1"), String("c2"), String("c1")})
+
+	// cleanup expression is evaluated at exit
+	expectRun(t, `
+	x := 1
+	out := 0
+	set := func(v) { out = v }
+	if true {
+		cleanup set(x)
+		x = 2
+	}
+	return out`, nil, Int(2))
+
+	// errors thrown by cleanups are propagated
+	expectErrHas(t, `if true { cleanup 1/0 }`, nil, "ZeroDivisionError")
+}
+
+func TestWrapGoError(t *testing.T) {
+	require.Nil(t, WrapGoError(nil))
+	// nil *Error is not a nil error
+	var err error = WrapGoError(nil)
+	require.True(t, err != nil)
+	require.Same(t, ErrType, WrapGoError(ErrType))
+	e := ErrType.NewError("x")
+	require.Same(t, e, WrapGoError(&RuntimeError{Err: e}))
+
+	err1 := errors.New("err1")
+	wrapped := WrapGoError(err1)
+	require.Equal(t, "err1", wrapped.Message)
+	require.Equal(t, "", wrapped.Name)
+	require.True(t, errors.Is(wrapped, err1))
+
+	var g Object = Map{"fn": &Function{
+		Value: func(args ...Object) (Object, error) {
+			return WrapGoError(err1), nil
+		},
+	}}
+	expectRun(t, `global fn; return [isError(fn()), string(fn())]`,
+		newOpts().Globals(g), Array{True, String("error: err1")})
+	expectErrIs(t, `global fn; throw fn()`, newOpts().Globals(g), err1)
+}
+
 func TestVMExamples(t *testing.T) {
 	ex1Module := `
 	var numOfErrors = 0
@@ -4,15 +4,18 @@ import (
 	"bytes"
 	"errors"
 	"fmt"
+	"math"
 	"os"
 	"reflect"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/ozanh/ugo/tests"
+	"github.com/ozanh/ugo/token"
 
 	. "github.com/ozanh/ugo"
 )
@@ -595,6 +598,181 @@ func TestVMBoolean(t *testing.T) {
 	expectRun(t, `return !(true - false)`, nil, False)
 	expectErrIs(t, `return true/false`, nil, ErrZeroDivision)
 	expectErrIs(t, `return 1/false`, nil, ErrZeroDivision)
+	expectErrIs(t, `return true%false`, nil, ErrZeroDivision)
+	expectErrIs(t, `return 1%false`, nil, ErrZeroDivision)
+}
+
+func TestVMStrictComparison(t *testing.T) {
+	lenient := []struct {
+		s string
+		e Object
+	}{
+		{s: `1 == 1u`, e: True},
+		{s: `1 == 1.0`, e: True},
+		{s: `1 == true`, e: True},
+		{s: `0 == false`, e: True},
+		{s: `'a' == 97`, e: True},
+		{s: `"a" == bytes("a")`, e: True},
+		{s: `1 != 1u`, e: False},
+		{s: `1 < 2u`, e: True},
+		{s: `1.5 > 1`, e: True},
+		{s: `true >= 1`, e: True},
+		{s: `undefined < 1`, e: True},
+		{s: `"a" < bytes("b")`, e: True},
+	}
+	strict := []struct {
+		s string
+		e Object
+	}{
+		{s: `1 == 1u`, e: False},
+		{s: `1 == 1.0`, e: False},
+		{s: `1 == true`, e: False},
+		{s: `0 == false`, e: False},
+		{s: `'a' == 97`, e: False},
+		{s: `"a" == bytes("a")`, e: False},
+		{s: `1 == undefined`, e: False},
+		{s: `1 != 1u`, e: True},
+		{s: `1 != true`, e: True},
+		{s: `1 == 1`, e: True},
+		{s: `1 != 1`, e: False},
+		{s: `"a" == "a"`, e: True},
+		{s: `undefined == undefined`, e: True},
+		{s: `[1, 2] == [1, 2]`, e: True},
+		{s: `[1] == [1u]`, e: True},
+		{s: `{a: 1} != {a: 1}`, e: False},
+		{s: `1 < 2`, e: True},
+		{s: `2u <= 1u`, e: False},
+		{s: `1.5 > 1.0`, e: True},
+		{s: `'b' >= 'a'`, e: True},
+		{s: `"a" < "b"`, e: True},
+		{s: `bytes("a") < bytes("b")`, e: True},
+		{s: `func() { a := 1; b := 1u; return a == b }()`, e: False},
+		{s: `func() { a := 1; b := 1; return a == b }()`, e: True},
+		{s: `func() { a := freeze({a: [1]}); return a == {a: [1]} }()`, e: True},
+		{s: `func() { a := freeze([1]); return [1] != a }()`, e: False},
+		{s: `func() { a := freeze([1]); return a == freeze([1]) }()`, e: True},
+		{s: `func() { a := freeze([1]); return a == {} }()`, e: False},
+	}
+	for _, tC := range lenient {
+		expectRun(t, `return `+tC.s, nil, tC.e)
+	}
+	for _, tC := range strict {
+		expectRun(t, `return `+tC.s, newOpts().StrictComparison(), tC.e)
+	}
+
+	for _, s := range []string{
+		`1 < 2u`, `1.5 > 1`, `true >= 1`, `'a' <= 98`, `undefined < 1`,
+		`"a" < bytes("b")`, `func() { a := 1; return a > 0.5 }()`,
+	} {
+		expectErrIs(t, `return `+s, newOpts().StrictComparison(), ErrType)
+	}
+	expectErrHas(t, `return 1 < 2u`, newOpts().StrictComparison(),
+		`TypeError: unsupported operand types for '<': 'int' and 'uint'`)
+	// values of same type are compared by their BinaryOp method
+	expectErrHas(t, `return [1] < [2]`, newOpts().StrictComparison(),
+		`TypeError: unsupported operand types for '<': 'array' and 'array'`)
+
+	// optimizer must not fold comparisons of different types in strict mode
+	opts := DefaultCompilerOptions
+	opts.StrictComparison = true
+	bc, err := Compile([]byte(`return [1 == 1u, 2 == 2, 1 != true]`), opts)
+	require.NoError(t, err)
+	ret, err := NewVM(bc).Run(nil)
+	require.NoError(t, err)
+	require.Equal(t, Array{False, True, True}, ret)
+
+	// imported source modules are compiled in strict mode as well
+	expectRun(t, `return import("mod")`,
+		newOpts().StrictComparison().Module("mod", `return 1 == 1u`), False)
+}
+
+func TestVMDeterministicMaps(t *testing.T) {
+	keys := `
+	keys := []
+	for k, v in m {
+		keys = append(keys, [k, v])
+	}
+	return keys`
+	expected := Array{
+		Array{String("a"), Int(1)},
+		Array{String("b"), Int(2)},
+		Array{String("c"), Int(3)},
+	}
+	// random order may produce sorted keys by chance, so run several times
+	for i := 0; i < 10; i++ {
+		expectRun(t, `m := {b: 2, a: 1, c: 3}`+keys,
+			newOpts().DeterministicMaps(), expected)
+		expectRun(t, `m := syncMap({b: 2, a: 1, c: 3})`+keys,
+			newOpts().DeterministicMaps(), expected)
+		expectRun(t, `global m`+keys,
+			newOpts().DeterministicMaps().Globals(
+				Map{"m": &SyncMap{Value: Map{"c": Int(3), "b": Int(2), "a": Int(1)}}}),
+			expected)
+		expectRun(t, `return func(m) {`+keys+`}({c: 3, a: 1, b: 2})`,
+			newOpts().DeterministicMaps(), expected)
+		expectRun(t, `return import("mod")`,
+			newOpts().DeterministicMaps().Module("mod",
+				`m := {c: 3, b: 2, a: 1}`+keys), expected)
+	}
+	expectRun(t, `m := {}`+keys, newOpts().DeterministicMaps(), Array{})
+	// other iterables are not affected
+	expectRun(t, `
+	r := []
+	for i, v in [3, 1, 2] { r = append(r, v) }
+	for i, v in "cab" { r = append(r, v) }
+	return r`, newOpts().DeterministicMaps(),
+		Array{Int(3), Int(1), Int(2), Char('c'), Char('a'), Char('b')})
+
+	// default iteration visits all keys in any order
+	opts := DefaultCompilerOptions
+	bc, err := Compile([]byte(`m := {b: 2, a: 1, c: 3}`+keys), opts)
+	require.NoError(t, err)
+	ret, err := NewVM(bc).Run(nil)
+	require.NoError(t, err)
+	require.ElementsMatch(t, expected, ret)
+}
+
+func TestVMCopyOnAssign(t *testing.T) {
+	// arrays and maps are assigned by reference by default
+	expectRun(t, `a1 := [1, 2]; a2 := a1; a1[0] = 5; return a2`,
+		nil, Array{Int(5), Int(2)})
+	expectRun(t, `m1 := {a: 1}; m2 := m1; m1.a = 5; return m2`,
+		nil, Map{"a": Int(5)})
+
+	// value semantics
+	opts := newOpts().CopyOnAssign()
+	expectRun(t, `a1 := [1, 2]; a2 := a1; a1[0] = 5; return [a1, a2]`,
+		opts, Array{Array{Int(5), Int(2)}, Array{Int(1), Int(2)}})
+	expectRun(t, `m1 := {a: 1}; m2 := m1; m1.a = 5; return [m1, m2]`,
+		opts, Array{Map{"a": Int(5)}, Map{"a": Int(1)}})
+	expectRun(t, `a1 := [[1]]; var a2 = a1; a1[0][0] = 5; return a2`,
+		opts, Array{Array{Int(1)}})
+	expectRun(t, `a1 := [1]; a2 := [a1]; a1[0] = 5; return a2`,
+		opts, Array{Array{Int(1)}})
+	expectRun(t, `a1 := [1]; a2 := undefined; a2 = a1; a1[0] = 5; return a2`,
+		opts, Array{Int(1)})
+	expectRun(t, `a1 := [1]; m := {}; m.x = a1; a1[0] = 5; return m`,
+		opts, Map{"x": Array{Int(1)}})
+	expectRun(t, `a1 := [1]; x, y := [a1, 2]; a1[0] = 5; return x`,
+		opts, Array{Int(1)})
+	expectRun(t, `global g; a1 := [1]; g = a1; a1[0] = 5; return g`,
+		newOpts().CopyOnAssign().Globals(Map{}), Array{Int(1)})
+	expectRun(t, `a1 := [1]; f := func() { a2 := a1; a2[0] = 5 }; f(); return a1`,
+		opts, Array{Int(1)})
+	cyclic := Map{"a": Int(1)}
+	cyclic["self"] = cyclic
+	expectRun(t, `global g; m := g; m.a = 2; return [g.a, m.self.a, same(m, m.self)]`,
+		newOpts().CopyOnAssign().Globals(Map{"g": cyclic}).Skip2Pass(),
+		Array{Int(1), Int(2), True})
+	expectRun(t, `m := {}; s := syncMap({m: m}); m.s = s; c := m
+	return [same(c, c.s.m), same(c.s, s)]`,
+		opts, Array{True, False})
+
+	// other values and arguments are not copied
+	expectRun(t, `b1 := bytes(1); b2 := b1; b1[0] = 5; return b2`,
+		opts, Bytes{5})
+	expectRun(t, `a1 := [1]; f := func(a) { a[0] = 5 }; f(a1); return a1`,
+		opts, Array{Int(5)})
 }
 
 func TestVMUndefined(t *testing.T) {
@@ -695,6 +873,174 @@ func TestVMBuiltinFunction(t *testing.T) {
 	expectErrIs(t, `copy()`, nil, ErrWrongNumArguments)
 	expectErrIs(t, `copy(1, 2)`, nil, ErrWrongNumArguments)
 
+	// copy is shallow, clone is deep
+	expectRun(t, `a := [[1]]; b := copy(a); a[0][0] = 2; return b`,
+		nil, Array{Array{Int(2)}})
+	expectRun(t, `a := {x: {y: 1}}; b := copy(a); a.x.y = 2; return b`,
+		nil, Map{"x": Map{"y": Int(2)}})
+	expectRun(t, `a := [bytes(1)]; b := copy(a); a[0][0] = 2; return b`,
+		nil, Array{Bytes{2}})
+	expectRun(t, `a := [[1]]; b := clone(a); a[0][0] = 2; return b`,
+		nil, Array{Array{Int(1)}})
+	expectRun(t, `a := {x: {y: 1}}; b := clone(a); a.x.y = 2; return b`,
+		nil, Map{"x": Map{"y": Int(1)}})
+	expectRun(t, `a := [bytes(1)]; b := clone(a); a[0][0] = 2; return b`,
+		nil, Array{Bytes{1}})
+	expectRun(t, `a := {x: [{y: 1}]}; b := clone(a); a.x[0].y = 2; return b`,
+		nil, Map{"x": Array{Map{"y": Int(1)}}})
+	expectRun(t, `a := [[1]]; b := clone(a); return a == b`, nil, True)
+	expectRun(t, `a := error("x"); b := clone(a); return string(a) == string(b)`,
+		nil, True)
+	expectRun(t, `return clone(undefined)`, nil, Undefined)
+	expectRun(t, `return clone(1)`, nil, Int(1))
+	expectRun(t, `return clone("x")`, nil, String("x"))
+	expectRun(t, `a := bytes(1); b := clone(a); a[0] = 2; return b`,
+		nil, Bytes{1})
+	g = &SyncMap{Value: Map{"x": Map{"y": Int(1)}}}
+	expectRun(t, `global g; b := copy(g); g.x.y = 2; return b`,
+		newOpts().Globals(Map{"g": g.DeepCopy()}).Skip2Pass(),
+		&SyncMap{Value: Map{"x": Map{"y": Int(2)}}})
+	expectRun(t, `global g; b := clone(g); g.x.y = 2; return b`,
+		newOpts().Globals(Map{"g": g.DeepCopy()}).Skip2Pass(),
+		&SyncMap{Value: Map{"x": Map{"y": Int(1)}}})
+	// cyclic and shared references are preserved in clones
+	expectRun(t, `m := {a: 1}; m.self = m; c := clone(m); c.a = 2
+	return [same(c, c.self), same(c, m), m.a, c.self.a]`,
+		nil, Array{True, False, Int(1), Int(2)})
+	expectRun(t, `a := [1, 2]; a[1] = a; c := clone(a); return [same(c, c[1]), same(a, c[1])]`,
+		nil, Array{True, False})
+	expectRun(t, `s := [1]; c := clone({x: s, y: s}); c.x[0] = 2; return c.y`,
+		nil, Array{Int(2)})
+	expectRun(t, `s := syncMap({}); s.x = s; c := clone(s)
+	return [same(c, c.x), same(c, s)]`,
+		nil, Array{True, False})
+	expectRun(t, `m := {}; s := syncMap({m: m}); m.s = s; c := clone(m)
+	return [same(c, c.s.m), same(c.s, s)]`,
+		nil, Array{True, False})
+	expectErrIs(t, `clone()`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `clone(1, 2)`, nil, ErrWrongNumArguments)
+
+	frozen := &Frozen{Value: Map{
+		"a": &Frozen{Value: Array{Int(1), Int(2)}},
+		"b": String("x"),
+	}}
+	expectRun(t, `return freeze({a: [1, 2], b: "x"})`, nil, frozen)
+	expectRun(t, `m := {a: [1, 2], b: "x"}; return freeze(m)`, nil, frozen)
+	expectRun(t, `m := {a: [1, 2]}; f := freeze(m); m.a[0] = 3; m.b = 4
+	return f`, nil, &Frozen{Value: Map{"a": &Frozen{Value: Array{Int(1), Int(2)}}}})
+	expectRun(t, `f := freeze([1, {x: 2}]); return [f[0], f[1].x, len(f), len(f[1])]`,
+		nil, Array{Int(1), Int(2), Int(2), Int(1)})
+	expectRun(t, `s := 0; for v in freeze([1, 2, 3]) { s += v }; return s`,
+		nil, Int(6))
+	expectRun(t, `return freeze([1, 2]) == freeze([1, 2])`, nil, True)
+	expectRun(t, `return [typeName(freeze([])), typeName(freeze({}))]`,
+		nil, Array{String("frozenArray"), String("frozenMap")})
+	expectRun(t, `m := {a: [1], b: "x"}
+	return [m == freeze(m), freeze(m) == m, [1, [2]] == freeze([1, [2]])]`,
+		nil, Array{True, True, True})
+	expectRun(t, `return [{a: 1} == freeze({a: 2}), freeze([1]) == [1, 2]]`,
+		nil, Array{False, False})
+	expectRun(t, `c := copy(freeze({a: [1]})); c.b = 2; c.a = 3; return c`,
+		nil, Map{"a": Int(3), "b": Int(2)})
+	expectRun(t, `c := clone(freeze({a: [1]})); c.a[0] = 3; return c`,
+		nil, Map{"a": Array{Int(3)}})
+	expectRun(t, `c := copy(freeze([[1]])); return [typeName(c), typeName(c[0])]`,
+		nil, Array{String("array"), String("frozenArray")})
+	expectRun(t, `return [typeName(copy(freeze({}))), typeName(clone(freeze([])))]`,
+		nil, Array{String("map"), String("array")})
+	expectRun(t, `c := clone(freeze([[1], {a: [2]}]))
+	return [typeName(c[0]), typeName(c[1]), typeName(c[1].a)]`,
+		nil, Array{String("array"), String("map"), String("array")})
+	expectRun(t, `f := freeze([1]); c := clone({a: f}); return same(c.a, f)`,
+		nil, True)
+	expectRun(t, `f := freeze({a: 1}); c := copy(f); c.a = 2; return f.a`,
+		nil, Int(1))
+	expectRun(t, `return [isMap(freeze({})), isArray(freeze([])),
+		isMap(freeze([])), isArray(freeze({}))]`,
+		nil, Array{True, True, False, False})
+	expectRun(t, `m := {a: 1}; m.self = m; f := freeze(m)
+	return [same(f, f.self), f.self.a, isMap(f.self)]`,
+		nil, Array{True, Int(1), True})
+	expectRun(t, `m := {}; m.self = m; return string(freeze(m))`,
+		nil, String(`{"self": {...}}`))
+	expectRun(t, `a := [1, 2]; a[1] = a; f := freeze(a); return same(f, f[1])`,
+		nil, True)
+	expectRun(t, `s := [1]; f := freeze([s, s]); return same(f[0], f[1])`,
+		nil, True)
+	expectRun(t, `m := {a: 1}; m.self = m; c := clone(freeze(m)); c.a = 2
+	return [same(c, c.self), typeName(c.self), c.self.a]`,
+		nil, Array{True, String("map"), Int(2)})
+	f := `f := freeze({a: 1, b: {c: 2}});`
+	expectRun(t, f+`return merge(f, {d: 3})`, nil,
+		Map{"a": Int(1), "b": &Frozen{Value: Map{"c": Int(2)}}, "d": Int(3)})
+	expectRun(t, f+`m := deepMerge({b: {e: 4}}, f); m.b.c = 5; return [m, f.b.c]`,
+		nil, Array{Map{"a": Int(1), "b": Map{"c": Int(5), "e": Int(4)}}, Int(2)})
+	expectRun(t, f+`return [pick(f, ["a"]), omit(f, ["b"])]`,
+		nil, Array{Map{"a": Int(1)}, Map{"a": Int(1)}})
+	expectRun(t, f+`s := syncMap(f); s.a = 2; return [s.a, f.a]`,
+		nil, Array{Int(2), Int(1)})
+	expectRun(t, f+`e := error("x", f); e.Data.a = 2; return [e.Data.a, f.a]`,
+		nil, Array{Int(2), Int(1)})
+	expectErrHas(t, f+`transaction(f, func(m) {})`, nil,
+		`TypeError: invalid type for argument '1st': expected map, found frozenMap`)
+	expectRun(t, `return freeze(1)`, nil, Int(1))
+	expectRun(t, `return freeze(undefined)`, nil, Undefined)
+	expectErrIs(t, `f := freeze([1]); f[0] = 2`, nil, ErrNotIndexAssignable)
+	expectErrIs(t, `f := freeze({a: 1}); f.b = 2`, nil, ErrNotIndexAssignable)
+	expectErrIs(t, `f := freeze({a: [1]}); f.a[0] = 2`, nil, ErrNotIndexAssignable)
+	expectErrIs(t, `m := {a: {b: 1}}; f := freeze(m); f.a.b = 2`,
+		nil, ErrNotIndexAssignable)
+	expectErrIs(t, `freeze()`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `freeze(1, 2)`, nil, ErrWrongNumArguments)
+	// constant frozen literals are shared
+	expectRun(t, `a := []
+	for i := 0; i < 2; i++ { a = append(a, freeze({x: [1]})) }
+	return same(a[0], a[1]) && same(a[0].x, a[1].x)`, nil, True)
+	expectRun(t, `f := func() { return freeze([1, "a", 1.5, undefined]) }
+	return same(f(), f())`, nil, True)
+	expectRun(t, `a := []; x := 1
+	for i := 0; i < 2; i++ { a = append(a, freeze([x])) }
+	return same(a[0], a[1])`, nil, False)
+	expectRun(t, `freeze := func(v) { return v }; a := []
+	for i := 0; i < 2; i++ { a = append(a, freeze([1])) }
+	return same(a[0], a[1])`, nil, False)
+
+	expectRun(t, `a := [1]; b := a; return same(a, b)`, nil, True)
+	expectRun(t, `return same([1], [1])`, nil, False)
+	expectRun(t, `a := [1, 2]; return same(a, a[:1])`, nil, False)
+	expectRun(t, `m := {}; n := m; return same(m, n)`, nil, True)
+	expectRun(t, `return same({}, {})`, nil, False)
+	expectRun(t, `f := func() {}; return same(f, f)`, nil, True)
+	expectRun(t, `return [same(1, 1), same(1, 2), same(1, 1u), same("a", "a")]`,
+		nil, Array{True, False, False, True})
+	expectRun(t, `return same(undefined, undefined)`, nil, True)
+	expectRun(t, `return same(len, len)`, nil, True)
+	expectErrIs(t, `same(1)`, nil, ErrWrongNumArguments)
+
+	expectRun(t, `return syncMap({a: 1})`, nil, &SyncMap{Value: Map{"a": Int(1)}})
+	expectRun(t, `return syncMap({})`, nil, &SyncMap{Value: Map{}})
+	expectRun(t, `m := {a: 1}; s := syncMap(m); m.a = 2; m.b = 3; return s`,
+		nil, &SyncMap{Value: Map{"a": Int(1)}})
+	expectRun(t, `return isSyncMap(syncMap({}))`, nil, True)
+	expectRun(t, `return unsyncMap(syncMap({a: 1}))`, nil, Map{"a": Int(1)})
+	expectRun(t, `s := syncMap({a: 1}); m := unsyncMap(s); s.a = 2; s.b = 3
+	delete(s, "a"); return m`, nil, Map{"a": Int(1)})
+	expectRun(t, `s := syncMap({a: 1}); m := unsyncMap(s); m.a = 2; return s`,
+		nil, &SyncMap{Value: Map{"a": Int(1)}})
+	expectRun(t, `return isMap(unsyncMap(syncMap({})))`, nil, True)
+	expectRun(t, `global g; m := unsyncMap(g); g.x = 2; return m`,
+		newOpts().Globals(Map{"g": &SyncMap{Value: Map{"x": Int(1)}}}).Skip2Pass(),
+		Map{"x": Int(1)})
+	expectRun(t, `global g; return unsyncMap(g)`,
+		newOpts().Globals(Map{"g": &SyncMap{}}), Map{})
+	expectErrIs(t, `syncMap()`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `syncMap({}, {})`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `syncMap(syncMap({}))`, nil, ErrType)
+	expectErrIs(t, `syncMap([])`, nil, ErrType)
+	expectErrIs(t, `unsyncMap()`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `unsyncMap({})`, nil, ErrType)
+	expectErrIs(t, `unsyncMap(1)`, nil, ErrType)
+
 	expectRun(t, `return repeat("abc", 3)`, nil, String("abcabcabc"))
 	expectRun(t, `return repeat("abc", 2)`, nil, String("abcabc"))
 	expectRun(t, `return repeat("abc", 1)`, nil, String("abc"))
@@ -733,6 +1079,98 @@ func TestVMBuiltinFunction(t *testing.T) {
 	expectErrIs(t, `return repeat('a', 1)`, nil, ErrType)
 	expectErrIs(t, `return repeat({}, 1)`, nil, ErrType)
 
+	expectRun(t, `return zip([1, 2, 3], ["a", "b"])`, nil,
+		Array{Array{Int(1), String("a")}, Array{Int(2), String("b")}})
+	expectRun(t, `return zip([1, 2], ["a", "b", "c"], [true])`, nil,
+		Array{Array{Int(1), String("a"), True}})
+	expectRun(t, `return zip([1, 2])`, nil, Array{Array{Int(1)}, Array{Int(2)}})
+	expectRun(t, `return zip([], [1])`, nil, Array{})
+	expectRun(t, `return unzip(zip([1, 2, 3], ["a", "b", "c"]))`, nil,
+		Array{Array{Int(1), Int(2), Int(3)},
+			Array{String("a"), String("b"), String("c")}})
+	expectRun(t, `return unzip(zip([1, 2, 3], ["a", "b"]))`, nil,
+		Array{Array{Int(1), Int(2)}, Array{String("a"), String("b")}})
+	expectRun(t, `return unzip([[1, 2], [3]])`, nil, Array{Array{Int(1), Int(3)}})
+	expectRun(t, `return unzip([])`, nil, Array{})
+	expectErrIs(t, `zip()`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `zip([], 1)`, nil, ErrType)
+	expectErrHas(t, `zip([], [], "")`, nil,
+		`TypeError: invalid type for argument '3rd': expected array, found string`)
+	expectErrIs(t, `unzip()`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `unzip([1], [2])`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `unzip({})`, nil, ErrType)
+	expectErrIs(t, `unzip([[1], 2])`, nil, ErrType)
+
+	expectRun(t, `return groupBy([1, 2, 3, 4, 5], func(x) { return x % 2 == 0 ? "even" : "odd" })`,
+		nil, Map{"odd": Array{Int(1), Int(3), Int(5)}, "even": Array{Int(2), Int(4)}})
+	expectRun(t, `return groupBy([1, 2, 3], func(x) { return x % 2 })`,
+		nil, Map{"1": Array{Int(1), Int(3)}, "0": Array{Int(2)}})
+	expectRun(t, `return groupBy([], func(x) { return x })`, nil, Map{})
+	expectRun(t, `return groupBy(["a", "b"], string)`,
+		nil, Map{"a": Array{String("a")}, "b": Array{String("b")}})
+	expectRun(t, `return countBy(["apple", "avocado", "banana", "cherry", "blueberry"], func(w) { return w[0] })`,
+		nil, Map{"97": Int(2), "98": Int(2), "99": Int(1)})
+	expectRun(t, `return countBy(["apple", "avocado", "banana"], func(w) { return char(w[0]) })`,
+		nil, Map{"a": Int(2), "b": Int(1)})
+	expectRun(t, `return countBy([], func(x) { return x })`, nil, Map{})
+	expectErrHas(t, `groupBy([1, 2], func(x) { throw "key error" })`,
+		nil, "error: key error")
+	expectErrHas(t, `countBy([1, 2], func(x) { throw "key error" })`,
+		nil, "error: key error")
+	expectErrIs(t, `countBy([1, "a"], func(x) { return x - 1 })`,
+		nil, ErrType)
+	expectErrIs(t, `groupBy()`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `groupBy([])`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `groupBy({}, string)`, nil, ErrType)
+	expectErrIs(t, `groupBy([], 1)`, nil, ErrType)
+	expectErrIs(t, `countBy("", string)`, nil, ErrType)
+	expectErrIs(t, `countBy([], [])`, nil, ErrType)
+
+	expectRun(t, `return unique([1, 1, 2, 3, 3])`, nil, Array{Int(1), Int(2), Int(3)})
+	expectRun(t, `return unique([3, 1, 3, 2, 1])`, nil, Array{Int(3), Int(1), Int(2)})
+	expectRun(t, `return unique([1, 1u, 1.0, "1", '1'])`, nil,
+		Array{Int(1), String("1"), Char('1')})
+	expectRun(t, `return unique([[1], [1], {a: 1}, {a: 1}])`, nil,
+		Array{Array{Int(1)}, Map{"a": Int(1)}})
+	expectRun(t, `return unique([])`, nil, Array{})
+	expectRun(t, `return unique([{id: 1, v: "a"}, {id: 2, v: "b"}, {id: 1, v: "c"}],
+		func(x) { return x.id })`, nil,
+		Array{Map{"id": Int(1), "v": String("a")}, Map{"id": Int(2), "v": String("b")}})
+	expectRun(t, `return unique([], func(x) { return x })`, nil, Array{})
+	expectErrHas(t, `unique([1], func(x) { throw "key error" })`,
+		nil, "error: key error")
+	expectErrIs(t, `unique()`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `unique([], string, 1)`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `unique({})`, nil, ErrType)
+	expectErrIs(t, `unique([], 1)`, nil, ErrType)
+
+	expectRun(t, `return matchAll("a(\\d)", "a1 b2 a3")`, nil,
+		Array{Array{String("a1"), String("1")}, Array{String("a3"), String("3")}})
+	expectRun(t, `return matchAll("\\d+", "a1 b22")`, nil,
+		Array{Array{String("1")}, Array{String("22")}})
+	expectRun(t, `return matchAll("x", "abc")`, nil, Array{})
+	expectRun(t, `return matchAll("b+", bytes("abbc"))`, nil,
+		Array{Array{Bytes("bb")}})
+	expectRun(t, `return replaceAll("(\\w+)@(\\w+)", "foo@bar x@y", "$2@$1")`,
+		nil, String("bar@foo y@x"))
+	expectRun(t, `return replaceAll("a", "banana", "o")`, nil, String("bonono"))
+	expectRun(t, `return replaceAll("(a)", bytes("banana"), "<$1>")`, nil,
+		Bytes("b<a>n<a>n<a>"))
+	expectRun(t, `return replaceAll("a", bytes("banana"), bytes("o"))`, nil,
+		Bytes("bonono"))
+	expectRun(t, `return isError(matchAll("a(", "a"))`, nil, True)
+	expectRun(t, `return isError(replaceAll("[a", "a", ""))`, nil, True)
+	expectRun(t, `return matchAll("a(", "a").Message`, nil,
+		String("error parsing regexp: missing closing ): `a(`"))
+	expectErrIs(t, `matchAll("a")`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `matchAll(1, "a")`, nil, ErrType)
+	expectErrIs(t, `matchAll("a", 1)`, nil, ErrType)
+	expectErrIs(t, `replaceAll("a", "b")`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `replaceAll(1, "a", "b")`, nil, ErrType)
+	expectErrIs(t, `replaceAll("a", [], "b")`, nil, ErrType)
+	expectErrIs(t, `replaceAll("a", "a", 1)`, nil, ErrType)
+	expectErrIs(t, `replaceAll("a", bytes(), 1)`, nil, ErrType)
+
 	expectRun(t, `return contains("xyz", "y")`, nil, True)
 	expectRun(t, `return contains("xyz", "a")`, nil, False)
 	expectRun(t, `return contains({a: 1}, "a")`, nil, True)
@@ -821,6 +1259,357 @@ func TestVMBuiltinFunction(t *testing.T) {
 	expectErrIs(t, `sortReverse([], [])`, nil, ErrWrongNumArguments)
 	expectErrIs(t, `sortReverse({})`, nil, ErrType)
 
+	// sort and sortReverse are stable, 1 and 1.0 are equal
+	expectRun(t, `return sort([1.0, 2, 1, 0, 1u])`,
+		nil, Array{Int(0), Float(1), Int(1), Uint(1), Int(2)})
+	expectRun(t, `return sortReverse([1.0, 2, 1, 0, 1u])`,
+		nil, Array{Int(2), Float(1), Int(1), Uint(1), Int(0)})
+
+	people := `people := [
+		{first: "John", last: "Smith", id: 1},
+		{first: "Jane", last: "Doe", id: 2},
+		{first: "Adam", last: "Smith", id: 3},
+		{first: "Jane", last: "Doe", id: 4},
+		{first: "Alice", last: "Brown", id: 5},
+		{first: "John", last: "Smith", id: 6},
+	]
+	`
+	expectRun(t, people+`
+	sorted := sortByKeys(people, [func(p) { return p.last }, func(p) { return p.first }])
+	ids := []
+	for p in sorted { ids = append(ids, p.id) }
+	return ids`, nil,
+		Array{Int(5), Int(2), Int(4), Int(3), Int(1), Int(6)})
+	expectRun(t, people+`
+	sortByKeys(people, func(p) { return p.first })
+	ids := []
+	for p in people { ids = append(ids, p.id) }
+	return ids`, nil,
+		Array{Int(3), Int(5), Int(2), Int(4), Int(1), Int(6)})
+	expectRun(t, people+`
+	sortByKeys(people, [func(p) { return -len(p.first) }, string])
+	return people[0].id`, nil, Int(5))
+	expectRun(t, `return sortByKeys([], [])`, nil, Array{})
+	expectRun(t, `return sortByKeys([3, 1.0, 2, 1], [])`,
+		nil, Array{Int(3), Float(1), Int(2), Int(1)})
+	expectRun(t, `
+	calls := 0
+	sortByKeys([3, 1, 2], func(x) { calls++; return x })
+	return calls`, nil, Int(3))
+	expectErrIs(t, `sortByKeys()`, nil, ErrWrongNumArguments)
+
+	expectRun(t, `return searchSorted([], 1)`, nil, Int(0))
+	expectRun(t, `return searchSorted([1, 3, 5, 7], 5)`, nil, Int(2))
+	expectRun(t, `return searchSorted([1, 3, 5, 7], 4)`, nil, Int(2))
+	expectRun(t, `return searchSorted([1, 3, 5, 7], 0)`, nil, Int(0))
+	expectRun(t, `return searchSorted([1, 3, 5, 7], 8)`, nil, Int(4))
+	expectRun(t, `return searchSorted([1, 2, 2, 2, 3], 2)`, nil, Int(1))
+	expectRun(t, `return searchSorted([1, 2, 2.0, 3], 2u)`, nil, Int(1))
+	expectRun(t, `return searchSorted(["a", "c"], "b")`, nil, Int(1))
+	expectRun(t, `return searchSorted([7, 5, 3, 1], 4, func(a, b) { return a > b })`,
+		nil, Int(2))
+	expectRun(t, `
+	arr := [{k: 1}, {k: 3}, {k: 3}, {k: 5}]
+	return searchSorted(arr, {k: 3}, func(a, b) { return a.k < b.k })`,
+		nil, Int(1))
+
+	expectRun(t, `return insertSorted([], 1)`, nil, Array{Int(1)})
+	expectRun(t, `return insertSorted([1, 3, 5], 4)`,
+		nil, Array{Int(1), Int(3), Int(4), Int(5)})
+	expectRun(t, `return insertSorted([1, 3, 5], 0)`,
+		nil, Array{Int(0), Int(1), Int(3), Int(5)})
+	expectRun(t, `return insertSorted([1, 3, 5], 6)`,
+		nil, Array{Int(1), Int(3), Int(5), Int(6)})
+	// inserted after equal elements
+	expectRun(t, `return insertSorted([1, 2, 2u, 3], 2.0)`,
+		nil, Array{Int(1), Int(2), Uint(2), Float(2), Int(3)})
+	expectRun(t, `return insertSorted([5, 3, 1], 3, func(a, b) { return a > b })`,
+		nil, Array{Int(5), Int(3), Int(3), Int(1)})
+	expectRun(t, `
+	a := append([1, 3], 5)
+	b := insertSorted(a, 2)
+	return [a, b]`, nil, Array{Array{Int(1), Int(3), Int(5)},
+		Array{Int(1), Int(2), Int(3), Int(5)}})
+	expectRun(t, `
+	arr := []
+	for x in [5, 1, 4, 1, 3] { arr = insertSorted(arr, x) }
+	return arr`, nil, Array{Int(1), Int(1), Int(3), Int(4), Int(5)})
+
+	expectRun(t, `return reverse([])`, nil, Array{})
+	expectRun(t, `return reverse([1, "a", 2.0])`,
+		nil, Array{Float(2), String("a"), Int(1)})
+	expectRun(t, `a := [1, 2, 3, 4]; reverse(a); return a`,
+		nil, Array{Int(4), Int(3), Int(2), Int(1)})
+	expectRun(t, `return reverse("")`, nil, String(""))
+	expectRun(t, `return reverse("abc")`, nil, String("cba"))
+	// strings are reversed by runes, bytes are reversed by bytes
+	expectRun(t, `return reverse("héllo, 世界")`, nil, String("界世 ,olléh"))
+	expectRun(t, `s := "héllo"; reverse(s); return s`, nil, String("héllo"))
+	expectRun(t, `return reverse(bytes("hé"))`,
+		nil, Bytes{0xa9, 0xc3, 'h'})
+	expectRun(t, `b := bytes("abc"); reverse(b); return b`,
+		nil, Bytes("cba"))
+	expectRun(t, `return reverse("a\xffb")`, nil, String("b\ufffda"))
+	expectRun(t, `return reverse(undefined)`, nil, Undefined)
+	expectErrIs(t, `reverse()`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `reverse([], [])`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `reverse({})`, nil, ErrType)
+
+	expectRun(t, `return fill([], 0)`, nil, Array{})
+	expectRun(t, `return fill([1, 2, 3], 0)`,
+		nil, Array{Int(0), Int(0), Int(0)})
+	expectRun(t, `a := [1, 2, 3, 4]; fill(a, "x", 1); return a`,
+		nil, Array{Int(1), String("x"), String("x"), String("x")})
+	expectRun(t, `return fill([1, 2, 3, 4], "x", 1, 3)`,
+		nil, Array{Int(1), String("x"), String("x"), Int(4)})
+	expectRun(t, `return fill([1, 2], "x", 1, 1)`, nil, Array{Int(1), Int(2)})
+	expectRun(t, `return fill([undefined, undefined, undefined], 1u)`,
+		nil, Array{Uint(1), Uint(1), Uint(1)})
+	expectErrIs(t, `fill()`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `fill([])`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `fill([], 0, 0, 0, 0)`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `fill({}, 0)`, nil, ErrType)
+	expectErrIs(t, `fill([], 0, "a")`, nil, ErrType)
+	expectErrIs(t, `fill([], 0, 0, "a")`, nil, ErrType)
+	expectErrIs(t, `fill([1], 0, -1)`, nil, ErrIndexOutOfBounds)
+	expectErrIs(t, `fill([1], 0, 0, 2)`, nil, ErrIndexOutOfBounds)
+	expectErrIs(t, `fill([1, 2], 0, 2, 1)`, nil, ErrIndexOutOfBounds)
+
+	expectRun(t, `return concat()`, nil, Array{})
+	expectRun(t, `return concat([])`, nil, Array{})
+	expectRun(t, `return concat([1], [], [2, 3], [[4]])`,
+		nil, Array{Int(1), Int(2), Int(3), Array{Int(4)}})
+	expectRun(t, `return concat(...[[1], [2]])`, nil, Array{Int(1), Int(2)})
+	expectRun(t, `a := [1]; b := concat(a, [2]); b[0] = 0; return a`,
+		nil, Array{Int(1)})
+	expectErrIs(t, `concat([], 1)`, nil, ErrType)
+
+	expectRun(t, `return merge({})`, nil, Map{})
+	expectRun(t, `return merge({a: 1}, {b: 2})`,
+		nil, Map{"a": Int(1), "b": Int(2)})
+	expectRun(t, `return merge({a: 1, b: 1}, {b: 2, c: 2}, {c: 3})`,
+		nil, Map{"a": Int(1), "b": Int(2), "c": Int(3)})
+	expectRun(t, `return merge({a: {x: 1}}, {a: {y: 2}})`,
+		nil, Map{"a": Map{"y": Int(2)}})
+	expectRun(t, `
+	a := {x: 1}; b := {x: 2, y: 2}
+	m := merge(a, b)
+	m.z = 3
+	return [a, b, m]`, nil, Array{
+		Map{"x": Int(1)},
+		Map{"x": Int(2), "y": Int(2)},
+		Map{"x": Int(2), "y": Int(2), "z": Int(3)},
+	})
+	expectRun(t, `return merge(...[{a: 1}, {a: 2}])`, nil, Map{"a": Int(2)})
+	expectErrIs(t, `merge()`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `merge({}, [])`, nil, ErrType)
+	expectErrIs(t, `merge(undefined)`, nil, ErrType)
+
+	expectRun(t, `return deepMerge({a: 1})`, nil, Map{"a": Int(1)})
+	expectRun(t, `
+	defaults := {db: {host: "localhost", port: 5432, opts: {ssl: false}}, debug: false}
+	config := {db: {port: 6543, opts: {timeout: 5}}, debug: true}
+	return deepMerge(defaults, config)`, nil, Map{
+		"db": Map{
+			"host": String("localhost"),
+			"port": Int(6543),
+			"opts": Map{"ssl": False, "timeout": Int(5)},
+		},
+		"debug": True,
+	})
+	// arrays and non-map values are replaced, not concatenated
+	expectRun(t, `return deepMerge({a: [1, 2], b: {c: 1}}, {a: [3], b: 1})`,
+		nil, Map{"a": Array{Int(3)}, "b": Int(1)})
+	expectRun(t, `return deepMerge({a: 1}, {a: {b: 1}}, {a: {c: 2}})`,
+		nil, Map{"a": Map{"b": Int(1), "c": Int(2)}})
+	// inputs are not modified
+	expectRun(t, `
+	a := {x: {y: 1}}; b := {x: {z: 2}}
+	m := deepMerge(a, b)
+	m.x.w = 3
+	return [a, b, m]`, nil, Array{
+		Map{"x": Map{"y": Int(1)}},
+		Map{"x": Map{"z": Int(2)}},
+		Map{"x": Map{"y": Int(1), "z": Int(2), "w": Int(3)}},
+	})
+	expectRun(t, `a := {x: {y: 1}}; m := deepMerge(a); m.x.y = 2; return a`,
+		nil, Map{"x": Map{"y": Int(1)}})
+	expectErrIs(t, `deepMerge()`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `deepMerge({}, 1)`, nil, ErrType)
+	expectErrHas(t, `m := {}; m.self = m; deepMerge({}, m)`, nil,
+		`TypeError: deepMerge: unsupported value: encountered a cycle via map`)
+	expectRun(t, `s := {v: 1}; return deepMerge({a: s, b: s}, {b: {w: 2}})`, nil,
+		Map{"a": Map{"v": Int(1)}, "b": Map{"v": Int(1), "w": Int(2)}})
+
+	expectRun(t, `return pick({a: 1, b: 2, c: 3}, ["a", "c"])`,
+		nil, Map{"a": Int(1), "c": Int(3)})
+	expectRun(t, `return pick({a: 1, b: 2}, ["a", "x", "a"])`,
+		nil, Map{"a": Int(1)})
+	expectRun(t, `return pick({a: 1}, [])`, nil, Map{})
+	expectRun(t, `return pick({}, ["a"])`, nil, Map{})
+	expectRun(t, `m := {a: 1, b: 2}; p := pick(m, ["a"]); p.a = 0; return [m, p]`,
+		nil, Array{Map{"a": Int(1), "b": Int(2)}, Map{"a": Int(0)}})
+	expectRun(t, `return omit({a: 1, b: 2, c: 3}, ["b", "x"])`,
+		nil, Map{"a": Int(1), "c": Int(3)})
+	expectRun(t, `return omit({a: 1}, [])`, nil, Map{"a": Int(1)})
+	// only top-level keys are omitted
+	expectRun(t, `
+	m := {id: 1, user: {id: 2, password: "x"}, password: "y"}
+	o := omit(m, ["password", "id"])
+	return [m, o]`, nil, Array{
+		Map{
+			"id":       Int(1),
+			"user":     Map{"id": Int(2), "password": String("x")},
+			"password": String("y"),
+		},
+		Map{"user": Map{"id": Int(2), "password": String("x")}},
+	})
+	for _, fn := range []string{"pick", "omit"} {
+		expectErrIs(t, fn+`({})`, nil, ErrWrongNumArguments)
+		expectErrIs(t, fn+`([], [])`, nil, ErrType)
+		expectErrIs(t, fn+`({}, "a")`, nil, ErrType)
+		expectErrIs(t, fn+`({}, ["a", 1])`, nil, ErrType)
+	}
+
+	expectRun(t, `
+	m := {a: {b: [{c: 1}, {c: [10, 20]}]}, "x.y": 1}
+	return [
+		getPath(m, "a.b.0.c"),
+		getPath(m, "a.b.1.c.1"),
+		getPath(m, "a.b.1"),
+		getPath(m, ""),
+		getPath(m, "a.b.2.c"),
+		getPath(m, "a.b.-1"),
+		getPath(m, "a.b.x"),
+		getPath(m, "a.x.c"),
+		getPath(m, "a.b.0.c.d"),
+		getPath(m, "x.y"),
+		getPath([[1, 2]], "0.1"),
+		getPath(undefined, "a"),
+		getPath(1, "0"),
+	]`, nil, Array{
+		Int(1), Int(20), Map{"c": Array{Int(10), Int(20)}},
+		Map{
+			"a":   Map{"b": Array{Map{"c": Int(1)}, Map{"c": Array{Int(10), Int(20)}}}},
+			"x.y": Int(1),
+		},
+		Undefined, Undefined, Undefined, Undefined, Undefined, Undefined,
+		Int(2), Undefined, Undefined,
+	})
+	expectErrIs(t, `getPath({})`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `getPath({}, undefined)`, nil, ErrType)
+
+	expectRun(t, `m := {a: {b: 1}}; setPath(m, "a.b", 2); return m`,
+		nil, Map{"a": Map{"b": Int(2)}})
+	expectRun(t, `m := {}; setPath(m, "a.b.c", 1); setPath(m, "a.d", 2); return m`,
+		nil, Map{"a": Map{"b": Map{"c": Int(1)}, "d": Int(2)}})
+	expectRun(t, `m := {a: undefined}; setPath(m, "a.b", 1); return m`,
+		nil, Map{"a": Map{"b": Int(1)}})
+	expectRun(t, `m := {a: [{b: 1}, undefined]}
+	setPath(m, "a.0.b", 2)
+	setPath(m, "a.1.c", 3)
+	return m`, nil, Map{"a": Array{Map{"b": Int(2)}, Map{"c": Int(3)}}})
+	expectRun(t, `a := [1, 2]; setPath(a, "1", 3); return a`,
+		nil, Array{Int(1), Int(3)})
+	expectRun(t, `return setPath({}, "a", 1)`, nil, Undefined)
+	expectErrIs(t, `setPath({a: []}, "a.0", 1)`, nil, ErrIndexOutOfBounds)
+	expectErrIs(t, `setPath({a: []}, "a.0.b", 1)`, nil, ErrIndexOutOfBounds)
+	expectErrIs(t, `setPath({a: []}, "a.x", 1)`, nil, ErrInvalidIndex)
+	expectErrIs(t, `setPath({}, "", 1)`, nil, ErrInvalidIndex)
+	expectErrIs(t, `setPath({a: 1}, "a.b.c", 1)`, nil, ErrNotIndexable)
+	expectErrIs(t, `setPath({a: 1}, "a.b", 1)`, nil, ErrNotIndexAssignable)
+	expectErrIs(t, `setPath({a: "x"}, "a.0", 1)`, nil, ErrNotIndexAssignable)
+	expectErrIs(t, `setPath({}, "a")`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `setPath({}, undefined, 1)`, nil, ErrType)
+
+	expectRun(t, `return makeArray(0)`, nil, Array{})
+	expectRun(t, `return makeArray(3)`, nil, Array{Undefined, Undefined, Undefined})
+	expectRun(t, `return makeArray(2, 10)`, nil, Array{Undefined, Undefined})
+	expectRun(t, `a := makeArray(2, 10); return [len(a), cap(a)]`,
+		nil, Array{Int(2), Int(10)})
+	expectRun(t, `
+	a := makeArray(0, 3)
+	b := a
+	for i := 0; i < 3; i++ { a = append(a, i) }
+	return [a, cap(a), b]`, nil, Array{
+		Array{Int(0), Int(1), Int(2)}, Int(3), Array{},
+	})
+	expectRun(t, `a := makeArray(1, 1); a[0] = 1; return a`, nil, Array{Int(1)})
+	expectRun(t, `return makeArray(2u, 2.0)`, nil, Array{Undefined, Undefined})
+	expectErrIs(t, `makeArray()`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `makeArray(1, 2, 3)`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `makeArray(-1)`, nil, ErrType)
+	expectErrIs(t, `makeArray(1, -1)`, nil, ErrType)
+	expectErrIs(t, `makeArray(2, 1)`, nil, ErrType)
+	expectErrIs(t, `makeArray([])`, nil, ErrType)
+	expectErrIs(t, `makeArray(1, {})`, nil, ErrType)
+	expectErrHas(t, `makeArray(0, 1<<62)`, nil,
+		`TypeError: size 4611686018427387904 exceeds the maximum 16777216`)
+	expectErrHas(t, `makeArray(1<<40)`, nil,
+		`TypeError: size 1099511627776 exceeds the maximum 16777216`)
+	expectRun(t, `return cap(makeArray(0, 1<<24))`, nil, Int(1<<24))
+	expectRun(t, `return makeMap()`, nil, Map{})
+	expectRun(t, `return makeMap(100)`, nil, Map{})
+	expectRun(t, `m := makeMap(2); m.a = 1; m.b = 2; m.c = 3; return m`,
+		nil, Map{"a": Int(1), "b": Int(2), "c": Int(3)})
+	expectErrIs(t, `makeMap(1, 2)`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `makeMap(-1)`, nil, ErrType)
+	expectErrHas(t, `makeMap(1<<62)`, nil,
+		`TypeError: size 4611686018427387904 exceeds the maximum 16777216`)
+	expectErrIs(t, `makeMap(undefined)`, nil, ErrType)
+
+	expectRun(t, `return tryDiv(10, 4)`, nil, Array{Int(2), Undefined})
+	expectRun(t, `return tryDiv(-10, 4u)`, nil,
+		Array{Uint(1<<64-10) / 4, Undefined})
+	expectRun(t, `return tryDiv(10u, 4)`, nil, Array{Uint(2), Undefined})
+	expectRun(t, `return tryDiv(10.0, 4)`, nil, Array{Float(2.5), Undefined})
+	expectRun(t, `return tryDiv(10, 4.0)`, nil, Array{Float(2.5), Undefined})
+	expectRun(t, `return tryDiv('d', '\x02')`, nil, Array{Char(50), Undefined})
+	expectRun(t, `return tryDiv(true, true)`, nil, Array{Int(1), Undefined})
+	expectRun(t, `return tryMod(10, 4)`, nil, Array{Int(2), Undefined})
+	expectRun(t, `return tryMod(-10, 3)`, nil, Array{Int(-1), Undefined})
+	expectRun(t, `return tryMod(10u, 4u)`, nil, Array{Uint(2), Undefined})
+	expectRun(t, `return tryMod('d', 'a')`, nil, Array{Char(3), Undefined})
+	for _, s := range []string{
+		`tryDiv(1, 0)`, `tryDiv(1, 0u)`, `tryDiv(1u, 0)`, `tryDiv(1.5, 0)`,
+		`tryDiv(1, 0.0)`, `tryDiv(1, false)`, `tryDiv(true, false)`,
+		`tryDiv('a', '\x00')`, `tryMod('a', '\x00')`,
+		`tryMod(1, 0)`, `tryMod(1u, 0u)`, `tryMod(1, 0u)`,
+		`tryMod(true, 0)`, `tryMod(1, false)`,
+	} {
+		expectRun(t, `
+		r, err := `+s+`
+		return [r, isError(err, ZeroDivisionError), string(err)]`, nil,
+			Array{Undefined, True, String("ZeroDivisionError: ")})
+	}
+	expectRun(t, `
+	r, err := tryDiv(1, 0)
+	if err != undefined { r = -1 }
+	return r`, nil, Int(-1))
+	expectErrIs(t, `tryDiv(1)`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `tryMod(1, 2, 3)`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `tryDiv("a", 0)`, nil, ErrType)
+	expectErrIs(t, `tryMod(1.5, 0)`, nil, ErrType)
+	expectErrIs(t, `tryDiv(undefined, 0)`, nil, ErrType)
+
+	for _, fn := range []string{"searchSorted", "insertSorted"} {
+		expectErrIs(t, fn+`()`, nil, ErrWrongNumArguments)
+		expectErrIs(t, fn+`([])`, nil, ErrWrongNumArguments)
+		expectErrIs(t, fn+`([], 1, 2, 3)`, nil, ErrWrongNumArguments)
+		expectErrIs(t, fn+`({}, 1)`, nil, ErrType)
+		expectErrIs(t, fn+`([], 1, 2)`, nil, ErrType)
+		expectErrIs(t, fn+`([1, 2], {})`, nil, ErrType)
+		expectErrHas(t, fn+`([1], 1, func(a, b) { throw "foo" })`, nil, "foo")
+	}
+	expectErrIs(t, `sortByKeys([])`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `sortByKeys({}, [])`, nil, ErrType)
+	expectErrIs(t, `sortByKeys([], 1)`, nil, ErrType)
+	expectErrIs(t, `sortByKeys([1], [string, 1])`, nil, ErrType)
+	expectErrIs(t, `sortByKeys([1, 2], func(x) { return x > 1 ? {} : 1 })`,
+		nil, ErrType)
+	expectErrHas(t, `sortByKeys([1, 2], func(x) { throw "foo" })`,
+		nil, "foo")
+
 	expectRun(t, `return error("x")`, nil,
 		&Error{Name: "error", Message: "x"})
 	expectRun(t, `return error(1)`, nil,
@@ -867,6 +1656,19 @@ func TestVMBuiltinFunction(t *testing.T) {
 				`"0b101"`: Int(5),
 			},
 		},
+		{
+			"intExact",
+			map[string]Object{
+				"1":                      Int(1),
+				"-1":                     Int(-1),
+				"1u":                     Int(1),
+				"2.0":                    Int(2),
+				"-2.0":                   Int(-2),
+				"1e18":                   Int(1e18),
+				"-9223372036854775808.0": Int(math.MinInt64),
+				"9223372036854775807u":   Int(math.MaxInt64),
+			},
+		},
 		{
 			"uint",
 			map[string]Object{
@@ -971,6 +1773,77 @@ func TestVMBuiltinFunction(t *testing.T) {
 	expectErrIs(t, `chars(1, 2)`, nil, ErrWrongNumArguments)
 
 	expectErrIs(t, `int([])`, nil, ErrType)
+	expectErrIs(t, `intExact("1")`, nil, ErrType)
+
+	fixed := map[string]Object{
+		`toInt8(127)`:                      Int(127),
+		`toInt8(128)`:                      Int(-128),
+		`toInt8(255)`:                      Int(-1),
+		`toInt8(256)`:                      Int(0),
+		`toInt8(-129)`:                     Int(127),
+		`toInt8(0x1F0)`:                    Int(-16),
+		`toInt8(200u)`:                     Int(-56),
+		`toInt8(300.7)`:                    Int(44),
+		`toInt8('a')`:                      Int(97),
+		`toInt8(true)`:                     Int(1),
+		`toInt8("0x80")`:                   Int(-128),
+		`toInt16(32767)`:                   Int(32767),
+		`toInt16(32768)`:                   Int(-32768),
+		`toInt16(0xFFFF)`:                  Int(-1),
+		`toInt16(65536)`:                   Int(0),
+		`toInt16(-32769)`:                  Int(32767),
+		`toInt32(2147483647)`:              Int(2147483647),
+		`toInt32(2147483648)`:              Int(-2147483648),
+		`toInt32(0xFFFFFFFF)`:              Int(-1),
+		`toInt32(4294967296)`:              Int(0),
+		`toInt32(-2147483649)`:             Int(2147483647),
+		`toInt32(-1u)`:                     Int(-1),
+		`toUint8(255)`:                     Uint(255),
+		`toUint8(256)`:                     Uint(0),
+		`toUint8(-1)`:                      Uint(255),
+		`toUint8(-128)`:                    Uint(128),
+		`toUint8(0x1FF)`:                   Uint(255),
+		`toUint16(65535)`:                  Uint(65535),
+		`toUint16(65537)`:                  Uint(1),
+		`toUint16(-1)`:                     Uint(65535),
+		`toUint32(4294967295)`:             Uint(4294967295),
+		`toUint32(4294967296)`:             Uint(0),
+		`toUint32(-1)`:                     Uint(4294967295),
+		`toUint32(-1u)`:                    Uint(4294967295),
+		`toInt8(toUint8(-100))`:            Int(-100),
+		`toInt16(toUint16(-30000))`:        Int(-30000),
+		`toInt32(toUint32(-70000))`:        Int(-70000),
+		`typeName(toInt32(1))`:             String("int"),
+		`typeName(toUint32(1))`:            String("uint"),
+		`toInt32(2147483647) + 1`:          Int(2147483648),
+		`toInt32(toInt32(2147483647) + 1)`: Int(-2147483648),
+	}
+	for k, v := range fixed {
+		expectRun(t, `return `+k, nil, v)
+	}
+	for _, f := range []string{
+		"toInt8", "toInt16", "toInt32", "toUint8", "toUint16", "toUint32",
+	} {
+		expectErrIs(t, f+`()`, nil, ErrWrongNumArguments)
+		expectErrIs(t, f+`(1, 2)`, nil, ErrWrongNumArguments)
+		expectErrIs(t, f+`([])`, nil, ErrType)
+		expectErrIs(t, f+`("x")`, nil, ErrType)
+	}
+	expectErrIs(t, `intExact()`, nil, ErrWrongNumArguments)
+	expectRun(t, `return string(intExact(2.5))`, nil,
+		String("error: float 2.5 has fractional part"))
+	expectRun(t, `return string(intExact(-0.1))`, nil,
+		String("error: float -0.1 has fractional part"))
+	expectRun(t, `return string(intExact(9223372036854775808u))`, nil,
+		String("error: uint 9223372036854775808 overflows int"))
+	expectRun(t, `return string(intExact(1e19))`, nil,
+		String("error: float 1e+19 overflows int"))
+	expectRun(t, `return string(intExact(-1e19))`, nil,
+		String("error: float -1e+19 overflows int"))
+	expectRun(t, `return string(intExact(9223372036854775807.0))`, nil,
+		String("error: float 9.223372036854776e+18 overflows int"))
+	expectRun(t, `return isError(intExact(float("inf")))`, nil, True)
+	expectRun(t, `return isError(intExact(float("nan")))`, nil, True)
 	expectErrIs(t, `uint([])`, nil, ErrType)
 	expectErrIs(t, `char([])`, nil, ErrType)
 	expectErrIs(t, `float([])`, nil, ErrType)
@@ -1036,6 +1909,18 @@ func TestVMBuiltinFunction(t *testing.T) {
 				`error("x")`, "true", "false", "[]", "{}",
 			},
 		},
+		{
+			`isValidChar`,
+			trueValues{
+				"'\x01'", `'a'`, `'九'`, `char(0)`, `char(0x10FFFF)`, `char(0xD7FF)`,
+				`char(0xE000)`, `'\uFFFD'`,
+			},
+			falseValues{
+				`char(0xD800)`, `char(0xDFFF)`, `char(0x110000)`, `char(-1)`,
+				`'a' + 0x110000`, "1", `""`, "1u", "1.1", `bytes()`, "undefined",
+				`error("x")`, "true", "false", "[]", "{}",
+			},
+		},
 		{
 			`isBool`,
 			trueValues{
@@ -1217,6 +2102,18 @@ func TestVMBuiltinFunction(t *testing.T) {
 	expectRun(t, `println("test", 1, 2u)`, newOpts().Skip2Pass(), Undefined)
 	require.Equal(t, "test 1 2\n", stdOut.String())
 
+	expectRun(t, `m := {}; m.self = m; return sprintf("%v", m)`,
+		nil, String(`{"self": {...}}`))
+	expectRun(t, `
+	global g
+	g.m = {g: g}
+	return [string(g), string(g.m)]`,
+		newOpts().Globals(Map{"g": &SyncMap{Value: Map{}}}), Array{
+			String(`{"m": {"g": {...}}}`),
+			String(`{"g": {"m": {...}}}`),
+		})
+	expectRun(t, `a := [1]; a = append(a, a); a[1] = a; return string(a)`,
+		nil, String(`[1, [...]]`))
 	expectRun(t, `return sprintf("test")`,
 		newOpts().Skip2Pass(), String("test"))
 	expectRun(t, `return sprintf("test %d", 1)`,
@@ -1226,6 +2123,966 @@ func TestVMBuiltinFunction(t *testing.T) {
 
 	expectErrIs(t, `printf()`, nil, ErrWrongNumArguments)
 	expectErrIs(t, `sprintf()`, nil, ErrWrongNumArguments)
+
+	writerOpts := func() *testopts {
+		return newOpts().Globals(Map{"w": &testWriter{}}).Skip2Pass()
+	}
+	expectRun(t, `global w; fprintf(w, "test"); return string(w)`,
+		writerOpts(), String("test"))
+	expectRun(t, `global w; fprintf(w, "test %d %s", 1, "a")
+	fprintf(w, "%v", [1, 2u]); return string(w)`,
+		writerOpts(), String("test 1 a[1, 2]"))
+	expectRun(t, `global w; fprintln(w); return string(w)`,
+		writerOpts(), String("\n"))
+	expectRun(t, `global w; fprintln(w, "test", 1, 2u)
+	fprintln(w, {a: 1}); return string(w)`,
+		writerOpts(), String("test 1 2\n{\"a\": 1}\n"))
+	expectRun(t, `global w; return fprintln(w, 1)`, writerOpts(), Undefined)
+	expectErrIs(t, `global w; fprintf(w)`, writerOpts(), ErrWrongNumArguments)
+	expectErrHas(t, `global w; fprintf(w)`, writerOpts(), `want>=2 got=1`)
+	expectErrIs(t, `fprintln()`, nil, ErrWrongNumArguments)
+	expectErrHas(t, `fprintf(1, "x")`, nil,
+		`TypeError: invalid type for argument '1st': expected writer, found int`)
+	expectErrHas(t, `fprintln("x")`, nil,
+		`TypeError: invalid type for argument '1st': expected writer, found string`)
+	expectErrHas(t, `global w; fprintln(w, 1)`,
+		newOpts().Globals(Map{"w": &testWriter{err: errors.New("closed")}}),
+		`closed`)
+}
+
+func TestVMBuffer(t *testing.T) {
+	expectRun(t, `b := buffer(); b.write(bytes(0x61, 0x62)); b.write("c")
+	b.writeString("d"); fprintf(b, "%d-%v", 1, [2]); fprintln(b, true)
+	return [b.string(), b.bytes(), b.len(), len(b), string(b)]`, nil,
+		Array{String("abcd1-[2]true\n"), Bytes("abcd1-[2]true\n"),
+			Int(14), Int(14), String("abcd1-[2]true\n")})
+	expectRun(t, `return buffer("çx").bytes()`, nil, Bytes("çx"))
+	expectRun(t, `return buffer(bytes(1, 2)).bytes()`, nil, Bytes{1, 2})
+	expectRun(t, `b := buffer(); return [b.write("ç"), b.writeString("ab")]`,
+		nil, Array{Int(2), Int(2)})
+	expectRun(t, `b := buffer("abc"); r := b.reset(); return [r, b.len(), b.string()]`,
+		nil, Array{Undefined, Int(0), String("")})
+	expectRun(t, `b := buffer("a"); v := b.bytes(); v[0] = 98; return b.string()`,
+		nil, String("a"))
+	expectRun(t, `b := buffer("a"); c := copy(b); c.write("b"); b.write("c")
+	return [b.string(), c.string()]`, nil, Array{String("ac"), String("ab")})
+	expectRun(t, `b := buffer(); return [typeName(b), bool(b), bool(buffer("a"))]`,
+		nil, Array{String("buffer"), False, True})
+	expectRun(t, `b := buffer(); return [b == b, b == buffer()]`,
+		nil, Array{True, False})
+
+	expectErrIs(t, `buffer(1, 2)`, nil, ErrWrongNumArguments)
+	expectErrHas(t, `buffer(1)`, nil,
+		`TypeError: invalid type for argument '1st': expected bytes|string, found int`)
+	expectErrIs(t, `buffer().write()`, nil, ErrWrongNumArguments)
+	expectErrHas(t, `buffer().write(1)`, nil,
+		`TypeError: invalid type for argument '1st': expected bytes|string, found int`)
+	expectErrHas(t, `buffer().writeString(bytes())`, nil,
+		`TypeError: invalid type for argument '1st': expected string, found bytes`)
+	expectErrIs(t, `buffer().string(1)`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `buffer().read()`, nil, ErrInvalidIndex)
+}
+
+func TestVMRetry(t *testing.T) {
+	expectRun(t, `
+	calls := 0
+	v := retry(func() {
+		calls++
+		if calls < 3 {
+			throw "fail " + string(calls)
+		}
+		return "ok"
+	})
+	return [v, calls]`, nil, Array{String("ok"), Int(3)})
+	expectRun(t, `
+	calls := 0
+	v := retry(func() {
+		calls++
+		throw NotImplementedError.New(string(calls))
+	}, {attempts: 5})
+	return [isError(v), isError(v, NotImplementedError), v.Message, calls]`,
+		nil, Array{True, True, String("5"), Int(5)})
+	expectRun(t, `
+	calls := 0
+	v := retry(func() { calls++; throw "x" }, {attempts: 1, delay: 1000})
+	return [string(v), calls]`, nil, Array{String("error: x"), Int(1)})
+	expectRun(t, `
+	calls := 0
+	v := retry(func() {
+		calls++
+		if calls < 3 { throw "x" }
+		return calls
+	}, {attempts: 3, delay: 1000, backoff: 2.5})
+	return v`, nil, Int(3))
+	expectRun(t, `return retry(func() { return error("not thrown") })`, nil,
+		&Error{Name: "error", Message: "not thrown"})
+	expectRun(t, `return retry(func() {})`, nil, Undefined)
+	expectRun(t, `return retry(len)`, nil,
+		ErrWrongNumArguments.NewError("want=1 got=0"))
+
+	expectErrIs(t, `retry()`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `retry(func(){}, {}, 1)`, nil, ErrWrongNumArguments)
+	expectErrHas(t, `retry(1)`, nil,
+		`TypeError: invalid type for argument '1st': expected callable, found int`)
+	expectErrHas(t, `retry(func(){}, 1)`, nil,
+		`TypeError: invalid type for argument '2nd': expected map, found int`)
+	expectErrHas(t, `retry(func(){}, {attempts: 0})`, nil,
+		`TypeError: invalid type for argument 'attempts': expected positive integer`)
+	expectErrHas(t, `retry(func(){}, {attempts: "1"})`, nil,
+		`TypeError: invalid type for argument 'attempts': expected int, found string`)
+	expectErrHas(t, `retry(func(){}, {delay: -1})`, nil,
+		`TypeError: invalid type for argument 'delay': expected non-negative integer`)
+	expectErrHas(t, `retry(func(){}, {backoff: 0})`, nil,
+		`TypeError: invalid type for argument 'backoff': expected positive number`)
+	expectErrHas(t, `retry(func(){}, {backoff: "2"})`, nil,
+		`TypeError: invalid type for argument 'backoff': expected float|int, found string`)
+	expectErrHas(t, `retry(func(){}, {attempt: 2})`, nil,
+		`InvalidIndexError: unknown retry option "attempt"`)
+	expectErrIs(t, `retry(func(){ exit(1) }, {attempts: 3})`, nil, ErrExit)
+
+	t.Run("abort", func(t *testing.T) {
+		bc, err := Compile([]byte(`
+		return retry(func() { throw "x" }, {attempts: 100, delay: 10000000000})`),
+			DefaultCompilerOptions)
+		require.NoError(t, err)
+		vm := NewVM(bc)
+		done := make(chan error, 1)
+		go func() {
+			_, err := vm.Run(nil)
+			done <- err
+		}()
+		time.Sleep(50 * time.Millisecond)
+		vm.Abort()
+		select {
+		case err := <-done:
+			require.True(t, errors.Is(err, ErrVMAborted), err)
+		case <-time.After(5 * time.Second):
+			t.Fatal("retry is not aborted")
+		}
+	})
+}
+
+func TestVMAbortWith(t *testing.T) {
+	errQuota := errors.New("quota exceeded")
+	for _, script := range []string{
+		`for {}`,
+		`f := func() { for {} }; f()`,
+		`try { for {} } catch err { return err }`,
+		`return retry(func() { for {} })`,
+		`return retry(func() { throw "x" }, {attempts: 100, delay: 10000000000})`,
+	} {
+		t.Run(script, func(t *testing.T) {
+			bc, err := Compile([]byte(script), DefaultCompilerOptions)
+			require.NoError(t, err)
+			vm := NewVM(bc)
+			done := make(chan error, 1)
+			go func() {
+				_, err := vm.Run(nil)
+				done <- err
+			}()
+			time.Sleep(20 * time.Millisecond)
+			vm.AbortWith(errQuota)
+			select {
+			case err := <-done:
+				require.Equal(t, errQuota, err)
+				require.True(t, vm.Aborted())
+			case <-time.After(5 * time.Second):
+				t.Fatal("vm is not aborted")
+			}
+		})
+	}
+
+	// abort reason is reset on the next run
+	bc, err := Compile([]byte(`return 1`), DefaultCompilerOptions)
+	require.NoError(t, err)
+	vm := NewVM(bc)
+	vm.AbortWith(errQuota)
+	ret, err := vm.Run(nil)
+	require.NoError(t, err)
+	require.Equal(t, Int(1), ret)
+
+	// nil reason is same as Abort
+	bc, err = Compile([]byte(`for {}`), DefaultCompilerOptions)
+	require.NoError(t, err)
+	vm = NewVM(bc)
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		vm.AbortWith(nil)
+	}()
+	_, err = vm.Run(nil)
+	require.True(t, errors.Is(err, ErrVMAborted), err)
+}
+
+func TestVMAborted(t *testing.T) {
+	bc, err := Compile([]byte(`param n; if n { for {} }; return 1`),
+		DefaultCompilerOptions)
+	require.NoError(t, err)
+	vm := NewVM(bc)
+	require.False(t, vm.Aborted())
+
+	ret, err := vm.Run(nil)
+	require.NoError(t, err)
+	require.Equal(t, Int(1), ret)
+	require.False(t, vm.Aborted())
+
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		vm.Abort()
+	}()
+	_, err = vm.Run(nil, True)
+	require.True(t, errors.Is(err, ErrVMAborted), err)
+	require.True(t, vm.Aborted())
+
+	vm.Clear()
+	require.False(t, vm.Aborted())
+
+	vm.AbortWith(errors.New("x"))
+	require.True(t, vm.Aborted())
+	vm.Clear()
+	require.False(t, vm.Aborted())
+	ret, err = vm.Run(nil)
+	require.NoError(t, err)
+	require.Equal(t, Int(1), ret)
+	require.False(t, vm.Aborted())
+
+	// runtime errors are not aborts
+	bc, err = Compile([]byte(`throw "x"`), DefaultCompilerOptions)
+	require.NoError(t, err)
+	vm = NewVM(bc)
+	_, err = vm.Run(nil)
+	require.Error(t, err)
+	require.False(t, vm.Aborted())
+}
+
+func TestVMAbortPartialResults(t *testing.T) {
+	bc, err := Compile([]byte(`
+	global results
+	results = []
+	for i := 0; true; i++ {
+		results = append(results, i)
+	}`), DefaultCompilerOptions)
+	require.NoError(t, err)
+	vm := NewVM(bc)
+	globals := Map{}
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		vm.Abort()
+	}()
+	_, err = vm.Run(globals)
+	require.True(t, errors.Is(err, ErrVMAborted), err)
+
+	results, ok := globals["results"].(Array)
+	require.True(t, ok, globals["results"])
+	require.NotEmpty(t, results)
+	for i, v := range results {
+		require.Equal(t, Int(i), v)
+	}
+	require.Equal(t, globals, vm.GetGlobals())
+}
+
+func TestVMRestoreGlobals(t *testing.T) {
+	expectRun(t, `
+	global x
+	snap := globalsSnapshot()
+	x.a = 2
+	return [snap.x.a, x.a]`,
+		newOpts().Globals(Map{"x": Map{"a": Int(1)}}).Skip2Pass(),
+		Array{Int(1), Int(2)})
+	expectErrIs(t, `globalsSnapshot(1)`, nil, ErrWrongNumArguments)
+	expectRun(t, `
+	global x
+	x.self = x
+	snap := globalsSnapshot()
+	return [same(snap.x, snap.x.self), same(snap.x, x)]`,
+		newOpts().Globals(Map{"x": Map{}}).Skip2Pass(),
+		Array{True, False})
+
+	bc, err := Compile([]byte(`
+	global (counter, items, snap)
+	snap = globalsSnapshot()
+	counter++
+	items = append(items, counter)
+	items[0] = -1
+	global added
+	added = true
+	return counter`), DefaultCompilerOptions)
+	require.NoError(t, err)
+
+	globals := Map{"counter": Int(0), "items": Array{Int(0)}}
+	vm := NewVM(bc)
+	ret, err := vm.Run(globals)
+	require.NoError(t, err)
+	require.Equal(t, Int(1), ret)
+	require.Equal(t, Array{Int(-1), Int(1)}, globals["items"])
+	require.Equal(t, True, globals["added"])
+
+	snap := globals["snap"]
+	require.Equal(t, Map{"counter": Int(0), "items": Array{Int(0)}}, snap)
+
+	// restoring updates the map passed to Run in place
+	vm.RestoreGlobals(snap)
+	require.Equal(t, Map{"counter": Int(0), "items": Array{Int(0)}}, globals)
+	require.Equal(t, globals, vm.GetGlobals())
+
+	// snapshot is not modified by later runs, it can be restored again
+	ret, err = vm.Run(globals)
+	require.NoError(t, err)
+	require.Equal(t, Int(1), ret)
+	vm.RestoreGlobals(snap)
+	require.Equal(t, Map{"counter": Int(0), "items": Array{Int(0)}}, globals)
+
+	// globals which are not maps are replaced
+	sm := &SyncMap{Value: Map{"counter": Int(5), "items": Array{}}}
+	_, err = vm.Run(sm)
+	require.NoError(t, err)
+	require.Equal(t, Int(6), sm.Value["counter"])
+	vm.RestoreGlobals(Map{"counter": Int(3)})
+	require.Equal(t, Map{"counter": Int(3)}, vm.GetGlobals())
+	require.Equal(t, Int(6), sm.Value["counter"])
+}
+
+func TestVMMemoize(t *testing.T) {
+	expectRun(t, `
+	calls := {}
+	f := memoize(func(a, b) {
+		k := sprintf("%v,%v", a, b)
+		calls[k] = (calls[k] || 0) + 1
+		return a + b
+	})
+	return [f(1, 2), f(1, 2), f(2, 1), f(1, 2), f(2, 1), calls]`,
+		nil, Array{Int(3), Int(3), Int(3), Int(3), Int(3),
+			Map{"1,2": Int(1), "2,1": Int(1)}})
+	expectRun(t, `
+	calls := 0
+	var fib
+	fib = memoize(func(n) {
+		calls++
+		return n < 2 ? n : fib(n-1) + fib(n-2)
+	})
+	return [fib(50), calls, fib(30), calls]`,
+		nil, Array{Int(12586269025), Int(51), Int(832040), Int(51)})
+	expectRun(t, `
+	calls := 0
+	f := memoize(func(...args) { calls++; return len(args) })
+	f(1); f("1"); f(1, 2); f("1, 2"); f([1, 2]); f(); f(); f(1)
+	return calls`, nil, Int(6))
+	expectRun(t, `
+	calls := []
+	f := memoize(func(x) { calls = append(calls, x); return x }, 2)
+	f(1); f(2); f(1); f(3); f(1); f(2)
+	return calls`, nil, Array{Int(1), Int(2), Int(3), Int(2)})
+	expectRun(t, `
+	calls := 0
+	f := memoize(func(x) { calls++; if x < 0 { throw "negative" }; return x })
+	try { f(-1) } catch {}
+	try { f(-1) } catch {}
+	return calls`, nil, Int(2))
+	expectRun(t, `return typeName(memoize(func(){}))`, nil, String("function"))
+	expectRun(t, `f := memoize(len); return [f("abc"), f([1])]`, nil,
+		Array{Int(3), Int(1)})
+	expectRun(t, `
+	calls := 0
+	f := memoize(func(x) { calls++; return x })
+	f({a: [1, "2"], b: {c: 3}}); f({b: {c: 3}, a: [1, "2"]})
+	f({a: [1, 2], b: {c: 3}}); f([[1], 2]); f([[1, 2]]); f(["[1, 2]"])
+	f(freeze([1])); f([1]); f([1])
+	return calls`, nil, Int(7))
+
+	expectErrHas(t, `memoize(len)(func() {})`, nil,
+		`TypeError: invalid type for argument '1st': expected primitive, `+
+			`array or map, found compiledFunction`)
+	expectErrHas(t, `memoize(func(...a) {})(1, [len])`, nil,
+		`TypeError: invalid type for argument '2nd': expected primitive, `+
+			`array or map, found builtinFunction`)
+	expectErrHas(t, `a := [1]; a[0] = a; memoize(len)(a)`, nil,
+		`TypeError: memoize: unsupported value for argument '1st': `+
+			`encountered a cycle`)
+
+	expectErrIs(t, `memoize()`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `memoize(len, 1, 2)`, nil, ErrWrongNumArguments)
+	expectErrHas(t, `memoize(1)`, nil,
+		`TypeError: invalid type for argument '1st': expected callable, found int`)
+	expectErrHas(t, `memoize(len, -1)`, nil,
+		`TypeError: invalid type for argument '2nd': expected non-negative integer`)
+	expectErrIs(t, `memoize(len)()`, nil, ErrWrongNumArguments)
+}
+
+func TestVMLazy(t *testing.T) {
+	expectRun(t, `
+	calls := 0
+	v := lazy(func() { calls++; return [calls] })
+	a := v(); b := v()
+	return [calls, a, b, same(a, b)]`, nil,
+		Array{Int(1), Array{Int(1)}, Array{Int(1)}, True})
+	expectRun(t, `
+	calls := 0
+	v := lazy(func() { calls++ })
+	v(); v(); v()
+	return [calls, v()]`, nil, Array{Int(1), Undefined})
+	expectRun(t, `
+	calls := 0
+	v := lazy(func() { calls++; throw NotImplementedError.New("x") })
+	out := []
+	for i := 0; i < 3; i++ {
+		try {
+			v()
+		} catch err {
+			out = append(out, isError(err, NotImplementedError), err.Message)
+		}
+	}
+	return [calls, out]`, nil, Array{Int(1), Array{True, String("x"),
+		True, String("x"), True, String("x")}})
+	expectRun(t, `
+	var v
+	v = lazy(func() { return v() })
+	try { v() } catch err { return string(err) }`, nil, String(
+		"TypeError: lazy value is called while it is being computed"))
+	expectRun(t, `v := lazy(len); try { v() } catch err { return string(err) }`,
+		nil, String("WrongNumberOfArgumentsError: want=1 got=0"))
+	expectRun(t, `return typeName(lazy(func(){}))`, nil, String("function"))
+
+	expectErrIs(t, `lazy()`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `lazy(len, 1)`, nil, ErrWrongNumArguments)
+	expectErrHas(t, `lazy(1)`, nil,
+		`TypeError: invalid type for argument '1st': expected callable, found int`)
+	expectErrIs(t, `lazy(func(){})(1)`, nil, ErrWrongNumArguments)
+}
+
+func TestVMTransaction(t *testing.T) {
+	expectRun(t, `
+	cfg := {host: "localhost", port: 80, debug: true}
+	ret := transaction(cfg, func(m) {
+		m.port = 8080
+		m.tls = true
+		delete(m, "debug")
+		return m.port
+	})
+	return [ret, cfg]`, nil, Array{Int(8080), Map{
+		"host": String("localhost"), "port": Int(8080), "tls": True}})
+	expectRun(t, `
+	cfg := {host: "localhost", port: 80, debug: true}
+	try {
+		transaction(cfg, func(m) {
+			m.port = 8080
+			delete(m, "debug")
+			throw error("invalid config")
+		})
+	} catch err {
+		return [string(err), cfg]
+	}`, nil, Array{String("error: invalid config"), Map{
+		"host": String("localhost"), "port": Int(80), "debug": True}})
+	// original map is not changed until the function returns
+	expectRun(t, `
+	cfg := {a: 1}
+	return transaction(cfg, func(m) {
+		m.a = 2
+		return [m.a, cfg.a, same(m, cfg)]
+	})`, nil, Array{Int(2), Int(1), False})
+	// returned errors do not discard changes
+	expectRun(t, `
+	cfg := {a: 1}
+	ret := transaction(cfg, func(m) { m.a = 2; return error("x") })
+	return [isError(ret), cfg.a]`, nil, Array{True, Int(2)})
+	// changes of nested containers are shared
+	expectRun(t, `
+	cfg := {db: {port: 1}}
+	try {
+		transaction(cfg, func(m) { m.db.port = 2; throw "x" })
+	} catch {}
+	return cfg.db.port`, nil, Int(2))
+	expectRun(t, `return transaction({}, len)`, nil, Int(0))
+
+	expectErrIs(t, `transaction({})`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `transaction({}, len, 1)`, nil, ErrWrongNumArguments)
+	expectErrHas(t, `transaction([], len)`, nil,
+		`TypeError: invalid type for argument '1st': expected map, found array`)
+	expectErrHas(t, `transaction({}, 1)`, nil,
+		`TypeError: invalid type for argument '2nd': expected callable, found int`)
+}
+
+func TestVMChunkWindows(t *testing.T) {
+	expectRun(t, `return chunk([1, 2, 3, 4], 2)`, nil,
+		Array{Array{Int(1), Int(2)}, Array{Int(3), Int(4)}})
+	expectRun(t, `return chunk([1, 2, 3, 4, 5], 2)`, nil,
+		Array{Array{Int(1), Int(2)}, Array{Int(3), Int(4)}, Array{Int(5)}})
+	expectRun(t, `return chunk([1, 2], 5)`, nil, Array{Array{Int(1), Int(2)}})
+	expectRun(t, `return chunk([], 3)`, nil, Array{})
+	expectRun(t, `return chunk([1, 2], 9223372036854775807)`, nil,
+		Array{Array{Int(1), Int(2)}})
+	expectRun(t, `return windows([1, 2], 9223372036854775807)`, nil, Array{})
+	// chunks do not share elements with the array
+	expectRun(t, `
+	arr := [1, 2, 3]
+	c := chunk(arr, 2)
+	c[0][0] = 9
+	return arr`, nil, Array{Int(1), Int(2), Int(3)})
+
+	expectRun(t, `return windows([1, 2, 3, 4], 2)`, nil, Array{
+		Array{Int(1), Int(2)}, Array{Int(2), Int(3)}, Array{Int(3), Int(4)}})
+	expectRun(t, `return windows([1, 2, 3], 3)`, nil,
+		Array{Array{Int(1), Int(2), Int(3)}})
+	expectRun(t, `return windows([1, 2], 3)`, nil, Array{})
+	expectRun(t, `
+	// moving average
+	out := []
+	for w in windows([2, 4, 6, 8], 3) {
+		out = append(out, (w[0] + w[1] + w[2]) / 3)
+	}
+	return out`, nil, Array{Int(4), Int(6)})
+
+	for _, name := range []string{"chunk", "windows"} {
+		expectErrIs(t, name+`([])`, nil, ErrWrongNumArguments)
+		expectErrHas(t, name+`([1], 0)`, nil,
+			`TypeError: invalid type for argument '2nd': expected positive integer, found non-positive integer`)
+		expectErrHas(t, name+`([1], -1)`, nil,
+			`TypeError: invalid type for argument '2nd': expected positive integer, found non-positive integer`)
+		expectErrHas(t, name+`("ab", 1)`, nil,
+			`TypeError: invalid type for argument '1st': expected array, found string`)
+		expectErrHas(t, name+`([1], "a")`, nil,
+			`TypeError: invalid type for argument '2nd': expected int, found string`)
+	}
+}
+
+func TestVMEnumerate(t *testing.T) {
+	mapFn := `
+	map := func(iterable, fn) {
+		out := []
+		for v in iterable {
+			out = append(out, fn(v))
+		}
+		return out
+	}
+	`
+	expectRun(t, mapFn+`
+	return map(enumerate(["a", "b", "c"]), func(p) {
+		i, v := p
+		return sprintf("%d:%s", i, v)
+	})`, nil, Array{String("0:a"), String("1:b"), String("2:c")})
+	// indexes of strings count characters instead of bytes
+	expectRun(t, mapFn+`
+	return map(enumerate("aé😀b"), func(p) { return p })`, nil, Array{
+		Array{Int(0), Char('a')}, Array{Int(1), Char('é')},
+		Array{Int(2), Char('😀')}, Array{Int(3), Char('b')}})
+	expectRun(t, `
+	out := []
+	for i, p in enumerate(bytes(7, 8)) {
+		out = append(out, i, p)
+	}
+	return out`, nil, Array{Int(0), Array{Int(0), Int(7)},
+		Int(1), Array{Int(1), Int(8)}})
+	expectRun(t, `
+	out := []
+	for p in enumerate({b: 2, a: 1, c: 3}) {
+		out = append(out, p)
+	}
+	return out`, newOpts().DeterministicMaps(), Array{Array{Int(0), Int(1)}, Array{Int(1), Int(2)},
+		Array{Int(2), Int(3)}})
+	// enumerator can be iterated multiple times
+	expectRun(t, `
+	e := enumerate([1, 2])
+	n := 0
+	for _ in e { n++ }
+	for _ in e { n++ }
+	return [n, typeName(e), string(e), e == e, e == enumerate([1, 2])]`,
+		nil, Array{Int(4), String("enumerator"), String("<enumerator>"),
+			True, False})
+	expectRun(t, `for _ in enumerate([]) { return 1 }; return 0`, nil, Int(0))
+
+	expectErrIs(t, `enumerate()`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `enumerate([], [])`, nil, ErrWrongNumArguments)
+	expectErrHas(t, `enumerate(1)`, nil,
+		`TypeError: invalid type for argument '1st': expected iterable, found int`)
+}
+
+func TestVMSizeOf(t *testing.T) {
+	expectRun(t, `return sizeOf(undefined)`, nil, Int(0))
+	expectRun(t, `return sizeOf(1) == sizeOf(2.5)`, nil, True)
+	expectRun(t, `return sizeOf("abcd") - sizeOf("")`, nil, Int(4))
+	expectRun(t, `return sizeOf([1]) < sizeOf([1, 2])`, nil, True)
+	expectRun(t, `
+	small := {name: "x", items: [1, 2, 3]}
+	large := {name: "x", items: []}
+	for i := 0; i < 1000; i++ {
+		large.items = append(large.items, {id: i, label: "item"})
+	}
+	return [sizeOf(small) < sizeOf(large), sizeOf(large) > 1000*16]`, nil,
+		Array{True, True})
+	// shared containers are counted once
+	expectRun(t, `
+	a := [1, 2, 3]
+	return sizeOf([a, a]) < sizeOf([a, [1, 2, 3]])`, nil, True)
+	// cyclic references do not hang
+	expectRun(t, `
+	m := {a: 1}
+	m.self = m
+	arr := [1, 2]
+	arr[1] = arr
+	m.arr = arr
+	return sizeOf(m) > sizeOf({a: 1})`, nil, True)
+	expectRun(t, `
+	m := syncMap({a: "x"})
+	m.self = m
+	return sizeOf(m) > 0`, nil, True)
+	expectRun(t, `return sizeOf(freeze([1, "ab"])) > sizeOf([1, "ab"])`,
+		nil, True)
+	expectRun(t, `return sizeOf(error("abc")) > sizeOf(error(""))`, nil, True)
+	expectRun(t, `return sizeOf(func(){}) > 0`, nil, True)
+
+	expectErrIs(t, `sizeOf()`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `sizeOf(1, 2)`, nil, ErrWrongNumArguments)
+}
+
+func TestVMDiff(t *testing.T) {
+	expectRun(t, `
+	old := {
+		name: "app",
+		db: {host: "localhost", port: 5432, opts: {ssl: false}},
+		tags: ["a", "b", "c"],
+		debug: true,
+	}
+	new := {
+		name: "app",
+		db: {host: "db.local", port: 5432, opts: {ssl: true, timeout: 10}},
+		tags: ["a", "x"],
+		log: {level: "info"},
+	}
+	return diff(old, new)`, nil, Map{
+		"added": Map{
+			"db.opts.timeout": Int(10),
+			"log":             Map{"level": String("info")},
+		},
+		"removed": Map{
+			"debug":  True,
+			"tags.2": String("c"),
+		},
+		"changed": Map{
+			"db.host":     Array{String("localhost"), String("db.local")},
+			"db.opts.ssl": Array{False, True},
+			"tags.1":      Array{String("b"), String("x")},
+		},
+	})
+	expectRun(t, `return diff({a: [1, {b: 2}]}, {a: [1, {b: 2}]})`, nil,
+		Map{"added": Map{}, "removed": Map{}, "changed": Map{}})
+	expectRun(t, `return diff([1], [1, 2, [3]])`, nil, Map{
+		"added":   Map{"1": Int(2), "2": Array{Int(3)}},
+		"removed": Map{},
+		"changed": Map{}})
+	expectRun(t, `return diff({a: 1, b: [1]}, {a: 1.0, b: {}})`, nil, Map{
+		"added":   Map{},
+		"removed": Map{},
+		"changed": Map{
+			"a": Array{Int(1), Float(1)},
+			"b": Array{Array{Int(1)}, Map{}},
+		}})
+	expectRun(t, `return diff(1, 2)`, nil, Map{
+		"added":   Map{},
+		"removed": Map{},
+		"changed": Map{"": Array{Int(1), Int(2)}}})
+	expectRun(t, `return diff(freeze({a: 1}), {a: 2}).changed`, nil,
+		Map{"a": Array{Int(1), Int(2)}})
+	expectRun(t, `x := {a: 1}; x.self = x; y := {a: 1}; y.self = y
+	return [diff(x, x), diff(x, y)]`, nil, Array{
+		Map{"added": Map{}, "removed": Map{}, "changed": Map{}},
+		Map{"added": Map{}, "removed": Map{}, "changed": Map{}}})
+	expectRun(t, `x := {a: 1}; x.self = x; d := diff(x, {a: 1, self: {a: 2}})
+	return [len(d.removed), same(d.removed["self.self"], x), d.changed]`,
+		nil, Array{Int(1), True,
+			Map{"self.a": Array{Int(1), Int(2)}}})
+	expectRun(t, `s := {a: 1}; return diff({x: s, y: s}, {x: {a: 2}, y: {a: 3}}).changed`,
+		nil, Map{"x.a": Array{Int(1), Int(2)}, "y.a": Array{Int(1), Int(3)}})
+	expectErrIs(t, `diff({})`, nil, ErrWrongNumArguments)
+}
+
+func TestVMPack(t *testing.T) {
+	expectRun(t, `return pack(">I H b 2s ?", 0x01020304, 0xABCD, -2, "hi", true)`,
+		nil, Bytes{1, 2, 3, 4, 0xAB, 0xCD, 0xFE, 'h', 'i', 1})
+	expectRun(t, `return pack("<I H b 2s ?", 0x01020304, 0xABCD, -2, "hi", true)`,
+		nil, Bytes{4, 3, 2, 1, 0xCD, 0xAB, 0xFE, 'h', 'i', 1})
+	expectRun(t, `return pack("!H", 1) == pack("H", 1)`, nil, True)
+	expectRun(t, `return pack("<q Q", -1, 1u)`, nil,
+		Bytes{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF,
+			1, 0, 0, 0, 0, 0, 0, 0})
+	expectRun(t, `return pack(">f d", 1.5, 2)`, nil,
+		Bytes{0x3F, 0xC0, 0, 0, 0x40, 0, 0, 0, 0, 0, 0, 0})
+	expectRun(t, `return pack("3B x 4s", 1, 2, 3, bytes("ab"))`, nil,
+		Bytes{1, 2, 3, 0, 'a', 'b', 0, 0})
+	expectRun(t, `return pack("2s", "abc")`, nil, Bytes{'a', 'b'})
+	expectRun(t, `return pack("B h", 257, 70000)`, nil, Bytes{1, 0x11, 0x70})
+	expectRun(t, `return pack("")`, nil, Bytes{})
+
+	// mixed record round trip in both byte orders
+	for _, order := range []string{"<", ">"} {
+		expectRun(t, `
+		format := "`+order+`b B h H i I q Q f d ? 3s"
+		values := [-1, 255u, -300, 60000u, -70000, 4000000000u,
+			-5000000000, 18446744073709551615u, 0.5, -1.25, true, bytes("abc")]
+		b := pack(format, ...values)
+		return [len(b), unpack(format, b) == values]`,
+			nil, Array{Int(46), True})
+	}
+	expectRun(t, `return unpack("<I H", bytes(4, 3, 2, 1, 0xCD, 0xAB))`, nil,
+		Array{Uint(0x01020304), Uint(0xABCD)})
+	expectRun(t, `return unpack(">i h b", bytes(0xFF, 0xFF, 0xFF, 0xFE, 0x80, 0, 0x80))`,
+		nil, Array{Int(-2), Int(-32768), Int(-128)})
+	expectRun(t, `return unpack("2x B ?", bytes(1, 2, 3, 0))`, nil,
+		Array{Uint(3), False})
+	expectRun(t, `b := bytes("ab"); v := unpack("2s", b); b[0] = 0; return v`,
+		nil, Array{Bytes("ab")})
+
+	// length mismatches are returned as error values
+	expectRun(t, `return string(unpack(">I H", bytes(1, 2, 3, 4)))`, nil,
+		String("error: unpack requires 6 bytes, got 4"))
+	expectRun(t, `return string(unpack("B", bytes(1, 2)))`, nil,
+		String("error: unpack requires 1 bytes, got 2"))
+	expectRun(t, `return string(pack(">I H", 1))`, nil,
+		String("error: pack requires 2 values, got 1"))
+	expectRun(t, `return isError(pack("B", 1, 2))`, nil, True)
+
+	expectErrIs(t, `pack()`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `unpack("B")`, nil, ErrWrongNumArguments)
+	expectErrHas(t, `pack(1)`, nil,
+		`TypeError: invalid type for argument '1st': expected string, found int`)
+	expectErrHas(t, `pack("B", "a")`, nil,
+		`TypeError: invalid type for argument '2nd': expected int|uint|char|bool, found string`)
+	expectErrHas(t, `pack("B d", 1, "a")`, nil,
+		`TypeError: invalid type for argument '3rd': expected float|int|uint, found string`)
+	expectErrHas(t, `pack("2s", 1)`, nil,
+		`TypeError: invalid type for argument '2nd': expected bytes|string, found int`)
+	expectErrHas(t, `pack("Z", 1)`, nil,
+		`TypeError: invalid pack format character: 'Z'`)
+	expectErrHas(t, `unpack("<3", bytes())`, nil,
+		`TypeError: invalid pack format: "3"`)
+	expectErrHas(t, `unpack("B", "a")`, nil,
+		`TypeError: invalid type for argument '2nd': expected bytes, found string`)
+	expectErrHas(t, `pack("3000000000x")`, nil,
+		`TypeError: pack format count is too large: 3000000000`)
+	expectErrHas(t, `unpack("9223372036854775807s9223372036854775807s2s", bytes())`,
+		nil, `TypeError: pack format count is too large: 9223372036854775807`)
+	expectErrHas(t, `pack("600000000s600000000s")`, nil,
+		`TypeError: pack format size is too large: "600000000s600000000s"`)
+	expectRun(t, `return len(pack("100000x"))`, nil, Int(100000))
+	expectRun(t, `return unpack("<2H 3x 2s", bytes(1, 0, 2, 0, 0, 0, 0, 7, 8))`,
+		nil, Array{Uint(1), Uint(2), Bytes{7, 8}})
+}
+
+type testWriter struct {
+	ObjectImpl
+	buf bytes.Buffer
+	err error
+}
+
+func (*testWriter) TypeName() string { return "testWriter" }
+
+func (o *testWriter) String() string { return o.buf.String() }
+
+func (o *testWriter) Write(p []byte) (int, error) {
+	if o.err != nil {
+		return 0, o.err
+	}
+	return o.buf.Write(p)
+}
+
+type testList struct {
+	ObjectImpl
+	values []Object
+}
+
+func (*testList) TypeName() string { return "testList" }
+
+func (o *testList) String() string { return "testList" }
+
+func (o *testList) Len() int { return len(o.values) }
+
+func (o *testList) Cap() int { return cap(o.values) }
+
+func (o *testList) IndexGet(index Object) (Object, error) {
+	i, ok := index.(Int)
+	if !ok {
+		return nil, NewIndexTypeError("int", index.TypeName())
+	}
+	if i < 0 || int(i) >= len(o.values) {
+		return nil, ErrIndexOutOfBounds
+	}
+	return o.values[i], nil
+}
+
+func TestVMLengthGetter(t *testing.T) {
+	values := make([]Object, 3, 5)
+	values[0], values[1], values[2] = Int(1), Int(2), Int(3)
+	g := Map{"l": &testList{values: values}}
+
+	expectRun(t, `global l; return [len(l), cap(l)]`,
+		newOpts().Globals(g).Skip2Pass(), Array{Int(3), Int(5)})
+	expectRun(t, `global l; s := 0; for i := 0; i < len(l); i++ { s += l[i] }
+	return s`, newOpts().Globals(g).Skip2Pass(), Int(6))
+	expectErrIs(t, `global l; return l[3]`,
+		newOpts().Globals(g).Skip2Pass(), ErrIndexOutOfBounds)
+	expectErrIs(t, `global l; return l["a"]`,
+		newOpts().Globals(g).Skip2Pass(), ErrType)
+
+	// objects not implementing LengthGetter, CapGetter and IndexGet
+	g = Map{"w": &testWriter{}}
+	expectRun(t, `global w; return [len(w), cap(w)]`,
+		newOpts().Globals(g).Skip2Pass(), Array{Int(0), Int(0)})
+	expectErrIs(t, `global w; return w[0]`,
+		newOpts().Globals(g).Skip2Pass(), ErrNotIndexable)
+}
+
+// testPairs is an ordered collection of key value pairs.
+type testPairs struct {
+	ObjectImpl
+	keys   []string
+	values []Object
+}
+
+func (*testPairs) TypeName() string { return "testPairs" }
+
+func (o *testPairs) String() string { return "testPairs" }
+
+func (o *testPairs) CanIterate() bool { return true }
+
+func (o *testPairs) Iterate() Iterator { return &testPairsIterator{p: o} }
+
+type testPairsIterator struct {
+	p *testPairs
+	i int
+}
+
+func (it *testPairsIterator) Next() bool {
+	it.i++
+	return it.i <= len(it.p.keys)
+}
+
+func (it *testPairsIterator) Key() Object { return String(it.p.keys[it.i-1]) }
+
+func (it *testPairsIterator) Value() Object { return it.p.values[it.i-1] }
+
+func TestVMCustomIterator(t *testing.T) {
+	g := Map{"p": &testPairs{
+		keys:   []string{"b", "a", "c"},
+		values: []Object{Int(1), Int(2), Int(3)},
+	}}
+	opts := newOpts().Globals(g).Skip2Pass()
+	expectRun(t, `global p; out := []
+	for k, v in p { out = append(out, [k, v]) }
+	return out`, opts, Array{
+		Array{String("b"), Int(1)},
+		Array{String("a"), Int(2)},
+		Array{String("c"), Int(3)},
+	})
+	expectRun(t, `global p; out := ""; for k, _ in p { out += k }; return out`,
+		opts, String("bac"))
+	expectRun(t, `global p; s := 0; for v in p { s += v }; return s`,
+		opts, Int(6))
+	expectRun(t, `global p; return isIterable(p)`, opts, True)
+	expectRun(t, `global p; for k, v in p { if k == "a" { return v } }`,
+		opts, Int(2))
+
+	g = Map{"w": &testWriter{}}
+	expectRun(t, `global w; return isIterable(w)`,
+		newOpts().Globals(g).Skip2Pass(), False)
+	expectErrIs(t, `global w; for k in w {}`,
+		newOpts().Globals(g).Skip2Pass(), ErrNotIterable)
+}
+
+func TestVMBuiltinExit(t *testing.T) {
+	expectErrIs(t, `exit()`, nil, ErrExit)
+	expectErrIs(t, `exit(3)`, nil, ErrExit)
+	expectErrHas(t, `exit(3)`, nil, "ExitError: exit code 3")
+	expectErrIs(t, `try { exit(1) } catch err { return err }`, nil, ErrExit)
+	expectErrIs(t, `try { exit(1) } catch err { } finally { }`, nil, ErrExit)
+	expectErrIs(t, `try { try { exit(1) } finally { } } catch err { }`, nil, ErrExit)
+	expectErrIs(t, `f := func() { try { exit(1) } catch err {} }; try { f() } catch err {}`,
+		nil, ErrExit)
+	expectErrIs(t, `exit("a")`, nil, ErrType)
+	expectErrIs(t, `exit(1, 2)`, nil, ErrWrongNumArguments)
+
+	g := Map{}
+	expectErrorGen(t, `
+	g := globals()
+	try {
+		try {
+			exit(3)
+		} catch err {
+			g.caught = true
+		} finally {
+			g.finally1 = true
+		}
+	} catch err {
+		g.caught = true
+	} finally {
+		g.finally2 = true
+	}`, newOpts().Globals(g), func(t *testing.T, err error) {
+		code, ok := ExitCode(err)
+		require.True(t, ok)
+		require.Equal(t, 3, code)
+	})
+	require.Equal(t, Map{"finally1": True, "finally2": True}, g)
+
+	code, ok := ExitCode(errors.New("x"))
+	require.False(t, ok)
+	require.Equal(t, 0, code)
+	code, ok = ExitCode(ErrType)
+	require.False(t, ok)
+	require.Equal(t, 0, code)
+}
+
+func TestVMBuiltinDump(t *testing.T) {
+	expectRun(t, `return dump(5)`, nil, String("int(5)"))
+	expectRun(t, `return dump(5u)`, nil, String("uint(5)"))
+	expectRun(t, `return dump(1.5)`, nil, String("float(1.5)"))
+	expectRun(t, `return dump(true)`, nil, String("bool(true)"))
+	expectRun(t, `return dump('x')`, nil, String("char('x')"))
+	expectRun(t, `return dump("x")`, nil, String(`string("x")`))
+	expectRun(t, `return dump(bytes(1, 2))`, nil, String("bytes([1 2])"))
+	expectRun(t, `return dump(undefined)`, nil, String("undefined"))
+	expectRun(t, `return dump(error("x"))`, nil, String("error(error: x)"))
+	expectRun(t, `return dump([])`, nil, String("array(len=0) []"))
+	expectRun(t, `return dump({})`, nil, String("map(len=0) {}"))
+	expectRun(t, `return dump({b: [1, "a"], a: {c: undefined}, d: []})`, nil,
+		String(`map(len=3) {
+  "a": map(len=1) {
+    "c": undefined,
+  },
+  "b": array(len=2) [
+    int(1),
+    string("a"),
+  ],
+  "d": array(len=0) [],
+}`))
+	expectRun(t, `m := {x: 1}; return dump([m, m])`, nil,
+		String(`array(len=2) [
+  map(len=1) {
+    "x": int(1),
+  },
+  map(len=1) {
+    "x": int(1),
+  },
+]`))
+	expectRun(t, `m := {x: 1}; m.self = m; return dump(m)`, nil,
+		String(`map(len=2) {
+  "self": map(<cycle>),
+  "x": int(1),
+}`))
+	expectRun(t, `a := [1, 2]; a[1] = a; return dump(a)`, nil,
+		String(`array(len=2) [
+  int(1),
+  array(<cycle>),
+]`))
+	expectRun(t, `a := [1, {}]; a[1].a = a; return dump(a)`, nil,
+		String(`array(len=2) [
+  int(1),
+  map(len=1) {
+    "a": array(<cycle>),
+  },
+]`))
+	expectRun(t, `return dump(globals())`,
+		newOpts().Globals(&SyncMap{Value: Map{"a": Int(1)}}).Skip2Pass(),
+		String(`syncMap(len=1) {
+  "a": int(1),
+}`))
+	expectErrIs(t, `dump()`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `dump(1, 2)`, nil, ErrWrongNumArguments)
 }
 
 func TestBytes(t *testing.T) {
@@ -1263,6 +3120,43 @@ func TestBytes(t *testing.T) {
 	expectErrIs(t, `return bytes("abcde")[-1]`, nil, ErrIndexOutOfBounds)
 	expectErrIs(t, `return bytes("abcde")[100]`, nil, ErrIndexOutOfBounds)
 	expectErrIs(t, `b1 := bytes("abcde");	b2 := b1[:cap(b1)+1]`, nil, ErrIndexOutOfBounds)
+
+	// comparison, lexicographic like Go's bytes.Compare
+	for _, tc := range []struct {
+		expr     string
+		expected Object
+	}{
+		{`bytes("abc") < bytes("abd")`, True},
+		{`bytes("abd") < bytes("abc")`, False},
+		{`bytes("abc") < bytes("abc")`, False},
+		{`bytes("abc") <= bytes("abc")`, True},
+		{`bytes("ab") < bytes("abc")`, True},
+		{`bytes() < bytes(0)`, True},
+		{`bytes(255) > bytes(1, 255)`, True},
+		{`bytes("abc") > bytes("ab")`, True},
+		{`bytes("abc") >= bytes("abd")`, False},
+		{`bytes("abc") == bytes("abc")`, True},
+		{`bytes("abc") != bytes("abd")`, True},
+		{`bytes("abc") < "abd"`, True},
+		{`bytes("abc") <= "abc"`, True},
+		{`bytes("b") > "abc"`, True},
+		{`bytes("abc") >= "abd"`, False},
+		{`bytes("abc") == "abc"`, True},
+		{`"abc" < bytes("abd")`, True},
+		{`"abc" <= bytes("ab")`, False},
+		{`"abd" > bytes("abc")`, True},
+		{`"abc" >= bytes("abc")`, True},
+		{`"abc" == bytes("abc")`, True},
+		{`"\xff" > bytes("\x7f")`, True},
+		{`bytes() > undefined`, True},
+		{`bytes() < undefined`, False},
+	} {
+		expectRun(t, `return `+tc.expr, nil, tc.expected)
+	}
+	expectErrIs(t, `return bytes() < 1`, nil, ErrType)
+	expectErrIs(t, `return bytes() > []`, nil, ErrType)
+	expectRun(t, `return sort([bytes("b"), bytes("ab"), bytes("a"), bytes()])`,
+		nil, Array{Bytes{}, Bytes("a"), Bytes("ab"), Bytes("b")})
 }
 
 func TestVMChar(t *testing.T) {
@@ -1285,6 +3179,25 @@ func TestVMChar(t *testing.T) {
 	expectRun(t, `return '4' >= '4'`, nil, True)
 	expectRun(t, `return '九' + "Hello"`, nil, String("九Hello"))
 	expectRun(t, `return "Hello" + '九'`, nil, String("Hello九"))
+
+	// invalid code points are converted to the Unicode replacement character
+	for _, s := range []string{
+		`char(0xD800)`, `char(0xDBFF)`, `char(0xDC00)`, `char(0xDFFF)`,
+		`char(0x110000)`, `char(-1)`, `('\U0010FFFF' + 1)`, `char(0x7FFFFFFF)`,
+	} {
+		expectRun(t, `return string(`+s+`)`, nil, String("\uFFFD"))
+		expectRun(t, `return "a" + `+s, nil, String("a\uFFFD"))
+		expectRun(t, `return `+s+` + "a"`, nil, String("\uFFFDa"))
+		expectRun(t, `return sprintf("%c", `+s+`)`, nil, String("\uFFFD"))
+	}
+	expectRun(t, `return string(char(0xD7FF))`, nil, String("\uD7FF"))
+	expectRun(t, `return string(char(0xE000))`, nil, String("\uE000"))
+	expectRun(t, `return string(char(0x10FFFF))`, nil, String("\U0010FFFF"))
+	// invalid code points are kept as is in arithmetic
+	expectRun(t, `return int(char(0xD800))`, nil, Int(0xD800))
+	expectRun(t, `return char(0x110000) - 1 == '\U0010FFFF'`, nil, True)
+	expectRun(t, `return char("")`, nil, Undefined)
+	expectRun(t, `return char("\xff")`, nil, Undefined)
 }
 
 func TestVMCondExpr(t *testing.T) {
@@ -1399,6 +3312,72 @@ func TestVMBuiltinError(t *testing.T) {
 	expectRun(t, `error("error").err`, nil, Undefined)
 	expectRun(t, `error("error").value_`, nil, Undefined)
 	expectRun(t, `error([1,2,3])[1]`, nil, Undefined)
+
+	// structured data
+	expectRun(t, `return error("x", {a: 1})`, nil,
+		&Error{Name: "error", Message: "x", Data: Map{"a": Int(1)}})
+	expectRun(t, `return error("x").Data`, nil, Map{})
+	expectRun(t, `return len(error("x").Data)`, nil, Int(0))
+	expectRun(t, `return len(error("x", {a: 1, b: 2}).Data)`, nil, Int(2))
+	expectRun(t, `return error("x", {a: 1}).a`, nil, Int(1))
+	expectRun(t, `return error("x", {a: 1})["a"]`, nil, Int(1))
+	expectRun(t, `return error("x", {a: 1}).b`, nil, Undefined)
+	expectRun(t, `
+	err := error("x", {a: 1, b: 2, c: 3})
+	out := 0
+	for k, v in err.Data {
+		if k != "b" { out += v }
+	}
+	return out`, nil, Int(4))
+	expectRun(t, `
+	err := error("x", {Name: "n", Message: "m", New: 1, Data: 2, other: 3})
+	return [err.Name, err.Message, typeName(err.New), err.Data.Data, err.other]`,
+		nil, Array{String("error"), String("x"), String("function"),
+			Int(2), Int(3)})
+	expectRun(t, `return error("x", {a: 1}).New("y").a`, nil, Int(1))
+	expectRun(t, `try { throw error("x", {a: 1}) } catch err { return err.a }`,
+		nil, Int(1))
+	expectRun(t, `try { throw error("x", {a: 1}) } catch err { return len(err.Data) }`,
+		nil, Int(1))
+	expectErrIs(t, `error("x", [])`, nil, ErrType)
+
+	// user defined error kinds
+	expectRun(t, `return errorKind("MyError")`, nil, &Error{Name: "MyError"})
+	expectRun(t, `return typeName(errorKind("MyError"))`, nil, String("error"))
+	expectRun(t, `
+	MyError := errorKind("MyError")
+	try {
+		throw MyError.New("x")
+	} catch err {
+		return [isError(err, MyError), isError(err, NotImplementedError),
+			isError(err, errorKind("MyError")), err.Name, err.Message,
+			string(err)]
+	}`, nil, Array{True, False, False, String("MyError"), String("x"),
+		String("MyError: x")})
+	expectRun(t, `
+	MyError := errorKind("MyError")
+	OtherError := errorKind("OtherError")
+	f := func(kind) {
+		try {
+			throw kind.New("y")
+		} catch err {
+			match err {
+			case OtherError:
+				return 1
+			case MyError:
+				return 2
+			}
+		}
+	}
+	return [f(MyError), f(OtherError), f(TypeError)]`,
+		nil, Array{Int(2), Int(1), Undefined})
+	expectRun(t, `
+	MyError := errorKind("MyError")
+	err := MyError.New("a")
+	return isError(err.New("b"), MyError)`, nil, True)
+	expectErrIs(t, `errorKind()`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `errorKind("a", "b")`, nil, ErrWrongNumArguments)
+	expectErrIs(t, `errorKind(1)`, nil, ErrType)
 }
 
 func TestVMFloat(t *testing.T) {
@@ -1539,164 +3518,534 @@ func TestFor(t *testing.T) {
 
 	expectRun(t, `
 	out := 0
-	func() {
-		for true {
-			out++
-			if out == 5 {
-				return
-			}
-		}
-	}()
-	return out`, nil, Int(5))
+	func() {
+		for true {
+			out++
+			if out == 5 {
+				return
+			}
+		}
+	}()
+	return out`, nil, Int(5))
+
+	expectRun(t, `
+	out := 0
+	for a:=1; a<=10; a++ {
+		out += a
+	}
+	return out`, nil, Int(55))
+
+	expectRun(t, `
+	out := 0
+	for a:=1; a<=3; a++ {
+		for b:=3; b<=6; b++ {
+			out += b
+		}
+	}
+	return out`, nil, Int(54))
+
+	expectRun(t, `
+	out := 0
+	func() {
+		for {
+			out++
+			if out == 5 {
+				break
+			}
+		}
+	}()
+	return out`, nil, Int(5))
+
+	expectRun(t, `
+	out := 0
+	func() {
+		for true {
+			out++
+			if out == 5 {
+				break
+			}
+		}
+	}()
+	return out`, nil, Int(5))
+
+	expectRun(t, `
+	return func() {
+		a := 0
+		for {
+			a++
+			if a == 5 {
+				break
+			}
+		}
+		return a
+	}()`, nil, Int(5))
+
+	expectRun(t, `
+	return func() {
+		a := 0
+		for true {
+			a++
+			if a== 5 {
+				break
+			}
+		}
+		return a
+	}()`, nil, Int(5))
+
+	expectRun(t, `
+	return func() {
+		a := 0
+		func() {
+			for {
+				a++
+				if a == 5 {
+					break
+				}
+			}
+		}()
+		return a
+	}()`, nil, Int(5))
+
+	expectRun(t, `
+	return func() {
+		a := 0
+		func() {
+			for true {
+				a++
+				if a == 5 {
+					break
+				}
+			}
+		}()
+		return a
+	}()`, nil, Int(5))
+
+	expectRun(t, `
+	return func() {
+		sum := 0
+		for a:=1; a<=10; a++ {
+			sum += a
+		}
+		return sum
+	}()`, nil, Int(55))
+
+	expectRun(t, `
+	return func() {
+		sum := 0
+		for a:=1; a<=4; a++ {
+			for b:=3; b<=5; b++ {
+				sum += b
+			}
+		}
+		return sum
+	}()`, nil, Int(48)) // (3+4+5) * 4
+
+	expectRun(t, `
+	a := 1
+	for ; a<=10; a++ {
+		if a == 5 {
+			break
+		}
+	}
+	return a`, nil, Int(5))
+
+	expectRun(t, `
+	out := 0
+	for a:=1; a<=10; a++ {
+		if a == 3 {
+			continue
+		}
+		out += a
+		if a == 5 {
+			break
+		}
+	}
+	return out`, nil, Int(12)) // 1 + 2 + 4 + 5
+
+	expectRun(t, `
+	out := 0
+	for a:=1; a<=10; {
+		if a == 3 {
+			a++
+			continue
+		}
+		out += a
+		if a == 5 {
+			break
+		}
+		a++
+	}
+	return out`, nil, Int(12)) // 1 + 2 + 4 + 5
+}
+
+func TestWhile(t *testing.T) {
+	expectRun(t, `
+	out := 0
+	while out < 5 {
+		out++
+	}
+	return out`, nil, Int(5))
+
+	expectRun(t, `
+	out := 0
+	a := 0
+	while a < 5 {
+		a++
+		if a == 3 { continue }
+		out += a
+	}
+	return out`, nil, Int(12)) // 1 + 2 + 4 + 5
+
+	expectRun(t, `
+	out := 0
+	while false {
+		out++
+	}
+	return out`, nil, Int(0))
+
+	expectRun(t, `
+	out := 0
+	do {
+		out++
+	} while out < 5
+	return out`, nil, Int(5))
+
+	// body runs once even if condition is initially false
+	expectRun(t, `
+	out := 0
+	do {
+		out++
+	} while false
+	return out`, nil, Int(1))
+
+	expectRun(t, `
+	out := 0
+	do {
+		out++
+	} while out > 10
+	return out`, nil, Int(1))
+
+	// continue jumps to the condition
+	expectRun(t, `
+	out := 0
+	a := 0
+	do {
+		a++
+		if a == 3 { continue }
+		out += a
+	} while a < 5
+	return out`, nil, Int(12)) // 1 + 2 + 4 + 5
+
+	expectRun(t, `
+	a := 0
+	do {
+		a++
+		if a == 3 { break }
+	} while true
+	return a`, nil, Int(3))
+
+	expectRun(t, `
+	out := []
+	do {
+		x := len(out)
+		do {
+			out = append(out, x)
+		} while false
+	} while len(out) < 3
+	return out`, nil, Array{Int(0), Int(1), Int(2)})
+
+	expectRun(t, `
+	f := func(n) {
+		do {
+			if n == 2 { return "two" }
+			n--
+		} while n > 0
+		return n
+	}
+	return [f(5), f(1), f(-1)]`, nil, Array{String("two"), Int(0), Int(-2)})
+
+	expectErrHas(t, `x := 0; do { a := 1 } while a`, newOpts().CompilerError(),
+		`Compile Error: unresolved reference "a"`)
+}
+
+func TestLabeledBlock(t *testing.T) {
+	expectRun(t, `
+	out := []
+	check: {
+		out = append(out, 1)
+		if len(out) > 0 {
+			break check
+		}
+		out = append(out, 2)
+	}
+	out = append(out, 3)
+	return out`, nil, Array{Int(1), Int(3)})
+
+	expectRun(t, `
+	out := []
+	check: {
+		out = append(out, 1)
+	}
+	out = append(out, 2)
+	return out`, nil, Array{Int(1), Int(2)})
+
+	// early exit in functions
+	expectRun(t, `
+	validate := func(v) {
+		errs := []
+		valid: {
+			if !isInt(v) {
+				errs = append(errs, "not int")
+				break valid
+			}
+			if v < 0 {
+				errs = append(errs, "negative")
+			}
+		}
+		return len(errs) == 0 ? "ok" : errs[0]
+	}
+	return [validate(1), validate("a"), validate(-1)]`,
+		nil, Array{String("ok"), String("not int"), String("negative")})
+
+	// nested blocks and loops
+	expectRun(t, `
+	out := ""
+	outer: {
+		inner: {
+			out += "a"
+			break inner
+			out += "b"
+		}
+		out += "c"
+		for i := 0; i < 3; i++ {
+			if i == 1 { break outer }
+			out += string(i)
+		}
+		out += "d"
+	}
+	return out + "e"`, nil, String("ac0e"))
+
+	// unlabeled break and continue belong to the enclosing loop
+	expectRun(t, `
+	out := []
+	for i := 0; i < 5; i++ {
+		blk: {
+			if i == 1 { continue }
+			if i == 3 { break }
+			out = append(out, i)
+		}
+	}
+	return out`, nil, Array{Int(0), Int(2)})
+
+	// break runs finally blocks
+	expectRun(t, `
+	out := []
+	blk: {
+		try {
+			try {
+				break blk
+			} finally {
+				out = append(out, 1)
+			}
+		} finally {
+			out = append(out, 2)
+		}
+		out = append(out, 3)
+	}
+	return out`, nil, Array{Int(1), Int(2)})
+
+	expectRun(t, `
+	x := 0
+	blk: {
+		x := 1
+		break blk
+	}
+	blk: {
+		x = 2
+	}
+	return x`, nil, Int(2))
+
+	expectErrHas(t, `for { break x }`, newOpts().CompilerError(),
+		`Compile Error: break label not defined: x`)
+	expectErrHas(t, `x: { func() { break x }() }`, newOpts().CompilerError(),
+		`Compile Error: break label not defined: x`)
+	expectErrHas(t, `x: { for { continue x } }`, newOpts().CompilerError(),
+		`Compile Error: invalid continue label: x`)
+	expectErrHas(t, `x: { break }`, newOpts().CompilerError(),
+		`Compile Error: break not allowed outside loop`)
+	expectErrHas(t, `x: { x: {} }`, newOpts().CompilerError(),
+		`Compile Error: label x already defined`)
+}
+
+func TestForMultipleInitPost(t *testing.T) {
+	expectRun(t, `
+	out := []
+	for i, j := 0, 10; i < j; i++, j-- {
+		out = append(out, [i, j])
+	}
+	return out`, nil, Array{
+		Array{Int(0), Int(10)}, Array{Int(1), Int(9)}, Array{Int(2), Int(8)},
+		Array{Int(3), Int(7)}, Array{Int(4), Int(6)},
+	})
+
+	expectRun(t, `
+	steps := 0
+	for lo, hi := 0, 100; lo < hi; lo += 10, hi -= 10 {
+		steps++
+	}
+	return steps`, nil, Int(5))
+
+	// values are assigned after all of them are evaluated
+	expectRun(t, `
+	out := []
+	for a, b := 0, 1; a < 20; a, b = b, a + b {
+		out = append(out, a)
+	}
+	return out`, nil, Array{Int(0), Int(1), Int(1), Int(2), Int(3), Int(5),
+		Int(8), Int(13)})
+
+	expectRun(t, `
+	x := 5
+	for x, y := x + 1, x; true; {
+		return [x, y]
+	}`, nil, Array{Int(6), Int(5)})
+
+	expectRun(t, `
+	var (i, j, k)
+	for i, j = 0, 0; i < 3; i++, j += 2, k = i + j {
+	}
+	return [i, j, k]`, nil, Array{Int(3), Int(6), Int(9)})
+
+	expectErrHas(t, `for i, j := 0, 1, 2; i < j; i++ {}`, newOpts().CompilerError(),
+		`Compile Error: multiple expressions on the right side not supported`)
+	expectErrHas(t, `for i := 0; i < 1; i, j += 1, 2 {}`, newOpts().CompilerError(),
+		`Parse Error: expected 1 expression`)
+}
+
+func TestForElse(t *testing.T) {
+	// else runs if loop is not terminated by break
+	expectRun(t, `
+	out := 0
+	for i := 0; i < 3; i++ {
+		out += i
+	} else {
+		out += 10
+	}
+	return out`, nil, Int(13))
 
 	expectRun(t, `
 	out := 0
-	for a:=1; a<=10; a++ {
-		out += a
+	for i := 0; i < 3; i++ {
+		if i == 1 { break }
+		out += i
+	} else {
+		out += 10
 	}
-	return out`, nil, Int(55))
+	return out`, nil, Int(0))
 
 	expectRun(t, `
 	out := 0
-	for a:=1; a<=3; a++ {
-		for b:=3; b<=6; b++ {
-			out += b
-		}
+	for i := 0; i < 3; i++ {
+		if i == 1 { continue }
+		out += i
+	} else {
+		out += 10
 	}
-	return out`, nil, Int(54))
+	return out`, nil, Int(12))
 
+	// loop without iterations
 	expectRun(t, `
 	out := 0
-	func() {
-		for {
-			out++
-			if out == 5 {
-				break
-			}
-		}
-	}()
-	return out`, nil, Int(5))
+	for false {
+		out++
+	} else {
+		out += 10
+	}
+	return out`, nil, Int(10))
 
 	expectRun(t, `
 	out := 0
-	func() {
-		for true {
-			out++
-			if out == 5 {
-				break
-			}
-		}
-	}()
-	return out`, nil, Int(5))
+	for {
+		out++
+		if out == 3 { break }
+	} else {
+		out += 10
+	}
+	return out`, nil, Int(3))
 
 	expectRun(t, `
-	return func() {
-		a := 0
-		for {
-			a++
-			if a == 5 {
-				break
-			}
-		}
-		return a
-	}()`, nil, Int(5))
+	out := 0
+	a := 0
+	while a < 3 {
+		a++
+	} else {
+		out = a
+	}
+	return out`, nil, Int(3))
 
+	// search loops
 	expectRun(t, `
-	return func() {
-		a := 0
-		for true {
-			a++
-			if a== 5 {
+	find := func(arr, x) {
+		idx := -1
+		for i, v in arr {
+			if v == x {
+				idx = i
 				break
 			}
+		} else {
+			return "not found"
 		}
-		return a
-	}()`, nil, Int(5))
-
-	expectRun(t, `
-	return func() {
-		a := 0
-		func() {
-			for {
-				a++
-				if a == 5 {
-					break
-				}
-			}
-		}()
-		return a
-	}()`, nil, Int(5))
-
-	expectRun(t, `
-	return func() {
-		a := 0
-		func() {
-			for true {
-				a++
-				if a == 5 {
-					break
-				}
-			}
-		}()
-		return a
-	}()`, nil, Int(5))
-
-	expectRun(t, `
-	return func() {
-		sum := 0
-		for a:=1; a<=10; a++ {
-			sum += a
-		}
-		return sum
-	}()`, nil, Int(55))
+		return idx
+	}
+	return [find([1, 2, 3], 2), find([1, 2, 3], 4), find([], 1)]`,
+		nil, Array{Int(1), String("not found"), String("not found")})
 
 	expectRun(t, `
-	return func() {
-		sum := 0
-		for a:=1; a<=4; a++ {
-			for b:=3; b<=5; b++ {
-				sum += b
-			}
-		}
-		return sum
-	}()`, nil, Int(48)) // (3+4+5) * 4
+	out := ""
+	for k, _ in {a: 1} {
+		out += k
+	} else {
+		out += "!"
+	}
+	return out`, nil, String("a!"))
 
+	// break and continue in else block belong to the enclosing loop
 	expectRun(t, `
-	a := 1
-	for ; a<=10; a++ {
-		if a == 5 {
-			break
+	out := []
+	for i := 0; i < 3; i++ {
+		for j in [] {
+		} else {
+			if i == 0 { continue }
+			if i == 2 { break }
 		}
+		out = append(out, i)
 	}
-	return a`, nil, Int(5))
+	return out`, nil, Array{Int(1)})
 
 	expectRun(t, `
-	out := 0
-	for a:=1; a<=10; a++ {
-		if a == 3 {
-			continue
-		}
-		out += a
-		if a == 5 {
-			break
+	out := []
+	for i in [1, 2] {
+		for j in [1, 2] {
+			if j > i { break }
+		} else {
+			out = append(out, i)
 		}
 	}
-	return out`, nil, Int(12)) // 1 + 2 + 4 + 5
+	return out`, nil, Array{Int(2)})
 
 	expectRun(t, `
-	out := 0
-	for a:=1; a<=10; {
-		if a == 3 {
-			a++
-			continue
-		}
-		out += a
-		if a == 5 {
-			break
-		}
-		a++
+	x := 1
+	for x := 5; x < 6; x++ {
+	} else {
+		x = 2
 	}
-	return out`, nil, Int(12)) // 1 + 2 + 4 + 5
+	return x`, nil, Int(2))
+
+	// loop variables are not visible in else block
+	expectErrHas(t, `for i := 0; i < 1; i++ {} else { i }`,
+		newOpts().CompilerError(), `Compile Error: unresolved reference "i"`)
+	expectErrHas(t, `for k, v in [1] {} else { v }`,
+		newOpts().CompilerError(), `Compile Error: unresolved reference "v"`)
 }
 
 func TestVMFunction(t *testing.T) {
@@ -2127,6 +4476,59 @@ func TestBlocksScope(t *testing.T) {
 	}`, newOpts().CompilerError(), `Compile Error: unresolved reference "f"`)
 }
 
+func TestVMNamedResults(t *testing.T) {
+	expectRun(t, `f := func() (a, b) { a = 1; b = 2; return }; return f()`,
+		nil, Array{Int(1), Int(2)})
+	expectRun(t, `f := func(x) (r) { r = x * 2; return }; return f(3)`,
+		nil, Int(6))
+	expectRun(t, `f := func() (a, b) { a = 1; return }; return f()`,
+		nil, Array{Int(1), Undefined})
+	expectRun(t, `f := func() (a, b) {}; return f()`,
+		nil, Array{Undefined, Undefined})
+	expectRun(t, `f := func() () { return }; return f()`, nil, Undefined)
+	expectRun(t, `f := func(x) (q, r) { q = x / 2; r = x % 2; return }
+	q, r := f(7); return [q, r]`, nil, Array{Int(3), Int(1)})
+
+	// explicit return values override named results
+	expectRun(t, `f := func() (a, b) { a = 1; b = 2; return 3 }; return f()`,
+		nil, Int(3))
+	expectRun(t, `f := func() (a, b) { a = 1; return b, a }; return f()`,
+		nil, Array{Undefined, Int(1)})
+
+	// end of function returns named results
+	expectRun(t, `f := func() (r) { r = 1; if r > 0 { r = 5 } }; return f()`,
+		nil, Int(5))
+	expectRun(t, `f := func(x) (r) { r = 1; if x { r = 2; return } }
+	return [f(true), f(false)]`, nil, Array{Int(2), Int(1)})
+	expectRun(t, `f := func() (r) {
+		for i := 0; i < 5; i++ { r = i; if i == 2 { return } }
+	}; return f()`, nil, Int(2))
+
+	// bare return uses function level results even if they are shadowed
+	expectRun(t, `f := func() (r) { r = 1; if true { r := 2; return } }
+	return f()`, nil, Int(1))
+	expectRun(t, `f := func() (r) {
+		inc := func() { r++ }
+		r = 1; inc(); inc()
+		return
+	}; return f()`, nil, Int(3))
+	expectRun(t, `f := func() (r) {
+		try { r = 1; throw "x" } catch { r = 2 } finally { r++ }
+		return
+	}; return f()`, nil, Int(3))
+	expectRun(t, `f := func(...a) (n) { n = len(a); return }; return f(1, 2)`,
+		nil, Int(2))
+	expectRun(t, `f := func() (r) { return }; return func() { return f() }()`,
+		nil, Undefined)
+
+	expectErrHas(t, `f := func(a) (a) {}`, newOpts().CompilerError(),
+		`Compile Error: "a" redeclared in this block`)
+	expectErrHas(t, `f := func() (a, a) {}`, newOpts().CompilerError(),
+		`Compile Error: "a" redeclared in this block`)
+	expectErrHas(t, `f := func() (...a) {}`, newOpts().CompilerError(),
+		`Parse Error: variadic named result`)
+}
+
 func TestVMIf(t *testing.T) {
 	expectRun(t, `var out; if (true) { out = 10 }; return out`,
 		nil, Int(10))
@@ -2209,6 +4611,97 @@ func TestVMIf(t *testing.T) {
 	expectRun(t, `a := 1; if a + 4; a { return a }`, nil, Int(1))
 }
 
+func TestVMMatch(t *testing.T) {
+	script := `
+	f := func(fn) {
+		try {
+			fn()
+		} catch err {
+			match e := err {
+			case WrongNumArgumentsError, TypeError:
+				return "args"
+			case NotImplementedError:
+				return "not implemented: " + e.Message
+			case _:
+				return "other: " + string(e)
+			}
+		}
+		return "ok"
+	}
+	return [
+		f(func() { throw NotImplementedError.New("x") }),
+		f(func() { throw NotImplementedError }),
+		f(func() { len() }),
+		f(func() { throw TypeError.New("y") }),
+		f(func() { throw "z" }),
+		f(func() {}),
+	]`
+	expectRun(t, script, nil, Array{String("not implemented: x"),
+		String("not implemented: "), String("args"), String("args"),
+		String("other: error: z"), String("ok")})
+
+	expectRun(t, `out := 0
+	match NotImplementedError.New("x") {
+	case TypeError:
+		out = 1
+	case NotImplementedError:
+		out = 2
+	case NotImplementedError:
+		out = 3
+	}
+	return out`, nil, Int(2))
+	expectRun(t, `match 1 { case NotImplementedError: return 1 }; return 2`,
+		nil, Int(2))
+	expectRun(t, `match error("x") { case _: return 1 }; return 2`,
+		nil, Int(1))
+	expectRun(t, `match v := 1 { case _: return v + 1 }`, nil, Int(2))
+	expectRun(t, `match 1 {}; return 2`, nil, Int(2))
+	expectRun(t, `myErr := error("mine")
+	match myErr.New("x") {
+	case NotImplementedError: return 1
+	case myErr: return 2
+	}`, nil, Int(2))
+	expectRun(t, `e := 1
+	match TypeError {
+	case e: return 1
+	case TypeError: return 2
+	}`, nil, Int(2))
+	expectRun(t, `calls := 0
+	f := func() { calls++; return TypeError }
+	match f() {
+	case NotImplementedError: return -1
+	case ZeroDivisionError, TypeError: return calls
+	}`, nil, Int(1))
+	expectRun(t, `v := 1
+	match e := TypeError { case _: v = 2 }
+	e := 3
+	return v + e`, nil, Int(5))
+	expectRun(t, `e := 1
+	match e := TypeError { case TypeError: e = 2 }
+	return e`, nil, Int(1))
+	expectRun(t, `a := 0
+	for i := 0; i < 3; i++ {
+		match i {
+		case _:
+			if i == 1 { continue }
+			a += i
+		}
+	}
+	return a`, nil, Int(2))
+	expectErrHas(t, `match 1 { case _: ; case TypeError: }`,
+		newOpts().CompilerError(), `unreachable case clause`)
+	// misspelled error kinds are not bindings
+	expectErrHas(t, `match TypeError { case TypeError: ; case TypError: }`,
+		newOpts().CompilerError(), `unresolved reference "TypError"`)
+
+	// keywords can be used as selectors and map keys
+	expectRun(t, `m := {match: 1, case: 2}
+	m.do = 3
+	m.cleanup = m.while || 4
+	return [m.match, m.case, m.do, m.cleanup, m.func]`,
+		nil, Array{Int(1), Int(2), Int(3), Int(4), Undefined})
+}
+
 func TestVMIncDec(t *testing.T) {
 	expectRun(t, `out := 0; out++; return out`, nil, Int(1))
 	expectRun(t, `out := 0; out--; return out`, nil, -Int(1))
@@ -2230,6 +4723,46 @@ func TestVMIncDec(t *testing.T) {
 		`Compile Error: unresolved reference ""`)
 }
 
+func TestVMBinaryOpNumericMatrix(t *testing.T) {
+	values := []Object{
+		Int(0), Int(1), Int(-7), Int(12), Int(1023), Int(1024), Int(1 << 40),
+		Int(math.MinInt64), Int(math.MaxInt64), Uint(0), Uint(5), Float(0),
+		Float(-2.5), Char(0), Char('a'), True, False,
+	}
+	for _, tok := range []token.Token{
+		token.Add, token.Sub, token.Mul, token.Quo, token.Rem, token.And,
+		token.Or, token.Xor, token.AndNot, token.Shl, token.Shr, token.Less,
+		token.LessEq, token.Greater, token.GreaterEq,
+	} {
+		bc, err := Compile([]byte("param (x, y); return x "+tok.String()+" y"),
+			DefaultCompilerOptions)
+		require.NoError(t, err)
+
+		for _, x := range values {
+			for _, y := range values {
+				if tok == token.Shl || tok == token.Shr {
+					if v, ok := ToGoInt64(y); ok && v < 0 {
+						continue // Go panics for negative shift count
+					}
+				}
+
+				expected, expectedErr := x.BinaryOp(tok, y)
+				ret, err := NewVM(bc).Run(nil, x, y)
+				msg := fmt.Sprintf("%s(%v) %s %s(%v)",
+					x.TypeName(), x, tok, y.TypeName(), y)
+				if expectedErr != nil {
+					require.Error(t, err, msg)
+					require.True(t, errors.Is(err, expectedErr) ||
+						strings.Contains(err.Error(), expectedErr.Error()), msg)
+					continue
+				}
+				require.NoError(t, err, msg)
+				require.Equal(t, expected, ret, msg)
+			}
+		}
+	}
+}
+
 func TestVMInteger(t *testing.T) {
 	expectRun(t, `return 5`, nil, Int(5))
 	expectRun(t, `return 10`, nil, Int(10))
@@ -2539,6 +5072,48 @@ func TestVMSourceModules(t *testing.T) {
 	`, newOpts().Module("mod1", `m2 := import("mod2"); m2.x = 2; return { x: 1, mod2: m2 }`).
 		Module("mod2", "m := { x: 0 }; return m"), True)
 
+	// __module__ is resolved to the path of the module being compiled
+	expectRun(t, `return __module__`, nil, String("(main)"))
+	expectRun(t, `return func() { return __module__ }()`, nil, String("(main)"))
+	expectRun(t, `__module__ := 1; return __module__`, nil, Int(1))
+	expectRun(t, `return [__module__, import("mod1")]`,
+		newOpts().Module("mod1", `return __module__`),
+		Array{String("(main)"), String("mod1")})
+	expectRun(t, `return [import("mod1"), import("mod2")]`,
+		newOpts().Module("mod1", `return func() { return __module__ }()`).
+			Module("mod2", `return [__module__, import("mod1")]`),
+		Array{String("mod1"), Array{String("mod2"), String("mod1")}})
+
+	opts := DefaultCompilerOptions
+	opts.ModulePath = "mymod"
+	opts.ModuleMap = NewModuleMap().
+		AddSourceModule("mod1", []byte(`return __module__`))
+	bc, err := Compile([]byte(`return __module__ + ":" + import("mod1")`), opts)
+	require.NoError(t, err)
+	ret, err := NewVM(bc).Run(nil)
+	require.NoError(t, err)
+	require.Equal(t, String("mymod:mod1"), ret)
+}
+
+func TestVMPositionIdents(t *testing.T) {
+	expectRun(t, `return __line__`, nil, Int(1))
+	expectRun(t, `return __column__`, nil, Int(8))
+	expectRun(t, `return __file__`, nil, String("(main)"))
+	expectRun(t, `
+	a := 1
+	  x := [__line__, __column__]
+	return x`, nil, Array{Int(3), Int(20)})
+	expectRun(t, `
+	f := func() {
+		return __line__
+	}
+	return [f(), __line__]`, nil, Array{Int(3), Int(5)})
+	expectRun(t, `
+	return [__file__, __line__] +
+		import("mod1")`,
+		newOpts().Module("mod1", "\n\nreturn [__file__, __line__, __column__]"),
+		Array{String("(main)"), Int(2), String("mod1"), Int(3), Int(29)})
+	expectRun(t, `__line__ := "x"; return __line__`, nil, String("x"))
 }
 
 func TestVMUnary(t *testing.T) {
@@ -3177,6 +5752,58 @@ func TestVMCall(t *testing.T) {
 	expectErrIs(t, `global f; return f()`, newOpts().Globals(Map{"f": Undefined}),
 		ErrNotCallable)
 
+	typed := &Function{
+		Name: "typed",
+		Value: func(args ...Object) (Object, error) {
+			return Array(args), nil
+		},
+		Params: []FuncParam{
+			{Name: "a", Type: "int"},
+			{Name: "b", Type: "string|bytes"},
+			{Name: "c"},
+		},
+	}
+	typedEx := &Function{
+		Name:    "typedEx",
+		Value:   typed.Value,
+		ValueEx: func(c Call) (Object, error) { return Int(c.Len()), nil },
+		Params: []FuncParam{
+			{Name: "a", Type: "int"},
+			{Name: "rest", Type: "float", Variadic: true},
+		},
+	}
+	typedOpts := func() *testopts {
+		return newOpts().Globals(Map{"f": typed, "g": typedEx})
+	}
+	expectRun(t, `global f; return f(1, "a", undefined)`, typedOpts(),
+		Array{Int(1), String("a"), Undefined})
+	expectRun(t, `global f; return f(...[1, bytes(), 2])`, typedOpts(),
+		Array{Int(1), Bytes{}, Int(2)})
+	expectRun(t, `global f; return f(1, ...["a", []])`, typedOpts(),
+		Array{Int(1), String("a"), Array{}})
+	expectErrHas(t, `global f; return f(1, "a")`, typedOpts(), `want=3 got=2`)
+	expectErrIs(t, `global f; return f(...[1, "a", 2, 3])`, typedOpts(),
+		ErrWrongNumArguments)
+	expectErrHas(t, `global f; return f(...[1, "a", 2, 3])`, typedOpts(),
+		`want=3 got=4`)
+	expectErrIs(t, `global f; return f(1u, "a", 2)`, typedOpts(), ErrType)
+	expectErrHas(t, `global f; return f(1, ...[2, 3])`, typedOpts(),
+		`TypeError: invalid type for argument '2nd': `+
+			`expected string|bytes, found int`)
+	expectRun(t, `global g; return g(1)`, typedOpts(), Int(1))
+	expectRun(t, `global g; return g(1, ...[1.5, 2.5])`, typedOpts(), Int(3))
+	expectErrHas(t, `global g; return g(...[])`, typedOpts(), `want>=1 got=0`)
+	expectErrHas(t, `global g; return g(1, 1.5, 2)`, typedOpts(),
+		`TypeError: invalid type for argument '3rd': expected float, found int`)
+	// metadata is validated when called from Go as well
+	_, err := typed.Call(Int(1))
+	require.True(t, errors.Is(err, ErrWrongNumArguments))
+	_, err = typedEx.Call(Int(1), String(""))
+	require.True(t, errors.Is(err, ErrType))
+	ret, err := typedEx.Call(Int(1), Float(1))
+	require.NoError(t, err)
+	require.Equal(t, Array{Int(1), Float(1)}, ret)
+
 	expectRun(t, `a := { b: func(x) { return x + 2 } }; return a.b(5)`, nil, Int(7))
 	expectRun(t, `a := { b: { c: func(x) { return x + 2 } } }; return a.b.c(5)`,
 		nil, Int(7))
@@ -3325,6 +5952,9 @@ type testopts struct {
 	skip2pass     bool
 	isCompilerErr bool
 	noPanic       bool
+	strict        bool
+	deterministic bool
+	copyOnAssign  bool
 }
 
 func newOpts() *testopts {
@@ -3351,6 +5981,21 @@ func (t *testopts) CompilerError() *testopts {
 	return t
 }
 
+func (t *testopts) StrictComparison() *testopts {
+	t.strict = true
+	return t
+}
+
+func (t *testopts) DeterministicMaps() *testopts {
+	t.deterministic = true
+	return t
+}
+
+func (t *testopts) CopyOnAssign() *testopts {
+	t.copyOnAssign = true
+	return t
+}
+
 func (t *testopts) NoPanic() *testopts {
 	t.noPanic = true
 	return t
@@ -3436,20 +6081,26 @@ func expectErrorGen(
 		{
 			name: "default",
 			opts: CompilerOptions{
-				ModuleMap:      opts.moduleMap,
-				OptimizeConst:  true,
-				TraceParser:    true,
-				TraceOptimizer: true,
-				TraceCompiler:  true,
+				ModuleMap:         opts.moduleMap,
+				OptimizeConst:     true,
+				StrictComparison:  opts.strict,
+				DeterministicMaps: opts.deterministic,
+				CopyOnAssign:      opts.copyOnAssign,
+				TraceParser:       true,
+				TraceOptimizer:    true,
+				TraceCompiler:     true,
 			},
 		},
 		{
 			name: "unoptimized",
 			opts: CompilerOptions{
-				ModuleMap:      opts.moduleMap,
-				TraceParser:    true,
-				TraceOptimizer: true,
-				TraceCompiler:  true,
+				ModuleMap:         opts.moduleMap,
+				StrictComparison:  opts.strict,
+				DeterministicMaps: opts.deterministic,
+				CopyOnAssign:      opts.copyOnAssign,
+				TraceParser:       true,
+				TraceOptimizer:    true,
+				TraceCompiler:     true,
 			},
 		},
 	}
@@ -3488,20 +6139,26 @@ func expectRun(t *testing.T, script string, opts *testopts, expect Object) {
 		{
 			name: "default",
 			opts: CompilerOptions{
-				ModuleMap:      opts.moduleMap,
-				OptimizeConst:  true,
-				TraceParser:    true,
-				TraceOptimizer: true,
-				TraceCompiler:  true,
+				ModuleMap:         opts.moduleMap,
+				OptimizeConst:     true,
+				StrictComparison:  opts.strict,
+				DeterministicMaps: opts.deterministic,
+				CopyOnAssign:      opts.copyOnAssign,
+				TraceParser:       true,
+				TraceOptimizer:    true,
+				TraceCompiler:     true,
 			},
 		},
 		{
 			name: "unoptimized",
 			opts: CompilerOptions{
-				ModuleMap:      opts.moduleMap,
-				TraceParser:    true,
-				TraceOptimizer: true,
-				TraceCompiler:  true,
+				ModuleMap:         opts.moduleMap,
+				StrictComparison:  opts.strict,
+				DeterministicMaps: opts.deterministic,
+				CopyOnAssign:      opts.copyOnAssign,
+				TraceParser:       true,
+				TraceOptimizer:    true,
+				TraceCompiler:     true,
 			},
 		},
 	}
@@ -3518,7 +6175,7 @@ func expectRun(t *testing.T, script string, opts *testopts, expect Object) {
 			// change after execution
 			expectBc := *gotBc
 			expectBc.Main = gotBc.Main.Copy().(*CompiledFunction)
-			expectBc.Constants = Array(gotBc.Constants).Copy().(Array)
+			expectBc.Constants = Array(gotBc.Constants).DeepCopy().(Array)
 			vm := NewVM(gotBc)
 			defer func() {
 				if r := recover(); r != nil {
@@ -3545,3 +6202,141 @@ func expectRun(t *testing.T, script string, opts *testopts, expect Object) {
 		})
 	}
 }
+
+func BenchmarkVMIntSum(b *testing.B) {
+	bc, err := Compile([]byte(`
+	sum := 0
+	for i := 0; i < 10000; i++ {
+		if i % 3 == 0 {
+			sum += i
+		}
+	}
+	return sum`), DefaultCompilerOptions)
+	if err != nil {
+		b.Fatal(err)
+	}
+	vm := NewVM(bc)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := vm.Run(nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func TestVMDecodedInstructions(t *testing.T) {
+	// more than 256 constants and long jumps require both bytes of 2-byte
+	// operands to be decoded.
+	var sb strings.Builder
+	sb.WriteString("sum := 0\nf := func(x) { return x * 2 }\n")
+	sb.WriteString("for i := 0; i < 3; i++ {\n")
+	for i := 0; i < 300; i++ {
+		fmt.Fprintf(&sb, "\tsum += %d.5 < 0 ? 0 : f(%d)\n", i, i)
+	}
+	sb.WriteString("}\nreturn sum")
+	expectRun(t, sb.String(), nil, Int(3*300*299))
+
+	expectRun(t, `
+	counter := func() {
+		n := 0
+		return func() { n++; return n }
+	}
+	a := counter()
+	b := counter()
+	a(); a(); b()
+	return [a(), b()]`, nil, Array{Int(3), Int(2)})
+	expectRun(t, `
+	var fib
+	fib = func(n) {
+		if n < 2 { return n }
+		return fib(n-1) + fib(n-2)
+	}
+	return fib(15)`, nil, Int(610))
+	expectRun(t, `
+	f := func(a, ...b) {
+		try {
+			throw a
+		} catch err {
+			return [err.Message, len(b)]
+		} finally {
+			b = undefined
+		}
+	}
+	return f("x", 1, 2)`, nil, Array{String("x"), Int(2)})
+
+	bc, err := Compile([]byte(`
+	return func(n) {
+		s := 0
+		for i := 0; i < n; i++ { s += i }
+		return s
+	}`), DefaultCompilerOptions)
+	require.NoError(t, err)
+	vm := NewVM(bc)
+	fn, err := vm.Run(nil)
+	require.NoError(t, err)
+	for _, n := range []int{5, 100} {
+		ret, err := vm.RunCompiledFunction(fn.(*CompiledFunction), nil, Int(n))
+		require.NoError(t, err)
+		require.Equal(t, Int(n*(n-1)/2), ret)
+	}
+}
+
+func BenchmarkVMHotLoop(b *testing.B) {
+	bc, err := Compile([]byte(`
+	arr := [1, 2, 3, 4, 5, 6, 7, 8]
+	add := func(a, b) { return a + b }
+	sum := 0
+	for i := 0; i < 2000; i++ {
+		v := arr[i % len(arr)]
+		if v > 4 && i % 2 == 0 {
+			sum = add(sum, v)
+		} else {
+			sum -= 1
+		}
+	}
+	return sum`), DefaultCompilerOptions)
+	if err != nil {
+		b.Fatal(err)
+	}
+	vm := NewVM(bc)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := vm.Run(nil); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkVMForeignFunction(b *testing.B) {
+	fbc, err := Compile([]byte(`return func(a, b) { return a + b }`),
+		DefaultCompilerOptions)
+	if err != nil {
+		b.Fatal(err)
+	}
+	add, err := NewVM(fbc).Run(nil)
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	bc, err := Compile([]byte(`
+	global add
+	sum := 0
+	for i := 0; i < 2000; i++ {
+		sum = add(sum, i)
+	}
+	return sum`), DefaultCompilerOptions)
+	if err != nil {
+		b.Fatal(err)
+	}
+	vm := NewVM(bc)
+	globals := Map{"add": add}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := vm.Run(globals); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -79,6 +79,46 @@ func funcPiOROeEx(fn func(int, Object) (Object, error)) CallableExFunc {
 	}
 }
 
+// funcPOsROEx is a generated function to make CallableExFunc.
+// Source: func(o Object, k string) (ret Object)
+func funcPOsROEx(fn func(Object, string) Object) CallableExFunc {
+	return func(args Call) (ret Object, err error) {
+		if err := args.CheckLen(2); err != nil {
+			return Undefined, err
+		}
+
+		o := args.Get(0)
+		k, ok := ToGoString(args.Get(1))
+		if !ok {
+			return Undefined, NewArgumentTypeError("2nd", "string", args.Get(1).TypeName())
+		}
+
+		ret = fn(o, k)
+		return
+	}
+}
+
+// funcPOsOReEx is a generated function to make CallableExFunc.
+// Source: func(o Object, k string, v Object) (err error)
+func funcPOsOReEx(fn func(Object, string, Object) error) CallableExFunc {
+	return func(args Call) (ret Object, err error) {
+		if err := args.CheckLen(3); err != nil {
+			return Undefined, err
+		}
+
+		o := args.Get(0)
+		k, ok := ToGoString(args.Get(1))
+		if !ok {
+			return Undefined, NewArgumentTypeError("2nd", "string", args.Get(1).TypeName())
+		}
+		v := args.Get(2)
+
+		err = fn(o, k, v)
+		ret = Undefined
+		return
+	}
+}
+
 // funcPOOROeEx is a generated function to make CallableExFunc.
 // Source: func(o Object, v Object) (ret Object, err error)
 func funcPOOROeEx(fn func(Object, Object) (Object, error)) CallableExFunc {
@@ -95,6 +135,22 @@ func funcPOOROeEx(fn func(Object, Object) (Object, error)) CallableExFunc {
 	}
 }
 
+// funcPOOROEx is a generated function to make CallableExFunc.
+// Source: func(o Object, v Object) (ret Object)
+func funcPOOROEx(fn func(Object, Object) Object) CallableExFunc {
+	return func(args Call) (ret Object, err error) {
+		if err := args.CheckLen(2); err != nil {
+			return Undefined, err
+		}
+
+		o := args.Get(0)
+		v := args.Get(1)
+
+		ret = fn(o, v)
+		return
+	}
+}
+
 // funcPOROeEx is a generated function to make CallableExFunc.
 // Source: func(o Object) (ret Object, err error)
 func funcPOROeEx(fn func(Object) (Object, error)) CallableExFunc {
@@ -237,6 +293,46 @@ func funcPiOROe(fn func(int, Object) (Object, error)) CallableFunc {
 	}
 }
 
+// funcPOsRO is a generated function to make CallableFunc.
+// Source: func(o Object, k string) (ret Object)
+func funcPOsRO(fn func(Object, string) Object) CallableFunc {
+	return func(args ...Object) (ret Object, err error) {
+		if len(args) != 2 {
+			return Undefined, ErrWrongNumArguments.NewError("want=2 got=" + strconv.Itoa(len(args)))
+		}
+
+		o := args[0]
+		k, ok := ToGoString(args[1])
+		if !ok {
+			return Undefined, NewArgumentTypeError("2nd", "string", args[1].TypeName())
+		}
+
+		ret = fn(o, k)
+		return
+	}
+}
+
+// funcPOsORe is a generated function to make CallableFunc.
+// Source: func(o Object, k string, v Object) (err error)
+func funcPOsORe(fn func(Object, string, Object) error) CallableFunc {
+	return func(args ...Object) (ret Object, err error) {
+		if len(args) != 3 {
+			return Undefined, ErrWrongNumArguments.NewError("want=3 got=" + strconv.Itoa(len(args)))
+		}
+
+		o := args[0]
+		k, ok := ToGoString(args[1])
+		if !ok {
+			return Undefined, NewArgumentTypeError("2nd", "string", args[1].TypeName())
+		}
+		v := args[2]
+
+		err = fn(o, k, v)
+		ret = Undefined
+		return
+	}
+}
+
 // funcPOOROe is a generated function to make CallableFunc.
 // Source: func(o Object, v Object) (ret Object, err error)
 func funcPOOROe(fn func(Object, Object) (Object, error)) CallableFunc {
@@ -253,6 +349,22 @@ func funcPOOROe(fn func(Object, Object) (Object, error)) CallableFunc {
 	}
 }
 
+// funcPOORO is a generated function to make CallableFunc.
+// Source: func(o Object, v Object) (ret Object)
+func funcPOORO(fn func(Object, Object) Object) CallableFunc {
+	return func(args ...Object) (ret Object, err error) {
+		if len(args) != 2 {
+			return Undefined, ErrWrongNumArguments.NewError("want=2 got=" + strconv.Itoa(len(args)))
+		}
+
+		o := args[0]
+		v := args[1]
+
+		ret = fn(o, v)
+		return
+	}
+}
+
 // funcPOROe is a generated function to make CallableFunc.
 // Source: func(o Object) (ret Object, err error)
 func funcPOROe(fn func(Object) (Object, error)) CallableFunc {